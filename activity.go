@@ -0,0 +1,191 @@
+package pop
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// ActiveQuery is a query running on the database server, as reported by
+// `ActiveQueries`.
+type ActiveQuery struct {
+	// PID identifies the backend, or the MySQL connection, running the query
+	PID int64
+	// User is the database user running the query
+	User string
+	// Database is the database the query runs against
+	Database string
+	// State is what the backend is doing, such as "active" or "Sending data"
+	State string
+	// Query is the text of the query
+	Query string
+	// Duration is how long the query has been running
+	Duration time.Duration
+}
+
+// Blocker is a query waiting on a lock held by another one, as reported by
+// `Blockers`.
+type Blocker struct {
+	// BlockedPID identifies the backend waiting on the lock
+	BlockedPID int64
+	// BlockedQuery is the query waiting on the lock
+	BlockedQuery string
+	// BlockingPID identifies the backend holding the lock
+	BlockingPID int64
+	// BlockingQuery is the last query of the backend holding the lock
+	BlockingQuery string
+	// Waiting is how long the blocked query has been waiting
+	Waiting time.Duration
+}
+
+// activityRow is the row shape shared by the dialect queries of
+// `ActiveQueries`.
+type activityRow struct {
+	PID      int64   `db:"pid"`
+	User     string  `db:"user_name"`
+	Database string  `db:"database_name"`
+	State    string  `db:"state"`
+	Query    string  `db:"query"`
+	Seconds  float64 `db:"seconds"`
+}
+
+// blockerRow is the row shape shared by the dialect queries of `Blockers`.
+type blockerRow struct {
+	BlockedPID    int64   `db:"blocked_pid"`
+	BlockedQuery  string  `db:"blocked_query"`
+	BlockingPID   int64   `db:"blocking_pid"`
+	BlockingQuery string  `db:"blocking_query"`
+	Seconds       float64 `db:"seconds"`
+}
+
+// activityMonitor is implemented by the dialects able to list and cancel
+// the queries running on the server.
+type activityMonitor interface {
+	activeQueries(ctx context.Context, db *sqlx.DB) ([]ActiveQuery, error)
+	blockers(ctx context.Context, db *sqlx.DB) ([]Blocker, error)
+	cancelQuery(ctx context.Context, db *sqlx.DB, pid int64) error
+}
+
+// ActiveQueries lists the queries running on the database server, the
+// longest running first, from pg_stat_activity on PostgreSQL and from the
+// process list on MySQL. Idle connections, and the one running the
+// listing, are left out:
+//
+//	queries, err := c.ActiveQueries(ctx)
+//	for _, q := range queries {
+//		if q.Duration > time.Minute {
+//			c.CancelQuery(q.PID)
+//		}
+//	}
+func (c *Connection) ActiveQueries(ctx context.Context) ([]ActiveQuery, error) {
+	am, db, err := c.activityMonitor()
+	if err != nil {
+		return nil, err
+	}
+	return am.activeQueries(ctx, db)
+}
+
+// Blockers lists the queries waiting on a lock, along with the backend
+// holding it. It uses pg_blocking_pids on PostgreSQL, and the
+// sys.innodb_lock_waits view on MySQL.
+func (c *Connection) Blockers(ctx context.Context) ([]Blocker, error) {
+	am, db, err := c.activityMonitor()
+	if err != nil {
+		return nil, err
+	}
+	return am.blockers(ctx, db)
+}
+
+// CancelQuery cancels the query running on the backend pid, as returned by
+// `ActiveQueries` or `Blockers`. The connection itself is left open.
+func (c *Connection) CancelQuery(pid int64) error {
+	am, db, err := c.activityMonitor()
+	if err != nil {
+		return err
+	}
+	return am.cancelQuery(context.Background(), db, pid)
+}
+
+// activityMonitor returns the dialect monitor of the connection, and the
+// primary database to ask.
+func (c *Connection) activityMonitor() (activityMonitor, *sqlx.DB, error) {
+	am, ok := c.Dialect.(activityMonitor)
+	if !ok {
+		return nil, nil, errors.Errorf("%s does not support query monitoring", c.Dialect.Details().Dialect)
+	}
+	if c.TX != nil {
+		return nil, nil, errors.New("query monitoring can not run in a transaction")
+	}
+	db := c.primaryDB()
+	if db == nil {
+		return nil, nil, errors.New("the connection is not open")
+	}
+	return am, db, nil
+}
+
+// primaryDB unwraps the store of the connection down to the database of the
+// primary, or returns nil if it is not open.
+func (c *Connection) primaryDB() *sqlx.DB {
+	s := c.Store
+	if is, ok := s.(*instrumentedStore); ok {
+		s = is.store
+	}
+	if rs, ok := s.(*routedStore); ok {
+		s = rs.store
+	}
+	if db, ok := s.(*dB); ok {
+		return db.DB
+	}
+	return nil
+}
+
+func (r activityRow) activeQuery() ActiveQuery {
+	return ActiveQuery{
+		PID:      r.PID,
+		User:     r.User,
+		Database: r.Database,
+		State:    r.State,
+		Query:    r.Query,
+		Duration: time.Duration(r.Seconds * float64(time.Second)),
+	}
+}
+
+func (r blockerRow) blocker() Blocker {
+	return Blocker{
+		BlockedPID:    r.BlockedPID,
+		BlockedQuery:  r.BlockedQuery,
+		BlockingPID:   r.BlockingPID,
+		BlockingQuery: r.BlockingQuery,
+		Waiting:       time.Duration(r.Seconds * float64(time.Second)),
+	}
+}
+
+// selectActivity runs a dialect query returning activityRows.
+func selectActivity(ctx context.Context, db *sqlx.DB, query string) ([]ActiveQuery, error) {
+	logSQL(nil, query)
+	rows := []activityRow{}
+	if err := db.SelectContext(ctx, &rows, query); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	queries := make([]ActiveQuery, 0, len(rows))
+	for _, r := range rows {
+		queries = append(queries, r.activeQuery())
+	}
+	return queries, nil
+}
+
+// selectBlockers runs a dialect query returning blockerRows.
+func selectBlockers(ctx context.Context, db *sqlx.DB, query string) ([]Blocker, error) {
+	logSQL(nil, query)
+	rows := []blockerRow{}
+	if err := db.SelectContext(ctx, &rows, query); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	blockers := make([]Blocker, 0, len(rows))
+	for _, r := range rows {
+		blockers = append(blockers, r.blocker())
+	}
+	return blockers, nil
+}
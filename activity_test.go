@@ -0,0 +1,22 @@
+package pop
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_activityRow_activeQuery(t *testing.T) {
+	r := require.New(t)
+
+	q := activityRow{PID: 42, User: "app", Database: "pop_test", State: "active", Query: "SELECT 1", Seconds: 1.5}.activeQuery()
+	r.Equal(int64(42), q.PID)
+	r.Equal("pop_test", q.Database)
+	r.Equal(1500*time.Millisecond, q.Duration)
+
+	b := blockerRow{BlockedPID: 1, BlockingPID: 2, BlockingQuery: "UPDATE users", Seconds: 2}.blocker()
+	r.Equal(int64(2), b.BlockingPID)
+	r.Equal("UPDATE users", b.BlockingQuery)
+	r.Equal(2*time.Second, b.Waiting)
+}
@@ -0,0 +1,50 @@
+package pop
+
+import (
+	"github.com/pkg/errors"
+)
+
+// applicationNamer is implemented by the dialects able to change the
+// application name for the rest of a transaction.
+type applicationNamer interface {
+	setApplicationName(c *Connection, name string) error
+}
+
+// queryTagger is implemented by the dialects which report the application
+// name by tagging the statements, instead of setting it on the session.
+type queryTagger interface {
+	tagQuery(name string, query string) string
+}
+
+// SetApplicationName overrides, for the rest of the transaction, the
+// application name set with the "application_name" option, so the jobs of
+// a service can be told apart in pg_stat_activity or the process list:
+//
+//	err := c.Transaction(func(tx *pop.Connection) error {
+//		if err := tx.SetApplicationName("billing-worker"); err != nil {
+//			return err
+//		}
+//		return tx.Create(&invoice)
+//	})
+//
+// Outside of a transaction, the name would stick to a random connection of
+// the pool, so an error is returned.
+func (c *Connection) SetApplicationName(name string) error {
+	an, ok := c.Dialect.(applicationNamer)
+	if !ok {
+		return errors.Errorf("%s does not support application names", c.Dialect.Details().Dialect)
+	}
+	if c.TX == nil {
+		return errors.New("the application name can only be overridden in a transaction")
+	}
+	return an.setApplicationName(c, name)
+}
+
+// applicationName returns the application name of the connection: the one
+// set on the transaction, or the one of its details.
+func (c *Connection) applicationName() string {
+	if c.appName != "" {
+		return c.appName
+	}
+	return c.Dialect.Details().ApplicationName()
+}
@@ -0,0 +1,46 @@
+package pop
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_withApplicationName(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal("postgres://u:p@h:5432/db?sslmode=disable&application_name=billing+worker", withApplicationName("postgres://u:p@h:5432/db?sslmode=disable", "billing worker"))
+	r.Equal("postgres://h/db?application_name=api", withApplicationName("postgres://h/db", "api"))
+	r.Equal("postgres://h/db?application_name=other", withApplicationName("postgres://h/db?application_name=other", "api"))
+	r.Equal("host=h dbname=db application_name='api'", withApplicationName("host=h dbname=db", "api"))
+	r.Equal("postgres://h/db", withApplicationName("postgres://h/db", ""))
+}
+
+func Test_ApplicationName_URL(t *testing.T) {
+	r := require.New(t)
+
+	cd := &ConnectionDetails{Dialect: "postgres", Database: "db", Host: "h", Port: "5432", User: "u", Options: map[string]string{"application_name": "api"}}
+	r.Equal("api", cd.ApplicationName())
+	r.Contains((&postgresql{ConnectionDetails: cd}).URL(), "&application_name=api")
+
+	cd = &ConnectionDetails{Dialect: "cockroach", Database: "db", Options: map[string]string{}}
+	r.Contains((&cockroach{ConnectionDetails: cd}).URL(), "application_name=cockroach")
+	cd.Options["program_name"] = "api"
+	r.Contains((&cockroach{ConnectionDetails: cd}).URL(), "application_name=api")
+}
+
+func Test_ApplicationName_MySQLTag(t *testing.T) {
+	r := require.New(t)
+
+	m := &mysql{ConnectionDetails: &ConnectionDetails{Dialect: "mysql", Options: map[string]string{"program_name": "api"}}}
+	c := &Connection{Dialect: m}
+	s := &instrumentedStore{conn: c}
+	r.Equal("/* api */ SELECT 1", s.tag("SELECT 1"))
+	r.True(readQuery.MatchString(s.tag("SELECT 1")))
+
+	c.appName = "worker*/"
+	r.Equal("/* worker */ SELECT 1", s.tag("SELECT 1"))
+
+	s = &instrumentedStore{conn: &Connection{Dialect: &postgresql{ConnectionDetails: m.ConnectionDetails}}}
+	r.Equal("SELECT 1", s.tag("SELECT 1"))
+}
@@ -61,3 +61,15 @@ func fieldIsNil(f reflect.Value) bool {
 	}
 	return f.Interface() == nil
 }
+
+// IsAssociationField validates if a struct field is tagged
+// as one of the known association types.
+func IsAssociationField(f reflect.StructField) bool {
+	tags := columns.TagsFor(f)
+	for name := range associationBuilders {
+		if !tags.Find(name).Empty() {
+			return true
+		}
+	}
+	return false
+}
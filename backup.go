@@ -0,0 +1,307 @@
+package pop
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// backupHeader starts the backups written in the native format.
+const backupHeader = `{"pop_backup":1`
+
+// BackupOptions tunes `Backup`.
+type BackupOptions struct {
+	// Tables restricts the backup to these tables, all of them by default
+	Tables []string
+	// Native skips pg_dump and mysqldump, and always writes the native format
+	Native bool
+}
+
+// backuper is implemented by the dialects having a dump tool, such as
+// pg_dump.
+type backuper interface {
+	backupCommand(tables []string) *exec.Cmd
+}
+
+// tableLister is implemented by the dialects able to list their tables.
+type tableLister interface {
+	tableNames(c *Connection) ([]string, error)
+}
+
+// backupLine is a line of a native backup: the header, the start of a
+// table, or a row of the current table.
+type backupLine struct {
+	Version int           `json:"pop_backup,omitempty"`
+	Dialect string        `json:"dialect,omitempty"`
+	Table   string        `json:"table,omitempty"`
+	Columns []string      `json:"columns,omitempty"`
+	Values  []interface{} `json:"values,omitempty"`
+}
+
+// Backup writes a backup of the database to w. It shells out to pg_dump
+// on PostgreSQL and to mysqldump on MySQL when they are installed, and
+// otherwise exports the rows in the native format of pop, one JSON line
+// per row. The native format holds the rows only, the schema is expected
+// to come from the migrations:
+//
+//	f, err := os.Create("backup.sql")
+//	err = c.Backup(f, pop.BackupOptions{Tables: []string{"users"}})
+func (c *Connection) Backup(w io.Writer, opts BackupOptions) error {
+	pr := c.newProgress("Backup", 0)
+	w = pr.writer(w)
+	if b, ok := c.Dialect.(backuper); ok && !opts.Native {
+		cmd := b.backupCommand(opts.Tables)
+		if _, err := exec.LookPath(cmd.Path); err == nil {
+			logMessage(nil, LogInfo, strings.Join(cmd.Args, " "))
+			cmd.Stdout = w
+			cmd.Stderr = os.Stderr
+			if err := cmd.Run(); err != nil {
+				return errors.WithStack(err)
+			}
+			pr.done()
+			return nil
+		}
+	}
+	if err := c.nativeBackup(w, opts.Tables, pr); err != nil {
+		return err
+	}
+	pr.done()
+	return nil
+}
+
+// Restore loads a backup written by `Backup`. Native backups replace the
+// rows of the tables they hold, in a single transaction; the other ones
+// are handed to psql or mysql. The foreign keys are only checked once
+// all of the rows are restored, when the database allows it, and the
+// PostgreSQL sequences are moved past the restored ids.
+func (c *Connection) Restore(r io.Reader) error {
+	pr := c.newProgress("Restore", 0)
+	br := bufio.NewReader(pr.reader(r))
+	head, _ := br.Peek(len(backupHeader))
+	var err error
+	if string(head) == backupHeader {
+		err = c.Transaction(func(tx *Connection) error {
+			return tx.nativeRestore(br, pr)
+		})
+	} else {
+		err = c.Dialect.LoadSchema(br)
+	}
+	if err != nil {
+		return err
+	}
+	pr.done()
+	return nil
+}
+
+func (c *Connection) nativeBackup(w io.Writer, tables []string, pr *progressReporter) error {
+	if len(tables) == 0 {
+		tl, ok := c.Dialect.(tableLister)
+		if !ok {
+			return errors.Errorf("%s can not list its tables, give them to the backup", c.Dialect.Details().Dialect)
+		}
+		var err error
+		if tables, err = tl.tableNames(c); err != nil {
+			return err
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(backupLine{Version: 1, Dialect: c.Dialect.Details().Dialect}); err != nil {
+		return errors.WithStack(err)
+	}
+	for _, t := range tables {
+		pr.p.Table = t
+		if err := c.backupTable(enc, t, pr); err != nil {
+			return errors.Wrapf(err, "could not back up %s", t)
+		}
+		pr.report()
+	}
+	return nil
+}
+
+func (c *Connection) backupTable(enc *json.Encoder, table string, pr *progressReporter) error {
+	query := fmt.Sprintf("SELECT * FROM %s", c.Dialect.Quote(table))
+	rows, err := c.Store.Queryx(query)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer rows.Close()
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	cols := make([]string, len(types))
+	for i, ct := range types {
+		cols[i] = ct.Name()
+	}
+	if err := enc.Encode(backupLine{Table: table, Columns: cols}); err != nil {
+		return errors.WithStack(err)
+	}
+	for rows.Next() {
+		values, err := rows.SliceScan()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		for i, v := range values {
+			values[i] = encodeBackupValue(v, columnKind(types[i].DatabaseTypeName()))
+		}
+		if err := enc.Encode(backupLine{Values: values}); err != nil {
+			return errors.WithStack(err)
+		}
+		pr.add(1)
+	}
+	return errors.WithStack(rows.Err())
+}
+
+// nativeRestore replaces the rows of the tables of the backup, with the
+// foreign keys checked at the end of the transaction, or not at all, as
+// the tables come in any order.
+func (c *Connection) nativeRestore(r io.Reader, pr *progressReporter) (err error) {
+	enable, err := c.deferForeignKeys()
+	if err != nil {
+		return errors.Wrap(err, "could not defer the foreign keys")
+	}
+	if enable != "" {
+		defer func() {
+			if eerr := c.RawQuery(enable).Exec(); eerr != nil && err == nil {
+				err = errors.Wrap(eerr, "could not check the foreign keys again")
+			}
+		}()
+	}
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	var table, insert string
+	tables := []string{}
+	for {
+		line := backupLine{}
+		if err := dec.Decode(&line); err == io.EOF {
+			return c.resetSequences(tables)
+		} else if err != nil {
+			return errors.Wrap(err, "could not read the backup")
+		}
+		switch {
+		case line.Version != 0:
+			continue
+		case line.Table != "":
+			if table != "" {
+				pr.report()
+			}
+			table = line.Table
+			tables = append(tables, table)
+			pr.p.Table = table
+			if err := c.RawQuery(fmt.Sprintf("DELETE FROM %s", c.Dialect.Quote(table))).Exec(); err != nil {
+				return errors.Wrapf(err, "could not restore %s", table)
+			}
+			cols := make([]string, len(line.Columns))
+			for i, col := range line.Columns {
+				cols[i] = c.Dialect.Quote(col)
+			}
+			marks := strings.TrimSuffix(strings.Repeat("?, ", len(cols)), ", ")
+			insert = fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", c.Dialect.Quote(table), strings.Join(cols, ", "), marks)
+		case insert != "":
+			values := make([]interface{}, len(line.Values))
+			for i, v := range line.Values {
+				values[i] = decodeBackupValue(v)
+			}
+			if err := c.RawQuery(insert, values...).Exec(); err != nil {
+				return errors.Wrapf(err, "could not restore %s", table)
+			}
+			pr.add(1)
+		}
+	}
+}
+
+// deferForeignKeys defers the checks of the foreign keys of the restore
+// transaction to its commit, on SQLite and on PostgreSQL for DEFERRABLE
+// constraints, or turns them off, on MySQL and on PostgreSQL for the
+// superusers. It returns the statement turning them back on, if needed.
+func (c *Connection) deferForeignKeys() (string, error) {
+	d := c.Dialect.Details()
+	switch {
+	case d.Dialect == "sqlite3":
+		return "", c.RawQuery("PRAGMA defer_foreign_keys = ON").Exec()
+	case d.Dialect == "mysql":
+		return "SET FOREIGN_KEY_CHECKS = 1", c.RawQuery("SET FOREIGN_KEY_CHECKS = 0").Exec()
+	case d.Dialect == "postgres" && !d.Redshift():
+		super := false
+		if err := c.Store.Get(&super, "SELECT rolsuper FROM pg_roles WHERE rolname = current_user"); err != nil {
+			return "", err
+		}
+		if super {
+			// the triggers checking the foreign keys are not fired.
+			return "", c.RawQuery("SET LOCAL session_replication_role = replica").Exec()
+		}
+		return "", c.RawQuery("SET CONSTRAINTS ALL DEFERRED").Exec()
+	}
+	return "", nil
+}
+
+// resetSequences moves the sequences of the serial and identity columns
+// of the restored PostgreSQL tables past their restored values.
+func (c *Connection) resetSequences(tables []string) error {
+	d := c.Dialect.Details()
+	if d.Dialect != "postgres" || d.Redshift() {
+		return nil
+	}
+	for _, t := range tables {
+		cols := []string{}
+		err := c.RawQuery("SELECT column_name FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = ? AND (column_default LIKE 'nextval(%' OR is_identity = 'YES')", t).All(&cols)
+		if err != nil {
+			return errors.Wrapf(err, "could not find the sequences of %s", t)
+		}
+		for _, col := range cols {
+			query := fmt.Sprintf("SELECT setval(pg_get_serial_sequence(?, ?), COALESCE(MAX(%s), 1), MAX(%s) IS NOT NULL) FROM %s", c.Dialect.Quote(col), c.Dialect.Quote(col), c.Dialect.Quote(t))
+			if err := c.RawQuery(query, c.Dialect.Quote(t), col).Exec(); err != nil {
+				return errors.Wrapf(err, "could not reset the sequence of %s.%s", t, col)
+			}
+		}
+	}
+	return nil
+}
+
+// encodeBackupValue turns the values JSON can not round trip, bytes and
+// times, into tagged objects. Some drivers return text as bytes, so bytes
+// are only kept for binary columns, or when they are not valid UTF-8.
+func encodeBackupValue(v interface{}, kind string) interface{} {
+	switch t := v.(type) {
+	case []byte:
+		if kind != "bytes" && utf8.Valid(t) {
+			return string(t)
+		}
+		return map[string]string{"b": base64.StdEncoding.EncodeToString(t)}
+	case time.Time:
+		return map[string]string{"t": t.Format(time.RFC3339Nano)}
+	}
+	return v
+}
+
+// decodeBackupValue reverses encodeBackupValue.
+func decodeBackupValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i
+		}
+		f, _ := t.Float64()
+		return f
+	case map[string]interface{}:
+		if s, ok := t["b"].(string); ok {
+			b, _ := base64.StdEncoding.DecodeString(s)
+			return b
+		}
+		if s, ok := t["t"].(string); ok {
+			tm, _ := time.Parse(time.RFC3339Nano, s)
+			return tm
+		}
+	}
+	return v
+}
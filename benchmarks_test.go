@@ -10,6 +10,7 @@ import (
 )
 
 func Benchmark_Create_Pop(b *testing.B) {
+	b.ReportAllocs()
 	transaction(func(tx *pop.Connection) {
 		for n := 0; n < b.N; n++ {
 			u := &User{
@@ -45,6 +46,7 @@ func Benchmark_Update(b *testing.B) {
 }
 
 func Benchmark_Find_Pop(b *testing.B) {
+	b.ReportAllocs()
 	transaction(func(tx *pop.Connection) {
 		u := &User{
 			Name: nulls.NewString("Mark Bates"),
@@ -56,6 +58,42 @@ func Benchmark_Find_Pop(b *testing.B) {
 	})
 }
 
+func Benchmark_All_Pop(b *testing.B) {
+	b.ReportAllocs()
+	transaction(func(tx *pop.Connection) {
+		for i := 0; i < 10; i++ {
+			tx.Create(&User{Name: nulls.NewString("Mark Bates")})
+		}
+		b.ResetTimer()
+		for n := 0; n < b.N; n++ {
+			users := Users{}
+			tx.Where("name = ?", "Mark Bates").Order("id").All(&users)
+		}
+	})
+}
+
+func Benchmark_All_Pop_1000(b *testing.B) {
+	b.ReportAllocs()
+	transaction(func(tx *pop.Connection) {
+		for i := 0; i < 1000; i++ {
+			tx.Create(&Book{Title: "Pride and Prejudice", Isbn: "PB1", Description: "A novel"})
+		}
+		b.ResetTimer()
+		for n := 0; n < b.N; n++ {
+			books := Books{}
+			tx.All(&books)
+		}
+	})
+}
+
+func Benchmark_ToSQL(b *testing.B) {
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		q := PDB.Where("name = ?", "Mark Bates").Where("alive = ?", true).Order("id").Limit(10)
+		q.ToSQL(&pop.Model{Value: &User{}})
+	}
+}
+
 func Benchmark_Find_Raw(b *testing.B) {
 	transaction(func(tx *pop.Connection) {
 		u := &User{
@@ -1,6 +1,7 @@
 package pop_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/markbates/pop"
@@ -68,3 +69,30 @@ func Test_Callbacks_on_Slice(t *testing.T) {
 		}
 	})
 }
+
+func Test_Callbacks_Context(t *testing.T) {
+	transaction(func(tx *pop.Connection) {
+		r := require.New(t)
+
+		r.NotNil(tx.Context())
+		ctx := context.WithValue(context.Background(), currentUserKey{}, "mark")
+
+		user := &CallbacksUser{}
+		r.NoError(tx.WithContext(ctx).Create(user))
+		r.Equal("BeforeSave by mark", user.BeforeS)
+
+		r.NoError(tx.Update(user))
+		r.Equal("BeforeSave", user.BeforeS)
+	})
+}
+
+func Test_Callbacks_Context_Transaction(t *testing.T) {
+	r := require.New(t)
+
+	ctx := context.WithValue(context.Background(), currentUserKey{}, "mark")
+	err := PDB.WithContext(ctx).Rollback(func(tx *pop.Connection) {
+		r.Equal("mark", tx.Context().Value(currentUserKey{}))
+	})
+	r.NoError(err)
+	r.Nil(PDB.Context().Value(currentUserKey{}))
+}
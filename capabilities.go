@@ -0,0 +1,46 @@
+package pop
+
+// Capabilities lists the SQL features supported by a dialect, so code
+// built on pop can branch on what the database can do rather than on
+// its name:
+//
+//	if c.Dialect.Capabilities().Upsert {
+//		// INSERT ... ON CONFLICT / ON DUPLICATE KEY UPDATE
+//	}
+type Capabilities struct {
+	// Returning is true when INSERT, UPDATE and DELETE statements can
+	// return the rows they touched.
+	Returning bool
+	// Upsert is true when an INSERT can update the conflicting row.
+	Upsert bool
+	// CTE is true when WITH queries (common table expressions) are
+	// supported.
+	CTE bool
+	// Savepoints is true when SAVEPOINT can be used in a transaction.
+	Savepoints bool
+	// LateralJoins is true when a subquery of the FROM clause can
+	// reference the tables preceding it.
+	LateralJoins bool
+	// RowLocks is true when SELECT can lock the rows it reads, with
+	// FOR UPDATE or FOR SHARE.
+	RowLocks bool
+	// DistinctOn is true when SELECT DISTINCT ON can keep the first row
+	// of each group of rows.
+	DistinctOn bool
+}
+
+// serverCapabilities is implemented by the dialects whose capabilities
+// depend on the version of the server, which they ask with s.
+type serverCapabilities interface {
+	serverCapabilities(s store) Capabilities
+}
+
+// Capabilities returns the capabilities of the dialect of the connection.
+// On MySQL, MariaDB and TiDB, they depend on the version of the server,
+// which is asked the first time.
+func (c *Connection) Capabilities() Capabilities {
+	if sc, ok := c.Dialect.(serverCapabilities); ok && c.Store != nil {
+		return sc.serverCapabilities(c.Store)
+	}
+	return c.Dialect.Capabilities()
+}
@@ -0,0 +1,51 @@
+package pop
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Capabilities(t *testing.T) {
+	r := require.New(t)
+
+	pg := newPostgreSQL(&ConnectionDetails{Dialect: "postgres"}).Capabilities()
+	r.True(pg.Returning)
+	r.True(pg.Upsert)
+	r.True(pg.LateralJoins)
+	r.True(pg.RowLocks)
+	r.True(pg.DistinctOn)
+
+	rs := newRedshift(&ConnectionDetails{Dialect: "postgres"}).Capabilities()
+	r.Equal(Capabilities{CTE: true}, rs)
+
+	mcd := &ConnectionDetails{Dialect: "mysql", Options: map[string]string{}}
+	my := mysqlCapabilities(mcd, "8.0.22")
+	r.False(my.Returning)
+	r.True(my.Upsert)
+	r.True(my.CTE)
+	r.True(my.LateralJoins)
+	r.True(my.RowLocks)
+	r.False(my.DistinctOn)
+
+	my = mysqlCapabilities(mcd, "8.0.13")
+	r.True(my.CTE)
+	r.False(my.LateralJoins)
+	my = mysqlCapabilities(mcd, "5.7.31-log")
+	r.False(my.CTE)
+	r.False(my.LateralJoins)
+
+	// the version is unknown until the server is asked.
+	my = newMySQL(mcd).Capabilities()
+	r.False(my.CTE)
+	r.True(my.Upsert)
+
+	maria := mysqlCapabilities(&ConnectionDetails{Dialect: "mysql", Options: map[string]string{"mariadb": "true"}}, "10.5.8-MariaDB")
+	r.True(maria.CTE)
+	r.False(maria.LateralJoins)
+	r.True(maria.Savepoints)
+
+	tidb := &ConnectionDetails{Dialect: "mysql", Options: map[string]string{"tidb": "true"}}
+	r.True(mysqlCapabilities(tidb, "5.7.25-TiDB-v5.1.0").CTE)
+	r.False(mysqlCapabilities(tidb, "5.7.25-TiDB-v4.0.9").CTE)
+}
@@ -0,0 +1,108 @@
+package pop
+
+import (
+	"context"
+	"database/sql/driver"
+	"math/rand"
+	"sync"
+	"time"
+
+	_mysql "github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+// Chaos injects failures into the statements of a connection, so that
+// the retry and transaction logic of an application can be tested, see
+// `Connection.WithChaos`. The probabilities are between 0 and 1.
+type Chaos struct {
+	// Seed seeds the random source deciding which statements fail, so
+	// that a run can be replayed.
+	Seed int64
+	// Timeout is the probability of a statement failing with a
+	// `QueryTimeoutError`, as if it had run out of time.
+	Timeout float64
+	// Serialization is the probability of a statement failing with a
+	// serialization failure, a deadlock on MySQL, which
+	// `TransactionWithRetry` retries.
+	Serialization float64
+	// DroppedConnection is the probability of a statement failing with
+	// driver.ErrBadConn, as if the connection had been dropped.
+	DroppedConnection float64
+	// Latency is added to the statements, with the probability
+	// LatencyProbability, before they are sent.
+	Latency            time.Duration
+	LatencyProbability float64
+	// Match restricts the failures to the statements it returns true
+	// for. They can hit any statement when it is nil.
+	Match func(query string) bool
+
+	mu       sync.Mutex
+	rnd      *rand.Rand
+	injected int
+}
+
+// WithChaos returns a copy of the connection, and of the transactions it
+// starts, injecting the failures configured by ch into its statements:
+//
+//	ch := &pop.Chaos{Seed: 1, Serialization: 0.2}
+//	err := c.WithChaos(ch).TransactionWithRetry(func(tx *pop.Connection) error {
+//		return tx.Update(account)
+//	})
+//
+// The failures are drawn from a random source seeded with ch.Seed, so a
+// test running its statements in the same order fails the same way on
+// every run. It is meant for tests only.
+func (c *Connection) WithChaos(ch *Chaos) *Connection {
+	cn := *c
+	cn.chaos = ch
+	if is, ok := c.Store.(*instrumentedStore); ok {
+		cn.Store = newInstrumentedStore(&cn, is.store)
+	}
+	return &cn
+}
+
+// Injected returns the number of failures injected so far.
+func (ch *Chaos) Injected() int {
+	defer ch.mu.Unlock()
+	ch.mu.Lock()
+	return ch.injected
+}
+
+// fault returns the failure to inject into the statement query of the
+// connection c, if any, after sleeping for the latency drawn for it.
+func (ch *Chaos) fault(c *Connection, query string) error {
+	if ch.Match != nil && !ch.Match(query) {
+		return nil
+	}
+	ch.mu.Lock()
+	if ch.rnd == nil {
+		ch.rnd = rand.New(rand.NewSource(ch.Seed))
+	}
+	sleep := ch.Latency > 0 && ch.rnd.Float64() < ch.LatencyProbability
+	var err error
+	switch f := ch.rnd.Float64(); {
+	case f < ch.Timeout:
+		err = &QueryTimeoutError{Err: context.DeadlineExceeded}
+	case f < ch.Timeout+ch.Serialization:
+		err = serializationFailure(c)
+	case f < ch.Timeout+ch.Serialization+ch.DroppedConnection:
+		err = driver.ErrBadConn
+	}
+	if err != nil {
+		ch.injected++
+	}
+	ch.mu.Unlock()
+	if sleep {
+		time.Sleep(ch.Latency)
+	}
+	return err
+}
+
+// serializationFailure returns the error of a transaction conflicting with
+// another one, as returned by the driver of the dialect of c.
+func serializationFailure(c *Connection) error {
+	if _, ok := c.Dialect.(*mysql); ok {
+		return &_mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock; try restarting transaction"}
+	}
+	return &pq.Error{Code: "40001", Message: "could not serialize access due to concurrent update"}
+}
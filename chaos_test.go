@@ -0,0 +1,78 @@
+package pop_test
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/markbates/pop"
+	"github.com/markbates/pop/nulls"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Chaos_Serialization(t *testing.T) {
+	r := require.New(t)
+
+	inserts := 0
+	ch := &pop.Chaos{
+		Serialization: 1,
+		// the first two inserts fail
+		Match: func(query string) bool {
+			if !strings.HasPrefix(query, "INSERT") {
+				return false
+			}
+			inserts++
+			return inserts <= 2
+		},
+	}
+	attempts := 0
+	err := PDB.WithChaos(ch).TransactionWithRetry(func(tx *pop.Connection) error {
+		attempts++
+		return tx.Create(&User{Name: nulls.NewString("Chaos")})
+	})
+	r.NoError(err)
+	r.Equal(2, ch.Injected())
+	r.Equal(3, attempts)
+
+	users := Users{}
+	r.NoError(PDB.Where("name = ?", "Chaos").All(&users))
+	r.Len(users, 1)
+	r.NoError(PDB.Destroy(&users[0]))
+}
+
+func Test_Chaos_Deterministic(t *testing.T) {
+	r := require.New(t)
+
+	failures := func() []bool {
+		c := PDB.WithChaos(&pop.Chaos{Seed: 42, DroppedConnection: 0.5})
+		res := []bool{}
+		for i := 0; i < 20; i++ {
+			_, err := c.Count(&User{})
+			res = append(res, err != nil)
+		}
+		return res
+	}
+	r.Equal(failures(), failures())
+	r.Contains(failures(), true)
+	r.Contains(failures(), false)
+}
+
+func Test_Chaos_Faults(t *testing.T) {
+	r := require.New(t)
+
+	_, err := PDB.WithChaos(&pop.Chaos{Timeout: 1}).Count(&User{})
+	r.True(pop.IsQueryTimeout(err))
+
+	_, err = PDB.WithChaos(&pop.Chaos{DroppedConnection: 1}).Count(&User{})
+	r.Equal(driver.ErrBadConn, errors.Cause(err))
+
+	start := time.Now()
+	_, err = PDB.WithChaos(&pop.Chaos{Latency: 20 * time.Millisecond, LatencyProbability: 1}).Count(&User{})
+	r.NoError(err)
+	r.True(time.Since(start) >= 20*time.Millisecond)
+
+	_, err = PDB.Count(&User{})
+	r.NoError(err)
+}
@@ -30,13 +30,17 @@ func (c clauses) Args() (args []interface{}) {
 }
 
 type fromClause struct {
-	From string
-	As   string
+	From       string
+	As         string
+	IndexHints string
 }
 
 type fromClauses []fromClause
 
 func (c fromClause) String() string {
+	if c.IndexHints != "" {
+		return fmt.Sprintf("%s AS %s %s", c.From, c.As, c.IndexHints)
+	}
 	return fmt.Sprintf("%s AS %s", c.From, c.As)
 }
 
@@ -6,9 +6,7 @@ import (
 	"io"
 	"os"
 	"os/exec"
-	"strconv"
 	"strings"
-	"sync"
 
 	"github.com/jmoiron/sqlx"
 	// Load CockroachdbQL Go driver
@@ -25,8 +23,7 @@ import (
 var _ dialect = &cockroach{}
 
 type cockroach struct {
-	translateCache    map[string]string
-	mu                sync.Mutex
+	translateCache    *rebindCache
 	ConnectionDetails *ConnectionDetails
 }
 
@@ -34,6 +31,12 @@ func (p *cockroach) Details() *ConnectionDetails {
 	return p.ConnectionDetails
 }
 
+// Quote quotes the identifier key, e.g. "users.name", if it is a reserved
+// word or if the "quote_identifiers" option is set.
+func (p *cockroach) Quote(key string) string {
+	return quoteIdentifier(p.ConnectionDetails, `"`, `"`, key)
+}
+
 func (p *cockroach) Create(s store, model *Model, cols columns.Columns) error {
 	keyType := model.PrimaryKeyType()
 	switch keyType {
@@ -43,30 +46,43 @@ func (p *cockroach) Create(s store, model *Model, cols columns.Columns) error {
 			ID int `db:"id"`
 		}{}
 		w := cols.Writeable()
-		query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) returning id", model.TableName(), w.String(), w.SymbolizedString())
-		Log(query)
-		stmt, err := s.PrepareNamed(query)
-		if err != nil {
-			return errors.WithStack(err)
-		}
-		err = stmt.Get(&id, model.Value)
+		query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) returning id", p.Quote(model.TableName()), w.QuotedString(p.Quote), w.SymbolizedString())
+		err := namedGet(s, &id, query, model.Value)
 		if err != nil {
 			return errors.WithStack(err)
 		}
 		model.setID(id.ID)
 		return nil
-	case "UUID":
-		return genericCreate(s, model, cols)
+	case "UUID", "string":
+		return genericCreate(s, model, cols, p)
 	}
 	return errors.Errorf("can not use %s as a primary key type!", keyType)
 }
 
+func (p *cockroach) upsert(s store, model *Model, cols, update, conflict []string) error {
+	return onConflictUpsert(s, model, cols, update, conflict, p)
+}
+
 func (p *cockroach) Update(s store, model *Model, cols columns.Columns) error {
-	return genericUpdate(s, model, cols)
+	return genericUpdate(s, model, cols, p)
 }
 
 func (p *cockroach) Destroy(s store, model *Model) error {
-	return genericDestroy(s, model)
+	return genericDestroy(s, model, p)
+}
+
+func (p *cockroach) nextSequenceValue(s store, name string) (int64, error) {
+	var n int64
+	query := "SELECT nextval($1)"
+	err := s.Get(&n, query, name)
+	return n, err
+}
+
+func (p *cockroach) reserveSequenceValues(s store, name string, n int) ([]int64, error) {
+	ns := []int64{}
+	query := "SELECT nextval($1) FROM generate_series(1, $2)"
+	err := s.Select(&ns, query, name, n)
+	return ns, err
 }
 
 func (p *cockroach) SelectOne(s store, model *Model, query Query) error {
@@ -86,7 +102,7 @@ func (p *cockroach) CreateDB() error {
 	}
 	defer db.Close()
 	query := fmt.Sprintf("CREATE DATABASE \"%s\"", deets.Database)
-	Log(query)
+	logSQL(nil, query)
 
 	_, err = db.Exec(query)
 	if err != nil {
@@ -105,7 +121,7 @@ func (p *cockroach) DropDB() error {
 	}
 	defer db.Close()
 	query := fmt.Sprintf("DROP DATABASE \"%s\" CASCADE;", deets.Database)
-	Log(query)
+	logSQL(nil, query)
 
 	_, err = db.Exec(query)
 	if err != nil {
@@ -119,20 +135,20 @@ func (p *cockroach) DropDB() error {
 func (p *cockroach) URL() string {
 	c := p.ConnectionDetails
 	if c.URL != "" {
-		return c.URL
+		return withApplicationName(c.URL, c.ApplicationName())
 	}
 	ssl := defaults.String(c.Options["sslmode"], "disable")
 
-	s := "postgres://%s:%s@%s:%s/%s?application_name=cockroach&sslmode=%s"
-	return fmt.Sprintf(s, c.User, c.Password, c.Host, c.Port, c.Database, ssl)
+	s := "postgres://%s:%s@%s:%s/%s?sslmode=%s"
+	return withApplicationName(fmt.Sprintf(s, c.User, c.Password, c.Host, c.Port, c.Database, ssl), defaults.String(c.ApplicationName(), "cockroach"))
 }
 
 func (p *cockroach) urlWithoutDb() string {
 	c := p.ConnectionDetails
 	ssl := defaults.String(c.Options["sslmode"], "disable")
 
-	s := "postgres://%s:%s@%s:%s/?application_name=cockroach&sslmode=%s"
-	return fmt.Sprintf(s, c.User, c.Password, c.Host, c.Port, ssl)
+	s := "postgres://%s:%s@%s:%s/?sslmode=%s"
+	return withApplicationName(fmt.Sprintf(s, c.User, c.Password, c.Host, c.Port, ssl), defaults.String(c.ApplicationName(), "cockroach"))
 }
 
 func (p *cockroach) MigrationURL() string {
@@ -140,28 +156,7 @@ func (p *cockroach) MigrationURL() string {
 }
 
 func (p *cockroach) TranslateSQL(sql string) string {
-	defer p.mu.Unlock()
-	p.mu.Lock()
-
-	if csql, ok := p.translateCache[sql]; ok {
-		return csql
-	}
-	curr := 1
-	out := make([]byte, 0, len(sql))
-	for i := 0; i < len(sql); i++ {
-		if sql[i] == '?' {
-			str := "$" + strconv.Itoa(curr)
-			for _, char := range str {
-				out = append(out, byte(char))
-			}
-			curr++
-		} else {
-			out = append(out, sql[i])
-		}
-	}
-	csql := string(out)
-	p.translateCache[sql] = csql
-	return csql
+	return p.translateCache.rebind(sql)
 }
 
 func (p *cockroach) FizzTranslator() fizz.Translator {
@@ -179,7 +174,7 @@ func (p *cockroach) DumpSchema(w io.Writer) error {
 		secure = "--insecure"
 	}
 	cmd := exec.Command("cockroach", "dump", p.Details().Database, "--dump-mode=schema", secure)
-	Log(strings.Join(cmd.Args, " "))
+	logMessage(nil, LogInfo, strings.Join(cmd.Args, " "))
 	cmd.Stdout = w
 	cmd.Stderr = os.Stderr
 
@@ -208,7 +203,7 @@ func (p *cockroach) LoadSchema(r io.Reader) error {
 		defer in.Close()
 		io.Copy(in, r)
 	}()
-	Log(strings.Join(cmd.Args, " "))
+	logMessage(nil, LogInfo, strings.Join(cmd.Args, " "))
 
 	bb := &bytes.Buffer{}
 	cmd.Stdout = bb
@@ -228,6 +223,22 @@ func (p *cockroach) LoadSchema(r io.Reader) error {
 	return nil
 }
 
+func (p *cockroach) insertAll(s store, query string, args []interface{}, n int) ([]int64, error) {
+	return returningInsertAll(s, query, args, n)
+}
+
+func (p *cockroach) Capabilities() Capabilities {
+	return Capabilities{
+		Returning:    true,
+		Upsert:       true,
+		CTE:          true,
+		Savepoints:   true,
+		LateralJoins: true,
+		RowLocks:     true,
+		DistinctOn:   true,
+	}
+}
+
 func (p *cockroach) TruncateAll(tx *Connection) error {
 	type table struct {
 		TableName string `sql:"table_name"`
@@ -250,8 +261,7 @@ func newCockroach(deets *ConnectionDetails) dialect {
 	deets.Dialect = "postgres"
 	cd := &cockroach{
 		ConnectionDetails: deets,
-		translateCache:    map[string]string{},
-		mu:                sync.Mutex{},
+		translateCache:    newRebindCache(),
 	}
 	return cd
 }
@@ -0,0 +1,28 @@
+package columns_test
+
+import (
+	"testing"
+
+	"github.com/markbates/pop/columns"
+)
+
+func Benchmark_ColumnsForStruct(b *testing.B) {
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		columns.ColumnsForStruct(&foo{}, "foo")
+	}
+}
+
+func Benchmark_Columns_Strings(b *testing.B) {
+	quote := func(s string) string { return `"` + s + `"` }
+	cols := columns.ColumnsForStruct(&foo{}, "foo")
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		w := cols.Writeable()
+		w.QuotedString(quote)
+		w.SymbolizedString()
+		w.QuotedUpdateString(quote)
+		cols.Readable().QuotedSelectString(quote)
+	}
+}
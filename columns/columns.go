@@ -1,6 +1,7 @@
 package columns
 
 import (
+	"bytes"
 	"fmt"
 	"sort"
 	"strings"
@@ -98,7 +99,7 @@ func (c *Columns) Remove(names ...string) {
 }
 
 func (c Columns) Writeable() *WriteableColumns {
-	w := &WriteableColumns{NewColumnsWithAlias(c.TableName, c.TableAlias)}
+	w := &WriteableColumns{newColumnsWithSize(c.TableName, c.TableAlias, len(c.Cols))}
 	for _, col := range c.Cols {
 		if col.Writeable {
 			w.Cols[col.Name] = col
@@ -108,7 +109,7 @@ func (c Columns) Writeable() *WriteableColumns {
 }
 
 func (c Columns) Readable() *ReadableColumns {
-	w := &ReadableColumns{NewColumnsWithAlias(c.TableName, c.TableAlias)}
+	w := &ReadableColumns{newColumnsWithSize(c.TableName, c.TableAlias, len(c.Cols))}
 	for _, col := range c.Cols {
 		if col.Readable {
 			w.Cols[col.Name] = col
@@ -118,21 +119,63 @@ func (c Columns) Readable() *ReadableColumns {
 }
 
 func (c Columns) String() string {
-	xs := []string{}
-	for _, t := range c.Cols {
-		xs = append(xs, t.Name)
-	}
-	sort.Strings(xs)
-	return strings.Join(xs, ", ")
+	return c.join(func(b *bytes.Buffer, col *Column) {
+		b.WriteString(col.Name)
+	})
+}
+
+// QuotedString is String, with the column names quoted by quote. The
+// columns are in the same order as in SymbolizedString.
+func (c Columns) QuotedString(quote func(string) string) string {
+	return c.join(func(b *bytes.Buffer, col *Column) {
+		b.WriteString(quote(col.Name))
+	})
 }
 
 func (c Columns) SymbolizedString() string {
-	xs := []string{}
-	for _, t := range c.Cols {
-		xs = append(xs, ":"+t.Name)
+	return c.join(func(b *bytes.Buffer, col *Column) {
+		b.WriteByte(':')
+		b.WriteString(col.Name)
+	})
+}
+
+// buffers are the buffers the column lists are written to.
+var buffers = sync.Pool{New: func() interface{} { return &bytes.Buffer{} }}
+
+// join writes the columns sorted by name with write, separated by commas.
+func (c Columns) join(write func(b *bytes.Buffer, col *Column)) string {
+	names := make([]string, 0, len(c.Cols))
+	for name := range c.Cols {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	b := buffers.Get().(*bytes.Buffer)
+	b.Reset()
+	for i, name := range names {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		write(b, c.Cols[name])
+	}
+	s := b.String()
+	buffers.Put(b)
+	return s
+}
+
+// clone returns a copy of the columns, whose columns can be changed.
+func (c Columns) clone() Columns {
+	cl := Columns{
+		lock:       &sync.RWMutex{},
+		Cols:       make(map[string]*Column, len(c.Cols)),
+		TableName:  c.TableName,
+		TableAlias: c.TableAlias,
+	}
+	cols := make([]Column, 0, len(c.Cols))
+	for name, col := range c.Cols {
+		cols = append(cols, *col)
+		cl.Cols[name] = &cols[len(cols)-1]
 	}
-	sort.Strings(xs)
-	return strings.Join(xs, ", ")
+	return cl
 }
 
 func NewColumns(tableName string) Columns {
@@ -147,3 +190,12 @@ func NewColumnsWithAlias(tableName string, tableAlias string) Columns {
 		TableAlias: tableAlias,
 	}
 }
+
+func newColumnsWithSize(tableName string, tableAlias string, size int) Columns {
+	return Columns{
+		lock:       &sync.RWMutex{},
+		Cols:       make(map[string]*Column, size),
+		TableName:  tableName,
+		TableAlias: tableAlias,
+	}
+}
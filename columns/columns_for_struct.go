@@ -1,9 +1,33 @@
 package columns
 
 import (
+	"fmt"
 	"reflect"
+	"sync"
 )
 
+// structKey identifies the columns of a struct type for a table.
+type structKey struct {
+	t          reflect.Type
+	tableName  string
+	tableAlias string
+}
+
+// structCache holds the columns of the struct types, which are built
+// from their tags once. It is reset by SetNamingStrategy.
+var structCache = map[structKey]Columns{}
+var structCacheMu = sync.RWMutex{}
+
+// resetCaches forgets the tags and columns derived from the structs.
+func resetCaches() {
+	tagsCacheMu.Lock()
+	tagsCache = map[tagsKey]Tags{}
+	tagsCacheMu.Unlock()
+	structCacheMu.Lock()
+	structCache = map[structKey]Columns{}
+	structCacheMu.Unlock()
+}
+
 // ColumnsForStruct returns a Columns instance for
 // the struct passed in.
 
@@ -12,6 +36,21 @@ func ColumnsForStruct(s interface{}, tableName string) (columns Columns) {
 }
 
 func ColumnsForStructWithAlias(s interface{}, tableName string, tableAlias string) (columns Columns) {
+	key := structKey{reflect.TypeOf(s), tableName, tableAlias}
+	structCacheMu.RLock()
+	cols, ok := structCache[key]
+	structCacheMu.RUnlock()
+	if !ok {
+		cols = columnsForStruct(s, tableName, tableAlias)
+		structCacheMu.Lock()
+		structCache[key] = cols
+		structCacheMu.Unlock()
+	}
+	// the callers are free to change their columns.
+	return cols.clone()
+}
+
+func columnsForStruct(s interface{}, tableName string, tableAlias string) (columns Columns) {
 	columns = NewColumnsWithAlias(tableName, tableAlias)
 	defer func() {
 		if r := recover(); r != nil {
@@ -55,6 +94,13 @@ func ColumnsForStructWithAlias(s interface{}, tableName string, tableAlias strin
 				c := cs[0]
 				c.SetSelectSQL(tag.Value)
 			}
+
+			//read from a partition table.
+			tag = popTags.Find("partition")
+			if !tag.Empty() {
+				c := cs[0]
+				c.SetSelectSQL(fmt.Sprintf("%s.%s", tag.Value, c.Name))
+			}
 		}
 	}
 
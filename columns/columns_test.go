@@ -32,12 +32,26 @@ func Test_Columns_Basics(t *testing.T) {
 		c := columns.ColumnsForStruct(f, "foo")
 		r.Equal(len(c.Cols), 4)
 		r.Equal(c.Cols["first_name"], &columns.Column{Name: "first_name", Writeable: false, Readable: true, SelectSQL: "first_name as f"})
-		r.Equal(c.Cols["LastName"], &columns.Column{Name: "LastName", Writeable: true, Readable: true, SelectSQL: "foo.LastName"})
+		r.Equal(c.Cols["last_name"], &columns.Column{Name: "last_name", Writeable: true, Readable: true, SelectSQL: "foo.last_name"})
 		r.Equal(c.Cols["read"], &columns.Column{Name: "read", Writeable: false, Readable: true, SelectSQL: "foo.read"})
 		r.Equal(c.Cols["write"], &columns.Column{Name: "write", Writeable: true, Readable: false, SelectSQL: "foo.write"})
 	}
 }
 
+func Test_Columns_Partition(t *testing.T) {
+	r := require.New(t)
+
+	type article struct {
+		Title string `db:"title"`
+		Body  string `db:"body" partition:"article_bodies"`
+	}
+
+	c := columns.ColumnsForStruct(&article{}, "articles")
+	r.Equal(c.Cols["body"], &columns.Column{Name: "body", Writeable: false, Readable: true, SelectSQL: "article_bodies.body"})
+	r.Equal("article_bodies.body, articles.title", c.Readable().SelectString())
+	r.Equal("title", c.Writeable().String())
+}
+
 func Test_Columns_Add(t *testing.T) {
 	r := require.New(t)
 
@@ -60,3 +74,15 @@ func Test_Columns_Remove(t *testing.T) {
 		r.Equal(len(c.Cols), 3)
 	}
 }
+
+func Test_ColumnsForStruct_Copies(t *testing.T) {
+	r := require.New(t)
+
+	c := columns.ColumnsForStruct(&foo{}, "foo")
+	c.Remove("last_name")
+	c.Cols["read"].SelectSQL = "changed"
+
+	c = columns.ColumnsForStruct(&foo{}, "foo")
+	r.Equal(len(c.Cols), 4)
+	r.Equal("foo.read", c.Cols["read"].SelectSQL)
+}
@@ -0,0 +1,71 @@
+package columns
+
+import (
+	"sync"
+	"unicode"
+)
+
+// NamingStrategy derives the name of a column from the name of a struct
+// field which does not have a db tag.
+type NamingStrategy func(field string) string
+
+// SnakeCase maps "UserID" to "user_id". It is the default strategy.
+func SnakeCase(field string) string {
+	rs := []rune(field)
+	out := make([]rune, 0, len(rs)+4)
+	for i, r := range rs {
+		if unicode.IsUpper(r) && i > 0 {
+			prev := rs[i-1]
+			nextLower := i+1 < len(rs) && unicode.IsLower(rs[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				out = append(out, '_')
+			}
+		}
+		out = append(out, unicode.ToLower(r))
+	}
+	return string(out)
+}
+
+// CamelCase maps "UserID" to "userID", and "HTMLBody" to "htmlBody".
+func CamelCase(field string) string {
+	rs := []rune(field)
+	for i := range rs {
+		// keep the last capital of a leading acronym, "HTMLBody" is "htmlBody".
+		if i > 0 && i+1 < len(rs) && unicode.IsUpper(rs[i]) && unicode.IsLower(rs[i+1]) {
+			break
+		}
+		if !unicode.IsUpper(rs[i]) {
+			break
+		}
+		rs[i] = unicode.ToLower(rs[i])
+	}
+	return string(rs)
+}
+
+// ExactName keeps the name of the field as is.
+func ExactName(field string) string {
+	return field
+}
+
+var namingStrategy NamingStrategy = SnakeCase
+var namingStrategyMu = sync.RWMutex{}
+
+// SetNamingStrategy changes how the column names of the fields without
+// a db tag are derived. It must be called before the models are used,
+// and before the connections are opened:
+//
+//	columns.SetNamingStrategy(columns.CamelCase)
+func SetNamingStrategy(n NamingStrategy) {
+	namingStrategyMu.Lock()
+	defer namingStrategyMu.Unlock()
+	namingStrategy = n
+	resetCaches()
+}
+
+// ColumnName returns the column name of a field without a db tag,
+// according to the naming strategy.
+func ColumnName(field string) string {
+	namingStrategyMu.RLock()
+	defer namingStrategyMu.RUnlock()
+	return namingStrategy(field)
+}
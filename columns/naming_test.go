@@ -0,0 +1,38 @@
+package columns_test
+
+import (
+	"testing"
+
+	"github.com/markbates/pop/columns"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NamingStrategies(t *testing.T) {
+	r := require.New(t)
+
+	for field, expected := range map[string][]string{
+		"ID":        {"id", "id"},
+		"LastName":  {"last_name", "lastName"},
+		"UserID":    {"user_id", "userID"},
+		"HTMLBody":  {"html_body", "htmlBody"},
+		"Address2":  {"address2", "address2"},
+		"createdAt": {"created_at", "createdAt"},
+	} {
+		r.Equal(expected[0], columns.SnakeCase(field))
+		r.Equal(expected[1], columns.CamelCase(field))
+		r.Equal(field, columns.ExactName(field))
+	}
+}
+
+func Test_SetNamingStrategy(t *testing.T) {
+	r := require.New(t)
+	defer columns.SetNamingStrategy(columns.SnakeCase)
+
+	columns.SetNamingStrategy(columns.CamelCase)
+	c := columns.ColumnsForStruct(&foo{}, "foo")
+	r.Equal("first_name, lastName, read", c.Readable().String())
+
+	columns.SetNamingStrategy(columns.ExactName)
+	c = columns.ColumnsForStruct(&foo{}, "foo")
+	r.Equal("LastName, first_name, read", c.Readable().String())
+}
@@ -10,9 +10,32 @@ type ReadableColumns struct {
 }
 
 func (c ReadableColumns) SelectString() string {
-	xs := []string{}
+	return c.joinSelects(func(t *Column) string {
+		return t.SelectSQL
+	})
+}
+
+// QuotedSelectString is SelectString, with the table and column names
+// quoted by quote. Columns with a custom select are left untouched.
+func (c ReadableColumns) QuotedSelectString(quote func(string) string) string {
+	alias := c.TableAlias
+	if alias == "" {
+		alias = c.TableName
+	}
+	return c.joinSelects(func(t *Column) string {
+		s := t.SelectSQL
+		if s == t.Name || (len(s) == len(alias)+1+len(t.Name) && strings.HasPrefix(s, alias) && s[len(alias)] == '.' && strings.HasSuffix(s, t.Name)) {
+			s = quote(s)
+		}
+		return s
+	})
+}
+
+// joinSelects joins the selects of the columns, sorted.
+func (c ReadableColumns) joinSelects(sel func(t *Column) string) string {
+	xs := make([]string, 0, len(c.Cols))
 	for _, t := range c.Cols {
-		xs = append(xs, t.SelectSQL)
+		xs = append(xs, sel(t))
 	}
 	sort.Strings(xs)
 	return strings.Join(xs, ", ")
@@ -12,7 +12,7 @@ func Test_Columns_ReadableString(t *testing.T) {
 	for _, f := range []interface{}{foo{}, &foo{}} {
 		c := columns.ColumnsForStruct(f, "foo")
 		u := c.Readable().String()
-		r.Equal(u, "LastName, first_name, read")
+		r.Equal(u, "first_name, last_name, read")
 	}
 }
 
@@ -21,7 +21,7 @@ func Test_Columns_Readable_SelectString(t *testing.T) {
 	for _, f := range []interface{}{foo{}, &foo{}} {
 		c := columns.ColumnsForStruct(f, "foo")
 		u := c.Readable().SelectString()
-		r.Equal(u, "first_name as f, foo.LastName, foo.read")
+		r.Equal(u, "first_name as f, foo.last_name, foo.read")
 	}
 }
 
@@ -30,6 +30,16 @@ func Test_Columns_ReadableString_Symbolized(t *testing.T) {
 	for _, f := range []interface{}{foo{}, &foo{}} {
 		c := columns.ColumnsForStruct(f, "foo")
 		u := c.Readable().SymbolizedString()
-		r.Equal(u, ":LastName, :first_name, :read")
+		r.Equal(u, ":first_name, :last_name, :read")
+	}
+}
+
+func Test_Columns_Readable_QuotedSelectString(t *testing.T) {
+	r := require.New(t)
+	quote := func(s string) string { return "[" + s + "]" }
+	for _, f := range []interface{}{foo{}, &foo{}} {
+		c := columns.ColumnsForStruct(f, "foo")
+		u := c.Readable().QuotedSelectString(quote)
+		r.Equal(u, "[foo.last_name], [foo.read], first_name as f")
 	}
 }
@@ -3,9 +3,23 @@ package columns
 import (
 	"reflect"
 	"strings"
+	"sync"
 )
 
-var tags = "db rw select belongs_to has_many has_one fk_id order_by many_to_many"
+var tags = "db rw select belongs_to has_many has_one fk_id order_by many_to_many counter_cache partition partition_by sequence"
+var tagNames = strings.Fields(tags)
+
+// tagsKey identifies the tags of a field, which only depend on its name
+// and its struct tag.
+type tagsKey struct {
+	name string
+	tag  reflect.StructTag
+}
+
+// tagsCache memoizes TagsFor, which is called for every field of a model
+// on every query. It is reset by SetNamingStrategy.
+var tagsCache = map[tagsKey]Tags{}
+var tagsCacheMu = sync.RWMutex{}
 
 // Tag represents a field tag defined exclusively for pop package.
 type Tag struct {
@@ -41,15 +55,28 @@ func (t Tags) Find(name string) Tag {
 // TagsFor is a function which returns all tags defined
 // in model field.
 func TagsFor(field reflect.StructField) Tags {
-	pTags := Tags{}
-	for _, tag := range strings.Fields(tags) {
+	key := tagsKey{field.Name, field.Tag}
+	tagsCacheMu.RLock()
+	pTags, ok := tagsCache[key]
+	tagsCacheMu.RUnlock()
+	if ok {
+		return pTags
+	}
+
+	pTags = Tags{}
+	for _, tag := range tagNames {
 		if valTag := field.Tag.Get(tag); valTag != "" {
 			pTags = append(pTags, Tag{valTag, tag})
 		}
 	}
 
 	if len(pTags) == 0 {
-		pTags = append(pTags, Tag{field.Name, "db"})
+		pTags = append(pTags, Tag{ColumnName(field.Name), "db"})
 	}
+	// the callers appending to the tags get their own copy.
+	pTags = pTags[:len(pTags):len(pTags)]
+	tagsCacheMu.Lock()
+	tagsCache[key] = pTags
+	tagsCacheMu.Unlock()
 	return pTags
 }
@@ -1,8 +1,7 @@
 package columns
 
 import (
-	"sort"
-	"strings"
+	"bytes"
 )
 
 type WriteableColumns struct {
@@ -10,10 +9,19 @@ type WriteableColumns struct {
 }
 
 func (c WriteableColumns) UpdateString() string {
-	xs := []string{}
-	for _, t := range c.Cols {
-		xs = append(xs, t.UpdateString())
-	}
-	sort.Strings(xs)
-	return strings.Join(xs, ", ")
+	return c.join(func(b *bytes.Buffer, t *Column) {
+		b.WriteString(t.Name)
+		b.WriteString(" = :")
+		b.WriteString(t.Name)
+	})
+}
+
+// QuotedUpdateString is UpdateString, with the column names quoted by
+// quote.
+func (c WriteableColumns) QuotedUpdateString(quote func(string) string) string {
+	return c.join(func(b *bytes.Buffer, t *Column) {
+		b.WriteString(quote(t.Name))
+		b.WriteString(" = :")
+		b.WriteString(t.Name)
+	})
 }
@@ -12,7 +12,7 @@ func Test_Columns_WriteableString_Symbolized(t *testing.T) {
 	for _, f := range []interface{}{foo{}, &foo{}} {
 		c := columns.ColumnsForStruct(f, "foo")
 		u := c.Writeable().SymbolizedString()
-		r.Equal(u, ":LastName, :write")
+		r.Equal(u, ":last_name, :write")
 	}
 }
 
@@ -21,7 +21,7 @@ func Test_Columns_UpdateString(t *testing.T) {
 	for _, f := range []interface{}{foo{}, &foo{}} {
 		c := columns.ColumnsForStruct(f, "foo")
 		u := c.Writeable().UpdateString()
-		r.Equal(u, "LastName = :LastName, write = :write")
+		r.Equal(u, "last_name = :last_name, write = :write")
 	}
 }
 
@@ -30,6 +30,32 @@ func Test_Columns_WriteableString(t *testing.T) {
 	for _, f := range []interface{}{foo{}, &foo{}} {
 		c := columns.ColumnsForStruct(f, "foo")
 		u := c.Writeable().String()
-		r.Equal(u, "LastName, write")
+		r.Equal(u, "last_name, write")
 	}
 }
+
+func Test_Columns_QuotedUpdateString(t *testing.T) {
+	r := require.New(t)
+	quote := func(s string) string { return "[" + s + "]" }
+	for _, f := range []interface{}{foo{}, &foo{}} {
+		c := columns.ColumnsForStruct(f, "foo")
+		u := c.Writeable().QuotedUpdateString(quote)
+		r.Equal(u, "[last_name] = :last_name, [write] = :write")
+		r.Equal(c.Writeable().QuotedString(quote), "[last_name], [write]")
+	}
+}
+
+func Test_Columns_QuotedString_Order(t *testing.T) {
+	r := require.New(t)
+	quote := func(s string) string {
+		if s == "write" {
+			return `"write"`
+		}
+		return s
+	}
+	c := columns.NewColumns("foo")
+	c.Add("alpha", "write", "zeta")
+	w := c.Writeable()
+	r.Equal(`alpha, "write", zeta`, w.QuotedString(quote))
+	r.Equal(":alpha, :write, :zeta", w.SymbolizedString())
+}
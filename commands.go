@@ -10,7 +10,7 @@ import (
 func CreateDB(c *Connection) error {
 	deets := c.Dialect.Details()
 	if deets.Database != "" {
-		Log(fmt.Sprintf("Create %s (%s)", deets.Database, c.URL()))
+		logMessage(c, LogInfo, fmt.Sprintf("Create %s (%s)", deets.Database, c.URL()))
 		return errors.Wrapf(c.Dialect.CreateDB(), "couldn't create database %s", deets.Database)
 	}
 	return nil
@@ -20,7 +20,7 @@ func CreateDB(c *Connection) error {
 func DropDB(c *Connection) error {
 	deets := c.Dialect.Details()
 	if deets.Database != "" {
-		Log(fmt.Sprintf("Drop %s (%s)", deets.Database, c.URL()))
+		logMessage(c, LogInfo, fmt.Sprintf("Drop %s (%s)", deets.Database, c.URL()))
 		return errors.Wrapf(c.Dialect.DropDB(), "couldn't drop database %s", deets.Database)
 	}
 	return nil
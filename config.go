@@ -12,7 +12,6 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/markbates/going/defaults"
-	"gopkg.in/yaml.v2"
 )
 
 var lookupPaths = []string{"", "./config", "/config", "../", "../config", "../..", "../../config"}
@@ -61,52 +60,75 @@ func AddLookupPaths(paths ...string) error {
 }
 
 func findConfigPath() (string, error) {
+	path, ok := findFile(ConfigName)
+	if !ok {
+		return "", errors.New("[POP]: Tried to load configuration file, but couldn't find it")
+	}
+	return path, nil
+}
+
+// findFile looks the file name up in the lookup paths.
+func findFile(name string) (string, bool) {
 	for _, p := range LookupPaths() {
-		path, _ := filepath.Abs(filepath.Join(p, ConfigName))
+		path, _ := filepath.Abs(filepath.Join(p, name))
 		if _, err := os.Stat(path); err == nil {
-			return path, err
+			return path, true
 		}
 	}
-	return "", errors.New("[POP]: Tried to load configuration file, but couldn't find it")
+	return "", false
 }
 
 // LoadFrom reads a configuration from the reader and sets up the connections
 func LoadFrom(r io.Reader) error {
+	b, err := renderConfig(r, nil)
+	if err != nil {
+		return err
+	}
+	deets, err := parseConfig(b)
+	if err != nil {
+		return err
+	}
+	for n, d := range deets {
+		con, err := NewConnection(d)
+		if err != nil {
+			return err
+		}
+		Connections[n] = con
+	}
+	return nil
+}
+
+// renderConfig executes the template of a configuration. missingEnv, if
+// not nil, is called with the variables read by env which are not set.
+func renderConfig(r io.Reader, missingEnv func(string)) ([]byte, error) {
+	credentials := &credentialsLoader{}
 	tmpl := template.New("test")
 	tmpl.Funcs(map[string]interface{}{
 		"envOr": func(s1, s2 string) string {
 			return defaults.String(os.Getenv(s1), s2)
 		},
 		"env": func(s1 string) string {
-			return os.Getenv(s1)
+			v := os.Getenv(s1)
+			if v == "" && missingEnv != nil {
+				missingEnv(s1)
+			}
+			return v
 		},
+		"credential": credentials.get,
 	})
 	b, err := ioutil.ReadAll(r)
 	if err != nil {
-		return errors.WithStack(err)
+		return nil, errors.WithStack(err)
 	}
 	t, err := tmpl.Parse(string(b))
 	if err != nil {
-		return errors.Wrap(err, "couldn't parse config template")
+		return nil, errors.Wrap(err, "couldn't parse config template")
 	}
 
 	var bb bytes.Buffer
 	err = t.Execute(&bb, nil)
 	if err != nil {
-		return errors.Wrap(err, "couldn't execute config template")
-	}
-
-	deets := map[string]*ConnectionDetails{}
-	err = yaml.Unmarshal(bb.Bytes(), &deets)
-	if err != nil {
-		return errors.Wrap(err, "couldn't unmarshal config to yaml")
-	}
-	for n, d := range deets {
-		con, err := NewConnection(d)
-		if err != nil {
-			return err
-		}
-		Connections[n] = con
+		return nil, errors.Wrap(err, "couldn't execute config template")
 	}
-	return nil
+	return bb.Bytes(), nil
 }
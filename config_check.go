@@ -0,0 +1,160 @@
+package pop
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/markbates/going/defaults"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// configKeys are the keys a connection of database.yml can have.
+var configKeys = map[string]bool{
+	"dialect": true, "database": true, "host": true, "port": true, "user": true,
+	"password": true, "url": true, "pool": true, "max_open": true, "max_idle": true, "conn_max_lifetime": true,
+	"conn_max_idle_time": true, "options": true, "replicas": true, "extends": true,
+}
+
+// ConfigProblem is a problem found in a configuration by `CheckConfig`.
+type ConfigProblem struct {
+	// Connection is the name of the connection, empty for the problems
+	// of the whole file
+	Connection string
+	// Problem describes the problem
+	Problem string
+}
+
+func (p ConfigProblem) String() string {
+	if p.Connection == "" {
+		return p.Problem
+	}
+	return fmt.Sprintf("%s: %s", p.Connection, p.Problem)
+}
+
+// CheckConfigFile checks the configuration file pop would load, see
+// `CheckConfig`.
+func CheckConfigFile(connect bool) ([]ConfigProblem, error) {
+	path, err := findConfigPath()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer f.Close()
+	return CheckConfig(f, connect)
+}
+
+// CheckConfig reads a configuration as `LoadFrom` does, without loading
+// it, and reports the problems found: unset environment variables, unknown
+// keys, and connection details which can not be turned into a connection
+// URL. If connect is true, the databases are also pinged, and SQLite
+// database files looked up; nothing is written to them. An error is only
+// returned if the configuration can not be read at all.
+func CheckConfig(r io.Reader, connect bool) ([]ConfigProblem, error) {
+	problems := []ConfigProblem{}
+	b, err := renderConfig(r, func(name string) {
+		problems = append(problems, ConfigProblem{Problem: fmt.Sprintf("environment variable %s is not set", name)})
+	})
+	if err != nil {
+		return problems, err
+	}
+
+	raw := map[string]map[string]interface{}{}
+	if err := yaml.Unmarshal(b, &raw); err != nil {
+		return problems, errors.Wrap(err, "couldn't unmarshal config to yaml")
+	}
+	for _, n := range sortedKeys(raw) {
+		problems = append(problems, unknownConfigKeys(n, raw[n])...)
+	}
+
+	deets, err := parseConfig(b)
+	if err != nil {
+		return append(problems, ConfigProblem{Problem: err.Error()}), nil
+	}
+	names := make([]string, 0, len(deets))
+	for n := range deets {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	for _, n := range names {
+		c, err := NewConnection(deets[n])
+		if err != nil {
+			problems = append(problems, ConfigProblem{Connection: n, Problem: errors.Cause(err).Error()})
+			continue
+		}
+		if !connect {
+			continue
+		}
+		for _, d := range append([]dialect{c.Dialect}, c.replicas...) {
+			if err := pingDialect(d); err != nil {
+				problems = append(problems, ConfigProblem{Connection: n, Problem: err.Error()})
+			}
+		}
+	}
+	return problems, nil
+}
+
+// unknownConfigKeys reports the keys of the entry n, and of its replicas,
+// pop does not know about.
+func unknownConfigKeys(n string, entry map[string]interface{}) []ConfigProblem {
+	problems := []ConfigProblem{}
+	keys := []string{}
+	for k := range entry {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if !configKeys[k] {
+			problems = append(problems, ConfigProblem{Connection: n, Problem: fmt.Sprintf("unknown key %s", k)})
+		}
+	}
+	replicas, _ := entry["replicas"].([]interface{})
+	for i, r := range replicas {
+		rm, ok := r.(map[interface{}]interface{})
+		if !ok {
+			problems = append(problems, ConfigProblem{Connection: n, Problem: fmt.Sprintf("replica %d is not a map", i)})
+			continue
+		}
+		for k := range rm {
+			if ks := fmt.Sprint(k); !configKeys[ks] || ks == "replicas" || ks == "extends" {
+				problems = append(problems, ConfigProblem{Connection: n, Problem: fmt.Sprintf("unknown key %s in replica %d", ks, i)})
+			}
+		}
+	}
+	return problems
+}
+
+// pingDialect checks that the database of d can be reached. SQLite
+// database files are only looked up, so they are not created.
+func pingDialect(d dialect) error {
+	cd := d.Details()
+	if cd.Dialect == "sqlite3" {
+		if cd.Database == ":memory:" || strings.HasPrefix(cd.Database, "file::memory:") || cd.Options["shared_memory"] == "true" {
+			return nil
+		}
+		if _, err := os.Stat(cd.Database); err != nil {
+			return errors.Errorf("database file %s does not exist", cd.Database)
+		}
+		return nil
+	}
+	db, err := openDB(d)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		return errors.Wrapf(err, "could not reach %s", defaults.String(cd.Host, cd.Database))
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package pop
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// parseConfig reads the connections of a database.yml file. An entry can
+// extend one or more other entries, whose keys it inherits; its options
+// are merged with theirs:
+//
+//	pool_defaults:
+//	  pool: 25
+//	  options:
+//	    read_your_writes: "2s"
+//
+//	production:
+//	  extends: pool_defaults
+//	  dialect: "postgres"
+//	  url: {{ env "DATABASE_URL" }}
+//
+// The extended entries with neither a dialect nor a url, such as
+// pool_defaults, are profiles: they are not connections. The other
+// entries missing them still fail to connect.
+func parseConfig(b []byte) (map[string]*ConnectionDetails, error) {
+	raw := map[string]map[string]interface{}{}
+	if err := yaml.Unmarshal(b, &raw); err != nil {
+		return nil, errors.Wrap(err, "couldn't unmarshal config to yaml")
+	}
+
+	extended := map[string]bool{}
+	for n, entry := range raw {
+		parents, err := extendedEntries(n, entry["extends"])
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range parents {
+			extended[p] = true
+		}
+	}
+
+	resolved := map[string]map[string]interface{}{}
+	deets := map[string]*ConnectionDetails{}
+	for _, n := range sortedKeys(raw) {
+		entry, err := resolveEntry(raw, resolved, n, nil)
+		if err != nil {
+			return nil, err
+		}
+		if extended[n] && entry["dialect"] == nil && entry["url"] == nil {
+			continue
+		}
+		eb, err := yaml.Marshal(entry)
+		if err != nil {
+			return nil, errors.Wrapf(err, "couldn't read %s", n)
+		}
+		d := &ConnectionDetails{}
+		if err := yaml.Unmarshal(eb, d); err != nil {
+			return nil, errors.Wrapf(err, "couldn't read %s", n)
+		}
+		deets[n] = d
+	}
+	return deets, nil
+}
+
+// resolveEntry returns the keys of the entry n, merged with the ones of
+// the entries it extends. chain is the list of the entries being resolved,
+// to catch cycles.
+func resolveEntry(raw, resolved map[string]map[string]interface{}, n string, chain []string) (map[string]interface{}, error) {
+	if entry, ok := resolved[n]; ok {
+		return entry, nil
+	}
+	for _, c := range chain {
+		if c == n {
+			return nil, errors.Errorf("%s extends itself: %s", n, strings.Join(append(chain, n), " -> "))
+		}
+	}
+	entry, ok := raw[n]
+	if !ok {
+		return nil, errors.Errorf("%s extends %s, which does not exist", chain[len(chain)-1], n)
+	}
+
+	parents, err := extendedEntries(n, entry["extends"])
+	if err != nil {
+		return nil, err
+	}
+	merged := map[string]interface{}{}
+	for _, p := range parents {
+		pe, err := resolveEntry(raw, resolved, p, append(chain, n))
+		if err != nil {
+			return nil, err
+		}
+		mergeEntry(merged, pe)
+	}
+	mergeEntry(merged, entry)
+	delete(merged, "extends")
+
+	resolved[n] = merged
+	return merged, nil
+}
+
+// extendedEntries reads the extends key, a name or a list of names.
+func extendedEntries(n string, v interface{}) ([]string, error) {
+	switch e := v.(type) {
+	case nil:
+		return []string{}, nil
+	case string:
+		return []string{e}, nil
+	case []interface{}:
+		names := make([]string, 0, len(e))
+		for _, x := range e {
+			s, ok := x.(string)
+			if !ok {
+				return nil, errors.Errorf("%s extends %v, which is not a name", n, x)
+			}
+			names = append(names, s)
+		}
+		return names, nil
+	}
+	return nil, errors.Errorf("%s extends %v, which is not a name or a list of names", n, v)
+}
+
+// mergeEntry copies the keys of src into dst; the options of both are
+// merged.
+func mergeEntry(dst, src map[string]interface{}) {
+	for k, v := range src {
+		if k != "options" {
+			dst[k] = v
+			continue
+		}
+		options := map[string]interface{}{}
+		for _, o := range []interface{}{dst[k], v} {
+			switch m := o.(type) {
+			case map[interface{}]interface{}:
+				for ok, ov := range m {
+					options[fmt.Sprint(ok)] = ov
+				}
+			case map[string]interface{}:
+				for ok, ov := range m {
+					options[ok] = ov
+				}
+			}
+		}
+		dst[k] = options
+	}
+}
+
+// sortedKeys returns the keys of m in order.
+func sortedKeys(m map[string]map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,108 @@
+package pop
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseConfig_Extends(t *testing.T) {
+	r := require.New(t)
+
+	deets, err := parseConfig([]byte(`
+pool_defaults:
+  pool: 25
+  options:
+    read_your_writes: "2s"
+    max_replica_lag: "30s"
+
+postgres_defaults:
+  extends: pool_defaults
+  dialect: "postgres"
+  user: "app"
+
+staging:
+  extends: postgres_defaults
+  database: "app_staging"
+
+production:
+  extends: [postgres_defaults]
+  database: "app_production"
+  pool: 50
+  options:
+    max_replica_lag: "10s"
+`))
+	r.NoError(err)
+	r.Len(deets, 3)
+
+	s := deets["staging"]
+	r.Equal("postgres", s.Dialect)
+	r.Equal("app", s.User)
+	r.Equal("app_staging", s.Database)
+	r.Equal(25, s.Pool)
+	r.Equal("2s", s.Options["read_your_writes"])
+
+	p := deets["production"]
+	r.Equal("app_production", p.Database)
+	r.Equal(50, p.Pool)
+	r.Equal("2s", p.Options["read_your_writes"])
+	r.Equal("10s", p.Options["max_replica_lag"])
+
+	r.NotNil(deets["postgres_defaults"])
+	r.Nil(deets["pool_defaults"])
+}
+
+func Test_parseConfig_Extends_Errors(t *testing.T) {
+	r := require.New(t)
+
+	_, err := parseConfig([]byte(`
+development:
+  extends: missing
+  dialect: "sqlite3"
+`))
+	r.EqualError(err, "development extends missing, which does not exist")
+
+	_, err = parseConfig([]byte(`
+a:
+  extends: b
+b:
+  extends: a
+  dialect: "sqlite3"
+`))
+	r.EqualError(err, "a extends itself: a -> b -> a")
+
+	// an entry extended by none is not a profile.
+	deets, err := parseConfig([]byte(`
+development:
+  database: "app_development"
+`))
+	r.NoError(err)
+	r.NotNil(deets["development"])
+	r.Error(LoadFrom(strings.NewReader(`
+development:
+  database: "app_development"
+`)))
+}
+
+func Test_parseConfig_Pool(t *testing.T) {
+	r := require.New(t)
+
+	deets, err := parseConfig([]byte(`
+development:
+  dialect: "postgres"
+  database: "app_development"
+  max_open: 20
+  max_idle: 5
+  conn_max_lifetime: "30m"
+  conn_max_idle_time: "5m"
+`))
+	r.NoError(err)
+
+	d := deets["development"]
+	r.Equal(20, d.MaxOpenConns())
+	r.Equal(5, d.MaxIdle)
+	r.Equal(30*time.Minute, d.ConnMaxLifetime)
+	r.Equal(5*time.Minute, d.ConnMaxIdleTime)
+}
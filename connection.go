@@ -1,18 +1,26 @@
 package pop
 
 import (
+	"context"
+	"database/sql"
 	"sync/atomic"
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/jmoiron/sqlx/reflectx"
 	"github.com/markbates/going/defaults"
 	"github.com/markbates/going/randx"
+	"github.com/markbates/pop/columns"
 	"github.com/pkg/errors"
 )
 
 // Connections contains all of the available connections
 var Connections = map[string]*Connection{}
 
+// fieldMapper maps the fields without a db tag to the columns pop would
+// write them to.
+var fieldMapper = reflectx.NewMapperFunc("db", columns.ColumnName)
+
 // Connection represents all of the necessary details for
 // talking with a datastore
 type Connection struct {
@@ -21,6 +29,22 @@ type Connection struct {
 	Dialect dialect
 	Elapsed int64
 	TX      *Tx
+
+	replicas []dialect
+	gate     *gate
+	appName  string
+	ctx      context.Context
+
+	noAssociations bool
+	progress       ProgressFunc
+	chaos          *Chaos
+	capture        *sqlCapture
+	logger         Logger
+	quiet          bool
+	slowThreshold  time.Duration
+	slowQuery      SlowQueryFunc
+	tracer         Tracer
+	middlewares    []Middleware
 }
 
 func (c *Connection) String() string {
@@ -45,22 +69,39 @@ func NewConnection(deets *ConnectionDetails) (*Connection, error) {
 		return nil, errors.WithStack(err)
 	}
 	c := &Connection{
-		ID: randx.String(30),
+		ID:            randx.String(30),
+		gate:          &gate{},
+		slowThreshold: deets.SlowQueryThreshold(),
+	}
+	c.Dialect, err = newDialect(deets)
+	if err != nil {
+		return c, errors.WithStack(err)
+	}
+	for _, rd := range deets.Replicas {
+		r, err := newDialect(rd)
+		if err != nil {
+			return c, errors.WithStack(err)
+		}
+		c.replicas = append(c.replicas, r)
 	}
+	return c, nil
+}
+
+func newDialect(deets *ConnectionDetails) (dialect, error) {
 	switch deets.Dialect {
 	case "postgres":
-		c.Dialect = newPostgreSQL(deets)
+		if deets.Redshift() {
+			return newRedshift(deets), nil
+		}
+		return newPostgreSQL(deets), nil
 	case "cockroach":
-		c.Dialect = newCockroach(deets)
+		return newCockroach(deets), nil
 	case "mysql":
-		c.Dialect = newMySQL(deets)
+		return newMySQL(deets), nil
 	case "sqlite3":
-		c.Dialect, err = newSQLite(deets)
-		if err != nil {
-			return c, errors.WithStack(err)
-		}
+		return newSQLite(deets)
 	}
-	return c, nil
+	return nil, errors.Errorf("Unknown dialect %s!", deets.Dialect)
 }
 
 // Connect takes the name of a connection, default is "development", and will
@@ -89,12 +130,55 @@ func (c *Connection) Open() error {
 	if c.Store != nil {
 		return nil
 	}
-	db, err := sqlx.Open(c.Dialect.Details().Dialect, c.Dialect.URL())
-	db.SetMaxOpenConns(c.Dialect.Details().Pool)
-	if err == nil {
-		c.Store = &dB{db}
+	db, err := openDB(c.Dialect)
+	if err != nil {
+		return err
+	}
+	var s store = newDB(c.Dialect, db)
+	if len(c.replicas) > 0 {
+		rs := &routedStore{store: s, next: new(uint32), session: newReadSession(c.Dialect.Details())}
+		for _, r := range c.replicas {
+			rdb, err := openDB(r)
+			if err != nil {
+				rs.Close()
+				return errors.Wrapf(err, "replica %s", r.Details().Host)
+			}
+			rs.replicas = append(rs.replicas, &replica{dialect: r, store: newDB(r, rdb)})
+		}
+		s = rs
+	}
+	c.Store = newInstrumentedStore(c, s)
+	if ao, ok := c.Dialect.(afterOpener); ok {
+		if err := ao.afterOpen(c.Store); err != nil {
+			c.Store.Close()
+			c.Store = nil
+			return errors.WithStack(err)
+		}
 	}
-	return errors.Wrap(err, "coudn't connection to database")
+	return nil
+}
+
+func openDB(d dialect) (*sqlx.DB, error) {
+	p, err := d.Details().credentialsProvider()
+	if err != nil {
+		return nil, err
+	}
+	var db *sqlx.DB
+	if p != nil {
+		cc, err := newCredentialsConnector(d, p)
+		if err != nil {
+			return nil, err
+		}
+		db = sqlx.NewDb(sql.OpenDB(cc), d.Details().Dialect)
+	} else {
+		db, err = sqlx.Open(d.Details().Dialect, d.URL())
+		if err != nil {
+			return nil, errors.Wrap(err, "coudn't connection to database")
+		}
+	}
+	d.Details().configurePool(db.DB)
+	db.Mapper = fieldMapper
+	return db, nil
 }
 
 // Close destroys an active datasource connection
@@ -128,41 +212,36 @@ func (c *Connection) Transaction(fn func(tx *Connection) error) error {
 
 // NewTransaction starts a new transaction on the connection
 func (c *Connection) NewTransaction() (*Connection, error) {
-	var cn *Connection
-	if c.TX == nil {
-		tx, err := c.Store.Transaction()
-		if err != nil {
-			return cn, errors.Wrap(err, "couldn't start a new transaction")
-		}
-		cn = &Connection{
-			ID:      randx.String(30),
-			Store:   tx,
-			Dialect: c.Dialect,
-			TX:      tx,
+	if c.TX != nil {
+		return c, nil
+	}
+	tx, err := c.Store.Transaction()
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't start a new transaction")
+	}
+	// the transaction keeps the settings of the connection.
+	cn := *c
+	cn.ID = randx.String(30)
+	cn.Elapsed = 0
+	cn.TX = tx
+	cn.Store = newInstrumentedStore(&cn, tx.statements())
+	// the N+1 counts of the transaction are dropped once it is over.
+	done := tx.done
+	tx.done = func() {
+		nPlusOne.forget(cn.ID)
+		if done != nil {
+			done()
 		}
-	} else {
-		cn = c
 	}
-	return cn, nil
+	return &cn, nil
 }
 
 // Rollback will open a new transaction and automatically rollback that transaction
 // when the inner function returns, regardless. This can be useful for tests, etc...
 func (c *Connection) Rollback(fn func(tx *Connection)) error {
-	var cn *Connection
-	if c.TX == nil {
-		tx, err := c.Store.Transaction()
-		if err != nil {
-			return errors.Wrap(err, "couldn't start a new transaction")
-		}
-		cn = &Connection{
-			ID:      randx.String(30),
-			Store:   tx,
-			Dialect: c.Dialect,
-			TX:      tx,
-		}
-	} else {
-		cn = c
+	cn, err := c.NewTransaction()
+	if err != nil {
+		return err
 	}
 	fn(cn)
 	return cn.TX.Rollback()
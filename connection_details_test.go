@@ -106,3 +106,62 @@ func Test_ConnectionDetails_Finalize_SQLite(t *testing.T) {
 	r.Equal(cd.Port, "")
 	r.Equal(cd.User, "")
 }
+
+func Test_ConnectionDetails_Finalize_MariaDB(t *testing.T) {
+	r := require.New(t)
+
+	cd := &pop.ConnectionDetails{
+		Dialect:  "mariadb",
+		Database: "database",
+	}
+	err := cd.Finalize()
+	r.NoError(err)
+
+	r.Equal("mysql", cd.Dialect)
+	r.Equal("3306", cd.Port)
+	r.True(cd.MariaDB())
+
+	cd = &pop.ConnectionDetails{Dialect: "mysql"}
+	r.NoError(cd.Finalize())
+	r.False(cd.MariaDB())
+}
+
+func Test_ConnectionDetails_Finalize_TiDB(t *testing.T) {
+	r := require.New(t)
+
+	cd := &pop.ConnectionDetails{
+		Dialect:  "tidb",
+		Database: "database",
+	}
+	err := cd.Finalize()
+	r.NoError(err)
+
+	r.Equal("mysql", cd.Dialect)
+	r.Equal("4000", cd.Port)
+	r.True(cd.TiDB())
+	r.False(cd.MariaDB())
+}
+
+func Test_ConnectionDetails_Finalize_Redshift(t *testing.T) {
+	r := require.New(t)
+
+	cd := &pop.ConnectionDetails{
+		Dialect:  "redshift",
+		Database: "database",
+	}
+	err := cd.Finalize()
+	r.NoError(err)
+
+	r.Equal("postgres", cd.Dialect)
+	r.Equal("5439", cd.Port)
+	r.True(cd.Redshift())
+}
+
+func Test_ConnectionDetails_MaxOpenConns(t *testing.T) {
+	r := require.New(t)
+
+	cd := &pop.ConnectionDetails{Pool: 5}
+	r.Equal(5, cd.MaxOpenConns())
+	cd.MaxOpen = 10
+	r.Equal(10, cd.MaxOpenConns())
+}
@@ -1 +1,15 @@
 package pop_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Connection_Capabilities(t *testing.T) {
+	r := require.New(t)
+	c := PDB.Dialect.Capabilities()
+	r.Equal(c, PDB.Capabilities())
+	r.True(c.CTE)
+	r.True(c.Savepoints)
+}
@@ -0,0 +1,134 @@
+package pop
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ConnectionURL returns the connection string pop opens the database of
+// cd with, so that other tools, such as migrations built in a separate
+// binary, connect the same way. cd is left untouched.
+func ConnectionURL(cd *ConnectionDetails) (string, error) {
+	d, err := dialectOf(cd)
+	if err != nil {
+		return "", err
+	}
+	return d.URL(), nil
+}
+
+// PostgresURL returns the PostgreSQL connection URL pop opens the database
+// of cd with, including its sslmode and application_name:
+//
+//	u, err := pop.PostgresURL(&pop.ConnectionDetails{
+//		Host:     "localhost",
+//		User:     "postgres",
+//		Database: "app_development",
+//	})
+//	// postgres://postgres:@localhost:5432/app_development?sslmode=disable
+//
+// The dialect of cd defaults to "postgres", and it can also be "cockroach"
+// or "redshift".
+func PostgresURL(cd *ConnectionDetails) (string, error) {
+	d, err := dialectOf(cd, "postgres")
+	if err != nil {
+		return "", err
+	}
+	switch d.(type) {
+	case *postgresql, *cockroach, *redshift:
+		return d.URL(), nil
+	}
+	return "", errors.Errorf("%s is not a PostgreSQL dialect", d.Details().Dialect)
+}
+
+// MySQLDSN returns the MySQL data source name pop opens the database of cd
+// with, including parseTime, multiStatements and clientFoundRows, which
+// pop relies on:
+//
+//	dsn, err := pop.MySQLDSN(&pop.ConnectionDetails{
+//		Host:     "localhost",
+//		User:     "root",
+//		Database: "app_development",
+//	})
+//	// root:@(localhost:3306)/app_development?parseTime=true&multiStatements=true&readTimeout=1s&clientFoundRows=true
+//
+// The dialect of cd defaults to "mysql", and it can also be "mariadb" or
+// "tidb".
+func MySQLDSN(cd *ConnectionDetails) (string, error) {
+	d, err := dialectOf(cd, "mysql")
+	if err != nil {
+		return "", err
+	}
+	if _, ok := d.(*mysql); !ok {
+		return "", errors.Errorf("%s is not a MySQL dialect", d.Details().Dialect)
+	}
+	return d.URL(), nil
+}
+
+// ParseURL returns the connection details of the connection URL u, the
+// reverse of `ConnectionURL`. The dialect is read from the scheme of u,
+// e.g. "postgres://", "mysql://" or "sqlite3://", and the query parameters
+// of the PostgreSQL URLs become options:
+//
+//	cd, err := pop.ParseURL("postgres://app:secret@db:5432/app?sslmode=require")
+//	// cd.Host == "db", cd.Options["sslmode"] == "require"
+func ParseURL(u string) (*ConnectionDetails, error) {
+	if !strings.Contains(u, "://") {
+		return nil, errors.Errorf("the URL %q has no scheme naming its dialect", u)
+	}
+	cd := &ConnectionDetails{URL: u, Options: map[string]string{}}
+	cd.setURLDialect()
+	if err := cd.Finalize(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if cd.Dialect == "postgres" || cd.Dialect == "cockroach" {
+		pu, err := url.Parse(u)
+		if err != nil {
+			return nil, errors.Wrapf(err, "couldn't parse %s", u)
+		}
+		for k, v := range pu.Query() {
+			if _, ok := cd.Options[k]; !ok && len(v) > 0 {
+				cd.Options[k] = v[0]
+			}
+		}
+	}
+	return cd, nil
+}
+
+// dialectOf returns the dialect of a finalized copy of cd, whose dialect
+// defaults to defaultDialect.
+func dialectOf(cd *ConnectionDetails, defaultDialect ...string) (dialect, error) {
+	deets := *cd
+	deets.setURLDialect()
+	if deets.Dialect == "" && deets.URL == "" && len(defaultDialect) > 0 {
+		deets.Dialect = defaultDialect[0]
+	}
+	if deets.Options != nil {
+		deets.Options = map[string]string{}
+		for k, v := range cd.Options {
+			deets.Options[k] = v
+		}
+	}
+	if err := deets.Finalize(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return newDialect(&deets)
+}
+
+// setURLDialect sets the dialect of the connection details from the scheme
+// of their URL, when Finalize can not read it: the MySQL data source names
+// are not URLs, and the SQLite URLs are paths.
+func (cd *ConnectionDetails) setURLDialect() {
+	i := strings.Index(cd.URL, "://")
+	if cd.Dialect != "" || i == -1 {
+		return
+	}
+	switch scheme := strings.ToLower(cd.URL[:i]); scheme {
+	case "sqlite", "sqlite3":
+		cd.Dialect = "sqlite3"
+		cd.URL = cd.URL[i+3:]
+	case "mysql", "mariadb", "tidb":
+		cd.Dialect = scheme
+	}
+}
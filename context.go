@@ -0,0 +1,165 @@
+package pop
+
+import (
+	"context"
+
+	"github.com/markbates/validate"
+)
+
+// WithContext returns a copy of the connection carrying ctx. Its queries
+// are cancelled with ctx, and the values of ctx, such as the current user
+// or a trace, are handed to the callbacks of the models. The copy shares
+// the pool, or the transaction, of c, and the transactions it starts
+// carry ctx as well:
+//
+//	tx := db.WithContext(r.Context())
+//	err := tx.Create(&post)
+//
+//	func (p *Post) BeforeCreate(tx *pop.Connection) error {
+//		p.AuthorID = tx.Context().Value(userKey).(int)
+//		return nil
+//	}
+func (c *Connection) WithContext(ctx context.Context) *Connection {
+	cn := *c
+	cn.ctx = ctx
+	if is, ok := c.Store.(*instrumentedStore); ok {
+		cn.Store = newInstrumentedStore(&cn, is.store)
+	}
+	return &cn
+}
+
+// Context returns the context set with `WithContext`, or
+// context.Background() if there is none.
+func (c *Connection) Context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
+// withContext returns a copy of q running on a copy of its connection
+// carrying ctx.
+func (q *Query) withContext(ctx context.Context) *Query {
+	cq := *q
+	cq.Connection = q.Connection.WithContext(ctx)
+	return &cq
+}
+
+// FindContext is `Find`, cancelled with ctx.
+func (c *Connection) FindContext(ctx context.Context, model interface{}, id interface{}) error {
+	return c.WithContext(ctx).Find(model, id)
+}
+
+// FindContext is `Find`, cancelled with ctx.
+func (q *Query) FindContext(ctx context.Context, model interface{}, id interface{}) error {
+	return q.withContext(ctx).Find(model, id)
+}
+
+// FirstContext is `First`, cancelled with ctx.
+func (c *Connection) FirstContext(ctx context.Context, model interface{}) error {
+	return c.WithContext(ctx).First(model)
+}
+
+// FirstContext is `First`, cancelled with ctx.
+func (q *Query) FirstContext(ctx context.Context, model interface{}) error {
+	return q.withContext(ctx).First(model)
+}
+
+// LastContext is `Last`, cancelled with ctx.
+func (c *Connection) LastContext(ctx context.Context, model interface{}) error {
+	return c.WithContext(ctx).Last(model)
+}
+
+// LastContext is `Last`, cancelled with ctx.
+func (q *Query) LastContext(ctx context.Context, model interface{}) error {
+	return q.withContext(ctx).Last(model)
+}
+
+// AllContext is `All`, cancelled with ctx.
+func (c *Connection) AllContext(ctx context.Context, models interface{}) error {
+	return c.WithContext(ctx).All(models)
+}
+
+// AllContext is `All`, cancelled with ctx.
+//
+//	err := tx.Where("name = ?", name).AllContext(r.Context(), &users)
+func (q *Query) AllContext(ctx context.Context, models interface{}) error {
+	return q.withContext(ctx).All(models)
+}
+
+// CountContext is `Count`, cancelled with ctx.
+func (c *Connection) CountContext(ctx context.Context, model interface{}) (int, error) {
+	return c.WithContext(ctx).Count(model)
+}
+
+// CountContext is `Count`, cancelled with ctx.
+func (q *Query) CountContext(ctx context.Context, model interface{}) (int, error) {
+	return q.withContext(ctx).Count(model)
+}
+
+// ExistsContext is `Exists`, cancelled with ctx.
+func (q *Query) ExistsContext(ctx context.Context, model interface{}) (bool, error) {
+	return q.withContext(ctx).Exists(model)
+}
+
+// ExecContext is `Exec`, cancelled with ctx.
+func (q *Query) ExecContext(ctx context.Context) error {
+	return q.withContext(ctx).Exec()
+}
+
+// ExecWithCountContext is `ExecWithCount`, cancelled with ctx.
+func (q *Query) ExecWithCountContext(ctx context.Context) (int, error) {
+	return q.withContext(ctx).ExecWithCount()
+}
+
+// LoadContext is `Load`, cancelled with ctx.
+func (c *Connection) LoadContext(ctx context.Context, model interface{}, fields ...string) error {
+	return c.WithContext(ctx).Load(model, fields...)
+}
+
+// ReloadContext is `Reload`, cancelled with ctx.
+func (c *Connection) ReloadContext(ctx context.Context, model interface{}) error {
+	return c.WithContext(ctx).Reload(model)
+}
+
+// CreateContext is `Create`, cancelled with ctx.
+func (c *Connection) CreateContext(ctx context.Context, model interface{}, excludeColumns ...string) error {
+	return c.WithContext(ctx).Create(model, excludeColumns...)
+}
+
+// SaveContext is `Save`, cancelled with ctx.
+func (c *Connection) SaveContext(ctx context.Context, model interface{}, excludeColumns ...string) error {
+	return c.WithContext(ctx).Save(model, excludeColumns...)
+}
+
+// UpdateContext is `Update`, cancelled with ctx.
+func (c *Connection) UpdateContext(ctx context.Context, model interface{}, excludeColumns ...string) error {
+	return c.WithContext(ctx).Update(model, excludeColumns...)
+}
+
+// DestroyContext is `Destroy`, cancelled with ctx.
+func (c *Connection) DestroyContext(ctx context.Context, model interface{}) error {
+	return c.WithContext(ctx).Destroy(model)
+}
+
+// ValidateAndCreateContext is `ValidateAndCreate`, cancelled with ctx.
+func (c *Connection) ValidateAndCreateContext(ctx context.Context, model interface{}, excludeColumns ...string) (*validate.Errors, error) {
+	return c.WithContext(ctx).ValidateAndCreate(model, excludeColumns...)
+}
+
+// ValidateAndSaveContext is `ValidateAndSave`, cancelled with ctx.
+func (c *Connection) ValidateAndSaveContext(ctx context.Context, model interface{}, excludeColumns ...string) (*validate.Errors, error) {
+	return c.WithContext(ctx).ValidateAndSave(model, excludeColumns...)
+}
+
+// ValidateAndUpdateContext is `ValidateAndUpdate`, cancelled with ctx.
+func (c *Connection) ValidateAndUpdateContext(ctx context.Context, model interface{}, excludeColumns ...string) (*validate.Errors, error) {
+	return c.WithContext(ctx).ValidateAndUpdate(model, excludeColumns...)
+}
+
+// TransactionContext is `Transaction`, with a transaction rolled back if
+// ctx is cancelled before fn returns. The connection handed to fn carries
+// ctx.
+func (c *Connection) TransactionContext(ctx context.Context, fn func(tx *Connection) error) error {
+	return c.WithContext(ctx).Transaction(fn)
+}
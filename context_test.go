@@ -0,0 +1,96 @@
+package pop_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/markbates/pop"
+	"github.com/markbates/pop/nulls"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Context_Finders(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		user := User{Name: nulls.NewString("Mark")}
+		r.NoError(tx.CreateContext(context.Background(), &user))
+
+		u := User{}
+		r.NoError(tx.FindContext(context.Background(), &u, user.ID))
+		r.Equal("Mark", u.Name.String)
+
+		users := Users{}
+		r.NoError(tx.Where("name = ?", "Mark").AllContext(context.Background(), &users))
+		r.Len(users, 1)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := tx.Where("name = ?", "Mark").AllContext(ctx, &users)
+		r.Error(err)
+		r.Equal(context.Canceled, errors.Cause(err))
+
+		_, err = tx.CountContext(ctx, &User{})
+		r.Error(err)
+		r.Error(tx.DestroyContext(ctx, &user))
+
+		// the query is left untouched by the cancelled context.
+		ct, err := tx.Count(&User{})
+		r.NoError(err)
+		r.Equal(1, ct)
+	})
+}
+
+func Test_TransactionContext(t *testing.T) {
+	r := require.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	err := PDB.TransactionContext(ctx, func(tx *pop.Connection) error {
+		r.Equal(ctx, tx.Context())
+		if err := tx.Create(&User{Name: nulls.NewString("Cancelled")}); err != nil {
+			return err
+		}
+		cancel()
+		return nil
+	})
+	r.Error(err)
+
+	ct, err := PDB.Where("name = ?", "Cancelled").Count(&User{})
+	r.NoError(err)
+	r.Equal(0, ct)
+}
+
+func Test_Deduplicate(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		user := User{Name: nulls.NewString("Mark")}
+		r.NoError(tx.Create(&user))
+
+		c := tx.WithContext(pop.Deduplicate(context.Background()))
+		done := make(chan error, 10)
+		for i := 0; i < 10; i++ {
+			go func() {
+				users := Users{}
+				if err := c.Where("name = ?", "Mark").All(&users); err != nil {
+					done <- err
+					return
+				}
+				if len(users) != 1 || users[0].ID != user.ID {
+					done <- errors.Errorf("unexpected users %v", users)
+					return
+				}
+				done <- nil
+			}()
+		}
+		for i := 0; i < 10; i++ {
+			r.NoError(<-done)
+		}
+
+		u := User{}
+		r.NoError(c.Find(&u, user.ID))
+		r.Equal("Mark", u.Name.String)
+		ct, err := c.Count(&User{})
+		r.NoError(err)
+		r.Equal(1, ct)
+	})
+}
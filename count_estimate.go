@@ -0,0 +1,104 @@
+package pop
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// CountEstimate returns an estimate of the number of records in the
+// database, see `Query.CountEstimate`.
+//
+//	c.CountEstimate(&User{})
+func (c *Connection) CountEstimate(model interface{}) (int, error) {
+	return Q(c).CountEstimate(model)
+}
+
+// CountEstimate returns an estimate of the number of records matching
+// the query, for cases such as dashboards where an exact count of a
+// huge table is too slow to be worth it. The estimate comes from the
+// table statistics (PostgreSQL reltuples, MySQL TABLE_ROWS) or, when the
+// query has conditions, from the planner row estimate. Dialects without
+// estimates fall back to an exact `Count`.
+//
+//	q.Where("sex = ?", "f").CountEstimate(&User{})
+func (q Query) CountEstimate(model interface{}) (int, error) {
+	m := &Model{Value: model}
+	dialect := q.Connection.Dialect.Details().Dialect
+	if _, ok := q.Connection.Dialect.(*cockroach); ok || (dialect != "postgres" && dialect != "mysql") {
+		return q.Count(model)
+	}
+
+	res := &rowCount{}
+	if len(q.whereClauses) == 0 && len(q.joinClauses) == 0 && len(q.belongsToThroughClauses) == 0 && q.RawSQL.Fragment == "" {
+		var stmt string
+		switch dialect {
+		case "postgres":
+			stmt = "SELECT reltuples::bigint AS row_count FROM pg_class WHERE relname = ?"
+		case "mysql":
+			stmt = "SELECT TABLE_ROWS AS row_count FROM information_schema.TABLES WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?"
+		}
+		err := q.Connection.timeFunc("CountEstimate", func() error {
+			stmt = q.Connection.Dialect.TranslateSQL(stmt)
+			return q.Connection.Store.Get(res, stmt, m.TableName())
+		})
+		return res.Count, errors.WithStack(err)
+	}
+
+	tmpQuery := Q(q.Connection)
+	q.Clone(tmpQuery)
+	tmpQuery.Paginator = nil
+	tmpQuery.orderClauses = clauses{}
+	tmpQuery.limitResults = 0
+	query, args, err := tmpQuery.toSQL(m)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+
+	err = q.Connection.timeFunc("CountEstimate", func() error {
+		switch dialect {
+		case "postgres":
+			plan := []string{}
+			stmt := "EXPLAIN (FORMAT JSON) " + query
+			if err := q.Connection.Store.Select(&plan, stmt, args...); err != nil {
+				return err
+			}
+			return parsePostgresPlanRows(plan, res)
+		default:
+			rows, err := q.Connection.Store.Queryx("EXPLAIN "+query, args...)
+			if err != nil {
+				return err
+			}
+			defer rows.Close()
+			// the first row is the table the query is driven by.
+			if rows.Next() {
+				row := map[string]interface{}{}
+				if err := rows.MapScan(row); err != nil {
+					return err
+				}
+				res.Count, _ = strconv.Atoi(explainString(row["rows"]))
+			}
+			return rows.Err()
+		}
+	})
+	return res.Count, errors.WithStack(err)
+}
+
+func parsePostgresPlanRows(plan []string, res *rowCount) error {
+	if len(plan) == 0 {
+		return errors.New("empty query plan")
+	}
+	p := []struct {
+		Plan struct {
+			Rows float64 `json:"Plan Rows"`
+		} `json:"Plan"`
+	}{}
+	if err := json.Unmarshal([]byte(plan[0]), &p); err != nil {
+		return errors.Wrap(err, "could not parse query plan")
+	}
+	if len(p) > 0 {
+		res.Count = int(p[0].Plan.Rows)
+	}
+	return nil
+}
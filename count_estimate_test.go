@@ -0,0 +1,18 @@
+package pop
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parsePostgresPlanRows(t *testing.T) {
+	r := require.New(t)
+
+	res := &rowCount{}
+	err := parsePostgresPlanRows([]string{`[{"Plan": {"Node Type": "Seq Scan", "Plan Rows": 1234}}]`}, res)
+	r.NoError(err)
+	r.Equal(1234, res.Count)
+
+	r.Error(parsePostgresPlanRows([]string{}, res))
+}
@@ -0,0 +1,116 @@
+package pop
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+
+	"github.com/markbates/inflect"
+	"github.com/markbates/pop/columns"
+	"github.com/pkg/errors"
+)
+
+// counterCache is a column on a parent table counting the number of
+// children belonging to it. It is declared on the belongs_to field of
+// the child model:
+//
+//	type Comment struct {
+//		ID     int  `db:"id"`
+//		PostID int  `db:"post_id"`
+//		Post   Post `belongs_to:"post" counter_cache:"comments_count"`
+//	}
+type counterCache struct {
+	Table      string
+	Column     string
+	ForeignKey string
+	ParentID   interface{}
+}
+
+// counterCaches returns the counter caches declared on the model.
+func (m *Model) counterCaches() []counterCache {
+	ccs := []counterCache{}
+	rv := reflect.Indirect(reflect.ValueOf(m.Value))
+	if rv.Kind() != reflect.Struct {
+		return ccs
+	}
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tags := columns.TagsFor(f)
+		cc := tags.Find("counter_cache")
+		if cc.Empty() || tags.Find("belongs_to").Empty() {
+			continue
+		}
+		pt := f.Type
+		if pt.Kind() == reflect.Ptr {
+			pt = pt.Elem()
+		}
+		idField, ok := t.FieldByName(inflect.Capitalize(pt.Name()) + "ID")
+		if !ok {
+			continue
+		}
+		parent := &Model{Value: reflect.New(pt).Interface()}
+		ccs = append(ccs, counterCache{
+			Table:      parent.TableName(),
+			Column:     cc.Value,
+			ForeignKey: columns.TagsFor(idField).Find("db").Value,
+			ParentID:   rv.FieldByIndex(idField.Index).Interface(),
+		})
+	}
+	return ccs
+}
+
+// updateCounterCaches adds delta to all of the counter caches of the model.
+func (c *Connection) updateCounterCaches(m *Model, delta int) error {
+	for _, cc := range m.counterCaches() {
+		id := cc.ParentID
+		if v, ok := id.(driver.Valuer); ok {
+			var err error
+			if id, err = v.Value(); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		if id == nil {
+			continue
+		}
+		stmt := fmt.Sprintf("UPDATE %s SET %s = %s + ? WHERE id = ?", cc.Table, cc.Column, cc.Column)
+		if err := c.RawQuery(stmt, delta, id).Exec(); err != nil {
+			return errors.Wrapf(err, "could not update counter cache %s.%s", cc.Table, cc.Column)
+		}
+	}
+	return nil
+}
+
+// RecountCounterCaches recomputes all of the counter caches declared on
+// the model from the actual number of rows in its table. This can be used
+// to repair counters after data was changed outside of Pop.
+//
+//	c.RecountCounterCaches(&Comment{})
+func (c *Connection) RecountCounterCaches(model interface{}) error {
+	m := &Model{Value: model}
+	for _, cc := range m.counterCaches() {
+		if err := c.RecountCounterCache(cc.Table, cc.Column, m.TableName(), cc.ForeignKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RecountCounterCache recomputes the counter column of the parent table
+// from the number of rows in the child table pointing at each parent
+// through the foreign key column. The soft deleted rows, with a
+// deleted_at, are not counted, as `Destroy` decrements the counters.
+//
+//	c.RecountCounterCache("posts", "comments_count", "comments", "post_id")
+func (c *Connection) RecountCounterCache(parent, column, child, fk string) error {
+	cols, err := c.tableColumns(child)
+	if err != nil {
+		return errors.Wrapf(err, "could not recount %s.%s", parent, column)
+	}
+	where := fmt.Sprintf("%s.%s = %s.id", child, fk, parent)
+	if _, ok := cols["deleted_at"]; ok {
+		where += fmt.Sprintf(" AND %s.deleted_at IS NULL", child)
+	}
+	stmt := fmt.Sprintf("UPDATE %s SET %s = (SELECT COUNT(*) FROM %s WHERE %s)", parent, column, child, where)
+	return errors.Wrapf(c.RawQuery(stmt).Exec(), "could not recount %s.%s", parent, column)
+}
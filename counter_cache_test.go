@@ -0,0 +1,113 @@
+package pop_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/markbates/pop"
+	"github.com/markbates/pop/nulls"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CounterCache_Create_Destroy(t *testing.T) {
+	transaction(func(tx *pop.Connection) {
+		r := require.New(t)
+
+		post := &Post{Title: "Pop"}
+		r.NoError(tx.Create(post))
+
+		c1 := &Comment{Body: "one", PostID: nulls.NewInt(post.ID)}
+		r.NoError(tx.Create(c1))
+		c2 := &Comment{Body: "two", PostID: nulls.NewInt(post.ID)}
+		r.NoError(tx.Create(c2))
+		r.NoError(tx.Create(&Comment{Body: "orphan"}))
+
+		r.NoError(tx.Reload(post))
+		r.Equal(2, post.CommentsCount)
+
+		r.NoError(tx.Destroy(c1))
+		r.NoError(tx.Reload(post))
+		r.Equal(1, post.CommentsCount)
+	})
+}
+
+func Test_CounterCache_Destroy_Twice(t *testing.T) {
+	transaction(func(tx *pop.Connection) {
+		r := require.New(t)
+
+		post := &Post{Title: "Pop"}
+		r.NoError(tx.Create(post))
+		c1 := &Comment{Body: "one", PostID: nulls.NewInt(post.ID)}
+		r.NoError(tx.Create(c1))
+		r.NoError(tx.Create(&Comment{Body: "two", PostID: nulls.NewInt(post.ID)}))
+
+		n, err := tx.DestroyWithCount(c1)
+		r.NoError(err)
+		r.Equal(1, n)
+		n, err = tx.DestroyWithCount(c1)
+		r.NoError(err)
+		r.Equal(0, n)
+
+		r.NoError(tx.Reload(post))
+		r.Equal(1, post.CommentsCount)
+	})
+}
+
+// PostNote is a soft deletable child of Post, stored in the notes table.
+type PostNote struct {
+	ID        int        `db:"id"`
+	Title     string     `db:"title"`
+	PostID    nulls.Int  `db:"post_id"`
+	Post      Post       `belongs_to:"post" counter_cache:"comments_count"`
+	DeletedAt nulls.Time `db:"deleted_at"`
+	CreatedAt time.Time  `db:"created_at"`
+	UpdatedAt time.Time  `db:"updated_at"`
+}
+
+func (PostNote) TableName() string {
+	return "notes"
+}
+
+func Test_CounterCache_SoftDelete(t *testing.T) {
+	transaction(func(tx *pop.Connection) {
+		r := require.New(t)
+
+		post := &Post{Title: "Pop"}
+		r.NoError(tx.Create(post))
+		n1 := &PostNote{Title: "one", PostID: nulls.NewInt(post.ID)}
+		r.NoError(tx.Create(n1))
+		r.NoError(tx.Create(&PostNote{Title: "two", PostID: nulls.NewInt(post.ID)}))
+
+		r.NoError(tx.Destroy(n1))
+		r.NoError(tx.Reload(post))
+		r.Equal(1, post.CommentsCount)
+
+		// the row is already soft deleted.
+		n, err := tx.DestroyWithCount(&PostNote{ID: n1.ID, PostID: n1.PostID})
+		r.NoError(err)
+		r.Equal(0, n)
+		r.NoError(tx.Reload(post))
+		r.Equal(1, post.CommentsCount)
+
+		// the soft deleted rows are not counted back.
+		r.NoError(tx.RecountCounterCaches(&PostNote{}))
+		r.NoError(tx.Reload(post))
+		r.Equal(1, post.CommentsCount)
+	})
+}
+
+func Test_CounterCache_Recount(t *testing.T) {
+	transaction(func(tx *pop.Connection) {
+		r := require.New(t)
+
+		post := &Post{Title: "Pop"}
+		r.NoError(tx.Create(post))
+		r.NoError(tx.Create(&Comment{Body: "one", PostID: nulls.NewInt(post.ID)}))
+
+		r.NoError(tx.RawQuery("UPDATE posts SET comments_count = 42").Exec())
+		r.NoError(tx.RecountCounterCaches(&Comment{}))
+
+		r.NoError(tx.Reload(post))
+		r.Equal(1, post.CommentsCount)
+	})
+}
@@ -0,0 +1,171 @@
+package pop
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// CredentialsName is the name of the encrypted credentials file, looked up
+// next to the configuration file.
+var CredentialsName = "database.credentials"
+
+// CredentialsKeyEnv is the environment variable holding the key of the
+// credentials file: 32 bytes, hex encoded.
+const CredentialsKeyEnv = "POP_CREDENTIALS_KEY"
+
+// credentialsLoader reads the credentials file the first time one of its
+// credentials is used by the configuration.
+type credentialsLoader struct {
+	creds map[string]string
+}
+
+// get is the credential function of the configuration template:
+//
+//	password: {{ credential "production_password" }}
+func (l *credentialsLoader) get(name string) (string, error) {
+	if l.creds == nil {
+		creds, err := LoadCredentials()
+		if err != nil {
+			return "", err
+		}
+		l.creds = creds
+	}
+	v, ok := l.creds[name]
+	if !ok {
+		return "", errors.Errorf("credential %s is not in %s", name, CredentialsName)
+	}
+	return v, nil
+}
+
+// CredentialsKey reads the key of the credentials file from the
+// POP_CREDENTIALS_KEY environment variable.
+func CredentialsKey() ([]byte, error) {
+	v := os.Getenv(CredentialsKeyEnv)
+	if v == "" {
+		return nil, errors.Errorf("%s is not set", CredentialsKeyEnv)
+	}
+	key, err := hex.DecodeString(v)
+	if err != nil || len(key) != 32 {
+		return nil, errors.Errorf("%s must be 32 bytes, hex encoded", CredentialsKeyEnv)
+	}
+	return key, nil
+}
+
+// NewCredentialsKey generates a random key for the credentials file, hex
+// encoded.
+func NewCredentialsKey() (string, error) {
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return "", errors.WithStack(err)
+	}
+	return hex.EncodeToString(key), nil
+}
+
+// EncryptCredentials encrypts credentials with AES-256-GCM.
+func EncryptCredentials(key []byte, creds map[string]string) ([]byte, error) {
+	gcm, err := credentialsCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := yaml.Marshal(creds)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	sealed := gcm.Seal(nonce, nonce, plain, nil)
+	return []byte(base64.StdEncoding.EncodeToString(sealed) + "\n"), nil
+}
+
+// DecryptCredentials decrypts credentials encrypted by
+// `EncryptCredentials`.
+func DecryptCredentials(key []byte, b []byte) (map[string]string, error) {
+	gcm, err := credentialsCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(b)))
+	if err != nil {
+		return nil, errors.Wrap(err, "malformed credentials")
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("malformed credentials")
+	}
+	n := gcm.NonceSize()
+	plain, err := gcm.Open(nil, sealed[:n], sealed[n:], nil)
+	if err != nil {
+		return nil, errors.New("could not decrypt the credentials, is the key right?")
+	}
+	creds := map[string]string{}
+	if err := yaml.Unmarshal(plain, &creds); err != nil {
+		return nil, errors.Wrap(err, "malformed credentials")
+	}
+	return creds, nil
+}
+
+// LoadCredentials decrypts the credentials file with the key of
+// `CredentialsKey`.
+func LoadCredentials() (map[string]string, error) {
+	path, ok := findFile(CredentialsName)
+	if !ok {
+		return nil, errors.Errorf("could not find %s", CredentialsName)
+	}
+	key, err := CredentialsKey()
+	if err != nil {
+		return nil, err
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return DecryptCredentials(key, b)
+}
+
+// SetCredential sets a credential in the credentials file, creating it
+// next to the configuration file if needed.
+func SetCredential(name, value string) error {
+	key, err := CredentialsKey()
+	if err != nil {
+		return err
+	}
+	path, ok := findFile(CredentialsName)
+	creds := map[string]string{}
+	if ok {
+		if creds, err = LoadCredentials(); err != nil {
+			return err
+		}
+	} else {
+		path = CredentialsName
+		if cp, err := findConfigPath(); err == nil && !filepath.IsAbs(CredentialsName) {
+			path = filepath.Join(filepath.Dir(cp), CredentialsName)
+		}
+	}
+	creds[name] = value
+	b, err := EncryptCredentials(key, creds)
+	if err != nil {
+		return err
+	}
+	return errors.WithStack(ioutil.WriteFile(path, b, 0600))
+}
+
+func credentialsCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	return gcm, errors.WithStack(err)
+}
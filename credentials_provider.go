@@ -0,0 +1,282 @@
+package pop
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	_mysql "github.com/go-sql-driver/mysql"
+	"github.com/markbates/going/defaults"
+	"github.com/pkg/errors"
+)
+
+// Credentials are the user and password a connection logs in with.
+type Credentials struct {
+	User     string
+	Password string
+}
+
+// CredentialsProvider resolves the credentials of a connection when it is
+// opened, and again when the database rejects them, so the passwords can
+// be rotated without restarting the application.
+type CredentialsProvider interface {
+	Credentials(ctx context.Context, cd *ConnectionDetails) (Credentials, error)
+}
+
+var credentialsProviders = map[string]CredentialsProvider{
+	"env":   EnvCredentials{},
+	"file":  FileCredentials{},
+	"vault": VaultCredentials{},
+}
+var credentialsProvidersMu = sync.RWMutex{}
+
+// RegisterCredentialsProvider makes a provider available to the
+// connections with the "credentials" option set to name:
+//
+//	pop.RegisterCredentialsProvider("aws", awsSecrets{})
+func RegisterCredentialsProvider(name string, p CredentialsProvider) {
+	credentialsProvidersMu.Lock()
+	defer credentialsProvidersMu.Unlock()
+	credentialsProviders[name] = p
+}
+
+// credentialsProvider returns the provider of the connection, set with
+// the CredentialsProvider field or the "credentials" option.
+func (cd *ConnectionDetails) credentialsProvider() (CredentialsProvider, error) {
+	if cd.CredentialsProvider != nil {
+		return cd.CredentialsProvider, nil
+	}
+	name := cd.Options["credentials"]
+	if name == "" {
+		return nil, nil
+	}
+	credentialsProvidersMu.RLock()
+	defer credentialsProvidersMu.RUnlock()
+	p, ok := credentialsProviders[name]
+	if !ok {
+		return nil, errors.Errorf("unknown credentials provider %s", name)
+	}
+	return p, nil
+}
+
+// EnvCredentials reads the credentials from the environment variables
+// named by the "credentials_user_env" and "credentials_password_env"
+// options, DATABASE_USER and DATABASE_PASSWORD by default.
+type EnvCredentials struct{}
+
+// Credentials implements CredentialsProvider.
+func (EnvCredentials) Credentials(ctx context.Context, cd *ConnectionDetails) (Credentials, error) {
+	return Credentials{
+		User:     os.Getenv(defaults.String(cd.Options["credentials_user_env"], "DATABASE_USER")),
+		Password: os.Getenv(defaults.String(cd.Options["credentials_password_env"], "DATABASE_PASSWORD")),
+	}, nil
+}
+
+// FileCredentials reads the credentials from the files named by the
+// "credentials_user_file" and "credentials_password_file" options, such
+// as mounted Kubernetes secrets. The user file is optional.
+type FileCredentials struct{}
+
+// Credentials implements CredentialsProvider.
+func (FileCredentials) Credentials(ctx context.Context, cd *ConnectionDetails) (Credentials, error) {
+	creds := Credentials{}
+	if p := cd.Options["credentials_user_file"]; p != "" {
+		b, err := ioutil.ReadFile(p)
+		if err != nil {
+			return creds, errors.WithStack(err)
+		}
+		creds.User = strings.TrimSpace(string(b))
+	}
+	p := cd.Options["credentials_password_file"]
+	if p == "" {
+		return creds, errors.New("the credentials_password_file option is not set")
+	}
+	b, err := ioutil.ReadFile(p)
+	if err != nil {
+		return creds, errors.WithStack(err)
+	}
+	creds.Password = strings.TrimSpace(string(b))
+	return creds, nil
+}
+
+// VaultCredentials reads the credentials from the HashiCorp Vault secret
+// at the "vault_path" option, for instance the dynamic credentials of the
+// database secrets engine ("database/creds/app") or a KV secret with
+// username and password keys. The address and token of Vault are read
+// from the "vault_addr" option or $VAULT_ADDR, and from $VAULT_TOKEN.
+type VaultCredentials struct {
+	// Client is the HTTP client used to reach Vault, http.DefaultClient
+	// if nil
+	Client *http.Client
+}
+
+// Credentials implements CredentialsProvider.
+func (v VaultCredentials) Credentials(ctx context.Context, cd *ConnectionDetails) (Credentials, error) {
+	creds := Credentials{}
+	addr := defaults.String(cd.Options["vault_addr"], os.Getenv("VAULT_ADDR"))
+	path := cd.Options["vault_path"]
+	if addr == "" || path == "" {
+		return creds, errors.New("the vault_addr and vault_path options must be set")
+	}
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/v1/%s", strings.TrimSuffix(addr, "/"), strings.TrimPrefix(path, "/")), nil)
+	if err != nil {
+		return creds, errors.WithStack(err)
+	}
+	req.Header.Set("X-Vault-Token", os.Getenv("VAULT_TOKEN"))
+	client := v.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return creds, errors.Wrap(err, "could not reach vault")
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return creds, errors.Errorf("vault returned %s for %s", res.Status, path)
+	}
+
+	secret := struct {
+		Data map[string]interface{} `json:"data"`
+	}{}
+	if err := json.NewDecoder(res.Body).Decode(&secret); err != nil {
+		return creds, errors.Wrap(err, "could not read the vault secret")
+	}
+	data := secret.Data
+	// KV version 2 secrets nest their values in a second data key.
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		data = nested
+	}
+	creds.User, _ = data["username"].(string)
+	creds.Password, _ = data["password"].(string)
+	if creds.Password == "" {
+		return creds, errors.Errorf("the vault secret %s has no password", path)
+	}
+	return creds, nil
+}
+
+// applyCredentials sets the credentials on the details, including their
+// URL if they have one.
+func applyCredentials(cd *ConnectionDetails, creds Credentials) error {
+	cd.User = defaults.String(creds.User, cd.User)
+	cd.Password = creds.Password
+	if cd.URL == "" {
+		return nil
+	}
+	switch cd.Dialect {
+	case "mysql":
+		cfg, err := _mysql.ParseDSN(trimMySQLScheme(cd.URL))
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		cfg.User = cd.User
+		cfg.Passwd = cd.Password
+		cd.URL = cfg.FormatDSN()
+	case "postgres", "cockroach":
+		u, err := url.Parse(cd.URL)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		u.User = url.UserPassword(cd.User, cd.Password)
+		cd.URL = u.String()
+	}
+	return nil
+}
+
+// isAuthError returns true if err is the database rejecting the
+// credentials.
+func isAuthError(err error) bool {
+	msg := err.Error()
+	for _, s := range []string{"28P01", "password authentication failed", "Error 1045", "Access denied for user"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// credentialsConnector opens the connections of a pool with credentials
+// from a provider. They are resolved again when the database rejects
+// them, so the new connections of the pool use the rotated ones.
+type credentialsConnector struct {
+	driver   driver.Driver
+	details  ConnectionDetails
+	provider CredentialsProvider
+
+	mu  sync.Mutex
+	dsn string
+}
+
+// newCredentialsConnector resolves the credentials of d, and sets them on
+// its details.
+func newCredentialsConnector(d dialect, p CredentialsProvider) (*credentialsConnector, error) {
+	cd := d.Details()
+	db, err := sql.Open(cd.Dialect, "")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	drv := db.Driver()
+	db.Close()
+
+	c := &credentialsConnector{driver: drv, details: *cd, provider: p}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	creds, err := p.Credentials(ctx, cd)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get the database credentials")
+	}
+	if err := applyCredentials(cd, creds); err != nil {
+		return nil, err
+	}
+	c.dsn = d.URL()
+	return c, nil
+}
+
+// Connect implements driver.Connector.
+func (c *credentialsConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	c.mu.Lock()
+	dsn := c.dsn
+	c.mu.Unlock()
+	conn, err := c.driver.Open(dsn)
+	if err == nil || !isAuthError(err) {
+		return conn, err
+	}
+	if dsn, err = c.refresh(ctx); err != nil {
+		return nil, err
+	}
+	return c.driver.Open(dsn)
+}
+
+// refresh resolves the credentials again.
+func (c *credentialsConnector) refresh(ctx context.Context) (string, error) {
+	cd := c.details
+	creds, err := c.provider.Credentials(ctx, &cd)
+	if err != nil {
+		return "", errors.Wrap(err, "could not get the database credentials")
+	}
+	if err := applyCredentials(&cd, creds); err != nil {
+		return "", err
+	}
+	d, err := newDialect(&cd)
+	if err != nil {
+		return "", err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.dsn = d.URL()
+	return c.dsn, nil
+}
+
+// Driver implements driver.Connector.
+func (c *credentialsConnector) Driver() driver.Driver {
+	return c.driver
+}
@@ -0,0 +1,76 @@
+package pop
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_EncryptCredentials(t *testing.T) {
+	r := require.New(t)
+
+	hk, err := NewCredentialsKey()
+	r.NoError(err)
+	key, err := hex.DecodeString(hk)
+	r.NoError(err)
+
+	b, err := EncryptCredentials(key, map[string]string{"production_password": "s3cr3t"})
+	r.NoError(err)
+	r.NotContains(string(b), "s3cr3t")
+
+	creds, err := DecryptCredentials(key, b)
+	r.NoError(err)
+	r.Equal("s3cr3t", creds["production_password"])
+
+	other, err := NewCredentialsKey()
+	r.NoError(err)
+	otherKey, _ := hex.DecodeString(other)
+	_, err = DecryptCredentials(otherKey, b)
+	r.Error(err)
+}
+
+func Test_Credentials_Config(t *testing.T) {
+	r := require.New(t)
+
+	dir, err := ioutil.TempDir("", "pop")
+	r.NoError(err)
+	defer os.RemoveAll(dir)
+
+	name := CredentialsName
+	defer func() { CredentialsName = name }()
+	CredentialsName = filepath.Join(dir, "database.credentials")
+
+	hk, err := NewCredentialsKey()
+	r.NoError(err)
+	defer os.Setenv(CredentialsKeyEnv, os.Getenv(CredentialsKeyEnv))
+	os.Setenv(CredentialsKeyEnv, hk)
+
+	r.NoError(SetCredential("production_password", "s3cr3t"))
+	r.NoError(SetCredential("production_user", "app"))
+
+	b, err := renderConfig(strings.NewReader(`
+production:
+  dialect: "postgres"
+  user: {{ credential "production_user" }}
+  password: {{ credential "production_password" }}
+`), nil)
+	r.NoError(err)
+	deets, err := parseConfig(b)
+	r.NoError(err)
+	r.Equal("app", deets["production"].User)
+	r.Equal("s3cr3t", deets["production"].Password)
+
+	_, err = renderConfig(strings.NewReader(`{{ credential "missing" }}`), nil)
+	r.Error(err)
+	r.Contains(err.Error(), "credential missing is not in")
+
+	os.Setenv(CredentialsKeyEnv, "")
+	_, err = renderConfig(strings.NewReader(`{{ credential "production_user" }}`), nil)
+	r.Error(err)
+	r.Contains(err.Error(), "POP_CREDENTIALS_KEY is not set")
+}
@@ -0,0 +1,128 @@
+package pop
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Cursor is the opaque token of a position in the results of a query
+// paginated with `PaginateByCursor`, which can be handed to clients.
+type Cursor string
+
+// NewCursor returns the cursor of the position after the row of id.
+func NewCursor(id interface{}) (Cursor, error) {
+	b, err := json.Marshal(id)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	return Cursor(base64.RawURLEncoding.EncodeToString(b)), nil
+}
+
+// ID returns the id of the row the cursor is after.
+func (c Cursor) ID() (interface{}, error) {
+	b, err := base64.RawURLEncoding.DecodeString(string(c))
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid cursor %q", string(c))
+	}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	var id interface{}
+	if err := d.Decode(&id); err != nil {
+		return nil, errors.Wrapf(err, "invalid cursor %q", string(c))
+	}
+	switch v := id.(type) {
+	case json.Number:
+		return v.Int64()
+	case string:
+		return v, nil
+	}
+	return nil, errors.Errorf("invalid cursor %q", string(c))
+}
+
+// CursorPaginator is the keyset pagination of a query: the pages are
+// ordered by id, and start after the id of the last row of the previous
+// page, which stays fast on large tables where OFFSET is not.
+type CursorPaginator struct {
+	// After is the id the page starts after, nil for the first page.
+	After interface{} `json:"-"`
+	// Size is the maximum number of results of the page.
+	Size int `json:"size"`
+	// Next is the cursor of the next page, empty on the last page.
+	Next Cursor `json:"next,omitempty"`
+	// CurrentEntriesSize is the number of results of the page.
+	CurrentEntriesSize int `json:"current_entries_size"`
+	err                error
+}
+
+// PaginateByCursor paginates the query by id, see `Query.PaginateByCursor`.
+func (c *Connection) PaginateByCursor(after interface{}, size int) *Query {
+	return Q(c).PaginateByCursor(after, size)
+}
+
+// PaginateByCursor returns the size rows whose id is greater than after,
+// which is nil for the first page, an id, or a `Cursor`. `All` sets the
+// cursor of the next page:
+//
+//	q := c.PaginateByCursor(pop.Cursor(req.URL.Query().Get("after")), 50)
+//	err := q.All(&users)
+//	next := q.CursorPaginator.Next
+//
+// The pages are ordered by id, the other orders of the query are ignored.
+func (q *Query) PaginateByCursor(after interface{}, size int) *Query {
+	if size < 1 {
+		size = PaginatorPerPageDefault
+	}
+	cp := &CursorPaginator{After: after, Size: size}
+	if c, ok := after.(Cursor); ok {
+		cp.After = nil
+		if c != "" {
+			cp.After, cp.err = c.ID()
+		}
+	}
+	q.CursorPaginator = cp
+	return q
+}
+
+// cursorClause returns the clause of the rows after the cursor.
+func (sq *sqlBuilder) cursorClause() (clause, bool) {
+	cp := sq.Query.CursorPaginator
+	if cp == nil || cp.After == nil {
+		return clause{}, false
+	}
+	return clause{Fragment: fmt.Sprintf("%s > ?", sq.cursorColumn()), Arguments: []interface{}{cp.After}}, true
+}
+
+func (sq *sqlBuilder) cursorColumn() string {
+	alias := sq.Model.As
+	if alias == "" {
+		alias = strings.Replace(sq.Model.TableName(), ".", "_", -1)
+	}
+	return sq.Query.Connection.Dialect.Quote(alias + ".id")
+}
+
+// setNext reads one row more than the size of the page, to tell whether
+// there is a next page, and removes it from models.
+func (cp *CursorPaginator) setNext(models interface{}) error {
+	v := reflect.Indirect(reflect.ValueOf(models))
+	cp.Next = ""
+	if v.Len() > cp.Size {
+		v.Set(v.Slice(0, cp.Size))
+		last := v.Index(cp.Size - 1)
+		if last.Kind() != reflect.Ptr {
+			last = last.Addr()
+		}
+		next, err := NewCursor((&Model{Value: last.Interface()}).ID())
+		if err != nil {
+			return err
+		}
+		cp.Next = next
+	}
+	cp.CurrentEntriesSize = v.Len()
+	return nil
+}
@@ -0,0 +1,61 @@
+package pop_test
+
+import (
+	"testing"
+
+	"github.com/markbates/pop"
+	"github.com/markbates/pop/nulls"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Cursor(t *testing.T) {
+	r := require.New(t)
+
+	c, err := pop.NewCursor(42)
+	r.NoError(err)
+	id, err := c.ID()
+	r.NoError(err)
+	r.Equal(int64(42), id)
+
+	c, err = pop.NewCursor("f8b1d6c6-0f3c-4b5e-9a39-3c7f0b6c1a2e")
+	r.NoError(err)
+	id, err = c.ID()
+	r.NoError(err)
+	r.Equal("f8b1d6c6-0f3c-4b5e-9a39-3c7f0b6c1a2e", id)
+
+	_, err = pop.Cursor("not a cursor").ID()
+	r.Error(err)
+}
+
+func Test_PaginateByCursor(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		for _, name := range []string{"A", "B", "C", "D", "E"} {
+			r.NoError(tx.Create(&User{Name: nulls.NewString(name)}))
+		}
+
+		names := []string{}
+		var after interface{} = pop.Cursor("")
+		for i := 0; i < 3; i++ {
+			q := tx.Order("name desc").PaginateByCursor(after, 2)
+			users := Users{}
+			r.NoError(q.All(&users))
+			for _, u := range users {
+				names = append(names, u.Name.String)
+			}
+			if q.CursorPaginator.Next == "" {
+				r.Equal(1, q.CursorPaginator.CurrentEntriesSize)
+				break
+			}
+			r.Equal(2, q.CursorPaginator.CurrentEntriesSize)
+			after = q.CursorPaginator.Next
+		}
+		r.Equal([]string{"A", "B", "C", "D", "E"}, names)
+
+		users := Users{}
+		r.NoError(tx.Where("name <> ?", "A").PaginateByCursor(nil, 10).All(&users))
+		r.Len(users, 4)
+
+		r.Error(tx.PaginateByCursor(pop.Cursor("!"), 10).All(&users))
+	})
+}
@@ -1,15 +1,32 @@
 package pop
 
-import "github.com/jmoiron/sqlx"
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+)
 
 type dB struct {
 	*sqlx.DB
+	// stmts caches the prepared statements, if enabled
+	stmts *stmtCache
+}
+
+// newDB returns the store of the database pool db of the dialect d.
+func newDB(d dialect, db *sqlx.DB) *dB {
+	return &dB{DB: db, stmts: newStmtCache(d.Details(), db)}
 }
 
 func (db *dB) Transaction() (*Tx, error) {
 	return newTX(db)
 }
 
+// TransactionContext starts a transaction which is rolled back if ctx is
+// cancelled before it is committed.
+func (db *dB) TransactionContext(ctx context.Context) (*Tx, error) {
+	return newTXContext(ctx, db)
+}
+
 func (db *dB) Rollback() error {
 	return nil
 }
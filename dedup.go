@@ -0,0 +1,122 @@
+package pop
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+type dedupKey struct{}
+
+// Deduplicate returns a copy of ctx in which the identical queries run at
+// the same time share a single round trip to the database. The queries
+// of the connections carrying the context, see `Connection.WithContext`,
+// are identical when they have the same SQL and arguments, go to the same
+// database, and load the same type of models:
+//
+//	ctx := pop.Deduplicate(r.Context())
+//	c := tx.WithContext(ctx)
+//	// the widgets rendered in parallel all load the current user once
+//	err := c.Find(&user, id)
+//
+// Each query gets its own copy of the models, though the slices, maps and
+// pointers they hold are shared. A query starting once the first one is
+// done runs again: use it for a request, not as a cache.
+func Deduplicate(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dedupKey{}, &dedupGroup{calls: map[string]*dedupCall{}})
+}
+
+// dedupGroup runs the queries of a context.
+type dedupGroup struct {
+	mu    sync.Mutex
+	calls map[string]*dedupCall
+}
+
+// dedupCall is a query in flight, and its result once it is done.
+type dedupCall struct {
+	wg     sync.WaitGroup
+	result reflect.Value
+	err    error
+	// dups is the number of queries waiting for this one
+	dups int
+}
+
+// do runs fn to load the value of key, unless it is already being loaded,
+// and copies the value into dest.
+func (g *dedupGroup) do(key string, dest interface{}, fn func(dest interface{}) error) error {
+	g.mu.Lock()
+	if call, ok := g.calls[key]; ok {
+		call.dups++
+		g.mu.Unlock()
+		call.wg.Wait()
+		if call.err != nil {
+			return call.err
+		}
+		copyResult(dest, call.result)
+		return nil
+	}
+	call := &dedupCall{}
+	call.wg.Add(1)
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	// the models are loaded apart from dest, which the caller may change
+	// before the other queries copied them.
+	call.result = reflect.New(reflect.TypeOf(dest).Elem())
+	call.err = fn(call.result.Interface())
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+	call.wg.Done()
+
+	if call.err != nil {
+		return call.err
+	}
+	copyResult(dest, call.result)
+	return nil
+}
+
+// copyResult copies the value result points to into dest. The elements of
+// a slice are appended to it, as they are when the rows are scanned, and
+// the models of a slice of pointers are copied.
+func copyResult(dest interface{}, result reflect.Value) {
+	d := reflect.ValueOf(dest).Elem()
+	r := result.Elem()
+	if r.Kind() != reflect.Slice {
+		d.Set(r)
+		return
+	}
+	if r.Type().Elem().Kind() != reflect.Ptr {
+		d.Set(reflect.AppendSlice(d, r))
+		return
+	}
+	for i := 0; i < r.Len(); i++ {
+		e := r.Index(i)
+		if !e.IsNil() {
+			cp := reflect.New(e.Type().Elem())
+			cp.Elem().Set(e.Elem())
+			e = cp
+		}
+		d.Set(reflect.Append(d, e))
+	}
+}
+
+// dedupe runs fn with dest, or shares the result of an identical query
+// running on the store s if the context of the connection was set up
+// with `Deduplicate`.
+func (c *Connection) dedupe(s store, query string, args []interface{}, dest interface{}, fn func(dest interface{}) error) error {
+	if c == nil || c.ctx == nil {
+		return fn(dest)
+	}
+	g, ok := c.ctx.Value(dedupKey{}).(*dedupGroup)
+	if !ok {
+		return fn(dest)
+	}
+	if is, ok := s.(*instrumentedStore); ok {
+		// the copies of a connection wrap the same store.
+		s = is.store
+	}
+	key := fmt.Sprintf("%p\x00%T\x00%s\x00%#v", s, dest, query, args)
+	return g.do(key, dest, fn)
+}
@@ -0,0 +1,95 @@
+package pop
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_dedupGroup(t *testing.T) {
+	r := require.New(t)
+
+	g := &dedupGroup{calls: map[string]*dedupCall{}}
+	started := make(chan struct{})
+	release := make(chan struct{})
+	calls := 0
+	load := func(dest interface{}) error {
+		calls++
+		close(started)
+		<-release
+		*dest.(*[]string) = append(*dest.(*[]string), "a", "b")
+		return nil
+	}
+
+	var leader []string
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		r.NoError(g.do("q", &leader, load))
+	}()
+	<-started
+
+	follower := []string{"z"}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		r.NoError(g.do("q", &follower, func(interface{}) error {
+			return errors.New("the query should be shared")
+		}))
+	}()
+	// lets the follower wait for the leader
+	for {
+		g.mu.Lock()
+		n := g.calls["q"].dups
+		g.mu.Unlock()
+		if n == 1 {
+			break
+		}
+	}
+	close(release)
+	wg.Wait()
+
+	r.Equal(1, calls)
+	r.Equal([]string{"a", "b"}, leader)
+	r.Equal([]string{"z", "a", "b"}, follower)
+	leader[0] = "changed"
+	r.Equal("a", follower[1])
+
+	// the queries done are not cached
+	err := g.do("q", &leader, func(interface{}) error {
+		return errors.New("run again")
+	})
+	r.EqualError(err, "run again")
+}
+
+func Test_copyResult_Pointers(t *testing.T) {
+	r := require.New(t)
+
+	type m struct{ Name string }
+	src := []*m{{Name: "a"}}
+	dest := []*m{}
+	copyResult(&dest, reflect.ValueOf(&src))
+	r.Len(dest, 1)
+	r.Equal("a", dest[0].Name)
+	dest[0].Name = "b"
+	r.Equal("a", src[0].Name)
+}
+
+func Test_Connection_dedupe(t *testing.T) {
+	r := require.New(t)
+
+	c := &Connection{}
+	n := 0
+	fn := func(dest interface{}) error {
+		n++
+		return nil
+	}
+	r.NoError(c.dedupe(nil, "SELECT 1", nil, &n, fn))
+	r.NoError(c.WithContext(Deduplicate(context.Background())).dedupe(nil, "SELECT 1", nil, new(int), fn))
+	r.Equal(2, n)
+}
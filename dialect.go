@@ -16,6 +16,7 @@ func init() {
 }
 
 type dialect interface {
+	quoter
 	URL() string
 	MigrationURL() string
 	Details() *ConnectionDetails
@@ -32,16 +33,22 @@ type dialect interface {
 	FizzTranslator() fizz.Translator
 	Lock(func() error) error
 	TruncateAll(*Connection) error
+	Capabilities() Capabilities
 }
 
-func genericCreate(s store, model *Model, cols columns.Columns) error {
+// afterOpener is implemented by dialects needing to set up a
+// connection once it is opened.
+type afterOpener interface {
+	afterOpen(store) error
+}
+
+func genericCreate(s store, model *Model, cols columns.Columns, q quoter) error {
 	keyType := model.PrimaryKeyType()
 	switch keyType {
 	case "int", "int64":
 		var id int64
 		w := cols.Writeable()
-		query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", model.TableName(), w.String(), w.SymbolizedString())
-		Log(query)
+		query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", q.Quote(model.TableName()), w.QuotedString(q.Quote), w.SymbolizedString())
 		res, err := s.NamedExec(query, model.Value)
 		if err != nil {
 			return errors.WithStack(err)
@@ -54,23 +61,21 @@ func genericCreate(s store, model *Model, cols columns.Columns) error {
 			return errors.WithStack(err)
 		}
 		return nil
-	case "UUID":
-		if model.ID() == emptyUUID {
+	case "UUID", "string":
+		if keyType == "UUID" && model.ID() == emptyUUID {
 			u, err := uuid.NewV4()
 			if err != nil {
 				return errors.WithStack(err)
 			}
 			model.setID(u)
 		}
+		if keyType == "string" && fmt.Sprint(model.ID()) == "" {
+			return errors.Errorf("%s can not be created without an id", model.TableName())
+		}
 		w := cols.Writeable()
 		w.Add("id")
-		query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", model.TableName(), w.String(), w.SymbolizedString())
-		Log(query)
-		stmt, err := s.PrepareNamed(query)
-		if err != nil {
-			return errors.WithStack(err)
-		}
-		_, err = stmt.Exec(model.Value)
+		query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", q.Quote(model.TableName()), w.QuotedString(q.Quote), w.SymbolizedString())
+		_, err := s.NamedExec(query, model.Value)
 		if err != nil {
 			return errors.WithStack(err)
 		}
@@ -79,27 +84,37 @@ func genericCreate(s store, model *Model, cols columns.Columns) error {
 	return errors.Errorf("can not use %s as a primary key type!", keyType)
 }
 
-func genericUpdate(s store, model *Model, cols columns.Columns) error {
-	stmt := fmt.Sprintf("UPDATE %s SET %s where %s", model.TableName(), cols.Writeable().UpdateString(), model.whereID())
-	Log(stmt)
-	_, err := s.NamedExec(stmt, model.Value)
+// namedGet runs the named query, binding the fields of arg, and scans the
+// row it returns into dest, e.g. the id returned by an INSERT.
+func namedGet(s store, dest interface{}, query string, arg interface{}) error {
+	q, args, err := s.BindNamed(query, arg)
 	if err != nil {
 		return errors.WithStack(err)
 	}
+	return s.Get(dest, q, args...)
+}
+
+func genericUpdate(s store, model *Model, cols columns.Columns, q quoter) error {
+	stmt := fmt.Sprintf("UPDATE %s SET %s where %s", q.Quote(model.TableName()), cols.Writeable().QuotedUpdateString(q.Quote), model.whereID(q))
+	res, err := s.NamedExec(stmt, model.Value)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	model.rowsAffected, _ = res.RowsAffected()
 	return nil
 }
 
-func genericDestroy(s store, model *Model) error {
-	stmt := fmt.Sprintf("DELETE FROM %s WHERE %s", model.TableName(), model.whereID())
-	err := genericExec(s, stmt)
+func genericDestroy(s store, model *Model, q quoter) error {
+	stmt := fmt.Sprintf("DELETE FROM %s WHERE %s", q.Quote(model.TableName()), model.whereID(q))
+	res, err := s.NamedExec(stmt, model.Value)
 	if err != nil {
 		return errors.WithStack(err)
 	}
+	model.rowsAffected, _ = res.RowsAffected()
 	return nil
 }
 
 func genericExec(s store, stmt string) error {
-	Log(stmt)
 	_, err := s.Exec(stmt)
 	if err != nil {
 		return errors.WithStack(err)
@@ -108,21 +123,34 @@ func genericExec(s store, stmt string) error {
 }
 
 func genericSelectOne(s store, model *Model, query Query) error {
-	sql, args := query.ToSQL(model)
-	Log(sql, args...)
-	err := s.Get(model.Value, sql, args...)
+	sql, args, err := query.toSQL(model)
 	if err != nil {
 		return errors.WithStack(err)
 	}
-	return nil
+	err = query.Connection.dedupe(s, sql, args, model.Value, func(dest interface{}) error {
+		m := *model
+		m.Value = dest
+		if query.strict(model) {
+			return strictGet(s, &m, sql, args)
+		}
+		return getModel(s, &m, sql, args)
+	})
+	return errors.WithStack(err)
 }
 
 func genericSelectMany(s store, models *Model, query Query) error {
-	sql, args := query.ToSQL(models)
-	Log(sql, args...)
-	err := s.Select(models.Value, sql, args...)
+	sql, args, err := query.toSQL(models)
 	if err != nil {
 		return errors.WithStack(err)
 	}
-	return nil
+	defer preallocate(models.Value, query.sizeHint())()
+	err = query.Connection.dedupe(s, sql, args, models.Value, func(dest interface{}) error {
+		m := *models
+		m.Value = dest
+		if query.strict(models) {
+			return strictSelect(s, &m, sql, args)
+		}
+		return selectModels(s, &m, sql, args)
+	})
+	return errors.WithStack(err)
 }
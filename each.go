@@ -0,0 +1,226 @@
+package pop
+
+import (
+	"reflect"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// Each runs fn on each of the records matching the query, see
+// `Query.Each`.
+func (c *Connection) Each(model interface{}, fn func(model interface{}) error) error {
+	return Q(c).Each(model, fn)
+}
+
+// Each scans the records matching the query into model one at a time, and
+// runs fn on it. The rows are streamed from the database, so that any
+// number of records can be processed without holding them in memory:
+//
+//	u := &User{}
+//	err := c.Where("active = ?", true).Each(u, func(interface{}) error {
+//		return mailer.Send(u.Email)
+//	})
+//
+// The model is reused for the next record once fn returns, and an error
+// returned by fn stops the iteration. When the query times out while the
+// rows are streamed, fn has run on the records read so far, and the error
+// is a `QueryTimeoutError`. The rows stay open while fn runs:
+// in a transaction, fn can not run queries on it with the drivers which
+// do not support it, such as PostgreSQL and MySQL. For the same reason,
+// the associations can not be loaded with `Eager`, use `EachBatch`.
+func (q *Query) Each(model interface{}, fn func(model interface{}) error) error {
+	if err := checkModel(model); err != nil {
+		return err
+	}
+	if q.eager {
+		return errors.New("the associations can not be loaded while the records are streamed, use EachBatch instead")
+	}
+	m := &Model{Value: model}
+	v := reflect.ValueOf(model).Elem()
+	pr := q.Connection.newProgress("Each", 0)
+	pr.p.Table = m.TableName()
+	return q.Connection.timeFunc("Each", func() error {
+		err := q.eachRow(m, func(rows *sqlx.Rows) error {
+			v.Set(reflect.Zero(v.Type()))
+			if err := rows.StructScan(model); err != nil {
+				// the rows are closed once the context is done.
+				return timeoutError(err)
+			}
+			if err := m.afterFind(q.Connection); err != nil {
+				return err
+			}
+			if err := fn(model); err != nil {
+				return err
+			}
+			pr.add(1)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		pr.done()
+		return nil
+	})
+}
+
+// EachBatch runs fn on the records matching the query in batches, see
+// `Query.EachBatch`.
+func (c *Connection) EachBatch(models interface{}, size int, fn func(batch interface{}) error) error {
+	return Q(c).EachBatch(models, size, fn)
+}
+
+// EachBatch scans the records matching the query into models, a pointer
+// to a slice, size records at a time, and runs fn on each batch. The rows
+// are streamed from the database, so that any number of records can be
+// processed without holding them in memory:
+//
+//	users := []User{}
+//	err := c.Order("id asc").EachBatch(&users, 1000, func(interface{}) error {
+//		return index.Add(users)
+//	})
+//
+// The slice is reused for the next batch once fn returns, and an error
+// returned by fn stops the iteration. As with `Each`, the rows stay open
+// while fn runs. When the query times out while the rows are streamed,
+// the records read so far are passed to fn as a last, partial, batch
+// before the `QueryTimeoutError` is returned.
+//
+// With `Eager`, each batch is read with a query of its own, using LIMIT and
+// OFFSET, and its associations are loaded once its rows are closed. The
+// query must then be ordered, so that the batches do not overlap, and
+// can not be paginated or limited.
+func (q *Query) EachBatch(models interface{}, size int, fn func(batch interface{}) error) error {
+	if err := checkModels(models); err != nil {
+		return err
+	}
+	if size < 1 {
+		return errors.Errorf("invalid batch size %d", size)
+	}
+	m := &Model{Value: models}
+	v := reflect.ValueOf(models).Elem()
+	if v.Kind() != reflect.Slice {
+		return errors.Errorf("%s is not a pointer to a slice", v.Type())
+	}
+	el := v.Type().Elem()
+	pr := q.Connection.newProgress("EachBatch", 0)
+	pr.p.Table = m.TableName()
+	batch := func(last bool) error {
+		if err := m.afterFind(q.Connection); err != nil {
+			return err
+		}
+		if q.eager {
+			if err := q.loadEager(models); err != nil {
+				return err
+			}
+		}
+		err := fn(models)
+		pr.p.Rows += int64(v.Len())
+		v.Set(v.Slice(0, 0))
+		if err == nil && last {
+			pr.done()
+		} else if err == nil {
+			pr.report()
+		}
+		return err
+	}
+	scan := func(rows *sqlx.Rows) error {
+		var e reflect.Value
+		if el.Kind() == reflect.Ptr {
+			e = reflect.New(el.Elem())
+		} else {
+			e = reflect.New(el)
+		}
+		if err := rows.StructScan(e.Interface()); err != nil {
+			// the rows are closed once the context is done.
+			return timeoutError(err)
+		}
+		if el.Kind() != reflect.Ptr {
+			e = e.Elem()
+		}
+		v.Set(reflect.Append(v, e))
+		return nil
+	}
+	if q.eager {
+		if q.Paginator != nil || q.CursorPaginator != nil || q.limitResults > 0 {
+			return errors.New("the associations can not be loaded in batches of a paginated or limited query")
+		}
+		return q.Connection.timeFunc("EachBatch", func() error {
+			for page := 1; ; page++ {
+				v.Set(reflect.MakeSlice(v.Type(), 0, size))
+				pq := *q
+				pq.Paginator = NewPaginator(page, size)
+				if err := pq.eachRow(m, scan); err != nil {
+					return err
+				}
+				if v.Len() == 0 {
+					pr.done()
+					return nil
+				}
+				last := v.Len() < size
+				if err := batch(last); err != nil || last {
+					return err
+				}
+			}
+		})
+	}
+	return q.Connection.timeFunc("EachBatch", func() error {
+		v.Set(reflect.MakeSlice(v.Type(), 0, size))
+		err := q.eachRow(m, func(rows *sqlx.Rows) error {
+			if err := scan(rows); err != nil {
+				return err
+			}
+			if v.Len() < size {
+				return nil
+			}
+			return batch(false)
+		})
+		if err != nil {
+			if IsQueryTimeout(err) && v.Len() > 0 {
+				if berr := batch(false); berr != nil {
+					return berr
+				}
+			}
+			return err
+		}
+		if v.Len() > 0 {
+			return batch(true)
+		}
+		pr.done()
+		return nil
+	})
+}
+
+// eachRow runs the query of m, and scan on each of its rows.
+func (q *Query) eachRow(m *Model, scan func(rows *sqlx.Rows) error) error {
+	if q.eager && q.eagerMode == EagerJoin {
+		return errors.New("the associations can not be joined to streamed records, use Eager instead")
+	}
+	query, args, err := q.toSQL(m)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	s := q.store()
+	rows, err := s.Queryx(query, args...)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer rows.Close()
+	if q.strict(m) {
+		if err = checkResultColumns(rows, m); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	for rows.Next() {
+		if err = scan(rows); err != nil {
+			return errors.WithStack(scanError(rows, m, err))
+		}
+	}
+	err = rows.Err()
+	if ctx := q.Connection.ctx; err == nil && ctx != nil {
+		// some drivers end the rows without an error once the context is
+		// done, the records read are then incomplete.
+		err = ctx.Err()
+	}
+	return errors.WithStack(timeoutError(err))
+}
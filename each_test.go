@@ -0,0 +1,107 @@
+package pop_test
+
+import (
+	"github.com/pkg/errors"
+	"testing"
+
+	"github.com/markbates/pop"
+	"github.com/markbates/pop/nulls"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Each(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		for _, name := range []string{"A", "B", "C"} {
+			r.NoError(tx.Create(&User{Name: nulls.NewString(name)}))
+		}
+
+		names := []string{}
+		u := &User{}
+		err := tx.Order("name asc").Each(u, func(m interface{}) error {
+			r.Equal(u, m)
+			names = append(names, u.Name.String)
+			return nil
+		})
+		r.NoError(err)
+		r.Equal([]string{"A", "B", "C"}, names)
+
+		stop := errors.New("stop")
+		count := 0
+		err = tx.Each(u, func(interface{}) error {
+			count++
+			return stop
+		})
+		r.Equal(stop, errors.Cause(err))
+		r.Equal(1, count)
+
+		r.Error(tx.Each(User{}, func(interface{}) error { return nil }))
+	})
+}
+
+func Test_EachBatch(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		u := &User{Name: nulls.NewString("Mark")}
+		r.NoError(tx.Create(u))
+		for _, title := range []string{"A", "B", "C", "D", "E"} {
+			r.NoError(tx.Create(&Book{Title: title, Isbn: title, UserID: nulls.NewInt(u.ID)}))
+		}
+
+		sizes := []int{}
+		titles := []string{}
+		books := Books{}
+		err := tx.Eager("User").Order("title asc").EachBatch(&books, 2, func(batch interface{}) error {
+			r.Equal(&books, batch)
+			sizes = append(sizes, len(books))
+			for _, b := range books {
+				r.Equal("Mark", b.User.Name.String)
+				titles = append(titles, b.Title)
+			}
+			return nil
+		})
+		r.NoError(err)
+		r.Equal([]int{2, 2, 1}, sizes)
+		r.Equal([]string{"A", "B", "C", "D", "E"}, titles)
+
+		ptrs := []*Book{}
+		count := 0
+		r.NoError(tx.Where("title > ?", "C").EachBatch(&ptrs, 10, func(interface{}) error {
+			count += len(ptrs)
+			return nil
+		}))
+		r.Equal(2, count)
+
+		r.NoError(tx.Where("title = ?", "Z").EachBatch(&books, 10, func(interface{}) error {
+			r.Fail("no batch expected")
+			return nil
+		}))
+		r.Error(tx.EachBatch(&books, 0, func(interface{}) error { return nil }))
+	})
+}
+
+func Test_EachBatch_Eager(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		u := &User{Name: nulls.NewString("Mark")}
+		r.NoError(tx.Create(u))
+		for _, title := range []string{"A", "B", "C", "D"} {
+			r.NoError(tx.Create(&Book{Title: title, Isbn: title, UserID: nulls.NewInt(u.ID)}))
+		}
+
+		sizes := []int{}
+		books := Books{}
+		err := tx.Eager("User").Order("title asc").EachBatch(&books, 2, func(interface{}) error {
+			sizes = append(sizes, len(books))
+			for _, b := range books {
+				r.Equal("Mark", b.User.Name.String)
+			}
+			return nil
+		})
+		r.NoError(err)
+		r.Equal([]int{2, 2}, sizes)
+
+		r.Error(tx.Eager("User").Limit(2).EachBatch(&books, 2, func(interface{}) error { return nil }))
+		r.Error(tx.Eager("User").Each(&Book{}, func(interface{}) error { return nil }))
+	})
+}
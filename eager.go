@@ -0,0 +1,294 @@
+package pop
+
+import (
+	"database/sql"
+	"reflect"
+	"strings"
+
+	"github.com/markbates/pop/associations"
+	"github.com/pkg/errors"
+)
+
+// Load loads all association or the fields specified in params for
+// an already loaded model, or slice of models. Nested associations
+// can be loaded by using a dotted path to the association.
+//
+//	tx.First(&u)
+//	tx.Load(&u)
+//	tx.Load(&u, "Books", "Books.User")
+func (c *Connection) Load(model interface{}, fields ...string) error {
+	q := Q(c)
+	q.eagerFields = fields
+	return q.loadEager(model)
+}
+
+// loadEager checks the requested eager fields against the guards set
+// on the query, then loads them into model.
+func (q *Query) loadEager(model interface{}) error {
+	if len(q.eagerFields) == 0 && len(q.eagerAllowed) > 0 {
+		q.eagerFields = q.eagerAllowed
+	}
+	if err := q.checkEagerFields(); err != nil {
+		return err
+	}
+	if q.eagerMode == EagerCache {
+		return q.eagerLoadCache(model)
+	}
+	return q.eagerAssociations(model)
+}
+
+// EagerWith is like `Eager` for a single field, whose queries are scoped
+// by scopes. The field can be nested, to scope each level separately:
+//
+//	q.EagerWith("Books", pop.Order("published_at desc"), pop.Where("published = ?", true)).
+//		EagerWith("Books.Writers", pop.Order("name asc"))
+//
+// The order of the scopes comes before the order_by tag of the
+// association.
+func (c *Connection) EagerWith(field string, scopes ...ScopeFunc) *Query {
+	return Q(c).EagerWith(field, scopes...)
+}
+
+// EagerWith is like `Eager` for a single field, whose queries are scoped
+// by scopes. The field can be nested, to scope each level separately:
+//
+//	q.EagerWith("Books", pop.Order("published_at desc"), pop.Where("published = ?", true)).
+//		EagerWith("Books.Writers", pop.Order("name asc"))
+//
+// The order of the scopes comes before the order_by tag of the
+// association.
+func (q *Query) EagerWith(field string, scopes ...ScopeFunc) *Query {
+	field = strings.TrimSpace(field)
+	if q.eagerScopes == nil {
+		q.eagerScopes = map[string][]ScopeFunc{}
+	}
+	q.eagerScopes[field] = append(q.eagerScopes[field], scopes...)
+	return q.Eager(field)
+}
+
+// scoped applies the scopes given with EagerWith to the field, relative to
+// the level q loads, to query.
+func (q *Query) scoped(field string, query *Query) *Query {
+	for _, sf := range q.eagerScopes[q.eagerPrefix+field] {
+		query = sf(query)
+	}
+	return query
+}
+
+// nestedQuery returns a copy of q loading the associations of field.
+func (q *Query) nestedQuery(field string) *Query {
+	sub := *q
+	sub.eagerPrefix = q.eagerPrefix + field + "."
+	return &sub
+}
+
+// EagerCache is like `Eager`, but loads each association of a slice of
+// models with a single `WHERE id IN (...)` query per associated table,
+// instead of a query per model.
+//
+//	c.EagerCache("Books", "Books.User").All(&users)
+func (c *Connection) EagerCache(fields ...string) *Query {
+	return Q(c).EagerCache(fields...)
+}
+
+// EagerCache is like `Eager`, but loads each association of a slice of
+// models with a single `WHERE id IN (...)` query per associated table,
+// instead of a query per model. The associations which can not be batched,
+// such as many_to_many, are still loaded one by one.
+func (q *Query) EagerCache(fields ...string) *Query {
+	q.eagerMode = EagerCache
+	return q.Eager(fields...)
+}
+
+// eagerLoadCache loads the associations of model, a model or a slice of
+// models, batching the lookups of each associated table.
+func (q *Query) eagerLoadCache(model interface{}) error {
+	return q.preload(preloadModels(reflect.ValueOf(model)), q.eagerFields)
+}
+
+func (q *Query) checkEagerFields() error {
+	if q.eagerErr != nil {
+		return q.eagerErr
+	}
+	allowed := map[string]bool{}
+	for _, f := range q.eagerAllowed {
+		// allowing a path allows every step leading to it.
+		parts := strings.Split(strings.TrimSpace(f), ".")
+		for i := range parts {
+			allowed[strings.Join(parts[:i+1], ".")] = true
+		}
+	}
+
+	for _, f := range q.eagerFields {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		depth := len(strings.Split(f, "."))
+		if q.eagerMaxDepth > 0 && depth > q.eagerMaxDepth {
+			return errors.Errorf("eager field %s is %d levels deep, the maximum is %d", f, depth, q.eagerMaxDepth)
+		}
+		if len(allowed) > 0 && !allowed[f] {
+			return errors.Errorf("eager field %s is not allowed", f)
+		}
+	}
+	return nil
+}
+
+func (q *Query) eagerAssociations(model interface{}) error {
+	// eagerAssociations for a slice or array model passed as a param.
+	v := reflect.ValueOf(model)
+	if reflect.Indirect(v).Kind() == reflect.Slice ||
+		reflect.Indirect(v).Kind() == reflect.Array {
+		// models of different types are batched by associated table.
+		if v.Elem().Type().Elem().Kind() == reflect.Interface {
+			return q.preload(preloadModels(v), q.eagerFields)
+		}
+		return q.eagerSliceAssociations(v.Elem())
+	}
+
+	fields, nested := splitEagerFields(q.eagerFields)
+	if len(fields) == 0 {
+		assos, err := associations.AssociationsForStruct(model)
+		if err != nil {
+			return err
+		}
+		return q.eachEager(len(assos), func(i int) error {
+			_, err := q.eagerAssociation(assos[i], "")
+			return err
+		})
+	}
+
+	return q.eachEager(len(fields), func(i int) error {
+		field := fields[i]
+		assos, err := associations.AssociationsForStruct(model, field)
+		if err != nil {
+			return err
+		}
+		for _, association := range assos {
+			loaded, err := q.eagerAssociation(association, field)
+			if err != nil {
+				return err
+			}
+			if loaded == nil || len(nested[field]) == 0 {
+				continue
+			}
+			sub := Q(q.Connection)
+			sub.eagerFields = nested[field]
+			sub.eagerContinue = q.eagerContinue
+			sub.eagerScopes = q.eagerScopes
+			sub.eagerPrefix = q.eagerPrefix + field + "."
+			sub.eagerParallel = q.eagerParallel
+			if err := sub.eagerAssociations(loaded); err != nil {
+				return errors.Wrapf(err, "could not load associations of %s", field)
+			}
+		}
+		return nil
+	})
+}
+
+// eagerAssociation loads a single association of the field, returning the
+// value it was loaded into, or nil if there was nothing to load.
+func (q *Query) eagerAssociation(association associations.Association, field string) (interface{}, error) {
+	if association == associations.SkippedAssociation {
+		return nil, nil
+	}
+
+	query := Q(q.Connection)
+	whereCondition, args := association.Constraint()
+	query = query.Where(whereCondition, args...)
+	query = q.scoped(field, query)
+
+	// validates if association is Sortable
+	sortable := (*associations.AssociationSortable)(nil)
+	t := reflect.TypeOf(association)
+	if t.Implements(reflect.TypeOf(sortable).Elem()) {
+		m := reflect.ValueOf(association).MethodByName("OrderBy")
+		out := m.Call([]reflect.Value{})
+		orderClause := out[0].String()
+		if orderClause != "" {
+			query = query.Order(orderClause)
+		}
+	}
+
+	value := association.Interface()
+	sqlSentence, args := query.ToSQL(&Model{Value: value})
+	query = query.RawQuery(sqlSentence, args...)
+
+	var err error
+	switch association.Kind() {
+	case reflect.Slice, reflect.Array:
+		err = query.All(value)
+	case reflect.Struct:
+		err = query.First(value)
+	}
+
+	if err != nil {
+		if errors.Cause(err) == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return value, nil
+}
+
+// eagerSliceAssociations loads the associations of every element of
+// the slice v. Failures are reported as EagerErrors, carrying the index
+// and ID of each failing element.
+func (q *Query) eagerSliceAssociations(v reflect.Value) error {
+	if v.Len() == 0 {
+		return nil
+	}
+
+	// a missing field is the same for every element, so report it once.
+	fields, _ := splitEagerFields(q.eagerFields)
+	el := reflect.New(v.Type().Elem()).Interface()
+	if _, err := associations.AssociationsForStruct(el, fields...); err != nil {
+		return err
+	}
+
+	errs := EagerErrors{}
+	for i := 0; i < v.Len(); i++ {
+		m := v.Index(i).Addr().Interface()
+		err := q.eagerAssociations(m)
+		if err == nil {
+			continue
+		}
+		errs = append(errs, &EagerError{
+			Index: i,
+			ID:    (&Model{Value: m}).ID(),
+			Err:   err,
+		})
+		if !q.eagerContinue {
+			break
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// splitEagerFields splits eager fields such as "Books.User" into the
+// list of top level associations to load, and the nested fields to
+// load for each of them.
+func splitEagerFields(fields []string) ([]string, map[string][]string) {
+	top := []string{}
+	nested := map[string][]string{}
+	for _, f := range fields {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		parts := strings.SplitN(f, ".", 2)
+		if _, ok := nested[parts[0]]; !ok {
+			top = append(top, parts[0])
+			nested[parts[0]] = []string{}
+		}
+		if len(parts) > 1 {
+			nested[parts[0]] = append(nested[parts[0]], parts[1])
+		}
+	}
+	return top, nested
+}
@@ -0,0 +1,33 @@
+package pop
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EagerError describes a failure to eager load the associations
+// of a single element of a slice.
+type EagerError struct {
+	// Index of the element in the slice
+	Index int
+	// ID is the primary key of the element
+	ID interface{}
+	// Err is the underlying error
+	Err error
+}
+
+func (e *EagerError) Error() string {
+	return fmt.Sprintf("eager loading element %d (id %v): %s", e.Index, e.ID, e.Err)
+}
+
+// EagerErrors collects all of the errors raised while eager loading
+// the associations of a slice of models.
+type EagerErrors []*EagerError
+
+func (e EagerErrors) Error() string {
+	xs := make([]string, 0, len(e))
+	for _, err := range e {
+		xs = append(xs, err.Error())
+	}
+	return strings.Join(xs, "; ")
+}
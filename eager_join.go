@@ -0,0 +1,439 @@
+package pop
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/markbates/inflect"
+	"github.com/markbates/pop/columns"
+	"github.com/pkg/errors"
+)
+
+// EagerMode is the strategy used to load the associations asked with
+// `Eager`.
+type EagerMode int
+
+const (
+	// EagerDefault loads the associations with a query per association,
+	// and per model for slices.
+	EagerDefault EagerMode = iota
+	// EagerJoin loads the belongs_to and has_one associations with a JOIN
+	// on the query of the models, and the other ones as EagerDefault does.
+	EagerJoin
+	// EagerCache loads the associations with a query per associated table,
+	// whatever the number of models.
+	EagerCache
+)
+
+var defaultEagerMode = EagerDefault
+var defaultEagerModeMu = sync.RWMutex{}
+
+// SetEagerMode sets the mode of the queries created from now on. It is
+// meant to be called once, at boot.
+func SetEagerMode(mode EagerMode) {
+	defaultEagerModeMu.Lock()
+	defer defaultEagerModeMu.Unlock()
+	defaultEagerMode = mode
+}
+
+func currentEagerMode() EagerMode {
+	defaultEagerModeMu.RLock()
+	defer defaultEagerModeMu.RUnlock()
+	return defaultEagerMode
+}
+
+// EagerJoin is like `Eager`, but loads the belongs_to and has_one
+// associations with a LEFT JOIN per association on the query of the models,
+// instead of a query per association and model.
+//
+//	c.EagerJoin("Owner", "Address").All(&dogs)
+func (c *Connection) EagerJoin(fields ...string) *Query {
+	return Q(c).EagerJoin(fields...)
+}
+
+// EagerJoin is like `Eager`, but loads the belongs_to and has_one
+// associations with a LEFT JOIN per association on the query of the models,
+// instead of a query per association and model. The other associations,
+// and the nested ones, are loaded as with `Eager`.
+//
+// The columns of the joined tables are selected along with the ones of the
+// model, so the where and order clauses must qualify the columns the tables
+// have in common, as in "dogs.name = ?".
+func (q *Query) EagerJoin(fields ...string) *Query {
+	q.eagerMode = EagerJoin
+	return q.Eager(fields...)
+}
+
+// joinedAssociation is a belongs_to or has_one association loaded with a
+// LEFT JOIN.
+type joinedAssociation struct {
+	field   reflect.StructField
+	target  reflect.Type
+	hasOne  bool
+	table   string
+	alias   string
+	on      string
+	columns []string
+	nested  []string
+}
+
+// selectMany runs the query of the models of m, joining the associations
+// asked with `EagerJoin`.
+func (q *Query) selectMany(m *Model) error {
+	if joins, err := q.eagerJoins(m); err != nil || len(joins) > 0 {
+		if err != nil {
+			return err
+		}
+		return q.selectJoined(m, joins, false)
+	}
+	return q.Connection.Dialect.SelectMany(q.store(), m, *q)
+}
+
+// selectOne runs the query of the model m, joining the associations asked
+// with `EagerJoin`.
+func (q *Query) selectOne(m *Model) error {
+	if joins, err := q.eagerJoins(m); err != nil || len(joins) > 0 {
+		if err != nil {
+			return err
+		}
+		return q.selectJoined(m, joins, true)
+	}
+	return q.Connection.Dialect.SelectOne(q.store(), m, *q)
+}
+
+// eagerJoins returns the associations of m to join, and leaves the other
+// eager fields of q to loadEager.
+func (q *Query) eagerJoins(m *Model) ([]*joinedAssociation, error) {
+	if !q.eager || q.eagerMode != EagerJoin || q.RawSQL.Fragment != "" {
+		return nil, nil
+	}
+	t := indirectType(reflect.TypeOf(m.Value))
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = indirectType(t.Elem())
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, nil
+	}
+	if len(q.eagerFields) == 0 && len(q.eagerAllowed) > 0 {
+		q.eagerFields = q.eagerAllowed
+	}
+	if err := q.checkEagerFields(); err != nil {
+		return nil, err
+	}
+
+	parent := joinParentAlias(m)
+	fields, nested := splitEagerFields(q.eagerFields)
+	names := preloadFieldNames(t, fields)
+	joins := []*joinedAssociation{}
+	rest := []string{}
+	for _, name := range names {
+		var j *joinedAssociation
+		// the scopes of EagerWith can not apply to a join.
+		if f, ok := t.FieldByName(name); ok && len(q.eagerScopes[name]) == 0 {
+			j = q.joinedAssociation(t, f, parent)
+		}
+		if j == nil {
+			rest = append(rest, name)
+			for _, n := range nested[name] {
+				rest = append(rest, name+"."+n)
+			}
+			continue
+		}
+		j.nested = nested[name]
+		joins = append(joins, j)
+	}
+	if len(joins) > 0 {
+		q.eagerFields = rest
+		q.eager = len(rest) > 0
+	}
+	return joins, nil
+}
+
+// joinParentAlias returns the alias of the table of m in the queries.
+func joinParentAlias(m *Model) string {
+	if m.As != "" {
+		return m.As
+	}
+	return strings.Replace(m.TableName(), ".", "_", -1)
+}
+
+// joinedAssociation returns the join of the field f of t, or nil if it
+// is not a belongs_to or has_one association.
+func (q *Query) joinedAssociation(t reflect.Type, f reflect.StructField, parent string) *joinedAssociation {
+	target := indirectType(f.Type)
+	if target.Kind() != reflect.Struct {
+		return nil
+	}
+	tags := columns.TagsFor(f)
+	quote := q.Connection.Dialect.Quote
+	tm := &Model{Value: reflect.New(target).Interface()}
+	j := &joinedAssociation{
+		field:  f,
+		target: target,
+		table:  tm.TableName(),
+		alias:  "eager_" + inflect.Underscore(f.Name),
+	}
+	switch {
+	case !tags.Find("belongs_to").Empty():
+		fk, ok := t.FieldByName(fmt.Sprintf("%sID", inflect.Capitalize(target.Name())))
+		if !ok {
+			return nil
+		}
+		j.on = fmt.Sprintf("%s.%s = %s.%s", quote(j.alias), quote("id"), quote(parent), quote(columns.TagsFor(fk).Find("db").Value))
+	case !tags.Find("has_one").Empty():
+		fk := fmt.Sprintf("%s_id", inflect.Underscore(t.Name()))
+		if v := tags.Find("fk_id").Value; v != "" {
+			fk = v
+		}
+		j.hasOne = true
+		j.on = fmt.Sprintf("%s.%s = %s.%s", quote(j.alias), quote(fk), quote(parent), quote("id"))
+	default:
+		return nil
+	}
+	cols := columns.ColumnsForStructWithAlias(tm.Value, j.table, j.alias).Readable()
+	for _, c := range cols.Cols {
+		// columns with a custom select are not read from the join.
+		if c.SelectSQL == c.Name || c.SelectSQL == j.alias+"."+c.Name {
+			j.columns = append(j.columns, c.Name)
+		}
+	}
+	sort.Strings(j.columns)
+	return j
+}
+
+// selectJoined runs the query of the models of m with a LEFT JOIN for each
+// association of joins, and scans the rows, associations included, into
+// m. It returns sql.ErrNoRows if one model is asked and none is found.
+func (q *Query) selectJoined(m *Model, joins []*joinedAssociation, one bool) error {
+	quote := q.Connection.Dialect.Quote
+	jq := *q
+	jq.joinClauses = append(joinClauses{}, q.joinClauses...)
+	extra := []string{}
+	for _, j := range joins {
+		jq.joinClauses = append(jq.joinClauses, joinClause{"LEFT JOIN", fmt.Sprintf("%s AS %s", quote(j.table), quote(j.alias)), j.on, nil})
+		for _, c := range j.columns {
+			extra = append(extra, fmt.Sprintf("%s.%s AS %s", quote(j.alias), quote(c), quote(j.alias+"__"+c)))
+		}
+	}
+	sb := jq.toSQLBuilder(m)
+	query, args, err := sb.String(), sb.Args(), sb.Err()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	base := sb.buildColumns().Readable().QuotedSelectString(quote)
+	query = strings.Replace(query, base+" FROM", fmt.Sprintf("%s, %s FROM", base, strings.Join(extra, ", ")), 1)
+
+	rows, err := q.Connection.Store.Queryx(query, args...)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer rows.Close()
+	names, err := rows.Columns()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	v := reflect.ValueOf(m.Value).Elem()
+	elem := v.Type()
+	if !one {
+		elem = elem.Elem()
+		v.Set(reflect.MakeSlice(v.Type(), 0, 0))
+	}
+	st := indirectType(elem)
+	hasOne := false
+	for _, j := range joins {
+		hasOne = hasOne || j.hasOne
+	}
+
+	// a has_one association matching several rows repeats its model.
+	seen := map[string]bool{}
+	found := false
+	for rows.Next() {
+		found = true
+		row := reflect.New(st).Elem()
+		dest := make([]interface{}, len(names))
+		joined := map[string]map[string]*interface{}{}
+		for i, name := range names {
+			if parts := strings.SplitN(name, "__", 2); len(parts) == 2 && strings.HasPrefix(parts[0], "eager_") {
+				if joined[parts[0]] == nil {
+					joined[parts[0]] = map[string]*interface{}{}
+				}
+				holder := new(interface{})
+				joined[parts[0]][parts[1]] = holder
+				dest[i] = holder
+				continue
+			}
+			if f, ok := columnField(st, name); ok {
+				dest[i] = row.FieldByIndex(f.Index).Addr().Interface()
+				continue
+			}
+			dest[i] = new(interface{})
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return errors.WithStack(err)
+		}
+		if hasOne {
+			id := fmt.Sprint((&Model{Value: row.Addr().Interface()}).ID())
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+		}
+		for _, j := range joins {
+			if err := j.assign(row, joined[j.alias]); err != nil {
+				return err
+			}
+		}
+
+		if elem.Kind() == reflect.Ptr {
+			row = row.Addr()
+		}
+		if one {
+			v.Set(row)
+			break
+		}
+		v.Set(reflect.Append(v, row))
+	}
+	if err := rows.Err(); err != nil {
+		return errors.WithStack(err)
+	}
+	if one && !found {
+		return errors.WithStack(sql.ErrNoRows)
+	}
+	rows.Close()
+
+	for _, j := range joins {
+		if len(j.nested) == 0 {
+			continue
+		}
+		if err := q.nestedQuery(j.field.Name).preload(j.loaded(v), j.nested); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// assign fills the association of row from the values of its columns,
+// unless the join did not match.
+func (j *joinedAssociation) assign(row reflect.Value, values map[string]*interface{}) error {
+	if id, ok := values["id"]; !ok || *id == nil {
+		return nil
+	}
+	target := reflect.New(j.target).Elem()
+	for col, holder := range values {
+		f, ok := columnField(j.target, col)
+		if !ok {
+			continue
+		}
+		if err := assignValue(target.FieldByIndex(f.Index), *holder); err != nil {
+			return errors.Wrapf(err, "could not scan %s.%s", j.field.Name, col)
+		}
+	}
+	field := row.FieldByIndex(j.field.Index)
+	if field.Kind() == reflect.Ptr {
+		field.Set(target.Addr())
+		return nil
+	}
+	field.Set(target)
+	return nil
+}
+
+// loaded returns the associations of the models of v the join matched.
+func (j *joinedAssociation) loaded(v reflect.Value) []interface{} {
+	values := []interface{}{}
+	for _, m := range preloadModels(v.Addr()) {
+		f := reflect.Indirect(reflect.ValueOf(m)).FieldByIndex(j.field.Index)
+		if f.Kind() == reflect.Ptr {
+			if !f.IsNil() {
+				values = append(values, f.Interface())
+			}
+			continue
+		}
+		if id := f.FieldByName("ID"); id.IsValid() && !reflect.DeepEqual(id.Interface(), reflect.Zero(id.Type()).Interface()) {
+			values = append(values, f.Addr().Interface())
+		}
+	}
+	return values
+}
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// assignValue sets dst, a field, to the value src read from the database,
+// converting it the way database/sql does for the common types.
+func assignValue(dst reflect.Value, src interface{}) error {
+	if dst.Addr().Type().Implements(scannerType) {
+		return dst.Addr().Interface().(sql.Scanner).Scan(src)
+	}
+	if src == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+	if dst.Kind() == reflect.Ptr {
+		v := reflect.New(dst.Type().Elem())
+		if err := assignValue(v.Elem(), src); err != nil {
+			return err
+		}
+		dst.Set(v)
+		return nil
+	}
+	sv := reflect.ValueOf(src)
+	if sv.Type().AssignableTo(dst.Type()) {
+		if b, ok := src.([]byte); ok {
+			src = append([]byte{}, b...)
+			sv = reflect.ValueOf(src)
+		}
+		dst.Set(sv)
+		return nil
+	}
+	s := asString(src)
+	var err error
+	switch dst.Kind() {
+	case reflect.String:
+		dst.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		if i, err = strconv.ParseInt(s, 10, 64); err == nil {
+			dst.SetInt(i)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var u uint64
+		if u, err = strconv.ParseUint(s, 10, 64); err == nil {
+			dst.SetUint(u)
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(s, 64); err == nil {
+			dst.SetFloat(f)
+		}
+	case reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(s); err == nil {
+			dst.SetBool(b)
+		}
+	default:
+		if sv.Type().ConvertibleTo(dst.Type()) {
+			dst.Set(sv.Convert(dst.Type()))
+			return nil
+		}
+		return errors.Errorf("unsupported conversion from %T to %s", src, dst.Type())
+	}
+	return errors.WithStack(err)
+}
+
+func asString(src interface{}) string {
+	switch v := src.(type) {
+	case []byte:
+		return string(v)
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(src)
+}
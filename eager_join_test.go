@@ -0,0 +1,106 @@
+package pop_test
+
+import (
+	"testing"
+
+	"github.com/markbates/pop"
+	"github.com/markbates/pop/nulls"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_EagerJoin_Belongs_To(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		user := User{Name: nulls.NewString("Mark"), Bio: nulls.NewString("writes books")}
+		r.NoError(tx.Create(&user))
+		r.NoError(tx.Create(&Book{Title: "Pop Book", Isbn: "PB1", UserID: nulls.NewInt(user.ID)}))
+		r.NoError(tx.Create(&Book{Title: "Orphan Book", Isbn: "PB2"}))
+
+		users := countSelects(tx, "users")
+		books := []Book{}
+		r.NoError(tx.EagerJoin("User").Order("books.title asc").All(&books))
+		r.Equal(0, *users)
+
+		r.Len(books, 2)
+		r.Equal("Orphan Book", books[0].Title)
+		r.Equal(0, books[0].User.ID)
+		r.Equal("Pop Book", books[1].Title)
+		r.Equal(user.ID, books[1].User.ID)
+		r.Equal("Mark", books[1].User.Name.String)
+		r.Equal("writes books", books[1].User.Bio.String)
+		r.False(books[1].User.CreatedAt.IsZero())
+	})
+}
+
+func Test_EagerJoin_Has_One(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		user := User{Name: nulls.NewString("Mark")}
+		r.NoError(tx.Create(&user))
+		song := Song{Title: "Hook - Blues Traveler", UserID: user.ID}
+		r.NoError(tx.Create(&song))
+
+		songs := countSelects(tx, "songs")
+		u := User{}
+		r.NoError(tx.EagerJoin("FavoriteSong").Find(&u, user.ID))
+		r.Equal(0, *songs)
+		r.Equal("Mark", u.Name.String)
+		r.Equal(song.ID, u.FavoriteSong.ID)
+		r.Equal(song.Title, u.FavoriteSong.Title)
+
+		r.NoError(tx.EagerJoin("FavoriteSong").Last(&u))
+		r.Equal(song.ID, u.FavoriteSong.ID)
+	})
+}
+
+func Test_EagerJoin_Mixed(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		user := User{Name: nulls.NewString("Mark")}
+		r.NoError(tx.Create(&user))
+		r.NoError(tx.Create(&Book{Title: "Pop Book", Isbn: "PB1", UserID: nulls.NewInt(user.ID)}))
+		r.NoError(tx.Create(&Song{Title: "Hook", UserID: user.ID}))
+
+		// Books is not joinable, and is loaded as with Eager.
+		u := User{}
+		r.NoError(tx.EagerJoin("FavoriteSong", "Books").First(&u))
+		r.Equal("Hook", u.FavoriteSong.Title)
+		r.Len(u.Books, 1)
+
+		// the nested fields of a joined association are loaded after it.
+		books := []Book{}
+		r.NoError(tx.EagerJoin("User.Books").All(&books))
+		r.Len(books, 1)
+		r.Equal(user.ID, books[0].User.ID)
+		r.Len(books[0].User.Books, 1)
+	})
+}
+
+func Test_EagerJoin_Not_Found(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		b := Book{}
+		err := tx.EagerJoin("User").Where("books.title = ?", "nothing").First(&b)
+		r.Error(err)
+		r.Equal("sql: no rows in result set", err.Error())
+	})
+}
+
+func Test_SetEagerMode(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		user := User{Name: nulls.NewString("Mark")}
+		r.NoError(tx.Create(&user))
+		r.NoError(tx.Create(&Book{Title: "Pop Book", Isbn: "PB1", UserID: nulls.NewInt(user.ID)}))
+
+		pop.SetEagerMode(pop.EagerJoin)
+		defer pop.SetEagerMode(pop.EagerDefault)
+
+		users := countSelects(tx, "users")
+		books := []Book{}
+		r.NoError(tx.Eager("User").All(&books))
+		r.Equal(0, *users)
+		r.Len(books, 1)
+		r.Equal("Mark", books[0].User.Name.String)
+	})
+}
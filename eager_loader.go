@@ -0,0 +1,132 @@
+package pop
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Loader collects the associations to eager load with `EagerLoad`, from
+// pointers to the fields of a model rather than from their names.
+type Loader struct {
+	base   reflect.Value
+	fields []string
+	err    error
+}
+
+// Load adds the association field points to. field must point to a
+// field of the model given to `EagerLoad`, or to a field of one of its
+// associations to load a nested association:
+//
+//	u := &User{Books: []Book{{}}}
+//	q.EagerLoad(u, func(l *pop.Loader) {
+//		l.Load(&u.FavoriteSong)
+//		l.Load(&u.Books[0].User) // "Books.User"
+//	})
+//
+// To reach the fields of a slice or a pointer association, the slice
+// must have an element and the pointer must not be nil.
+func (l *Loader) Load(field interface{}) {
+	if l.err != nil {
+		return
+	}
+	p := reflect.ValueOf(field)
+	if p.Kind() != reflect.Ptr || p.IsNil() {
+		l.err = errors.Errorf("eager load: %T is not a pointer to a field", field)
+		return
+	}
+	path := fieldPath(l.base, p.Pointer(), p.Type().Elem())
+	if len(path) == 0 {
+		l.err = errors.Errorf("eager load: %T does not point to a field of %s", field, l.base.Type())
+		return
+	}
+	l.fields = append(l.fields, strings.Join(path, "."))
+}
+
+// fieldPath returns the names of the fields leading from the struct v to
+// the field of type t at addr, or nil if there is none.
+func fieldPath(v reflect.Value, addr uintptr, t reflect.Type) []string {
+	st := v.Type()
+	for i := 0; i < st.NumField(); i++ {
+		sf := st.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		f := v.Field(i)
+		if f.UnsafeAddr() == addr && sf.Type == t {
+			return []string{sf.Name}
+		}
+		if path := innerFieldPath(f, addr, t); path != nil {
+			return append([]string{sf.Name}, path...)
+		}
+	}
+	return nil
+}
+
+// innerFieldPath looks for the field at addr in the struct, struct
+// pointer or slice of structs f.
+func innerFieldPath(f reflect.Value, addr uintptr, t reflect.Type) []string {
+	switch f.Kind() {
+	case reflect.Struct:
+		start := f.UnsafeAddr()
+		if addr >= start && addr < start+f.Type().Size() {
+			return fieldPath(f, addr, t)
+		}
+	case reflect.Ptr:
+		if !f.IsNil() && f.Elem().Kind() == reflect.Struct {
+			return innerFieldPath(f.Elem(), addr, t)
+		}
+	case reflect.Slice:
+		for i := 0; i < f.Len(); i++ {
+			if path := innerFieldPath(f.Index(i), addr, t); path != nil {
+				return path
+			}
+		}
+	}
+	return nil
+}
+
+// EagerLoad is like `Eager`, with the associations given as pointers to
+// the fields of model, so that renaming a field breaks the build instead
+// of the query:
+//
+//	u := &User{}
+//	tx.EagerLoad(u, func(l *pop.Loader) {
+//		l.Load(&u.Books)
+//	}).Find(u, id)
+//
+// model is only used to resolve the fields, it can be a blank value of
+// the type loaded, for example when loading a slice with `All`.
+func (c *Connection) EagerLoad(model interface{}, fn func(l *Loader)) *Query {
+	return Q(c).EagerLoad(model, fn)
+}
+
+// EagerLoad is like `Eager`, with the associations given as pointers to
+// the fields of model, so that renaming a field breaks the build instead
+// of the query:
+//
+//	u := &User{}
+//	tx.EagerLoad(u, func(l *pop.Loader) {
+//		l.Load(&u.Books)
+//	}).Find(u, id)
+//
+// model is only used to resolve the fields, it can be a blank value of
+// the type loaded, for example when loading a slice with `All`.
+func (q *Query) EagerLoad(model interface{}, fn func(l *Loader)) *Query {
+	v := reflect.ValueOf(model)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		q.eager, q.eagerErr = true, errors.Errorf("eager load: %T is not a pointer to a struct", model)
+		return q
+	}
+	l := &Loader{base: v.Elem()}
+	fn(l)
+	if l.err != nil {
+		q.eager, q.eagerErr = true, l.err
+		return q
+	}
+	if len(l.fields) == 0 {
+		return q
+	}
+	return q.Eager(l.fields...)
+}
@@ -0,0 +1,65 @@
+package pop
+
+import "sync"
+
+// EagerParallel is like `Eager`, loading the associations of a model with
+// up to n queries at a time rather than one after the other. See
+// `Query.EagerParallel`.
+//
+//	c.EagerParallel(4, "Books", "FavoriteSong", "Houses").Find(&u, id)
+func (c *Connection) EagerParallel(n int, fields ...string) *Query {
+	return Q(c).EagerParallel(n, fields...)
+}
+
+// EagerParallel is like `Eager`, loading the associations of a model with
+// up to n queries at a time rather than one after the other. The
+// associations of a model are independent of each other, so each of them,
+// along with its nested associations, is loaded on its own connection of
+// the pool; for a slice, the models are still processed in turn.
+//
+//	q.EagerParallel(4, "Books", "FavoriteSong", "Houses").Find(&u, id)
+//
+// The statements of a transaction all go through its single connection,
+// so its associations are loaded one after the other.
+func (q *Query) EagerParallel(n int, fields ...string) *Query {
+	q.eagerParallel = n
+	return q.Eager(fields...)
+}
+
+// eachEager runs fn for each of the n associations to load, in parallel
+// if the query allows it. It returns the error of the first association
+// which failed.
+func (q *Query) eachEager(n int, fn func(i int) error) error {
+	workers := q.eagerParallel
+	if workers > n {
+		workers = n
+	}
+	if workers < 2 || q.Connection == nil || q.Connection.TX != nil {
+		for i := 0; i < n; i++ {
+			if err := fn(i); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	errs := make([]error, n)
+	sem := make(chan struct{}, workers)
+	wg := sync.WaitGroup{}
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = fn(i)
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
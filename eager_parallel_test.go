@@ -0,0 +1,53 @@
+package pop
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// concurrency returns the number of calls of eachEager running at most at
+// the same time.
+func concurrency(q *Query, n int) (int, error) {
+	mu := sync.Mutex{}
+	running, max := 0, 0
+	err := q.eachEager(n, func(i int) error {
+		mu.Lock()
+		running++
+		if running > max {
+			max = running
+		}
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		if i%2 == 1 {
+			return fmt.Errorf("failed %d", i)
+		}
+		return nil
+	})
+	return max, err
+}
+
+func Test_eachEager(t *testing.T) {
+	r := require.New(t)
+
+	q := Q(&Connection{})
+	max, err := concurrency(q, 6)
+	r.Equal(1, max)
+	r.EqualError(err, "failed 1")
+
+	q.eagerParallel = 3
+	max, err = concurrency(q, 6)
+	r.Equal(3, max)
+	r.EqualError(err, "failed 1")
+
+	// a transaction runs a statement at a time.
+	q.Connection.TX = &Tx{}
+	max, _ = concurrency(q, 6)
+	r.Equal(1, max)
+}
@@ -10,6 +10,9 @@ import (
 
 // Reload fetch fresh data for a given model, using its ID
 func (c *Connection) Reload(model interface{}) error {
+	if err := checkModel(model); err != nil {
+		return err
+	}
 	sm := Model{Value: model}
 	return c.Find(model, sm.ID())
 }
@@ -17,18 +20,24 @@ func (c *Connection) Reload(model interface{}) error {
 // Exec runs the given query
 func (q *Query) Exec() error {
 	return q.Connection.timeFunc("Exec", func() error {
-		sql, args := q.ToSQL(nil)
-		Log(sql, args...)
-		_, err := q.Connection.Store.Exec(sql, args...)
+		sql, args, err := q.toSQL(nil)
+		if err != nil {
+			return err
+		}
+		_, err = q.Connection.Store.Exec(sql, args...)
 		return err
 	})
 }
 
+// ExecWithCount runs the given query, and returns the number of rows it
+// affected.
 func (q *Query) ExecWithCount() (int, error) {
 	count := int64(0)
-	return int(count), q.Connection.timeFunc("Exec", func() error {
-		sql, args := q.ToSQL(nil)
-		Log(sql, args...)
+	err := q.Connection.timeFunc("Exec", func() error {
+		sql, args, err := q.toSQL(nil)
+		if err != nil {
+			return err
+		}
 		result, err := q.Connection.Store.Exec(sql, args...)
 		if err != nil {
 			return err
@@ -37,11 +46,18 @@ func (q *Query) ExecWithCount() (int, error) {
 		count, err = result.RowsAffected()
 		return err
 	})
+	return int(count), err
 }
 
 // ValidateAndSave applies validation rules on the given entry, then save it
-// if the validation succeed, excluding the given columns.
+// if the validation succeed, excluding the given columns. A pointer to a
+// slice is validated and saved as a whole, as `ValidateAndCreate` does.
 func (c *Connection) ValidateAndSave(model interface{}, excludeColumns ...string) (*validate.Errors, error) {
+	if models, ok := sliceModels(model); ok {
+		return c.validateAndWriteAll(models, (*Model).validateSave, func(tx *Connection, m interface{}) error {
+			return tx.Save(m, excludeColumns...)
+		})
+	}
 	sm := &Model{Value: model}
 	verrs, err := sm.validateSave(c)
 	if err != nil {
@@ -56,11 +72,32 @@ func (c *Connection) ValidateAndSave(model interface{}, excludeColumns ...string
 var emptyUUID = uuid.Nil.String()
 
 // Save wraps the Create and Update methods. It executes a Create if no ID is provided with the entry;
-// or issues an Update otherwise.
+// or issues an Update otherwise. A model with a string key is always given one, so it is created
+// unless a row already has its key. A pointer to a slice saves each of its entries.
 func (c *Connection) Save(model interface{}, excludeColumns ...string) error {
+	if models, ok := sliceModels(model); ok {
+		return c.writeAll(models, func(tx *Connection, m interface{}) error {
+			return tx.Save(m, excludeColumns...)
+		})
+	}
+	if err := checkModel(model); err != nil {
+		return err
+	}
 	sm := &Model{Value: model}
 	id := sm.ID()
 
+	if sm.PrimaryKeyType() == "string" {
+		idq := fmt.Sprintf("%s = ?", c.Dialect.Quote(sm.TableName()+".id"))
+		exists, err := Q(c).Where(idq, fmt.Sprint(id)).Exists(model)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return c.Create(model, excludeColumns...)
+		}
+		return c.Update(model, excludeColumns...)
+	}
+
 	if fmt.Sprint(id) == "0" || fmt.Sprint(id) == emptyUUID {
 		return c.Create(model, excludeColumns...)
 	}
@@ -69,7 +106,17 @@ func (c *Connection) Save(model interface{}, excludeColumns ...string) error {
 
 // ValidateAndCreate applies validation rules on the given entry, then creates it
 // if the validation succeed, excluding the given columns.
+//
+// Given a pointer to a slice, it validates all of the entries, checking
+// the "unique" validate tags with a single query per column, and creates
+// them in a transaction if they are all valid. The errors are then keyed
+// by the index of the entry, as in "3.email".
 func (c *Connection) ValidateAndCreate(model interface{}, excludeColumns ...string) (*validate.Errors, error) {
+	if models, ok := sliceModels(model); ok {
+		return c.validateAndWriteAll(models, (*Model).validateCreate, func(tx *Connection, m interface{}) error {
+			return tx.Create(m, excludeColumns...)
+		})
+	}
 	sm := &Model{Value: model}
 	verrs, err := sm.validateCreate(c)
 	if err != nil {
@@ -83,7 +130,24 @@ func (c *Connection) ValidateAndCreate(model interface{}, excludeColumns ...stri
 
 // Create add a new given entry to the database, excluding the given columns.
 // It updates `created_at` and `updated_at` columns automatically.
+//
+// Given a pointer to a slice, it creates each of the entries, running their
+// callbacks, in transactions of `SliceBatchSize` entries. The failures are
+// returned as `SliceErrors`, holding the index of each failing entry.
 func (c *Connection) Create(model interface{}, excludeColumns ...string) error {
+	if models, ok := sliceModels(model); ok {
+		return c.writeAll(models, func(tx *Connection, m interface{}) error {
+			return tx.Create(m, excludeColumns...)
+		})
+	}
+	if err := checkModel(model); err != nil {
+		return err
+	}
+	if c.TX == nil && c.hasPartitions(model) {
+		return c.Transaction(func(tx *Connection) error {
+			return tx.Create(model, excludeColumns...)
+		})
+	}
 	return c.timeFunc("Create", func() error {
 		var err error
 		sm := &Model{Value: model}
@@ -98,11 +162,30 @@ func (c *Connection) Create(model interface{}, excludeColumns ...string) error {
 
 		cols := columns.ColumnsForStructWithAlias(model, sm.TableName(), sm.As)
 		cols.Remove(excludeColumns...)
+		c.removeAssociationColumns(model, cols)
 
+		sm.setDiscriminator()
 		sm.touchCreatedAt()
 		sm.touchUpdatedAt()
 
-		if err = c.Dialect.Create(c.Store, sm, cols); err != nil {
+		if err = c.ensureRangePartition(sm); err != nil {
+			return err
+		}
+
+		if seq := sm.sequence(); seq != "" {
+			err = c.createFromSequence(sm, cols, seq)
+		} else {
+			err = c.Dialect.Create(c.Store, sm, cols)
+		}
+		if err != nil {
+			return err
+		}
+
+		if err = c.writePartitions(sm, false, excludeColumns...); err != nil {
+			return err
+		}
+
+		if err = c.updateCounterCaches(sm, 1); err != nil {
 			return err
 		}
 
@@ -115,8 +198,14 @@ func (c *Connection) Create(model interface{}, excludeColumns ...string) error {
 }
 
 // ValidateAndUpdate applies validation rules on the given entry, then update it
-// if the validation succeed, excluding the given columns.
+// if the validation succeed, excluding the given columns. A pointer to a
+// slice is validated and updated as a whole, as `ValidateAndCreate` does.
 func (c *Connection) ValidateAndUpdate(model interface{}, excludeColumns ...string) (*validate.Errors, error) {
+	if models, ok := sliceModels(model); ok {
+		return c.validateAndWriteAll(models, (*Model).validateUpdate, func(tx *Connection, m interface{}) error {
+			return tx.Update(m, excludeColumns...)
+		})
+	}
 	sm := &Model{Value: model}
 	verrs, err := sm.validateUpdate(c)
 	if err != nil {
@@ -129,9 +218,45 @@ func (c *Connection) ValidateAndUpdate(model interface{}, excludeColumns ...stri
 }
 
 // Update writes changes from an entry to the database, excluding the given columns.
-// It updates the `updated_at` column automatically.
+// It updates the `updated_at` column automatically. A pointer to a slice
+// updates each of its entries, as `Create` does.
 func (c *Connection) Update(model interface{}, excludeColumns ...string) error {
-	return c.timeFunc("Update", func() error {
+	_, err := c.UpdateWithCount(model, excludeColumns...)
+	return err
+}
+
+// UpdateWithCount is like Update, but also returns the number of rows
+// updated: 0 if the row of the entry is gone, which tells a lost update
+// without another query. For a pointer to a slice, it is the total of its
+// entries. MySQL counts the rows matched rather than the rows changed
+// with the clientFoundRows parameter, which pop sets unless the
+// connection has its own URL.
+//
+// The models with a `LockVersion int` field are locked optimistically:
+// their row is only updated if its lock_version is the one of the model,
+// which is incremented, and `ErrStaleObject` is returned otherwise.
+func (c *Connection) UpdateWithCount(model interface{}, excludeColumns ...string) (int, error) {
+	count := 0
+	if models, ok := sliceModels(model); ok {
+		err := c.writeAll(models, func(tx *Connection, m interface{}) error {
+			n, err := tx.UpdateWithCount(m, excludeColumns...)
+			count += n
+			return err
+		})
+		return count, err
+	}
+	if err := checkModel(model); err != nil {
+		return count, err
+	}
+	if c.TX == nil && c.hasPartitions(model) {
+		err := c.Transaction(func(tx *Connection) error {
+			var err error
+			count, err = tx.UpdateWithCount(model, excludeColumns...)
+			return err
+		})
+		return count, err
+	}
+	err := c.timeFunc("Update", func() error {
 		var err error
 		sm := &Model{Value: model}
 
@@ -145,10 +270,29 @@ func (c *Connection) Update(model interface{}, excludeColumns ...string) error {
 		cols := columns.ColumnsForStructWithAlias(model, sm.TableName(), sm.As)
 		cols.Remove("id", "created_at")
 		cols.Remove(excludeColumns...)
+		c.removeAssociationColumns(model, cols)
+
+		lv, locked := sm.lockVersion()
+		if locked {
+			cols.Add("lock_version")
+			lv.SetInt(lv.Int() + 1)
+			sm.checkLockVersion = true
+		}
 
 		sm.touchUpdatedAt()
 
-		if err = c.Dialect.Update(c.Store, sm, cols); err != nil {
+		err = c.Dialect.Update(c.Store, sm, cols)
+		if locked && (err != nil || sm.rowsAffected == 0) {
+			lv.SetInt(lv.Int() - 1)
+		}
+		if err != nil {
+			return err
+		}
+		count = int(sm.rowsAffected)
+		if locked && count == 0 {
+			return ErrStaleObject
+		}
+		if err = c.writePartitions(sm, true, excludeColumns...); err != nil {
 			return err
 		}
 		if err = sm.afterUpdate(c); err != nil {
@@ -157,21 +301,70 @@ func (c *Connection) Update(model interface{}, excludeColumns ...string) error {
 
 		return sm.afterSave(c)
 	})
+	return count, err
 }
 
-// Destroy deletes a given entry from the database
+// Destroy deletes a given entry from the database. A pointer to a slice
+// deletes each of its entries, as `Create` creates them. The models with
+// a `DeletedAt nulls.Time` field are soft deleted: their deleted_at is set
+// instead, and queries skip them unless they are `Unscoped`.
 func (c *Connection) Destroy(model interface{}) error {
-	return c.timeFunc("Destroy", func() error {
+	_, err := c.DestroyWithCount(model)
+	return err
+}
+
+// DestroyWithCount is like Destroy, but also returns the number of rows
+// deleted: 0 if the row of the entry was already gone. For a pointer to a
+// slice, it is the total of its entries.
+func (c *Connection) DestroyWithCount(model interface{}) (int, error) {
+	return c.destroy(model, false)
+}
+
+func (c *Connection) destroy(model interface{}, hard bool) (int, error) {
+	count := 0
+	if models, ok := sliceModels(model); ok {
+		err := c.writeAll(models, func(tx *Connection, m interface{}) error {
+			n, err := tx.destroy(m, hard)
+			count += n
+			return err
+		})
+		return count, err
+	}
+	if err := checkModel(model); err != nil {
+		return count, err
+	}
+	if c.TX == nil && c.hasPartitions(model) {
+		err := c.Transaction(func(tx *Connection) error {
+			var err error
+			count, err = tx.destroy(model, hard)
+			return err
+		})
+		return count, err
+	}
+	err := c.timeFunc("Destroy", func() error {
 		var err error
 		sm := &Model{Value: model}
 
 		if err = sm.beforeDestroy(c); err != nil {
 			return err
 		}
-		if err = c.Dialect.Destroy(c.Store, sm); err != nil {
+		if !hard && sm.softDeletable() {
+			err = c.softDestroy(sm)
+		} else if err = c.destroyPartitions(sm); err == nil {
+			err = c.Dialect.Destroy(c.Store, sm)
+		}
+		if err != nil {
 			return err
 		}
+		count = int(sm.rowsAffected)
+
+		if count > 0 {
+			if err = c.updateCounterCaches(sm, -1); err != nil {
+				return err
+			}
+		}
 
 		return sm.afterDestroy(c)
 	})
+	return count, err
 }
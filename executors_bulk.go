@@ -0,0 +1,211 @@
+package pop
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/markbates/pop/columns"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// bulkInserter is implemented by the dialects able to get back the ids
+// generated by a multi-row INSERT.
+type bulkInserter interface {
+	// insertAll runs the INSERT of n rows, and returns their ids in the
+	// order of the rows.
+	insertAll(s store, query string, args []interface{}, n int) ([]int64, error)
+}
+
+// returningInsertAll reads the ids generated by a multi-row INSERT back
+// with RETURNING, which returns the rows in the order of the VALUES.
+func returningInsertAll(s store, query string, args []interface{}, n int) ([]int64, error) {
+	ids := make([]int64, 0, n)
+	err := s.Select(&ids, query+" RETURNING id", args...)
+	return ids, err
+}
+
+// CreateAll inserts a slice of models with multi-row INSERT statements of
+// batchSize rows, instead of one statement per model as `Create` does.
+// The callbacks of the models are run, and their timestamps and ids are
+// set, as with `Create`; all of the models are inserted in a transaction.
+//
+//	users := make([]User, 50000)
+//	err := c.CreateAll(&users, 1000)
+//
+// The generated integer ids are read back on PostgreSQL, CockroachDB,
+// SQLite and MySQL, unless its innodb_autoinc_lock_mode is 2. The
+// databases limit the number of values of a statement, so batchSize times
+// the number of columns should stay under 65535 on PostgreSQL, and 32766
+// on SQLite.
+func (c *Connection) CreateAll(models interface{}, batchSize int) error {
+	ms, ok := sliceModels(models)
+	if !ok {
+		return errors.Errorf("CreateAll needs a pointer to a slice of models, not %T", models)
+	}
+	if len(ms) == 0 {
+		return nil
+	}
+	if batchSize <= 0 {
+		batchSize = len(ms)
+	}
+	if c.TX != nil {
+		return c.createAll(models, ms, batchSize)
+	}
+	// the SliceErrors are returned as is, not wrapped by Transaction.
+	var err error
+	txErr := c.Transaction(func(tx *Connection) error {
+		err = tx.createAll(models, ms, batchSize)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return txErr
+}
+
+func (c *Connection) createAll(models interface{}, ms []interface{}, batchSize int) error {
+	sms := make([]*Model, len(ms))
+	t := reflect.TypeOf(ms[0])
+	for i, m := range ms {
+		if reflect.TypeOf(m) != t {
+			return errors.Errorf("CreateAll can not mix %s and %s models", t, reflect.TypeOf(m))
+		}
+		sm := &Model{Value: m}
+		if err := sm.beforeSave(c); err != nil {
+			return SliceErrors{{Index: i, ID: sm.ID(), Err: err}}
+		}
+		if err := sm.beforeCreate(c); err != nil {
+			return SliceErrors{{Index: i, ID: sm.ID(), Err: err}}
+		}
+		sm.setDiscriminator()
+		sm.touchCreatedAt()
+		sm.touchUpdatedAt()
+		sms[i] = sm
+	}
+	if err := c.ensureRangePartitions(sms); err != nil {
+		return err
+	}
+
+	first := sms[0]
+	cols := columns.ColumnsForStructWithAlias(first.Value, first.TableName(), first.As)
+	c.removeAssociationColumns(first.Value, cols)
+	w := cols.Writeable()
+
+	// the ids are generated by the database unless they are set here.
+	explicit := false
+	switch kt := first.PrimaryKeyType(); kt {
+	case "int", "int64":
+		if first.sequence() != "" {
+			if err := c.AssignIDs(models); err != nil {
+				return err
+			}
+			explicit = true
+		}
+	case "UUID", "string":
+		for i, sm := range sms {
+			if kt == "UUID" && sm.ID() == emptyUUID {
+				u, err := uuid.NewV4()
+				if err != nil {
+					return errors.WithStack(err)
+				}
+				sm.setID(u)
+			}
+			if kt == "string" && fmt.Sprint(sm.ID()) == "" {
+				return SliceErrors{{Index: i, Err: errors.Errorf("%s can not be created without an id", sm.TableName())}}
+			}
+		}
+		explicit = true
+	default:
+		return errors.Errorf("can not use %s as a primary key type!", kt)
+	}
+	if explicit {
+		w.Add("id")
+	}
+
+	names := []string{}
+	for name := range w.Cols {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pr := c.newProgress("CreateAll", int64(len(sms)))
+	pr.p.Table = first.TableName()
+	for start := 0; start < len(sms); start += batchSize {
+		end := start + batchSize
+		if end > len(sms) {
+			end = len(sms)
+		}
+		err := c.timeFunc("CreateAll", func() error {
+			return c.insertAll(sms[start:end], names, explicit)
+		})
+		if err != nil {
+			return err
+		}
+		pr.p.Rows = int64(end)
+		if end < len(sms) {
+			pr.report()
+		}
+	}
+
+	for i, sm := range sms {
+		err := c.writePartitions(sm, false)
+		if err == nil {
+			err = c.updateCounterCaches(sm, 1)
+		}
+		if err == nil {
+			err = sm.afterCreate(c)
+		}
+		if err == nil {
+			err = sm.afterSave(c)
+		}
+		if err != nil {
+			return SliceErrors{{Index: i, ID: sm.ID(), Err: err}}
+		}
+	}
+	pr.done()
+	return nil
+}
+
+// insertAll inserts the columns names of sms with a single statement,
+// and sets the ids generated by the database unless they are explicit.
+func (c *Connection) insertAll(sms []*Model, names []string, explicit bool) error {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = c.Dialect.Quote(name)
+	}
+	row := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(names)), ", ") + ")"
+
+	rows := make([]string, len(sms))
+	args := make([]interface{}, 0, len(sms)*len(names))
+	for i, sm := range sms {
+		v := reflect.Indirect(reflect.ValueOf(sm.Value))
+		for _, name := range names {
+			f := fieldMapper.FieldByName(v, name)
+			if !f.IsValid() {
+				return errors.Errorf("could not find the field of the column %s of %s", name, sm.TableName())
+			}
+			args = append(args, f.Interface())
+		}
+		rows[i] = row
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s", c.Dialect.Quote(sms[0].TableName()), strings.Join(quoted, ", "), strings.Join(rows, ", "))
+	query = c.Dialect.TranslateSQL(query)
+
+	bi, ok := c.Dialect.(bulkInserter)
+	if explicit || !ok {
+		_, err := c.Store.Exec(query, args...)
+		return errors.WithStack(err)
+	}
+	ids, err := bi.insertAll(c.Store, query, args, len(sms))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for i, id := range ids {
+		sms[i].setID(id)
+	}
+	return nil
+}
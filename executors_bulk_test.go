@@ -0,0 +1,74 @@
+package pop_test
+
+import (
+	"testing"
+
+	"github.com/markbates/pop"
+	"github.com/markbates/pop/nulls"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CreateAll(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		users := []User{}
+		for _, name := range []string{"A", "B", "C", "D", "E"} {
+			users = append(users, User{Name: nulls.NewString(name)})
+		}
+		r.NoError(tx.CreateAll(&users, 2))
+
+		for _, u := range users {
+			r.NotZero(u.ID)
+			r.False(u.CreatedAt.IsZero())
+			found := User{}
+			r.NoError(tx.Find(&found, u.ID))
+			r.Equal(u.Name.String, found.Name.String)
+		}
+	})
+}
+
+func Test_CreateAll_Callbacks(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		users := []*CallbacksUser{{}, {}}
+		r.NoError(tx.CreateAll(&users, 0))
+		for _, u := range users {
+			r.NotZero(u.ID)
+			r.Equal("BeforeCreate", u.BeforeC)
+			r.Equal("AfterCreate", u.AfterC)
+		}
+
+		picky := []pickyUser{{Name: nulls.NewString("Mark")}, {}}
+		err := tx.CreateAll(&picky, 10)
+		r.Error(err)
+		errs, ok := err.(pop.SliceErrors)
+		r.True(ok)
+		r.Equal(1, errs[0].Index)
+	})
+}
+
+func Test_CreateAll_Explicit_IDs(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		songs := []Song{{Title: "Hook"}, {Title: "Run-Around"}}
+		r.NoError(tx.CreateAll(&songs, 10))
+		r.NotEqual(songs[0].ID, songs[1].ID)
+		s := Song{}
+		r.NoError(tx.Find(&s, songs[1].ID))
+		r.Equal("Run-Around", s.Title)
+
+		products := []Product{{ID: "SKU-1", Name: "Pen"}, {ID: "SKU-2", Name: "Ink"}}
+		r.NoError(tx.CreateAll(&products, 1))
+		ct, err := tx.Count(&Product{})
+		r.NoError(err)
+		r.Equal(2, ct)
+
+		r.Error(tx.CreateAll(&[]Product{{Name: "No SKU"}}, 1))
+	})
+}
+
+func Test_CreateAll_Not_A_Slice(t *testing.T) {
+	r := require.New(t)
+	r.Error(PDB.CreateAll(&User{}, 10))
+	r.NoError(PDB.CreateAll(&[]User{}, 10))
+}
@@ -0,0 +1,91 @@
+package pop
+
+import (
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// SliceBatchSize is the number of elements of a slice written in a single
+// transaction by Create, Save, Update and Destroy.
+var SliceBatchSize = 100
+
+// sliceModels returns the models of model if it is a pointer to a slice
+// of models.
+func sliceModels(model interface{}) ([]interface{}, bool) {
+	if checkModels(model) != nil {
+		return nil, false
+	}
+	switch indirectType(reflect.TypeOf(model)).Kind() {
+	case reflect.Struct, reflect.Interface:
+		return preloadModels(reflect.ValueOf(model)), true
+	}
+	return nil, false
+}
+
+// writeAll runs write on each of models, with their callbacks, in batches
+// of SliceBatchSize. Each batch has its own transaction: an element failing
+// rolls its batch back, along with the ids and timestamps set on its models,
+// and the following batches are still written. In a transaction, the
+// elements are written in it, and the first failure stops the writes. The
+// failures are returned as SliceErrors.
+func (c *Connection) writeAll(models []interface{}, write func(tx *Connection, model interface{}) error) error {
+	size := SliceBatchSize
+	if size <= 0 {
+		size = len(models)
+	}
+	errs := SliceErrors{}
+	for start := 0; start < len(models); start += size {
+		end := start + size
+		if end > len(models) {
+			end = len(models)
+		}
+		batch := func(tx *Connection) error {
+			for i, m := range models[start:end] {
+				if err := write(tx, m); err != nil {
+					return &SliceError{Index: start + i, ID: (&Model{Value: m}).ID(), Err: err}
+				}
+			}
+			return nil
+		}
+		if c.TX != nil {
+			if err := batch(c); err != nil {
+				errs = append(errs, err.(*SliceError))
+				break
+			}
+			continue
+		}
+		snaps := snapshotModels(models[start:end])
+		if err := c.Transaction(batch); err != nil {
+			restoreModels(models[start:end], snaps)
+			se, ok := errors.Cause(err).(*SliceError)
+			if !ok {
+				// the batch failed to commit.
+				se = &SliceError{Index: start, ID: (&Model{Value: models[start]}).ID(), Err: err}
+			}
+			errs = append(errs, se)
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// snapshotModels copies the values of models, pointers to structs.
+func snapshotModels(models []interface{}) []reflect.Value {
+	snaps := make([]reflect.Value, len(models))
+	for i, m := range models {
+		v := reflect.ValueOf(m).Elem()
+		snaps[i] = reflect.New(v.Type()).Elem()
+		snaps[i].Set(v)
+	}
+	return snaps
+}
+
+// restoreModels sets models back to the values of their snapshots.
+func restoreModels(models []interface{}, snaps []reflect.Value) {
+	for i, m := range models {
+		reflect.ValueOf(m).Elem().Set(snaps[i])
+	}
+}
@@ -0,0 +1,139 @@
+package pop_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/markbates/pop"
+	"github.com/markbates/pop/nulls"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// pickyUser refuses to be created without a name.
+type pickyUser struct {
+	ID        int          `db:"id"`
+	Name      nulls.String `db:"name"`
+	CreatedAt time.Time    `db:"created_at"`
+	UpdatedAt time.Time    `db:"updated_at"`
+}
+
+func (pickyUser) TableName() string {
+	return "users"
+}
+
+func (u *pickyUser) BeforeCreate(tx *pop.Connection) error {
+	if u.Name.String == "" {
+		return errors.New("a name is required")
+	}
+	return nil
+}
+
+func Test_Create_Slice(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		users := []CallbacksUser{{}, {}, {}}
+		r.NoError(tx.Create(&users))
+		for _, u := range users {
+			r.NotZero(u.ID)
+			r.Equal("BeforeCreate", u.BeforeC)
+			r.Equal("AfterCreate", u.AfterC)
+		}
+		ct, err := tx.Count(&CallbacksUser{})
+		r.NoError(err)
+		r.Equal(3, ct)
+	})
+}
+
+func Test_Save_Update_Destroy_Slice(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		existing := &CallbacksUser{}
+		r.NoError(tx.Create(existing))
+
+		users := []*CallbacksUser{existing, {}}
+		r.NoError(tx.Save(&users))
+		r.Equal("AfterUpdate", users[0].AfterU)
+		r.Equal("AfterCreate", users[1].AfterC)
+		r.NotZero(users[1].ID)
+
+		users[0].AfterU, users[1].AfterU = "", ""
+		r.NoError(tx.Update(&users))
+		r.Equal("BeforeUpdate", users[0].BeforeU)
+		r.Equal("BeforeUpdate", users[1].BeforeU)
+
+		r.NoError(tx.Destroy(&users))
+		r.Equal("AfterDestroy", users[0].AfterD)
+		r.Equal("AfterDestroy", users[1].AfterD)
+		ct, err := tx.Count(&CallbacksUser{})
+		r.NoError(err)
+		r.Equal(0, ct)
+	})
+}
+
+func Test_Create_Slice_Errors(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		users := []pickyUser{{Name: nulls.NewString("Mark")}, {}, {Name: nulls.NewString("Ringo")}}
+		err := tx.Create(&users)
+		r.Error(err)
+		errs, ok := err.(pop.SliceErrors)
+		r.True(ok)
+		r.Len(errs, 1)
+		r.Equal(1, errs[0].Index)
+		r.Contains(errs[0].Error(), "a name is required")
+	})
+}
+
+func Test_Create_Slice_Batches(t *testing.T) {
+	r := require.New(t)
+
+	size := pop.SliceBatchSize
+	pop.SliceBatchSize = 2
+	defer func() { pop.SliceBatchSize = size }()
+
+	users := []pickyUser{}
+	for _, name := range []string{"Batch A", "Batch B", "Batch C", "", "Batch E"} {
+		users = append(users, pickyUser{Name: nulls.NewString(name)})
+	}
+	err := PDB.Create(&users)
+	r.Error(err)
+	errs, ok := err.(pop.SliceErrors)
+	r.True(ok)
+	r.Len(errs, 1)
+	r.Equal(3, errs[0].Index)
+
+	// the models of the rolled back batch are as they were.
+	r.Zero(users[2].ID)
+	r.True(users[2].CreatedAt.IsZero())
+	r.NotZero(users[1].ID)
+	r.NotZero(users[4].ID)
+
+	// the batch of the failing user is rolled back, the others are kept.
+	saved := []pickyUser{}
+	r.NoError(PDB.Where("name like ?", "Batch %").Order("name asc").All(&saved))
+	r.Len(saved, 3)
+	r.Equal("Batch A", saved[0].Name.String)
+	r.Equal("Batch B", saved[1].Name.String)
+	r.Equal("Batch E", saved[2].Name.String)
+	r.NoError(PDB.Destroy(&saved))
+}
+
+func Test_ValidateAndSave_Slice(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		cars := []TaggedCar{{Name: "Fiat"}, {Name: ""}}
+		verrs, err := tx.ValidateAndSave(&cars)
+		r.NoError(err)
+		r.True(verrs.HasAny())
+		r.NotEmpty(verrs.Get("1.name"))
+		r.Zero(cars[0].ID)
+
+		cars[1].Name = "Ford"
+		verrs, err = tx.ValidateAndSave(&cars)
+		r.NoError(err)
+		r.False(verrs.HasAny())
+		r.NotZero(cars[0].ID)
+		r.NotZero(cars[1].ID)
+	})
+}
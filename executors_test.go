@@ -1,7 +1,9 @@
 package pop_test
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/markbates/pop"
 	"github.com/markbates/pop/nulls"
@@ -329,3 +331,204 @@ func Test_Destroy_UUID(t *testing.T) {
 		r.Equal(count, ctx)
 	})
 }
+
+func Test_UpdateWithCount(t *testing.T) {
+	transaction(func(tx *pop.Connection) {
+		r := require.New(t)
+
+		user := User{Name: nulls.NewString("Mark")}
+		r.NoError(tx.Create(&user))
+
+		user.Name = nulls.NewString("Ringo")
+		count, err := tx.UpdateWithCount(&user)
+		r.NoError(err)
+		r.Equal(1, count)
+
+		// the row is gone, so nothing is updated.
+		r.NoError(tx.RawQuery("delete from users where id = ?", user.ID).Exec())
+		count, err = tx.UpdateWithCount(&user)
+		r.NoError(err)
+		r.Equal(0, count)
+	})
+}
+
+func Test_DestroyWithCount(t *testing.T) {
+	transaction(func(tx *pop.Connection) {
+		r := require.New(t)
+
+		users := []User{{Name: nulls.NewString("Mark")}, {Name: nulls.NewString("Ringo")}}
+		r.NoError(tx.Create(&users))
+
+		count, err := tx.DestroyWithCount(&users)
+		r.NoError(err)
+		r.Equal(2, count)
+
+		count, err = tx.DestroyWithCount(&users[0])
+		r.NoError(err)
+		r.Equal(0, count)
+	})
+}
+
+func Test_ReservedWordColumns(t *testing.T) {
+	transaction(func(tx *pop.Connection) {
+		r := require.New(t)
+
+		ranking := Ranking{Order: 1, Group: "a"}
+		r.NoError(tx.Create(&ranking))
+		r.NotZero(ranking.ID)
+
+		found := Ranking{}
+		r.NoError(tx.Find(&found, ranking.ID))
+		r.Equal(1, found.Order)
+		r.Equal("a", found.Group)
+
+		ranking.Order = 2
+		r.NoError(tx.Update(&ranking))
+
+		r.NoError(tx.Find(&found, ranking.ID))
+		r.Equal(2, found.Order)
+		r.Equal("a", found.Group)
+
+		r.NoError(tx.Destroy(&found))
+		count, err := tx.Count(&Ranking{})
+		r.NoError(err)
+		r.Equal(0, count)
+	})
+}
+
+type shouted string
+
+type shoutedRanking struct {
+	ID        int       `db:"id"`
+	Order     int       `db:"order"`
+	Group     shouted   `db:"group"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func (shoutedRanking) TableName() string {
+	return "rankings"
+}
+
+func Test_RegisterValueConverter(t *testing.T) {
+	pop.RegisterValueConverter(PDB.Dialect.Details().Dialect, func(v interface{}) (interface{}, bool) {
+		if s, ok := v.(shouted); ok {
+			return strings.ToUpper(string(s)), true
+		}
+		return v, false
+	})
+
+	transaction(func(tx *pop.Connection) {
+		r := require.New(t)
+
+		sr := shoutedRanking{Order: 1, Group: "a"}
+		r.NoError(tx.Create(&sr))
+
+		ranking := Ranking{}
+		r.NoError(tx.Where(tx.Dialect.Quote("group")+" = ?", shouted("a")).First(&ranking))
+		r.Equal("A", ranking.Group)
+	})
+}
+
+func Test_ValidateAndCreate_Tags(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		car := &TaggedCar{Name: "VW"}
+		verrs, err := tx.ValidateAndCreate(car)
+		r.NoError(err)
+		r.False(verrs.HasAny())
+		r.NotZero(car.ID)
+
+		car = &TaggedCar{}
+		verrs, err = tx.ValidateAndCreate(car)
+		r.NoError(err)
+		r.Equal([]string{"Name can not be blank."}, verrs.Get("name"))
+		r.Zero(car.ID)
+
+		car = &TaggedCar{Name: "Volkswagen Beetle"}
+		verrs, err = tx.ValidateAndSave(car)
+		r.NoError(err)
+		r.Equal([]string{"Name must be at most 10 characters."}, verrs.Get("name"))
+	})
+}
+
+func Test_ValidateAndCreate_Unique(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		car := &UniqueCar{Name: "VW"}
+		verrs, err := tx.ValidateAndCreate(car)
+		r.NoError(err)
+		r.False(verrs.HasAny())
+
+		verrs, err = tx.ValidateAndCreate(&UniqueCar{Name: "VW"})
+		r.NoError(err)
+		r.Equal([]string{"Name has already been taken."}, verrs.Get("name"))
+
+		verrs, err = tx.ValidateAndUpdate(car)
+		r.NoError(err)
+		r.False(verrs.HasAny())
+	})
+}
+
+func Test_ValidateAndCreate_Slice(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		r.NoError(tx.Create(&UniqueCar{Name: "VW"}))
+
+		cars := []UniqueCar{{Name: "Audi"}, {Name: "VW"}, {Name: "Fiat"}, {Name: "Audi"}, {}}
+		verrs, err := tx.ValidateAndCreate(&cars)
+		r.NoError(err)
+		r.Equal(3, verrs.Count())
+		r.Equal([]string{"Name has already been taken."}, verrs.Get("1.name"))
+		r.Equal([]string{"Name has already been taken."}, verrs.Get("3.name"))
+		r.Equal([]string{"Name can not be blank."}, verrs.Get("4.name"))
+		r.Zero(cars[0].ID)
+
+		cars = []UniqueCar{{Name: "Audi"}, {Name: "Fiat"}}
+		verrs, err = tx.ValidateAndCreate(&cars)
+		r.NoError(err)
+		r.False(verrs.HasAny())
+		r.NotZero(cars[0].ID)
+		r.NotZero(cars[1].ID)
+
+		count, err := tx.Count(&UniqueCar{})
+		r.NoError(err)
+		r.Equal(3, count)
+	})
+}
+
+type viewBook struct {
+	ID        int       `db:"id"`
+	Title     string    `db:"title"`
+	Isbn      string    `db:"isbn"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+	Owner     *User
+	Readers   []User
+}
+
+func (viewBook) TableName() string {
+	return "books"
+}
+
+func Test_WithoutAssociations(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		b := &viewBook{Title: "Pop", Isbn: "PB1", Owner: &User{Name: nulls.NewString("Mark")}}
+		r.NoError(tx.WithoutAssociations().Create(b))
+		r.NotZero(b.ID)
+
+		b.Title = "Pop 2"
+		r.NoError(tx.Q().WithoutAssociations().Connection.Update(b))
+
+		books := []viewBook{{Title: "A", Isbn: "PB2"}, {Title: "B", Isbn: "PB3"}}
+		r.NoError(tx.WithoutAssociations().CreateAll(&books, 0))
+
+		book := &Book{}
+		r.NoError(tx.Find(book, b.ID))
+		r.Equal("Pop 2", book.Title)
+
+		// the nested structs are mapped to owner and readers columns otherwise.
+		r.Error(tx.Create(&viewBook{Title: "C", Isbn: "PB4"}))
+	})
+}
@@ -0,0 +1,105 @@
+package pop
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DebugExplain enables, when `Debug` is also enabled, running EXPLAIN
+// on every SELECT with a WHERE clause, and logging a warning when the
+// database plans a full scan of a table. This is a development aid to
+// find missing indexes, it doubles the number of queries being run.
+var DebugExplain = false
+
+// DebugExplainRows is the estimated number of rows a table scan must
+// read before `DebugExplain` warns about it. SQLite does not estimate
+// rows, so every full scan is reported.
+var DebugExplainRows = 1000
+
+func init() {
+	AddInstrumenter(explainAdvisor)
+}
+
+var explainWhere = regexp.MustCompile(`(?is)^\s*select\s.+\swhere\s`)
+
+func explainAdvisor(e QueryEvent) {
+	if !Debug || !DebugExplain || e.Err != nil || e.Connection == nil {
+		return
+	}
+	if !explainWhere.MatchString(e.SQL) {
+		return
+	}
+	scans, err := explainScans(e.Connection, e.SQL, e.Args)
+	if err != nil {
+		logMessage(e.Connection, LogWarn, fmt.Sprintf("could not explain query: %s", err))
+		return
+	}
+	for _, s := range scans {
+		logMessage(e.Connection, LogWarn, fmt.Sprintf("WARNING: full scan of %s, consider adding an index for: %s", s, e.SQL))
+	}
+}
+
+var pgSeqScan = regexp.MustCompile(`Seq Scan on (\S+).*rows=(\d+)`)
+var sqliteScan = regexp.MustCompile(`^SCAN (?:TABLE )?(\S+)`)
+
+// explainScans runs EXPLAIN for the query and returns the tables
+// the database plans to scan in full.
+func explainScans(c *Connection, query string, args []interface{}) ([]string, error) {
+	stmt := "EXPLAIN " + query
+	if c.Dialect.Details().Dialect == "sqlite3" {
+		stmt = "EXPLAIN QUERY PLAN " + query
+	}
+	// the EXPLAIN is sent to the wrapped store, so it does
+	// not get instrumented itself.
+	s := c.Store
+	if is, ok := s.(*instrumentedStore); ok {
+		s = is.store
+	}
+	rows, err := s.Queryx(stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	scans := []string{}
+	for rows.Next() {
+		row := map[string]interface{}{}
+		if err := rows.MapScan(row); err != nil {
+			return scans, err
+		}
+		switch c.Dialect.Details().Dialect {
+		case "postgres":
+			m := pgSeqScan.FindStringSubmatch(explainString(row["QUERY PLAN"]))
+			if len(m) == 3 {
+				if n, _ := strconv.Atoi(m[2]); n >= DebugExplainRows {
+					scans = append(scans, m[1])
+				}
+			}
+		case "mysql":
+			if explainString(row["type"]) == "ALL" {
+				if n, _ := strconv.Atoi(explainString(row["rows"])); n >= DebugExplainRows {
+					scans = append(scans, explainString(row["table"]))
+				}
+			}
+		case "sqlite3":
+			detail := explainString(row["detail"])
+			if m := sqliteScan.FindStringSubmatch(detail); len(m) == 2 && !strings.Contains(detail, "INDEX") {
+				scans = append(scans, m[1])
+			}
+		}
+	}
+	return scans, rows.Err()
+}
+
+func explainString(v interface{}) string {
+	switch t := v.(type) {
+	case []byte:
+		return string(t)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(t)
+	}
+}
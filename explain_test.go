@@ -0,0 +1,44 @@
+package pop_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/markbates/pop"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DebugExplain(t *testing.T) {
+	if os.Getenv("SODA_DIALECT") != "sqlite" {
+		t.Skip("the plan assertions are SQLite specific")
+	}
+	r := require.New(t)
+
+	logs := []string{}
+	oldLog := pop.Log
+	pop.Log = func(s string, args ...interface{}) {
+		logs = append(logs, s)
+	}
+	pop.Debug = true
+	pop.DebugExplain = true
+	defer func() {
+		pop.Log = oldLog
+		pop.Debug = false
+		pop.DebugExplain = false
+	}()
+
+	transaction(func(tx *pop.Connection) {
+		r.NoError(tx.Where("title = ?", "Pop").All(&Books{}))
+		r.NoError(tx.Where("id = ?", 1).All(&Books{}))
+	})
+
+	warnings := 0
+	for _, l := range logs {
+		if strings.HasPrefix(l, "WARNING:") {
+			warnings++
+			r.Contains(l, "full scan of books")
+		}
+	}
+	r.Equal(1, warnings)
+}
@@ -48,6 +48,10 @@ func (fm *FileMigrator) findMigrations() error {
 				return nil
 			}
 			m := matches[0]
+			b, err := ioutil.ReadFile(p)
+			if err != nil {
+				return errors.WithStack(err)
+			}
 			mf := Migration{
 				Path:      p,
 				Version:   m[1],
@@ -74,6 +78,7 @@ func (fm *FileMigrator) findMigrations() error {
 					}
 					return nil
 				},
+				DependsOn: migrationDependencies(string(b)),
 			}
 			fm.Migrations[mf.Direction] = append(fm.Migrations[mf.Direction], mf)
 		}
@@ -88,7 +93,7 @@ func migrationContent(mf Migration, c *Connection, r io.Reader) (string, error)
 		return "", nil
 	}
 
-	content := string(b)
+	content := stripMigrationDirectives(string(b))
 
 	t := template.Must(template.New("sql").Parse(content))
 	var bb bytes.Buffer
@@ -1,14 +1,11 @@
 package pop
 
 import (
-	"database/sql"
 	"fmt"
 	"reflect"
 	"regexp"
 	"strconv"
 
-	"github.com/markbates/pop/associations"
-	"github.com/pkg/errors"
 	"github.com/satori/go.uuid"
 )
 
@@ -23,18 +20,31 @@ func (c *Connection) Find(model interface{}, id interface{}) error {
 }
 
 // Find the first record of the model in the database with a particular id.
+// The id is matched against the type of the ID field of the model, so a
+// model with a string key is found by its string, even if it looks like
+// a number.
 //
 //	q.Find(&User{}, 1)
+//	q.Find(&Product{}, "SKU-42")
 func (q *Query) Find(model interface{}, id interface{}) error {
+	if err := checkModel(model); err != nil {
+		return err
+	}
 	m := &Model{Value: model}
-	idq := fmt.Sprintf("%s.id = ?", m.TableName())
+	idq := fmt.Sprintf("%s = ?", q.Connection.Dialect.Quote(m.TableName()+".id"))
+	if m.PrimaryKeyType() == "string" {
+		return q.Where(idq, fmt.Sprint(id)).First(model)
+	}
 	switch t := id.(type) {
 	case uuid.UUID:
-		return q.Where(idq, t.String()).First(model)
+		return q.Where(idq, t).First(model)
 	case string:
 		var err error
 		id, err = strconv.Atoi(t)
 		if err != nil {
+			if u, err := uuid.FromString(t); err == nil {
+				return q.Where(idq, u).First(model)
+			}
 			return q.Where(idq, t).First(model)
 		}
 	}
@@ -53,10 +63,13 @@ func (c *Connection) First(model interface{}) error {
 //
 //	q.Where("name = ?", "mark").First(&User{})
 func (q *Query) First(model interface{}) error {
+	if err := checkModel(model); err != nil {
+		return err
+	}
 	err := q.Connection.timeFunc("First", func() error {
 		q.Limit(1)
 		m := &Model{Value: model}
-		if err := q.Connection.Dialect.SelectOne(q.Connection.Store, m, *q); err != nil {
+		if err := q.selectOne(m); err != nil {
 			return err
 		}
 		return m.afterFind(q.Connection)
@@ -67,7 +80,7 @@ func (q *Query) First(model interface{}) error {
 	}
 
 	if q.eager {
-		return q.eagerAssociations(model)
+		return q.loadEager(model)
 	}
 	return nil
 }
@@ -83,11 +96,19 @@ func (c *Connection) Last(model interface{}) error {
 //
 //	q.Where("name = ?", "mark").Last(&User{})
 func (q *Query) Last(model interface{}) error {
+	if err := checkModel(model); err != nil {
+		return err
+	}
 	err := q.Connection.timeFunc("Last", func() error {
 		q.Limit(1)
-		q.Order("id desc")
 		m := &Model{Value: model}
-		if err := q.Connection.Dialect.SelectOne(q.Connection.Store, m, *q); err != nil {
+		if q.eager && q.eagerMode == EagerJoin {
+			// the joined tables have an id too.
+			q.Order(fmt.Sprintf("%s desc", q.Connection.Dialect.Quote(joinParentAlias(m)+".id")))
+		} else {
+			q.Order("id desc")
+		}
+		if err := q.selectOne(m); err != nil {
 			return err
 		}
 		return m.afterFind(q.Connection)
@@ -98,7 +119,7 @@ func (q *Query) Last(model interface{}) error {
 	}
 
 	if q.eager {
-		return q.eagerAssociations(model)
+		return q.loadEager(model)
 	}
 
 	return nil
@@ -115,9 +136,18 @@ func (c *Connection) All(models interface{}) error {
 //
 //	q.Where("name = ?", "mark").All(&[]User{})
 func (q *Query) All(models interface{}) error {
+	if err := checkModels(models); err != nil {
+		return err
+	}
+	if q.CursorPaginator != nil && q.CursorPaginator.err != nil {
+		return q.CursorPaginator.err
+	}
 	err := q.Connection.timeFunc("All", func() error {
 		m := &Model{Value: models}
-		err := q.Connection.Dialect.SelectMany(q.Connection.Store, m, *q)
+		err := q.selectMany(m)
+		if err == nil && q.CursorPaginator != nil {
+			err = q.CursorPaginator.setNext(models)
+		}
 		if err == nil && q.Paginator != nil {
 			ct, err := q.Count(models)
 			if err == nil {
@@ -141,85 +171,12 @@ func (q *Query) All(models interface{}) error {
 	}
 
 	if q.eager {
-		return q.eagerAssociations(models)
+		return q.loadEager(models)
 	}
 
 	return nil
 }
 
-// Load loads all association or the fields specified in params for
-// an already loaded model.
-//
-// tx.First(&u)
-// tx.Load(&u)
-func (c *Connection) Load(model interface{}, fields ...string) error {
-	q := Q(c)
-	q.eagerFields = fields
-	return q.eagerAssociations(model)
-}
-
-func (q *Query) eagerAssociations(model interface{}) error {
-	var err error
-
-	// eagerAssociations for a slice or array model passed as a param.
-	v := reflect.ValueOf(model)
-	if reflect.Indirect(v).Kind() == reflect.Slice ||
-		reflect.Indirect(v).Kind() == reflect.Array {
-		v = v.Elem()
-		for i := 0; i < v.Len(); i++ {
-			err = q.eagerAssociations(v.Index(i).Addr().Interface())
-			if err != nil {
-				return err
-			}
-		}
-		return err
-	}
-
-	assos, err := associations.AssociationsForStruct(model, q.eagerFields...)
-
-	if err != nil {
-		return err
-	}
-
-	for _, association := range assos {
-		if association == associations.SkippedAssociation {
-			continue
-		}
-
-		query := Q(q.Connection)
-		whereCondition, args := association.Constraint()
-		query = query.Where(whereCondition, args...)
-
-		// validates if association is Sortable
-		sortable := (*associations.AssociationSortable)(nil)
-		t := reflect.TypeOf(association)
-		if t.Implements(reflect.TypeOf(sortable).Elem()) {
-			m := reflect.ValueOf(association).MethodByName("OrderBy")
-			out := m.Call([]reflect.Value{})
-			orderClause := out[0].String()
-			if orderClause != "" {
-				query = query.Order(orderClause)
-			}
-		}
-
-		sqlSentence, args := query.ToSQL(&Model{Value: association.Interface()})
-		query = query.RawQuery(sqlSentence, args...)
-
-		if association.Kind() == reflect.Slice || association.Kind() == reflect.Array {
-			err = query.All(association.Interface())
-		}
-
-		if association.Kind() == reflect.Struct {
-			err = query.First(association.Interface())
-		}
-
-		if err != nil && errors.Cause(err) != sql.ErrNoRows {
-			return err
-		}
-	}
-	return nil
-}
-
 // Exists returns true/false if a record exists in the database that matches
 // the query.
 //
@@ -254,9 +211,19 @@ func (q Query) CountByField(model interface{}, field string) (int, error) {
 
 	err := tmpQuery.Connection.timeFunc("CountByField", func() error {
 		tmpQuery.Paginator = nil
+		tmpQuery.CursorPaginator = nil
+		tmpQuery.lockMode = ""
 		tmpQuery.orderClauses = clauses{}
 		tmpQuery.limitResults = 0
-		query, args := tmpQuery.ToSQL(&Model{Value: model})
+		if rc, ok := tmpQuery.Connection.Dialect.(rowCounter); ok {
+			n, err := rc.count(*tmpQuery, &Model{Value: model}, field)
+			res.Count = n
+			return err
+		}
+		query, args, err := tmpQuery.toSQL(&Model{Value: model})
+		if err != nil {
+			return err
+		}
 		//when query contains custom selected fields / executed using RawQuery,
 		//	sql may already contains limit and offset
 
@@ -269,8 +236,9 @@ func (q Query) CountByField(model interface{}, field string) (int, error) {
 		}
 
 		countQuery := fmt.Sprintf("select count(%s) as row_count from (%s) a", field, query)
-		Log(countQuery, args...)
-		return q.Connection.Store.Get(res, countQuery, args...)
+		return q.Connection.dedupe(q.Connection.Store, countQuery, args, res, func(dest interface{}) error {
+			return q.Connection.Store.Get(dest, countQuery, args...)
+		})
 	})
 	return res.Count, err
 }
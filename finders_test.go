@@ -2,6 +2,7 @@ package pop_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/markbates/pop"
 	"github.com/markbates/pop/nulls"
@@ -190,6 +191,69 @@ func Test_Load_Associations_Loaded_Model(t *testing.T) {
 	})
 }
 
+func Test_Load_Nested_Associations(t *testing.T) {
+	transaction(func(tx *pop.Connection) {
+		a := require.New(t)
+
+		user := User{Name: nulls.NewString("Mark")}
+		a.NoError(tx.Create(&user))
+
+		book := Book{Title: "Pop Book", Isbn: "PB1", UserID: nulls.NewInt(user.ID)}
+		a.NoError(tx.Create(&book))
+
+		u := User{}
+		a.NoError(tx.Find(&u, user.ID))
+
+		a.NoError(tx.Load(&u, "Books.User"))
+		a.Equal(len(u.Books), 1)
+		a.Equal(u.Books[0].Title, book.Title)
+		a.Equal(u.Books[0].User.ID, user.ID)
+		a.Zero(u.FavoriteSong.Title)
+
+		users := Users{}
+		a.NoError(tx.Where("id = ?", user.ID).All(&users))
+		a.NoError(tx.Load(&users, "Books", "Books.User"))
+		a.Equal(len(users[0].Books), 1)
+		a.Equal(users[0].Books[0].User.ID, user.ID)
+
+		err := tx.Load(&u, "Books.Nope")
+		a.Error(err)
+	})
+}
+
+func Test_Eager_Guards(t *testing.T) {
+	transaction(func(tx *pop.Connection) {
+		a := require.New(t)
+
+		user := User{Name: nulls.NewString("Mark")}
+		a.NoError(tx.Create(&user))
+
+		book := Book{Title: "Pop Book", Isbn: "PB1", UserID: nulls.NewInt(user.ID)}
+		a.NoError(tx.Create(&book))
+
+		u := User{}
+		err := tx.Eager("Books.User.Books").EagerMaxDepth(2).Find(&u, user.ID)
+		a.Error(err)
+		a.Contains(err.Error(), "maximum is 2")
+
+		u = User{}
+		err = tx.Eager("Books.User").EagerMaxDepth(2).Find(&u, user.ID)
+		a.NoError(err)
+		a.Equal(u.Books[0].User.ID, user.ID)
+
+		u = User{}
+		err = tx.Eager("FavoriteSong").EagerAllow("Books.User").Find(&u, user.ID)
+		a.Error(err)
+		a.Contains(err.Error(), "FavoriteSong is not allowed")
+
+		u = User{}
+		err = tx.Eager().EagerAllow("Books").Find(&u, user.ID)
+		a.NoError(err)
+		a.Equal(len(u.Books), 1)
+		a.Zero(u.Books[0].User.ID)
+	})
+}
+
 func Test_First(t *testing.T) {
 	transaction(func(tx *pop.Connection) {
 		a := require.New(t)
@@ -291,6 +355,56 @@ func Test_All_Eager_Field_Not_Found_Error(t *testing.T) {
 	})
 }
 
+type orphanAddress struct {
+	ID          int           `db:"id"`
+	Street      string        `db:"street"`
+	HouseNumber int           `db:"house_number"`
+	CreatedAt   time.Time     `db:"created_at"`
+	UpdatedAt   time.Time     `db:"updated_at"`
+	Ghosts      []orphanGhost `has_many:"ghosts"`
+}
+
+func (orphanAddress) TableName() string {
+	return "addresses"
+}
+
+type orphanGhost struct {
+	ID int `db:"id"`
+}
+
+func (orphanGhost) TableName() string {
+	return "ghosts_that_do_not_exist"
+}
+
+func Test_All_Eager_Slice_Errors(t *testing.T) {
+	transaction(func(tx *pop.Connection) {
+		a := require.New(t)
+
+		for _, street := range []string{"Broadway", "Main"} {
+			address := Address{Street: street, HouseNumber: 1}
+			a.NoError(tx.Create(&address))
+		}
+
+		u := []orphanAddress{}
+		err := tx.Eager().Order("id asc").All(&u)
+		a.Error(err)
+		errs, ok := err.(pop.EagerErrors)
+		a.True(ok)
+		a.Len(errs, 1)
+		a.Equal(0, errs[0].Index)
+		a.Equal(u[0].ID, errs[0].ID)
+
+		u = []orphanAddress{}
+		err = tx.Eager().EagerContinue().Order("id asc").All(&u)
+		a.Error(err)
+		errs, ok = err.(pop.EagerErrors)
+		a.True(ok)
+		a.Len(errs, 2)
+		a.Equal(1, errs[1].Index)
+		a.Equal(u[1].ID, errs[1].ID)
+	})
+}
+
 func Test_All_Eager_Allow_Chain_Call(t *testing.T) {
 	transaction(func(tx *pop.Connection) {
 		a := require.New(t)
@@ -410,6 +524,22 @@ func Test_Count_RawQuery(t *testing.T) {
 	})
 }
 
+func Test_CountEstimate(t *testing.T) {
+	transaction(func(tx *pop.Connection) {
+		a := require.New(t)
+
+		for _, name := range []string{"Mark", "Joe"} {
+			a.NoError(tx.Create(&User{Name: nulls.NewString(name)}))
+		}
+
+		c, err := tx.Where("name = ?", "Mark").CountEstimate(&User{})
+		a.NoError(err)
+		if tx.Dialect.Details().Dialect == "sqlite3" {
+			a.Equal(1, c)
+		}
+	})
+}
+
 func Test_Exists(t *testing.T) {
 	transaction(func(tx *pop.Connection) {
 		a := require.New(t)
@@ -425,3 +555,110 @@ func Test_Exists(t *testing.T) {
 		a.True(t)
 	})
 }
+
+func Test_EagerWith(t *testing.T) {
+	transaction(func(tx *pop.Connection) {
+		r := require.New(t)
+
+		user := User{Name: nulls.NewString("Mark")}
+		r.NoError(tx.Create(&user))
+		for _, title := range []string{"Pop 1", "Buffalo", "Pop 2"} {
+			r.NoError(tx.Create(&Book{Title: title, Isbn: "PB1", UserID: nulls.NewInt(user.ID)}))
+		}
+
+		u := User{}
+		err := tx.EagerWith("Books", pop.Where("title like ?", "Pop%"), pop.Order("title desc")).Find(&u, user.ID)
+		r.NoError(err)
+		r.Len(u.Books, 2)
+		r.Equal("Pop 2", u.Books[0].Title)
+		r.Equal("Pop 1", u.Books[1].Title)
+
+		// each level has its own scopes.
+		u = User{}
+		err = tx.EagerWith("Books").EagerWith("Books.User", pop.Where("name = ?", "Ringo")).Find(&u, user.ID)
+		r.NoError(err)
+		r.Len(u.Books, 3)
+		r.Equal(0, u.Books[0].User.ID)
+
+		users := Users{}
+		err = tx.EagerCache().EagerWith("Books", pop.Where("title = ?", "Buffalo")).EagerWith("Books.User").All(&users)
+		r.NoError(err)
+		r.Len(users, 1)
+		r.Len(users[0].Books, 1)
+		r.Equal("Mark", users[0].Books[0].User.Name.String)
+
+		// a scoped association is not joined.
+		books := Books{}
+		err = tx.EagerJoin().EagerWith("User", pop.Where("name = ?", "Ringo")).All(&books)
+		r.NoError(err)
+		r.Len(books, 3)
+		r.Equal(0, books[0].User.ID)
+	})
+}
+
+func Test_EagerLoad(t *testing.T) {
+	transaction(func(tx *pop.Connection) {
+		r := require.New(t)
+
+		user := User{Name: nulls.NewString("Mark")}
+		r.NoError(tx.Create(&user))
+		r.NoError(tx.Create(&Book{Title: "Pop Book", Isbn: "PB1", UserID: nulls.NewInt(user.ID)}))
+		r.NoError(tx.Create(&Song{Title: "Hook", UserID: user.ID}))
+
+		u := &User{}
+		err := tx.EagerLoad(u, func(l *pop.Loader) {
+			l.Load(&u.FavoriteSong)
+		}).Find(u, user.ID)
+		r.NoError(err)
+		r.Equal("Hook", u.FavoriteSong.Title)
+		r.Len(u.Books, 0)
+
+		// a nested field is reached through an element of the slice.
+		blank := &User{Books: Books{{}}}
+		users := Users{}
+		err = tx.EagerLoad(blank, func(l *pop.Loader) {
+			l.Load(&blank.Books[0].User)
+		}).All(&users)
+		r.NoError(err)
+		r.Len(users, 1)
+		r.Len(users[0].Books, 1)
+		r.Equal("Mark", users[0].Books[0].User.Name.String)
+
+		other := &User{}
+		err = tx.EagerLoad(u, func(l *pop.Loader) {
+			l.Load(&other.Books)
+		}).Find(u, user.ID)
+		r.Error(err)
+		r.Contains(err.Error(), "does not point to a field")
+	})
+}
+
+func Test_Find_EagerParallel(t *testing.T) {
+	a := require.New(t)
+
+	user := User{Name: nulls.NewString("Parallel")}
+	a.NoError(PDB.Create(&user))
+	defer PDB.Destroy(&user)
+	books := Books{
+		{Title: "Pop Book", Isbn: "PB1", UserID: nulls.NewInt(user.ID)},
+		{Title: "New Pop Book", Isbn: "PB2", UserID: nulls.NewInt(user.ID)},
+	}
+	a.NoError(PDB.Create(&books))
+	defer PDB.Destroy(&books)
+	song := Song{Title: "Hook - Blues Traveler", UserID: user.ID}
+	a.NoError(PDB.Create(&song))
+	defer PDB.Destroy(&song)
+
+	u := User{}
+	a.NoError(PDB.EagerParallel(4, "Books", "FavoriteSong", "Houses").Find(&u, user.ID))
+	a.Len(u.Books, 2)
+	a.Equal("New Pop Book", u.Books[0].Title)
+	a.Equal(song.ID, u.FavoriteSong.ID)
+	a.Len(u.Houses, 0)
+
+	users := Users{}
+	a.NoError(PDB.Where("id = ?", user.ID).EagerParallel(4).All(&users))
+	a.Len(users, 1)
+	a.Len(users[0].Books, 2)
+	a.Equal(song.ID, users[0].FavoriteSong.ID)
+}
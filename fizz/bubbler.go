@@ -56,6 +56,10 @@ func (b *Bubbler) Bubble(s string) (string, error) {
 	env.Define("drop_table", f.DropTable())
 	env.Define("rename_table", f.RenameTable())
 
+	// sequences:
+	env.Define("create_sequence", f.CreateSequence())
+	env.Define("drop_sequence", f.DropSequence())
+
 	_, err := env.Execute(s)
 	return b.String(), errors.Wrap(err, "parse error")
 }
@@ -0,0 +1,62 @@
+package fizz
+
+import "github.com/pkg/errors"
+
+// Sequence is a named generator of integer values. The "start" and
+// "increment" options default to 1.
+type Sequence struct {
+	Name    string
+	Options Options
+}
+
+// SequenceTranslator is implemented by the translators supporting
+// sequences.
+type SequenceTranslator interface {
+	CreateSequence(Sequence) (string, error)
+	DropSequence(Sequence) (string, error)
+}
+
+func (f fizzer) CreateSequence() interface{} {
+	return func(name string, options Options) {
+		st, ok := f.Bubbler.Translator.(SequenceTranslator)
+		if !ok {
+			f.add("", errors.Errorf("sequences are not supported by %T", f.Bubbler.Translator))
+			return
+		}
+		f.add(st.CreateSequence(Sequence{Name: name, Options: options}))
+	}
+}
+
+func (f fizzer) DropSequence() interface{} {
+	return func(name string) {
+		st, ok := f.Bubbler.Translator.(SequenceTranslator)
+		if !ok {
+			f.add("", errors.Errorf("sequences are not supported by %T", f.Bubbler.Translator))
+			return
+		}
+		f.add(st.DropSequence(Sequence{Name: name}))
+	}
+}
+
+// Start returns the "start" option of the sequence, 1 by default.
+func (s Sequence) Start() int {
+	return s.intOption("start")
+}
+
+// Increment returns the "increment" option of the sequence, 1 by
+// default.
+func (s Sequence) Increment() int {
+	return s.intOption("increment")
+}
+
+func (s Sequence) intOption(name string) int {
+	switch v := s.Options[name].(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	}
+	return 1
+}
@@ -0,0 +1,23 @@
+package fizz
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// noSequences is a translator without sequences.
+type noSequences struct {
+	Translator
+}
+
+func Test_Sequences_Unsupported(t *testing.T) {
+	r := require.New(t)
+
+	_, err := AString(`create_sequence("invoice_numbers", {})`, noSequences{})
+	r.Error(err)
+	r.Contains(err.Error(), "sequences are not supported")
+
+	_, err = AString(`drop_sequence("invoice_numbers")`, noSequences{})
+	r.Error(err)
+}
@@ -61,6 +61,20 @@ func (t *Table) Timestamps() {
 	t.Columns = append(t.Columns, []Column{CREATED_COL, UPDATED_COL}...)
 }
 
+// Tree adds a nullable "parent_id" column referencing the parent row, for
+// tables storing a tree as an adjacency list. The column has the type of the
+// primary key if it was already declared, and is indexed.
+func (t *Table) Tree() {
+	colType := "integer"
+	for _, c := range t.Columns {
+		if c.Primary {
+			colType = c.ColType
+		}
+	}
+	t.Column("parent_id", colType, Options{"null": true})
+	t.Options["tree"] = true
+}
+
 func (t *Table) ColumnNames() []string {
 	cols := make([]string, len(t.Columns))
 	for i, c := range t.Columns {
@@ -87,6 +101,7 @@ func (f fizzer) CreateTable() interface{} {
 		t := Table{
 			Name:    name,
 			Columns: []Column{},
+			Options: map[string]interface{}{},
 		}
 
 		fn(&t)
@@ -107,6 +122,16 @@ func (f fizzer) CreateTable() interface{} {
 		}
 
 		f.add(f.Bubbler.CreateTable(t))
+
+		if t.Options["tree"] == true {
+			f.add(f.Bubbler.AddIndex(Table{
+				Name: t.Name,
+				Indexes: []Index{{
+					Name:    fmt.Sprintf("%s_parent_id_idx", t.Name),
+					Columns: []string{"parent_id"},
+				}},
+			}))
+		}
 	}
 }
 
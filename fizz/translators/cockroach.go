@@ -26,6 +26,7 @@ func NewCockroach(url string, name string) *Cockroach {
 }
 
 func (p *Cockroach) CreateTable(t fizz.Table) (string, error) {
+	t = shortenNames(t, postgresMaxIdentifier)
 	p.Schema.SetTable(&t)
 	sql := []string{}
 	cols := []string{}
@@ -186,6 +187,7 @@ func (p *Cockroach) RenameColumn(t fizz.Table) (string, error) {
 }
 
 func (p *Cockroach) AddIndex(t fizz.Table) (string, error) {
+	t = shortenNames(t, postgresMaxIdentifier)
 	if len(t.Indexes) == 0 {
 		return "", errors.New("Not enough indexes supplied!")
 	}
@@ -214,6 +216,7 @@ func (p *Cockroach) DropIndex(t fizz.Table) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	i.Name = existingName(i.Name, postgresMaxIdentifier, hasIndex(tableInfo))
 
 	newIndexes := []fizz.Index{}
 	for _, c := range tableInfo.Indexes {
@@ -238,6 +241,8 @@ func (p *Cockroach) RenameIndex(t fizz.Table) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	oi.Name = existingName(oi.Name, postgresMaxIdentifier, hasIndex(tableInfo))
+	ni.Name = shortenIdentifier(ni.Name, postgresMaxIdentifier)
 
 	for _, c := range tableInfo.Indexes {
 		if c.Name == oi.Name {
@@ -249,6 +254,7 @@ func (p *Cockroach) RenameIndex(t fizz.Table) (string, error) {
 }
 
 func (p *Cockroach) AddForeignKey(t fizz.Table) (string, error) {
+	t = shortenNames(t, postgresMaxIdentifier)
 	if len(t.ForeignKeys) == 0 {
 		return "", errors.New("Not enough foreign keys supplied!")
 	}
@@ -273,6 +279,14 @@ func (p *Cockroach) DropForeignKey(t fizz.Table) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	fk.Name = existingName(fk.Name, postgresMaxIdentifier, func(name string) bool {
+		for _, key := range tableInfo.ForeignKeys {
+			if key.Name == name {
+				return true
+			}
+		}
+		return false
+	})
 	newFKs := []fizz.ForeignKey{}
 	for _, key := range tableInfo.ForeignKeys {
 		if key.Name != fk.Name {
@@ -290,6 +304,18 @@ func (p *Cockroach) DropForeignKey(t fizz.Table) (string, error) {
 	return s, nil
 }
 
+// hasIndex returns a function telling whether the table has an index.
+func hasIndex(t *fizz.Table) func(string) bool {
+	return func(name string) bool {
+		for _, i := range t.Indexes {
+			if i.Name == name {
+				return true
+			}
+		}
+		return false
+	}
+}
+
 func (p *Cockroach) buildAddColumn(c fizz.Column) string {
 	s := fmt.Sprintf("\"%s\" %s", c.Name, p.colType(c))
 
@@ -390,3 +416,11 @@ func (p *Cockroach) buildForeignKey(t fizz.Table, fk fizz.ForeignKey, onCreate b
 
 	return s
 }
+
+func (p *Cockroach) CreateSequence(s fizz.Sequence) (string, error) {
+	return fmt.Sprintf("CREATE SEQUENCE \"%s\" START WITH %d INCREMENT BY %d;COMMIT TRANSACTION;BEGIN TRANSACTION;", s.Name, s.Start(), s.Increment()), nil
+}
+
+func (p *Cockroach) DropSequence(s fizz.Sequence) (string, error) {
+	return fmt.Sprintf("DROP SEQUENCE \"%s\";COMMIT TRANSACTION;BEGIN TRANSACTION;", s.Name), nil
+}
@@ -25,6 +25,9 @@ func (p *CockroachSuite) crdbt() *translators.Cockroach {
 	schema["table"] = ta
 	ta = &fizz.Table{Name: "profiles"}
 	schema["profiles"] = ta
+	ta = &fizz.Table{Name: "organization_memberships"}
+	ta.Indexes = []fizz.Index{{Name: "organization_memberships_organization_id_user_id_role_id_team_i"}}
+	schema["organization_memberships"] = ta
 
 	ret.Schema.ReplaceSchema(schema)
 	return ret
@@ -229,6 +232,19 @@ func (p *CockroachSuite) Test_Cockroach_RenameIndex() {
 	r.Equal(ddl, res)
 }
 
+func (p *CockroachSuite) Test_Cockroach_LongIdentifiers() {
+	r := p.Require()
+
+	// the index was created with the name truncated by CockroachDB.
+	ddl := `DROP INDEX IF EXISTS "organization_memberships_organization_id_user_id_role_id_team_i";COMMIT TRANSACTION;BEGIN TRANSACTION;`
+	res, _ := fizz.AString(`drop_index("organization_memberships", "organization_memberships_organization_id_user_id_role_id_team_id_idx")`, p.crdbt())
+	r.Equal(ddl, res)
+
+	ddl = `DROP INDEX IF EXISTS "organization_memberships_organization_id_user_id_role__6868dae5";COMMIT TRANSACTION;BEGIN TRANSACTION;`
+	res, _ = fizz.AString(`drop_index("users", "organization_memberships_organization_id_user_id_role_id_team_id_idx")`, p.crdbt())
+	r.Equal(ddl, res)
+}
+
 func (p *CockroachSuite) buildSchema() translators.Schema {
 	schema := map[string]*fizz.Table{}
 	ta := &fizz.Table{Name: "testTable"}
@@ -0,0 +1,85 @@
+package translators
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"unicode/utf8"
+
+	"github.com/markbates/pop/fizz"
+)
+
+const (
+	// postgresMaxIdentifier is NAMEDATALEN - 1, PostgreSQL and CockroachDB
+	// silently truncate the longer names.
+	postgresMaxIdentifier = 63
+	// mysqlMaxIdentifier is the length of the index and constraint names
+	// MySQL accepts.
+	mysqlMaxIdentifier = 64
+)
+
+// shortenIdentifier returns name if it fits in max bytes. Longer names are
+// truncated, and suffixed with a hash of the whole name: names differing
+// after max bytes stay distinct, and a name is always shortened the same
+// way, so that the index created by a migration can be dropped by the
+// next one.
+func shortenIdentifier(name string, max int) string {
+	if len(name) <= max {
+		return name
+	}
+	sum := sha1.Sum([]byte(name))
+	suffix := "_" + hex.EncodeToString(sum[:])[:8]
+	n := max - len(suffix)
+	for n > 0 && !utf8.RuneStart(name[n]) {
+		n--
+	}
+	return name[:n] + suffix
+}
+
+// truncatedIdentifier returns name cut to max bytes, the way PostgreSQL
+// and CockroachDB truncated the long names before fizz shortened them.
+func truncatedIdentifier(name string, max int) string {
+	if len(name) <= max {
+		return name
+	}
+	n := max
+	for n > 0 && !utf8.RuneStart(name[n]) {
+		n--
+	}
+	return name[:n]
+}
+
+// existingName returns the shortened name of an index or foreign key, or
+// the truncated name it was created with, if only that one exists.
+func existingName(name string, max int, exists func(string) bool) string {
+	short := shortenIdentifier(name, max)
+	if short == name || exists(short) {
+		return short
+	}
+	if old := truncatedIdentifier(name, max); exists(old) {
+		return old
+	}
+	return short
+}
+
+// shortenNames returns t with the names of its indexes and foreign keys
+// shortened to max bytes. MySQL refused the longer names, the existing
+// names all fit, whereas PostgreSQL truncated them: see existingName.
+func shortenNames(t fizz.Table, max int) fizz.Table {
+	if t.Indexes != nil {
+		indexes := make([]fizz.Index, len(t.Indexes))
+		for i, ix := range t.Indexes {
+			ix.Name = shortenIdentifier(ix.Name, max)
+			indexes[i] = ix
+		}
+		t.Indexes = indexes
+	}
+	if t.ForeignKeys != nil {
+		fks := make([]fizz.ForeignKey, len(t.ForeignKeys))
+		for i, fk := range t.ForeignKeys {
+			fk.Name = shortenIdentifier(fk.Name, max)
+			fks[i] = fk
+		}
+		t.ForeignKeys = fks
+	}
+	return t
+}
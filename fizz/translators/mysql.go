@@ -12,6 +12,13 @@ import (
 
 type MySQL struct {
 	Schema SchemaQuery
+	// MariaDB enables the MariaDB flavor of the generated SQL.
+	MariaDB bool
+	// TiDB enables the TiDB flavor of the generated SQL.
+	TiDB bool
+	// UUIDBinary stores the uuid columns as BINARY(16), instead of
+	// char(36).
+	UUIDBinary bool
 }
 
 func NewMySQL(url, name string) *MySQL {
@@ -22,7 +29,24 @@ func NewMySQL(url, name string) *MySQL {
 	}
 }
 
+// NewMariaDB returns a MySQL translator generating SQL for MariaDB,
+// where it diverges from MySQL.
+func NewMariaDB(url, name string) *MySQL {
+	m := NewMySQL(url, name)
+	m.MariaDB = true
+	return m
+}
+
+// NewTiDB returns a MySQL translator generating SQL for TiDB, where it
+// diverges from MySQL.
+func NewTiDB(url, name string) *MySQL {
+	m := NewMySQL(url, name)
+	m.TiDB = true
+	return m
+}
+
 func (p *MySQL) CreateTable(t fizz.Table) (string, error) {
+	t = shortenNames(t, mysqlMaxIdentifier)
 	sql := []string{}
 	cols := []string{}
 	for _, c := range t.Columns {
@@ -115,6 +139,7 @@ func (p *MySQL) RenameColumn(t fizz.Table) (string, error) {
 }
 
 func (p *MySQL) AddIndex(t fizz.Table) (string, error) {
+	t = shortenNames(t, mysqlMaxIdentifier)
 	if len(t.Indexes) == 0 {
 		return "", errors.New("Not enough indexes supplied!")
 	}
@@ -127,6 +152,7 @@ func (p *MySQL) AddIndex(t fizz.Table) (string, error) {
 }
 
 func (p *MySQL) DropIndex(t fizz.Table) (string, error) {
+	t = shortenNames(t, mysqlMaxIdentifier)
 	if len(t.Indexes) == 0 {
 		return "", errors.New("Not enough indexes supplied!")
 	}
@@ -135,13 +161,14 @@ func (p *MySQL) DropIndex(t fizz.Table) (string, error) {
 }
 
 func (p *MySQL) RenameIndex(t fizz.Table) (string, error) {
+	t = shortenNames(t, mysqlMaxIdentifier)
 	schema := p.Schema.(*mysqlSchema)
 	version, err := schema.Version()
 	if err != nil {
 		return "", errors.WithStack(err)
 	}
-	if !strings.HasPrefix(version, "5.7") {
-		return "", errors.New("renaming indexes on MySQL versions less than 5.7 is not supported by fizz; use raw SQL instead")
+	if !canRenameIndex(version) {
+		return "", errors.New("renaming indexes on MySQL versions less than 5.7, or MariaDB versions less than 10.5.2, is not supported by fizz; use raw SQL instead")
 	}
 	ix := t.Indexes
 	if len(ix) < 2 {
@@ -153,6 +180,7 @@ func (p *MySQL) RenameIndex(t fizz.Table) (string, error) {
 }
 
 func (p *MySQL) AddForeignKey(t fizz.Table) (string, error) {
+	t = shortenNames(t, mysqlMaxIdentifier)
 	if len(t.ForeignKeys) == 0 {
 		return "", errors.New("Not enough foreign keys supplied!")
 	}
@@ -161,6 +189,7 @@ func (p *MySQL) AddForeignKey(t fizz.Table) (string, error) {
 }
 
 func (p *MySQL) DropForeignKey(t fizz.Table) (string, error) {
+	t = shortenNames(t, mysqlMaxIdentifier)
 	if len(t.ForeignKeys) == 0 {
 		return "", errors.New("Not enough foreign keys supplied!")
 	}
@@ -193,11 +222,39 @@ func (p *MySQL) buildColumn(c fizz.Column) string {
 	}
 
 	if c.Primary && (c.ColType == "integer" || strings.ToLower(c.ColType) == "int") {
-		s = fmt.Sprintf("%s AUTO_INCREMENT", s)
+		if p.TiDB {
+			// sequential ids make all the inserts hit the same TiKV
+			// region, AUTO_RANDOM spreads them.
+			s = strings.Replace(s, p.colType(c), "BIGINT", 1)
+			s = fmt.Sprintf("%s AUTO_RANDOM", s)
+		} else {
+			s = fmt.Sprintf("%s AUTO_INCREMENT", s)
+		}
+	}
+	if p.MariaDB && isJSONType(c.ColType) {
+		// MariaDB stores JSON as LONGTEXT, and unlike MySQL before 8.0.16
+		// it enforces CHECK constraints, so the content can be validated.
+		s = fmt.Sprintf("%s CHECK (JSON_VALID(%s))", s, c.Name)
 	}
 	return s
 }
 
+func isJSONType(t string) bool {
+	t = strings.ToLower(t)
+	return t == "json" || t == "jsonb"
+}
+
+// canRenameIndex returns true if the server supports
+// ALTER TABLE ... RENAME INDEX: MySQL 5.7+ and MariaDB 10.5.2+.
+func canRenameIndex(version string) bool {
+	var major, minor, patch int
+	fmt.Sscanf(version, "%d.%d.%d", &major, &minor, &patch)
+	if strings.Contains(version, "MariaDB") {
+		return major > 10 || (major == 10 && (minor > 5 || (minor == 5 && patch >= 2)))
+	}
+	return major > 5 || (major == 5 && minor >= 7)
+}
+
 func (p *MySQL) colType(c fizz.Column) string {
 	switch strings.ToLower(c.ColType) {
 	case "string":
@@ -207,9 +264,17 @@ func (p *MySQL) colType(c fizz.Column) string {
 		}
 		return fmt.Sprintf("VARCHAR (%s)", s)
 	case "uuid":
+		if p.UUIDBinary {
+			return "BINARY(16)"
+		}
 		return "char(36)"
 	case "timestamp", "time", "datetime":
 		return "DATETIME"
+	case "json", "jsonb":
+		if p.MariaDB {
+			return "LONGTEXT"
+		}
+		return "JSON"
 	default:
 		return c.ColType
 	}
@@ -233,3 +298,23 @@ func (p *MySQL) buildForeignKey(t fizz.Table, fk fizz.ForeignKey, onCreate bool)
 
 	return s
 }
+
+// CreateSequence creates a native sequence on MariaDB and TiDB. MySQL has
+// no sequences, they are emulated with an AUTO_INCREMENT table, and can not
+// have an increment other than 1.
+func (p *MySQL) CreateSequence(s fizz.Sequence) (string, error) {
+	if p.MariaDB || p.TiDB {
+		return fmt.Sprintf("CREATE SEQUENCE %s START WITH %d INCREMENT BY %d;", s.Name, s.Start(), s.Increment()), nil
+	}
+	if s.Increment() != 1 {
+		return "", errors.Errorf("MySQL sequences can not have an increment, %s has %d", s.Name, s.Increment())
+	}
+	return fmt.Sprintf("CREATE TABLE %s (\nid BIGINT NOT NULL AUTO_INCREMENT,\nPRIMARY KEY(id)\n) ENGINE=InnoDB AUTO_INCREMENT=%d;", s.Name, s.Start()), nil
+}
+
+func (p *MySQL) DropSequence(s fizz.Sequence) (string, error) {
+	if p.MariaDB || p.TiDB {
+		return fmt.Sprintf("DROP SEQUENCE %s;", s.Name), nil
+	}
+	return fmt.Sprintf("DROP TABLE %s;", s.Name), nil
+}
@@ -93,6 +93,27 @@ updated_at DATETIME NOT NULL
 	r.Equal(ddl, res)
 }
 
+func (p *MySQLSuite) Test_MySQL_CreateTable_UUIDBinary() {
+	r := p.Require()
+	ddl := `CREATE TABLE users (
+id BINARY(16) NOT NULL,
+PRIMARY KEY(id),
+company_id BINARY(16),
+created_at DATETIME NOT NULL,
+updated_at DATETIME NOT NULL
+) ENGINE=InnoDB;`
+
+	bt := translators.NewMySQL("", "")
+	bt.UUIDBinary = true
+	res, _ := fizz.AString(`
+	create_table("users", func(t) {
+		t.Column("id", "uuid", {"primary": true})
+		t.Column("company_id", "uuid", {"null": true})
+	})
+	`, bt)
+	r.Equal(ddl, res)
+}
+
 func (p *MySQLSuite) Test_MySQL_CreateTables_WithForeignKeys() {
 	r := p.Require()
 	ddl := `CREATE TABLE users (
@@ -253,3 +274,69 @@ func (p *MySQLSuite) Test_MySQL_DropForeignKey() {
 	res, _ := fizz.AString(`drop_foreign_key("profiles", "profiles_users_id_fk", {})`, myt)
 	r.Equal(ddl, res)
 }
+
+func (p *MySQLSuite) Test_MySQL_JSONColumn() {
+	r := p.Require()
+	ddl := `ALTER TABLE users ADD COLUMN settings JSON;`
+
+	res, _ := fizz.AString(`add_column("users", "settings", "json", {"null": true})`, myt)
+	r.Equal(ddl, res)
+}
+
+func (p *MySQLSuite) Test_MariaDB_JSONColumn() {
+	r := p.Require()
+	ddl := `ALTER TABLE users ADD COLUMN settings LONGTEXT CHECK (JSON_VALID(settings));`
+
+	res, _ := fizz.AString(`add_column("users", "settings", "json", {"null": true})`, translators.NewMariaDB("", ""))
+	r.Equal(ddl, res)
+}
+
+func (p *MySQLSuite) Test_TiDB_CreateTable() {
+	r := p.Require()
+	ddl := `CREATE TABLE users (
+id BIGINT NOT NULL AUTO_RANDOM,
+PRIMARY KEY(id),
+email VARCHAR (20) NOT NULL,
+created_at DATETIME NOT NULL,
+updated_at DATETIME NOT NULL
+) ENGINE=InnoDB;`
+
+	res, _ := fizz.AString(`
+	create_table("users", func(t) {
+		t.Column("email", "string", {"size":20})
+	})
+	`, translators.NewTiDB("", ""))
+	r.Equal(ddl, res)
+}
+
+func (p *MySQLSuite) Test_MySQL_CreateSequence() {
+	r := p.Require()
+	ddl := `CREATE TABLE invoice_numbers (
+id BIGINT NOT NULL AUTO_INCREMENT,
+PRIMARY KEY(id)
+) ENGINE=InnoDB AUTO_INCREMENT=1000;`
+
+	res, _ := fizz.AString(`create_sequence("invoice_numbers", {"start": 1000})`, myt)
+	r.Equal(ddl, res)
+
+	_, err := fizz.AString(`create_sequence("invoice_numbers", {"increment": 2})`, myt)
+	r.Error(err)
+}
+
+func (p *MySQLSuite) Test_MySQL_DropSequence() {
+	r := p.Require()
+	res, _ := fizz.AString(`drop_sequence("invoice_numbers")`, myt)
+	r.Equal(`DROP TABLE invoice_numbers;`, res)
+}
+
+func (p *MySQLSuite) Test_MySQL_LongIdentifiers() {
+	r := p.Require()
+
+	ddl := `CREATE INDEX organization_memberships_organization_id_user_id_role_i_6868dae5 ON organization_memberships (organization_id, user_id, role_id, team_id);`
+	res, _ := fizz.AString(`add_index("organization_memberships", ["organization_id", "user_id", "role_id", "team_id"], {})`, myt)
+	r.Equal(ddl, res)
+
+	ddl = `ALTER TABLE organization_memberships ADD CONSTRAINT organization_memberships_organization_settings_organiza_754d51e5 FOREIGN KEY (organization_id) REFERENCES organization_settings (organization_id);`
+	res, _ = fizz.AString(`add_foreign_key("organization_memberships", "organization_id", {"organization_settings": ["organization_id"]}, {})`, myt)
+	r.Equal(ddl, res)
+}
@@ -10,13 +10,23 @@ import (
 )
 
 type Postgres struct {
+	// Redshift enables the Amazon Redshift flavor of the generated SQL.
+	Redshift bool
 }
 
 func NewPostgres() *Postgres {
 	return &Postgres{}
 }
 
+// NewRedshift returns a Postgres translator generating SQL for Amazon
+// Redshift, where it diverges from PostgreSQL. Redshift has no indexes,
+// so the index operations generate no SQL.
+func NewRedshift() *Postgres {
+	return &Postgres{Redshift: true}
+}
+
 func (p *Postgres) CreateTable(t fizz.Table) (string, error) {
+	t = shortenNames(t, postgresMaxIdentifier)
 	sql := []string{}
 	cols := []string{}
 	var s string
@@ -27,6 +37,9 @@ func (p *Postgres) CreateTable(t fizz.Table) (string, error) {
 				s = fmt.Sprintf("\"%s\" %s PRIMARY KEY", c.Name, p.colType(c))
 			case "integer", "INT", "int":
 				s = fmt.Sprintf("\"%s\" SERIAL PRIMARY KEY", c.Name)
+				if p.Redshift {
+					s = fmt.Sprintf("\"%s\" BIGINT IDENTITY(1,1) PRIMARY KEY", c.Name)
+				}
 			default:
 				return "", errors.Errorf("can not use %s as a primary key", c.ColType)
 			}
@@ -51,7 +64,9 @@ func (p *Postgres) CreateTable(t fizz.Table) (string, error) {
 		if err != nil {
 			return "", err
 		}
-		sql = append(sql, s)
+		if s != "" {
+			sql = append(sql, s)
+		}
 	}
 
 	return strings.Join(sql, "\n"), nil
@@ -105,9 +120,13 @@ func (p *Postgres) RenameColumn(t fizz.Table) (string, error) {
 }
 
 func (p *Postgres) AddIndex(t fizz.Table) (string, error) {
+	t = shortenNames(t, postgresMaxIdentifier)
 	if len(t.Indexes) == 0 {
 		return "", errors.New("Not enough indexes supplied!")
 	}
+	if p.Redshift {
+		return "", nil
+	}
 	i := t.Indexes[0]
 	s := fmt.Sprintf("CREATE INDEX \"%s\" ON \"%s\" (%s);", i.Name, t.Name, strings.Join(i.Columns, ", "))
 	if i.Unique {
@@ -120,8 +139,12 @@ func (p *Postgres) DropIndex(t fizz.Table) (string, error) {
 	if len(t.Indexes) == 0 {
 		return "", errors.New("Not enough indexes supplied!")
 	}
-	i := t.Indexes[0]
-	return fmt.Sprintf("DROP INDEX \"%s\";", i.Name), nil
+	if p.Redshift {
+		return "", nil
+	}
+	name := t.Indexes[0].Name
+	s := fmt.Sprintf("DROP INDEX \"%s\";", shortenIdentifier(name, postgresMaxIdentifier))
+	return legacyIndexRename(name) + s, nil
 }
 
 func (p *Postgres) RenameIndex(t fizz.Table) (string, error) {
@@ -129,12 +152,41 @@ func (p *Postgres) RenameIndex(t fizz.Table) (string, error) {
 	if len(ix) < 2 {
 		return "", errors.New("Not enough indexes supplied!")
 	}
-	oi := ix[0]
-	ni := ix[1]
-	return fmt.Sprintf("ALTER INDEX \"%s\" RENAME TO \"%s\";", oi.Name, ni.Name), nil
+	if p.Redshift {
+		return "", nil
+	}
+	on := shortenIdentifier(ix[0].Name, postgresMaxIdentifier)
+	nn := shortenIdentifier(ix[1].Name, postgresMaxIdentifier)
+	s := fmt.Sprintf("ALTER INDEX \"%s\" RENAME TO \"%s\";", on, nn)
+	return legacyIndexRename(ix[0].Name) + s, nil
+}
+
+// legacyIndexRename returns the statement giving its shortened name to the
+// index name was truncated to by PostgreSQL, if it was created before fizz
+// shortened the long names, so that it can be dropped or renamed.
+func legacyIndexRename(name string) string {
+	short := shortenIdentifier(name, postgresMaxIdentifier)
+	if short == name {
+		return ""
+	}
+	old := truncatedIdentifier(name, postgresMaxIdentifier)
+	return fmt.Sprintf("DO $$ BEGIN IF to_regclass('\"%s\"') IS NULL AND to_regclass('\"%s\"') IS NOT NULL THEN ALTER INDEX \"%s\" RENAME TO \"%s\"; END IF; END $$;\n", short, old, old, short)
+}
+
+// legacyForeignKeyRename is legacyIndexRename for the foreign key name of
+// table.
+func legacyForeignKeyRename(table, name string) string {
+	short := shortenIdentifier(name, postgresMaxIdentifier)
+	if short == name {
+		return ""
+	}
+	old := truncatedIdentifier(name, postgresMaxIdentifier)
+	exists := "SELECT 1 FROM pg_constraint WHERE conrelid = to_regclass('%s') AND conname = '%s'"
+	return fmt.Sprintf("DO $$ BEGIN IF NOT EXISTS ("+exists+") AND EXISTS ("+exists+") THEN ALTER TABLE %s RENAME CONSTRAINT \"%s\" TO \"%s\"; END IF; END $$;\n", table, short, table, old, table, old, short)
 }
 
 func (p *Postgres) AddForeignKey(t fizz.Table) (string, error) {
+	t = shortenNames(t, postgresMaxIdentifier)
 	if len(t.ForeignKeys) == 0 {
 		return "", errors.New("Not enough foreign keys supplied!")
 	}
@@ -148,6 +200,8 @@ func (p *Postgres) DropForeignKey(t fizz.Table) (string, error) {
 	}
 
 	fk := t.ForeignKeys[0]
+	legacy := legacyForeignKeyRename(t.Name, fk.Name)
+	fk.Name = shortenIdentifier(fk.Name, postgresMaxIdentifier)
 
 	var ifExists string
 	if v, ok := fk.Options["if_exists"]; ok && v.(bool) {
@@ -155,7 +209,7 @@ func (p *Postgres) DropForeignKey(t fizz.Table) (string, error) {
 	}
 
 	s := fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s %s;", t.Name, ifExists, fk.Name)
-	return s, nil
+	return legacy + s, nil
 }
 
 func (p *Postgres) buildAddColumn(c fizz.Column) string {
@@ -205,9 +259,23 @@ func (p *Postgres) colType(c fizz.Column) string {
 		}
 		return fmt.Sprintf("VARCHAR (%s)", s)
 	case "uuid":
+		if p.Redshift {
+			return "CHAR (36)"
+		}
 		return "UUID"
 	case "time", "datetime":
 		return "timestamp"
+	case "text":
+		if p.Redshift {
+			// Redshift TEXT columns are VARCHAR (256)
+			return "VARCHAR (65535)"
+		}
+		return c.ColType
+	case "json", "jsonb":
+		if p.Redshift {
+			return "SUPER"
+		}
+		return c.ColType
 	default:
 		return c.ColType
 	}
@@ -231,3 +299,17 @@ func (p *Postgres) buildForeignKey(t fizz.Table, fk fizz.ForeignKey, onCreate bo
 
 	return s
 }
+
+func (p *Postgres) CreateSequence(s fizz.Sequence) (string, error) {
+	if p.Redshift {
+		return "", errors.New("Redshift does not support sequences")
+	}
+	return fmt.Sprintf("CREATE SEQUENCE \"%s\" START WITH %d INCREMENT BY %d;", s.Name, s.Start(), s.Increment()), nil
+}
+
+func (p *Postgres) DropSequence(s fizz.Sequence) (string, error) {
+	if p.Redshift {
+		return "", errors.New("Redshift does not support sequences")
+	}
+	return fmt.Sprintf("DROP SEQUENCE \"%s\";", s.Name), nil
+}
@@ -61,6 +61,27 @@ func (p *PostgreSQLSuite) Test_Postgres_CreateTable_UUID() {
 	r.Equal(ddl, res)
 }
 
+func (p *PostgreSQLSuite) Test_Postgres_CreateTable_Tree() {
+	r := p.Require()
+	ddl := `CREATE TABLE "categories" (
+"id" UUID PRIMARY KEY,
+"name" VARCHAR (255) NOT NULL,
+"parent_id" UUID,
+"created_at" timestamp NOT NULL,
+"updated_at" timestamp NOT NULL
+);
+CREATE INDEX "categories_parent_id_idx" ON "categories" (parent_id);`
+
+	res, _ := fizz.AString(`
+	create_table("categories", func(t) {
+		t.Column("id", "uuid", {"primary": true})
+		t.Column("name", "string", {})
+		t.Tree()
+	})
+	`, pgt)
+	r.Equal(ddl, res)
+}
+
 func (p *PostgreSQLSuite) Test_Postgre_CreateTables_WithForeignKeys() {
 	r := p.Require()
 	ddl := `CREATE TABLE "users" (
@@ -221,3 +242,67 @@ func (p *PostgreSQLSuite) Test_Postgres_DropForeignKey() {
 	res, _ := fizz.AString(`drop_foreign_key("profiles", "profiles_users_id_fk", {})`, pgt)
 	r.Equal(ddl, res)
 }
+
+func (p *PostgreSQLSuite) Test_Redshift_CreateTable() {
+	r := p.Require()
+	ddl := `CREATE TABLE "events" (
+"id" BIGINT IDENTITY(1,1) PRIMARY KEY,
+"account_id" CHAR (36) NOT NULL,
+"payload" SUPER,
+"notes" VARCHAR (65535) NOT NULL,
+"created_at" timestamp NOT NULL,
+"updated_at" timestamp NOT NULL
+);`
+
+	res, err := fizz.AString(`
+	create_table("events", func(t) {
+		t.Column("account_id", "uuid", {})
+		t.Column("payload", "jsonb", {"null": true})
+		t.Column("notes", "text", {})
+		t.Index("account_id", {})
+	})
+	`, translators.NewRedshift())
+	r.NoError(err)
+	r.Equal(ddl, res)
+}
+
+func (p *PostgreSQLSuite) Test_Postgres_CreateSequence() {
+	r := p.Require()
+	res, _ := fizz.AString(`create_sequence("invoice_numbers", {"start": 1000})`, pgt)
+	r.Equal(`CREATE SEQUENCE "invoice_numbers" START WITH 1000 INCREMENT BY 1;`, res)
+}
+
+func (p *PostgreSQLSuite) Test_Postgres_DropSequence() {
+	r := p.Require()
+	res, _ := fizz.AString(`drop_sequence("invoice_numbers")`, pgt)
+	r.Equal(`DROP SEQUENCE "invoice_numbers";`, res)
+}
+
+func (p *PostgreSQLSuite) Test_Postgres_LongIdentifiers() {
+	r := p.Require()
+
+	ddl := `CREATE INDEX "organization_memberships_organization_id_user_id_role__6868dae5" ON "organization_memberships" (organization_id, user_id, role_id, team_id);`
+	res, _ := fizz.AString(`add_index("organization_memberships", ["organization_id", "user_id", "role_id", "team_id"], {})`, pgt)
+	r.Equal(ddl, res)
+
+	// the index may have been created with the name truncated by PostgreSQL.
+	ddl = `DO $$ BEGIN IF to_regclass('"organization_memberships_organization_id_user_id_role__6868dae5"') IS NULL AND to_regclass('"organization_memberships_organization_id_user_id_role_id_team_i"') IS NOT NULL THEN ALTER INDEX "organization_memberships_organization_id_user_id_role_id_team_i" RENAME TO "organization_memberships_organization_id_user_id_role__6868dae5"; END IF; END $$;
+DROP INDEX "organization_memberships_organization_id_user_id_role__6868dae5";`
+	res, _ = fizz.AString(`drop_index("organization_memberships", "organization_memberships_organization_id_user_id_role_id_team_id_idx")`, pgt)
+	r.Equal(ddl, res)
+
+	res, _ = fizz.AString(`rename_index("organization_memberships", "organization_memberships_organization_id_user_id_role_id_team_id_idx", "members_idx")`, pgt)
+	r.Contains(res, `ALTER INDEX "organization_memberships_organization_id_user_id_role_id_team_i" RENAME TO "organization_memberships_organization_id_user_id_role__6868dae5"; END IF; END $$;`)
+	r.Contains(res, `ALTER INDEX "organization_memberships_organization_id_user_id_role__6868dae5" RENAME TO "members_idx";`)
+
+	res, _ = fizz.AString(`drop_index("users", "email_idx")`, pgt)
+	r.Equal(`DROP INDEX "email_idx";`, res)
+
+	ddl = `ALTER TABLE organization_memberships ADD CONSTRAINT organization_memberships_organization_settings_organiz_754d51e5 FOREIGN KEY (organization_id) REFERENCES organization_settings (organization_id);`
+	res, _ = fizz.AString(`add_foreign_key("organization_memberships", "organization_id", {"organization_settings": ["organization_id"]}, {})`, pgt)
+	r.Equal(ddl, res)
+
+	res, _ = fizz.AString(`drop_foreign_key("organization_memberships", "organization_memberships_organization_settings_organization_id_fk", {})`, pgt)
+	r.Contains(res, `ALTER TABLE organization_memberships RENAME CONSTRAINT "organization_memberships_organization_settings_organization_id_" TO "organization_memberships_organization_settings_organiz_754d51e5"; END IF; END $$;`)
+	r.Contains(res, `ALTER TABLE organization_memberships DROP CONSTRAINT  organization_memberships_organization_settings_organiz_754d51e5;`)
+}
@@ -11,6 +11,9 @@ import (
 
 type SQLite struct {
 	Schema SchemaQuery
+	// ForeignKeys is set when the foreign keys are enforced on the
+	// connections running the migrations.
+	ForeignKeys bool
 }
 
 func NewSQLite(url string) *SQLite {
@@ -88,8 +91,11 @@ func (p *SQLite) RenameTable(t []fizz.Table) (string, error) {
 }
 
 func (p *SQLite) ChangeColumn(t fizz.Table) (string, error) {
-	tableInfo, err := p.Schema.TableInfo(t.Name)
+	if len(t.Columns) == 0 {
+		return "", errors.New("Not enough columns supplied!")
+	}
 
+	tableInfo, err := p.Schema.TableInfo(t.Name)
 	if err != nil {
 		return "", err
 	}
@@ -101,24 +107,7 @@ func (p *SQLite) ChangeColumn(t fizz.Table) (string, error) {
 		}
 	}
 
-	sql := []string{}
-	s, err := p.withTempTable(t.Name, func(tempTable fizz.Table) (string, error) {
-		createTableSQL, err := p.CreateTable(*tableInfo)
-		if err != nil {
-			return "", err
-		}
-
-		ins := fmt.Sprintf("INSERT INTO \"%s\" (%s) SELECT %s FROM \"%s\";", t.Name, strings.Join(tableInfo.ColumnNames(), ", "), strings.Join(tableInfo.ColumnNames(), ", "), tempTable.Name)
-		return strings.Join([]string{createTableSQL, ins}, "\n"), nil
-	})
-
-	if err != nil {
-		return "", err
-	}
-
-	sql = append(sql, s)
-
-	return strings.Join(sql, "\n"), nil
+	return p.rebuildTable(tableInfo, tableInfo.ColumnNames())
 }
 
 func (p *SQLite) AddColumn(t fizz.Table) (string, error) {
@@ -148,7 +137,6 @@ func (p *SQLite) DropColumn(t fizz.Table) (string, error) {
 		return "", err
 	}
 
-	sql := []string{}
 	droppedColumn := t.Columns[0]
 
 	newColumns := []fizz.Column{}
@@ -161,37 +149,21 @@ func (p *SQLite) DropColumn(t fizz.Table) (string, error) {
 
 	newIndexes := []fizz.Index{}
 	for _, i := range tableInfo.Indexes {
-		s, err := p.DropIndex(fizz.Table{
-			Name:    tableInfo.Name,
-			Indexes: []fizz.Index{i},
-		})
-		if err != nil {
-			return "", err
-		}
-		sql = append(sql, s)
 		if tableInfo.HasColumns(i.Columns...) {
 			newIndexes = append(newIndexes, i)
 		}
 	}
 	tableInfo.Indexes = newIndexes
 
-	s, err := p.withTempTable(t.Name, func(tempTable fizz.Table) (string, error) {
-		createTableSQL, err := p.CreateTable(*tableInfo)
-		if err != nil {
-			return "", err
+	newForeignKeys := []fizz.ForeignKey{}
+	for _, fk := range tableInfo.ForeignKeys {
+		if fk.Column != droppedColumn.Name {
+			newForeignKeys = append(newForeignKeys, fk)
 		}
-
-		s := fmt.Sprintf("INSERT INTO \"%s\" (%s) SELECT %s FROM \"%s\";", tableInfo.Name, strings.Join(tableInfo.ColumnNames(), ", "), strings.Join(tableInfo.ColumnNames(), ", "), tempTable.Name)
-
-		return strings.Join([]string{createTableSQL, s}, "\n"), nil
-	})
-
-	if err != nil {
-		return "", err
 	}
-	sql = append(sql, s)
+	tableInfo.ForeignKeys = newForeignKeys
 
-	return strings.Join(sql, "\n"), nil
+	return p.rebuildTable(tableInfo, tableInfo.ColumnNames())
 }
 
 func (p *SQLite) RenameColumn(t fizz.Table) (string, error) {
@@ -207,8 +179,6 @@ func (p *SQLite) RenameColumn(t fizz.Table) (string, error) {
 	oldColumn := t.Columns[0]
 	newColumn := t.Columns[1]
 
-	sql := []string{}
-
 	oldColumns := tableInfo.ColumnNames()
 	for ic, c := range tableInfo.Columns {
 		if c.Name == oldColumn.Name {
@@ -217,14 +187,6 @@ func (p *SQLite) RenameColumn(t fizz.Table) (string, error) {
 	}
 
 	for _, i := range tableInfo.Indexes {
-		s, err := p.DropIndex(fizz.Table{
-			Name:    tableInfo.Name,
-			Indexes: []fizz.Index{i},
-		})
-		if err != nil {
-			return "", err
-		}
-		sql = append(sql, s)
 		for ic, c := range i.Columns {
 			if c == oldColumn.Name {
 				i.Columns[ic] = newColumn.Name
@@ -232,23 +194,13 @@ func (p *SQLite) RenameColumn(t fizz.Table) (string, error) {
 		}
 	}
 
-	s, err := p.withTempTable(t.Name, func(tempTable fizz.Table) (string, error) {
-		createTableSQL, err := p.CreateTable(*tableInfo)
-		if err != nil {
-			return "", err
+	for ifk, fk := range tableInfo.ForeignKeys {
+		if fk.Column == oldColumn.Name {
+			tableInfo.ForeignKeys[ifk].Column = newColumn.Name
 		}
-
-		ins := fmt.Sprintf("INSERT INTO \"%s\" (%s) SELECT %s FROM \"%s\";", t.Name, strings.Join(tableInfo.ColumnNames(), ", "), strings.Join(oldColumns, ", "), tempTable.Name)
-		return strings.Join([]string{createTableSQL, ins}, "\n"), nil
-	})
-
-	if err != nil {
-		return "", err
 	}
 
-	sql = append(sql, s)
-
-	return strings.Join(sql, "\n"), nil
+	return p.rebuildTable(tableInfo, oldColumns)
 }
 
 func (p *SQLite) AddIndex(t fizz.Table) (string, error) {
@@ -256,10 +208,7 @@ func (p *SQLite) AddIndex(t fizz.Table) (string, error) {
 		return "", errors.New("Not enough indexes supplied!")
 	}
 	i := t.Indexes[0]
-	s := fmt.Sprintf("CREATE INDEX \"%s\" ON \"%s\" (%s);", i.Name, t.Name, strings.Join(i.Columns, ", "))
-	if i.Unique {
-		s = strings.Replace(s, "CREATE", "CREATE UNIQUE", 1)
-	}
+	s := p.buildIndex(t.Name, i)
 
 	tableInfo, err := p.Schema.TableInfo(t.Name)
 	if err != nil {
@@ -340,26 +289,89 @@ func (p *SQLite) RenameIndex(t fizz.Table) (string, error) {
 }
 
 func (p *SQLite) AddForeignKey(t fizz.Table) (string, error) {
-	return "", errors.New("SQLite does not support this feature")
+	if len(t.ForeignKeys) == 0 {
+		return "", errors.New("Not enough foreign keys supplied!")
+	}
+
+	tableInfo, err := p.Schema.TableInfo(t.Name)
+	if err != nil {
+		return "", err
+	}
+	tableInfo.ForeignKeys = append(tableInfo.ForeignKeys, t.ForeignKeys[0])
+
+	return p.rebuildTable(tableInfo, tableInfo.ColumnNames())
 }
 
 func (p *SQLite) DropForeignKey(t fizz.Table) (string, error) {
-	return "", errors.New("SQLite does not support this feature")
-}
+	if len(t.ForeignKeys) == 0 {
+		return "", errors.New("Not enough foreign keys supplied!")
+	}
+	fk := t.ForeignKeys[0]
+
+	tableInfo, err := p.Schema.TableInfo(t.Name)
+	if err != nil {
+		return "", err
+	}
+
+	newForeignKeys := []fizz.ForeignKey{}
+	for _, f := range tableInfo.ForeignKeys {
+		if f.Name != fk.Name {
+			newForeignKeys = append(newForeignKeys, f)
+		}
+	}
+	if len(newForeignKeys) == len(tableInfo.ForeignKeys) {
+		if v, ok := fk.Options["if_exists"]; ok && v.(bool) {
+			return "", nil
+		}
+		return "", errors.Errorf("could not find foreign key %s on table %s", fk.Name, t.Name)
+	}
+	tableInfo.ForeignKeys = newForeignKeys
 
-func (p *SQLite) withTempTable(table string, fn func(fizz.Table) (string, error)) (string, error) {
-	tempTable := fizz.Table{Name: fmt.Sprintf("_%s_tmp", table)}
+	return p.rebuildTable(tableInfo, tableInfo.ColumnNames())
+}
 
-	sql := []string{fmt.Sprintf("ALTER TABLE \"%s\" RENAME TO \"%s\";", table, tempTable.Name)}
-	s, err := fn(tempTable)
+// rebuildTable recreates a table from its schema, following the steps
+// SQLite recommends for the ALTER TABLE operations it does not support:
+// create a new table, copy the data from the old columns, drop the old
+// table and rename the new one. The indexes are dropped along with the
+// old table, so they are created again on the new one. The tables are not
+// rebuilt when the foreign keys are enforced, as dropping the old table
+// would then apply the ON DELETE actions of the tables referencing it.
+func (p *SQLite) rebuildTable(t *fizz.Table, oldColumns []string) (string, error) {
+	if p.ForeignKeys {
+		return "", errors.Errorf("can not rebuild table %s while the foreign keys are enforced, turn the foreign_keys option off to run this migration", t.Name)
+	}
+	tmp := *t
+	tmp.Name = fmt.Sprintf("_%s_tmp", t.Name)
+	tmp.Indexes = []fizz.Index{}
+
+	createTableSQL, err := p.CreateTable(tmp)
+	p.Schema.Delete(tmp.Name)
 	if err != nil {
 		return "", err
 	}
-	sql = append(sql, s, fmt.Sprintf("DROP TABLE \"%s\";", tempTable.Name))
+
+	sql := []string{
+		createTableSQL,
+		fmt.Sprintf("INSERT INTO \"%s\" (%s) SELECT %s FROM \"%s\";", tmp.Name, strings.Join(t.ColumnNames(), ", "), strings.Join(oldColumns, ", "), t.Name),
+		fmt.Sprintf("DROP TABLE \"%s\";", t.Name),
+		fmt.Sprintf("ALTER TABLE \"%s\" RENAME TO \"%s\";", tmp.Name, t.Name),
+	}
+	for _, i := range t.Indexes {
+		sql = append(sql, p.buildIndex(t.Name, i))
+	}
 
 	return strings.Join(sql, "\n"), nil
 }
 
+func (p *SQLite) buildIndex(table string, i fizz.Index) string {
+	s := fmt.Sprintf("CREATE INDEX \"%s\" ON \"%s\" (%s);", i.Name, table, strings.Join(i.Columns, ", "))
+	if i.Unique {
+		s = strings.Replace(s, "CREATE", "CREATE UNIQUE", 1)
+	}
+	return s
+}
+
 func (p *SQLite) buildColumn(c fizz.Column) string {
 	s := fmt.Sprintf("\"%s\" %s", c.Name, p.colType(c))
 	if c.Options["null"] == nil {
@@ -407,3 +419,20 @@ func (p *SQLite) buildForeignKey(t fizz.Table, fk fizz.ForeignKey, onCreate bool
 
 	return s
 }
+
+// CreateSequence emulates a sequence with an AUTOINCREMENT table, which
+// can not have an increment other than 1.
+func (p *SQLite) CreateSequence(s fizz.Sequence) (string, error) {
+	if s.Increment() != 1 {
+		return "", errors.Errorf("SQLite sequences can not have an increment, %s has %d", s.Name, s.Increment())
+	}
+	sql := fmt.Sprintf("CREATE TABLE \"%s\" (\n\"id\" INTEGER PRIMARY KEY AUTOINCREMENT\n);", s.Name)
+	if s.Start() != 1 {
+		sql = fmt.Sprintf("%s\nINSERT INTO sqlite_sequence (name, seq) VALUES ('%s', %d);", sql, s.Name, s.Start()-1)
+	}
+	return sql, nil
+}
+
+func (p *SQLite) DropSequence(s fizz.Sequence) (string, error) {
+	return fmt.Sprintf("DROP TABLE \"%s\";", s.Name), nil
+}
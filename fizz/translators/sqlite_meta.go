@@ -22,6 +22,38 @@ type sqliteIndexInfo struct {
 	Name string `db:"name"`
 }
 
+type sqliteForeignKeyListInfo struct {
+	ID       int    `db:"id"`
+	Seq      int    `db:"seq"`
+	Table    string `db:"table"`
+	From     string `db:"from"`
+	To       string `db:"to"`
+	OnUpdate string `db:"on_update"`
+	OnDelete string `db:"on_delete"`
+	Match    string `db:"match"`
+}
+
+// ToForeignKey converts the info to a foreign key. SQLite does not keep
+// the names of constraints, so the name fizz gives by default is used.
+func (fi sqliteForeignKeyListInfo) ToForeignKey(table string) fizz.ForeignKey {
+	fk := fizz.ForeignKey{
+		Name:   fmt.Sprintf("%s_%s_%s_fk", table, fi.Table, fi.To),
+		Column: fi.From,
+		References: fizz.ForeignKeyRef{
+			Table:   fi.Table,
+			Columns: []string{fi.To},
+		},
+		Options: fizz.Options{},
+	}
+	if fi.OnUpdate != "" && fi.OnUpdate != "NO ACTION" {
+		fk.Options["on_update"] = fi.OnUpdate
+	}
+	if fi.OnDelete != "" && fi.OnDelete != "NO ACTION" {
+		fk.Options["on_delete"] = fi.OnDelete
+	}
+	return fk
+}
+
 type sqliteTableInfo struct {
 	CID     int         `db:"cid"`
 	Name    string      `db:"name"`
@@ -103,6 +135,10 @@ func (p *sqliteSchema) buildTableData(table *fizz.Table) error {
 	if err != nil {
 		return err
 	}
+	err = p.buildTableForeignKeys(table)
+	if err != nil {
+		return err
+	}
 	p.schema[table.Name] = table
 	return nil
 }
@@ -120,6 +156,10 @@ func (p *sqliteSchema) buildTableIndexes(t *fizz.Table) error {
 		if err != nil {
 			return err
 		}
+		// indexes SQLite creates for its own use can not be created again
+		if strings.HasPrefix(li.Name, "sqlite_autoindex_") {
+			continue
+		}
 
 		i := fizz.Index{
 			Name:    li.Name,
@@ -147,3 +187,26 @@ func (p *sqliteSchema) buildTableIndexes(t *fizz.Table) error {
 	}
 	return nil
 }
+
+func (p *sqliteSchema) buildTableForeignKeys(t *fizz.Table) error {
+	prag := fmt.Sprintf("PRAGMA foreign_key_list(%s)", t.Name)
+	res, err := p.db.Queryx(prag)
+	if err != nil {
+		return err
+	}
+	defer res.Close()
+
+	for res.Next() {
+		fi := sqliteForeignKeyListInfo{}
+		err = res.StructScan(&fi)
+		if err != nil {
+			return err
+		}
+		// fizz foreign keys are on a single column
+		if fi.Seq > 0 {
+			continue
+		}
+		t.ForeignKeys = append(t.ForeignKeys, fi.ToForeignKey(t.Name))
+	}
+	return res.Err()
+}
@@ -136,14 +136,14 @@ func (p *SQLiteSuite) Test_SQLite_RenameTable_NotEnoughValues() {
 func (p *SQLiteSuite) Test_SQLite_ChangeColumn() {
 	r := p.Require()
 
-	ddl := `ALTER TABLE "users" RENAME TO "_users_tmp";
-CREATE TABLE "users" (
+	ddl := `CREATE TABLE "_users_tmp" (
 "id" INTEGER PRIMARY KEY AUTOINCREMENT,
 "created_at" TEXT NOT NULL DEFAULT 'foo',
 "updated_at" DATETIME NOT NULL
 );
-INSERT INTO "users" (id, created_at, updated_at) SELECT id, created_at, updated_at FROM "_users_tmp";
-DROP TABLE "_users_tmp";`
+INSERT INTO "_users_tmp" (id, created_at, updated_at) SELECT id, created_at, updated_at FROM "users";
+DROP TABLE "users";
+ALTER TABLE "_users_tmp" RENAME TO "users";`
 
 	schema.schema["users"] = &fizz.Table{
 		Name: "users",
@@ -172,13 +172,13 @@ func (p *SQLiteSuite) Test_SQLite_AddColumn() {
 
 func (p *SQLiteSuite) Test_SQLite_DropColumn() {
 	r := p.Require()
-	ddl := `ALTER TABLE "users" RENAME TO "_users_tmp";
-CREATE TABLE "users" (
+	ddl := `CREATE TABLE "_users_tmp" (
 "id" INTEGER PRIMARY KEY AUTOINCREMENT,
 "updated_at" DATETIME NOT NULL
 );
-INSERT INTO "users" (id, updated_at) SELECT id, updated_at FROM "_users_tmp";
-DROP TABLE "_users_tmp";`
+INSERT INTO "_users_tmp" (id, updated_at) SELECT id, updated_at FROM "users";
+DROP TABLE "users";
+ALTER TABLE "_users_tmp" RENAME TO "users";`
 
 	schema.schema["users"] = &fizz.Table{
 		Name: "users",
@@ -193,16 +193,33 @@ DROP TABLE "_users_tmp";`
 	r.Equal(ddl, res)
 }
 
+func (p *SQLiteSuite) Test_SQLite_DropColumn_ForeignKeys() {
+	r := p.Require()
+	schema.schema["users"] = &fizz.Table{
+		Name: "users",
+		Columns: []fizz.Column{
+			fizz.INT_ID_COL,
+			fizz.CREATED_COL,
+			fizz.UPDATED_COL,
+		},
+	}
+	fkt := &translators.SQLite{Schema: schema, ForeignKeys: true}
+	_, err := fizz.AString(`drop_column("users", "created_at")`, fkt)
+
+	r.Error(err)
+	r.Contains(err.Error(), "can not rebuild table users while the foreign keys are enforced")
+}
+
 func (p *SQLiteSuite) Test_SQLite_RenameColumn() {
 	r := p.Require()
-	ddl := `ALTER TABLE "users" RENAME TO "_users_tmp";
-CREATE TABLE "users" (
+	ddl := `CREATE TABLE "_users_tmp" (
 "id" INTEGER PRIMARY KEY AUTOINCREMENT,
 "created_when" DATETIME NOT NULL,
 "updated_at" DATETIME NOT NULL
 );
-INSERT INTO "users" (id, created_when, updated_at) SELECT id, created_at, updated_at FROM "_users_tmp";
-DROP TABLE "_users_tmp";`
+INSERT INTO "_users_tmp" (id, created_when, updated_at) SELECT id, created_at, updated_at FROM "users";
+DROP TABLE "users";
+ALTER TABLE "_users_tmp" RENAME TO "users";`
 
 	schema.schema["users"] = &fizz.Table{
 		Name: "users",
@@ -217,6 +234,85 @@ DROP TABLE "_users_tmp";`
 	r.Equal(ddl, res)
 }
 
+func (p *SQLiteSuite) Test_SQLite_DropColumn_KeepsIndexesAndForeignKeys() {
+	r := p.Require()
+	ddl := `CREATE TABLE "_profiles_tmp" (
+"id" INTEGER PRIMARY KEY AUTOINCREMENT,
+"user_id" INT NOT NULL,
+FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+INSERT INTO "_profiles_tmp" (id, user_id) SELECT id, user_id FROM "profiles";
+DROP TABLE "profiles";
+ALTER TABLE "_profiles_tmp" RENAME TO "profiles";
+CREATE INDEX "profiles_user_id_idx" ON "profiles" (user_id);`
+
+	schema.schema["profiles"] = &fizz.Table{
+		Name: "profiles",
+		Columns: []fizz.Column{
+			fizz.INT_ID_COL,
+			{Name: "user_id", ColType: "INT"},
+			{Name: "bio", ColType: "TEXT"},
+		},
+		Indexes: []fizz.Index{
+			{Name: "profiles_user_id_idx", Columns: []string{"user_id"}},
+			{Name: "profiles_bio_idx", Columns: []string{"bio"}},
+		},
+		ForeignKeys: []fizz.ForeignKey{
+			{
+				Name:       "profiles_users_id_fk",
+				Column:     "user_id",
+				References: fizz.ForeignKeyRef{Table: "users", Columns: []string{"id"}},
+				Options:    fizz.Options{"on_delete": "CASCADE"},
+			},
+		},
+	}
+	res, _ := fizz.AString(`drop_column("profiles", "bio")`, sqt)
+
+	r.Equal(ddl, res)
+}
+
+func (p *SQLiteSuite) Test_SQLite_AddForeignKey() {
+	r := p.Require()
+	ddl := `CREATE TABLE "_profiles_tmp" (
+"id" INTEGER PRIMARY KEY AUTOINCREMENT,
+"user_id" INT NOT NULL,
+FOREIGN KEY (user_id) REFERENCES users (id)
+);
+INSERT INTO "_profiles_tmp" (id, user_id) SELECT id, user_id FROM "profiles";
+DROP TABLE "profiles";
+ALTER TABLE "_profiles_tmp" RENAME TO "profiles";`
+
+	schema.schema["profiles"] = &fizz.Table{
+		Name: "profiles",
+		Columns: []fizz.Column{
+			fizz.INT_ID_COL,
+			{Name: "user_id", ColType: "INT"},
+		},
+	}
+	res, err := fizz.AString(`add_foreign_key("profiles", "user_id", {"users": ["id"]}, {})`, sqt)
+	r.NoError(err)
+	r.Equal(ddl, res)
+
+	ddl = `CREATE TABLE "_profiles_tmp" (
+"id" INTEGER PRIMARY KEY AUTOINCREMENT,
+"user_id" INT NOT NULL
+);
+INSERT INTO "_profiles_tmp" (id, user_id) SELECT id, user_id FROM "profiles";
+DROP TABLE "profiles";
+ALTER TABLE "_profiles_tmp" RENAME TO "profiles";`
+
+	res, err = fizz.AString(`drop_foreign_key("profiles", "profiles_users_id_fk", {})`, sqt)
+	r.NoError(err)
+	r.Equal(ddl, res)
+
+	_, err = fizz.AString(`drop_foreign_key("profiles", "profiles_users_id_fk", {})`, sqt)
+	r.Error(err)
+
+	res, err = fizz.AString(`drop_foreign_key("profiles", "profiles_users_id_fk", {"if_exists": true})`, sqt)
+	r.NoError(err)
+	r.Equal("", res)
+}
+
 func (p *SQLiteSuite) Test_SQLite_AddIndex() {
 	r := p.Require()
 
@@ -302,3 +398,20 @@ CREATE UNIQUE INDEX "new_ix" ON "users" (id, created_at);`
 	res, _ := fizz.AString(`rename_index("users", "old_ix", "new_ix")`, sqt)
 	r.Equal(ddl, res)
 }
+
+func (p *SQLiteSuite) Test_SQLite_CreateSequence() {
+	r := p.Require()
+	ddl := `CREATE TABLE "invoice_numbers" (
+"id" INTEGER PRIMARY KEY AUTOINCREMENT
+);
+INSERT INTO sqlite_sequence (name, seq) VALUES ('invoice_numbers', 999);`
+
+	res, _ := fizz.AString(`create_sequence("invoice_numbers", {"start": 1000})`, sqt)
+	r.Equal(ddl, res)
+}
+
+func (p *SQLiteSuite) Test_SQLite_DropSequence() {
+	r := p.Require()
+	res, _ := fizz.AString(`drop_sequence("invoice_numbers")`, sqt)
+	r.Equal(`DROP TABLE "invoice_numbers";`, res)
+}
@@ -0,0 +1,113 @@
+package pop
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// UpdateGoldenEnv is the environment variable which, when set, makes
+// `GoldenSQL` write the golden files rather than compare them.
+const UpdateGoldenEnv = "POP_UPDATE_GOLDEN"
+
+// sqlCapture records the statements sent by a connection.
+type sqlCapture struct {
+	mu         sync.Mutex
+	statements []string
+}
+
+func (sc *sqlCapture) add(query string) {
+	defer sc.mu.Unlock()
+	sc.mu.Lock()
+	sc.statements = append(sc.statements, query)
+}
+
+// CaptureSQL runs fn with a copy of the connection recording the
+// statements it sends, including the ones of its transactions, and
+// returns them in order:
+//
+//	stmts, err := c.CaptureSQL(func(c *pop.Connection) error {
+//		return c.Where("email = ?", email).First(&user)
+//	})
+func (c *Connection) CaptureSQL(fn func(c *Connection) error) ([]string, error) {
+	sc := &sqlCapture{}
+	cn := *c
+	cn.capture = sc
+	if is, ok := c.Store.(*instrumentedStore); ok {
+		cn.Store = newInstrumentedStore(&cn, is.store)
+	}
+	err := fn(&cn)
+	return sc.statements, err
+}
+
+// GoldenSQL runs fn as `CaptureSQL` does, and compares the statements it
+// sends with the golden file at path, one statement per line. It returns
+// an error describing the first difference, so that the changes of the
+// SQL pop generates show up in the tests of an application:
+//
+//	err := c.GoldenSQL("testdata/first_user.sql", func(c *pop.Connection) error {
+//		return c.Where("email = ?", email).First(&user)
+//	})
+//
+// The file is written instead, along with its directory, when the
+// POP_UPDATE_GOLDEN environment variable is set:
+//
+//	$ POP_UPDATE_GOLDEN=1 go test ./...
+func (c *Connection) GoldenSQL(path string, fn func(c *Connection) error) error {
+	stmts, err := c.CaptureSQL(fn)
+	if err != nil {
+		return err
+	}
+	got := goldenLines(stmts)
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return errors.WithStack(err)
+		}
+		return errors.WithStack(ioutil.WriteFile(path, []byte(strings.Join(got, "\n")+"\n"), 0644))
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.Wrapf(err, "could not read the golden file, set %s=1 to write it", UpdateGoldenEnv)
+	}
+	want := strings.Split(strings.TrimRight(string(b), "\n"), "\n")
+	if len(b) == 0 {
+		want = []string{}
+	}
+	return compareGolden(path, want, got)
+}
+
+// goldenLines returns the statements on a line each, with their runs of
+// white space collapsed.
+func goldenLines(stmts []string) []string {
+	lines := make([]string, len(stmts))
+	for i, s := range stmts {
+		lines[i] = strings.Join(strings.Fields(s), " ")
+	}
+	return lines
+}
+
+func compareGolden(path string, want, got []string) error {
+	for i := 0; i < len(want) || i < len(got); i++ {
+		w, g := "(none)", "(none)"
+		if i < len(want) {
+			w = want[i]
+		}
+		if i < len(got) {
+			g = got[i]
+		}
+		if w != g {
+			bb := &bytes.Buffer{}
+			fmt.Fprintf(bb, "statement %d differs from the golden file %s\n", i+1, path)
+			fmt.Fprintf(bb, "\tgolden: %s\n", w)
+			fmt.Fprintf(bb, "\tactual: %s", g)
+			return errors.New(bb.String())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package pop_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/markbates/pop"
+	"github.com/markbates/pop/nulls"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CaptureSQL(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		stmts, err := tx.CaptureSQL(func(c *pop.Connection) error {
+			if err := c.Create(&User{Name: nulls.NewString("Mark")}); err != nil {
+				return err
+			}
+			_, err := c.Where("name = ?", "Mark").Count(&User{})
+			return err
+		})
+		r.NoError(err)
+		r.Len(stmts, 2)
+		r.Contains(stmts[0], "INSERT INTO users")
+		r.Contains(stmts[1], "WHERE name = ?")
+
+		// the copy only records its own statements
+		stmts, err = tx.CaptureSQL(func(c *pop.Connection) error { return nil })
+		r.NoError(err)
+		r.Len(stmts, 0)
+	})
+}
+
+func Test_GoldenSQL(t *testing.T) {
+	r := require.New(t)
+	dir, err := ioutil.TempDir("", "pop")
+	r.NoError(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "golden", "users.sql")
+
+	transaction(func(tx *pop.Connection) {
+		find := func(c *pop.Connection) error {
+			return c.Where("name = ?", "Mark").All(&Users{})
+		}
+		err := tx.GoldenSQL(path, find)
+		r.Error(err)
+		r.Contains(err.Error(), pop.UpdateGoldenEnv)
+
+		os.Setenv(pop.UpdateGoldenEnv, "1")
+		err = tx.GoldenSQL(path, find)
+		os.Unsetenv(pop.UpdateGoldenEnv)
+		r.NoError(err)
+		b, err := ioutil.ReadFile(path)
+		r.NoError(err)
+		r.Contains(string(b), "WHERE name = ?")
+
+		r.NoError(tx.GoldenSQL(path, find))
+
+		err = tx.GoldenSQL(path, func(c *pop.Connection) error {
+			return c.Where("email = ?", "mark@example.com").All(&Users{})
+		})
+		r.Error(err)
+		r.Contains(err.Error(), "statement 1 differs")
+		r.Contains(err.Error(), "WHERE email = ?")
+	})
+}
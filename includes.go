@@ -0,0 +1,85 @@
+package pop
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/markbates/inflect"
+	"github.com/markbates/pop/associations"
+	"github.com/pkg/errors"
+)
+
+// ParseIncludes parses an include string, such as the one found in
+// `?include=author,comments.user`, into a list of fields that can be
+// given to `Eager` or `Load`. Each segment of a path must match an
+// association of the model, either by its field name or its underscored
+// field name. Anything else is rejected, so the include string can
+// safely come from API clients.
+//
+//	fields, err := pop.ParseIncludes(&User{}, req.URL.Query().Get("include"))
+//	err = tx.Eager(fields...).Find(&u, id)
+func ParseIncludes(model interface{}, include string) ([]string, error) {
+	t := reflect.TypeOf(model)
+	fields := []string{}
+	seen := map[string]bool{}
+	for _, path := range strings.Split(include, ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		field, err := includePath(t, path)
+		if err != nil {
+			return fields, err
+		}
+		if !seen[field] {
+			seen[field] = true
+			fields = append(fields, field)
+		}
+	}
+	return fields, nil
+}
+
+// includePath resolves a dotted include path against the type t,
+// returning the matching dotted path of Go field names.
+func includePath(t reflect.Type, path string) (string, error) {
+	names := []string{}
+	for _, segment := range strings.Split(path, ".") {
+		t = modelType(t)
+		if t.Kind() != reflect.Struct {
+			return "", errors.Errorf("can not include %s: %s is not a model", path, t)
+		}
+		f, ok := associationField(t, strings.TrimSpace(segment))
+		if !ok {
+			return "", errors.Errorf("can not include %s: %s is not an association of %s", path, segment, t.Name())
+		}
+		names = append(names, f.Name)
+		t = f.Type
+	}
+	return strings.Join(names, "."), nil
+}
+
+func associationField(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Name != name && inflect.Underscore(f.Name) != name {
+			continue
+		}
+		if associations.IsAssociationField(f) {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// modelType unwraps pointers, slices and arrays down to the type
+// of a single model.
+func modelType(t reflect.Type) reflect.Type {
+	for {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array:
+			t = t.Elem()
+		default:
+			return t
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package pop_test
+
+import (
+	"testing"
+
+	"github.com/markbates/pop"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParseIncludes(t *testing.T) {
+	r := require.New(t)
+
+	fields, err := pop.ParseIncludes(&User{}, "books, books.user,favorite_song,Books")
+	r.NoError(err)
+	r.Equal([]string{"Books", "Books.User", "FavoriteSong"}, fields)
+
+	fields, err = pop.ParseIncludes(&Users{}, "houses")
+	r.NoError(err)
+	r.Equal([]string{"Houses"}, fields)
+
+	fields, err = pop.ParseIncludes(&User{}, "")
+	r.NoError(err)
+	r.Len(fields, 0)
+}
+
+func Test_ParseIncludes_Rejects_Non_Associations(t *testing.T) {
+	r := require.New(t)
+
+	for _, include := range []string{"email", "books.title", "nope", "books.user.nope"} {
+		_, err := pop.ParseIncludes(&User{}, include)
+		r.Error(err, include)
+	}
+}
@@ -0,0 +1,190 @@
+package pop
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// IndexInfo describes an index, or a foreign key, found by `IndexReport`.
+type IndexInfo struct {
+	// Table the index belongs to
+	Table string
+	// Name of the index, or of the foreign key
+	Name string
+	// Columns of the index, in order
+	Columns []string
+	// DuplicateOf is the index covering the same columns, for duplicates
+	DuplicateOf string
+}
+
+func (i IndexInfo) String() string {
+	s := fmt.Sprintf("%s.%s (%s)", i.Table, i.Name, strings.Join(i.Columns, ", "))
+	if i.DuplicateOf != "" {
+		s = fmt.Sprintf("%s, same as %s", s, i.DuplicateOf)
+	}
+	return s
+}
+
+// IndexReport lists the indexes worth a look before a cleanup migration.
+type IndexReport struct {
+	// Unused are the indexes never scanned since the statistics of the
+	// database were reset, leaving out primary keys and unique indexes.
+	// It is only filled on PostgreSQL and MySQL.
+	Unused []IndexInfo
+	// Duplicates are the indexes covering the same columns, in the same
+	// order, as another index of their table
+	Duplicates []IndexInfo
+	// MissingForeignKeyIndexes are the foreign keys whose columns do not
+	// start any index of their table, which makes the deletes of the rows
+	// they reference scan the whole table
+	MissingForeignKeyIndexes []IndexInfo
+}
+
+// indexCatalog is implemented by the dialects able to list their indexes
+// and foreign keys.
+type indexCatalog interface {
+	indexes(c *Connection) ([]IndexInfo, error)
+	foreignKeys(c *Connection) ([]IndexInfo, error)
+}
+
+// unusedIndexLister is implemented by the dialects keeping index usage
+// statistics.
+type unusedIndexLister interface {
+	unusedIndexes(c *Connection) ([]IndexInfo, error)
+}
+
+// indexRow is a row of the index catalog queries, with comma separated
+// columns.
+type indexRow struct {
+	Table   string `db:"table_name"`
+	Name    string `db:"index_name"`
+	Columns string `db:"columns"`
+}
+
+func indexRows(c *Connection, query string, args ...interface{}) ([]IndexInfo, error) {
+	rows := []indexRow{}
+	if err := c.RawQuery(query, args...).All(&rows); err != nil {
+		return nil, err
+	}
+	infos := make([]IndexInfo, 0, len(rows))
+	for _, r := range rows {
+		i := IndexInfo{Table: r.Table, Name: r.Name}
+		if r.Columns != "" {
+			i.Columns = strings.Split(r.Columns, ",")
+		}
+		infos = append(infos, i)
+	}
+	return infos, nil
+}
+
+// IndexReport reads the catalogs of the database to find the unused and
+// duplicate indexes, and the foreign keys without an index:
+//
+//	report, err := c.IndexReport()
+//	for _, i := range report.Unused {
+//		fmt.Println(i)
+//	}
+func (c *Connection) IndexReport() (*IndexReport, error) {
+	ic, ok := c.Dialect.(indexCatalog)
+	if !ok {
+		return nil, errors.Errorf("%s does not support index reports", c.Dialect.Details().Dialect)
+	}
+	report := &IndexReport{Unused: []IndexInfo{}}
+	err := c.timeFunc("IndexReport", func() error {
+		indexes, err := ic.indexes(c)
+		if err != nil {
+			return errors.Wrap(err, "could not list the indexes")
+		}
+		fks, err := ic.foreignKeys(c)
+		if err != nil {
+			return errors.Wrap(err, "could not list the foreign keys")
+		}
+		report.Duplicates = duplicateIndexes(indexes)
+		report.MissingForeignKeyIndexes = unindexedForeignKeys(indexes, fks)
+
+		if ul, ok := c.Dialect.(unusedIndexLister); ok {
+			unused, err := ul.unusedIndexes(c)
+			if err != nil {
+				return errors.Wrap(err, "could not list the unused indexes")
+			}
+			byName := map[string]IndexInfo{}
+			for _, i := range indexes {
+				byName[i.Table+"."+i.Name] = i
+			}
+			for _, u := range unused {
+				if i, ok := byName[u.Table+"."+u.Name]; ok {
+					u.Columns = i.Columns
+				}
+				report.Unused = append(report.Unused, u)
+			}
+		}
+		return nil
+	})
+	return report, err
+}
+
+// duplicateIndexes returns the indexes with the same columns as another
+// index of their table, whose name comes first.
+func duplicateIndexes(indexes []IndexInfo) []IndexInfo {
+	sorted := append([]IndexInfo{}, indexes...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Table != sorted[j].Table {
+			return sorted[i].Table < sorted[j].Table
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	first := map[string]string{}
+	dups := []IndexInfo{}
+	for _, i := range sorted {
+		key := i.Table + ":" + strings.Join(i.Columns, ",")
+		if f, ok := first[key]; ok {
+			i.DuplicateOf = f
+			dups = append(dups, i)
+			continue
+		}
+		first[key] = i.Name
+	}
+	return dups
+}
+
+// unindexedForeignKeys returns the foreign keys whose columns are not the
+// leading columns of an index of their table.
+func unindexedForeignKeys(indexes []IndexInfo, fks []IndexInfo) []IndexInfo {
+	missing := []IndexInfo{}
+	for _, fk := range fks {
+		covered := false
+		for _, i := range indexes {
+			if i.Table == fk.Table && len(i.Columns) >= len(fk.Columns) && sameColumns(i.Columns[:len(fk.Columns)], fk.Columns) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			missing = append(missing, fk)
+		}
+	}
+	sort.Slice(missing, func(i, j int) bool {
+		return missing[i].Table+"."+missing[i].Name < missing[j].Table+"."+missing[j].Name
+	})
+	return missing
+}
+
+// sameColumns compares two lists of columns, in any order.
+func sameColumns(a, b []string) bool {
+	seen := map[string]int{}
+	for _, c := range a {
+		seen[c]++
+	}
+	for _, c := range b {
+		seen[c]--
+	}
+	for _, n := range seen {
+		if n != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,170 @@
+package pop
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// subtype describes a concrete type of a single table inheritance hierarchy.
+type subtype struct {
+	Base          reflect.Type
+	Type          reflect.Type
+	Discriminator string
+}
+
+var subtypes = map[reflect.Type]subtype{}
+var subtypesByBase = map[reflect.Type]map[string]reflect.Type{}
+var subtypesMu = sync.RWMutex{}
+
+// RegisterSubtype declares subtype as a concrete type of base, stored in the
+// same table, and identified by the discriminator value of its "type" column.
+// Both types must have a `Type string` field mapped to that column. The
+// subtype usually implements `TableNameAble` to share the table of base.
+//
+//	pop.RegisterSubtype(&Vehicle{}, "car", &Car{})
+//	pop.RegisterSubtype(&Vehicle{}, "truck", &Truck{})
+//
+// Creating a subtype sets its discriminator automatically, and queries on a
+// subtype only return the rows of that subtype.
+func RegisterSubtype(base interface{}, discriminator string, sub interface{}) {
+	bt := indirectType(reflect.TypeOf(base))
+	st := indirectType(reflect.TypeOf(sub))
+
+	defer subtypesMu.Unlock()
+	subtypesMu.Lock()
+	subtypes[st] = subtype{Base: bt, Type: st, Discriminator: discriminator}
+	if subtypesByBase[bt] == nil {
+		subtypesByBase[bt] = map[string]reflect.Type{}
+	}
+	subtypesByBase[bt][discriminator] = st
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+	}
+	return t
+}
+
+// subtype returns the subtype registration of the model, if any.
+func (m *Model) subtype() (subtype, bool) {
+	if _, ok := m.Value.(string); ok {
+		return subtype{}, false
+	}
+	defer subtypesMu.RUnlock()
+	subtypesMu.RLock()
+	st, ok := subtypes[indirectType(reflect.TypeOf(m.Value))]
+	return st, ok
+}
+
+func (m *Model) setDiscriminator() {
+	st, ok := m.subtype()
+	if !ok {
+		return
+	}
+	fbn, err := m.fieldByName("Type")
+	if err == nil && fbn.Kind() == reflect.String {
+		fbn.SetString(st.Discriminator)
+	}
+}
+
+// discriminatorClause restricts a query on a subtype to the rows of that subtype.
+func (sq *sqlBuilder) discriminatorClause() (clause, bool) {
+	st, ok := sq.Model.subtype()
+	if !ok {
+		return clause{}, false
+	}
+	alias := sq.Model.As
+	if alias == "" {
+		alias = strings.Replace(sq.Model.TableName(), ".", "_", -1)
+	}
+	return clause{
+		Fragment:  fmt.Sprintf("%s.type = ?", alias),
+		Arguments: []interface{}{st.Discriminator},
+	}, true
+}
+
+// AllSubtypes queries the base type of a single table inheritance hierarchy,
+// and returns each row as a pointer to its concrete subtype. Rows without a
+// registered subtype are returned as pointers to the base type.
+//
+//	vehicles := []Vehicle{}
+//	all, err := c.AllSubtypes(&vehicles)
+//	for _, v := range all {
+//		switch v := v.(type) {
+//		case *Car:
+//		case *Truck:
+//		}
+//	}
+func (c *Connection) AllSubtypes(models interface{}) ([]interface{}, error) {
+	return Q(c).AllSubtypes(models)
+}
+
+// AllSubtypes queries the base type of a single table inheritance hierarchy,
+// and returns each row as a pointer to its concrete subtype. Rows without a
+// registered subtype are returned as pointers to the base type.
+//
+//	vehicles := []Vehicle{}
+//	all, err := q.Where("wheels > ?", 2).AllSubtypes(&vehicles)
+func (q *Query) AllSubtypes(models interface{}) ([]interface{}, error) {
+	if err := q.All(models); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	bt := indirectType(reflect.TypeOf(models))
+	subtypesMu.RLock()
+	byDiscriminator := subtypesByBase[bt]
+	subtypesMu.RUnlock()
+
+	rows := reflect.Indirect(reflect.ValueOf(models))
+	values := make([]*Model, rows.Len())
+	ids := map[string][]interface{}{}
+	for i := 0; i < rows.Len(); i++ {
+		el := rows.Index(i)
+		if el.Kind() != reflect.Ptr {
+			el = el.Addr()
+		}
+		m := &Model{Value: el.Interface()}
+		values[i] = m
+		d, err := m.fieldByName("Type")
+		if err != nil {
+			return nil, errors.Errorf("%s does not have a Type field", bt.Name())
+		}
+		if _, ok := byDiscriminator[d.String()]; ok {
+			ids[d.String()] = append(ids[d.String()], m.ID())
+		}
+	}
+
+	concrete := map[string]interface{}{}
+	for discriminator, dids := range ids {
+		subs := reflect.New(reflect.SliceOf(byDiscriminator[discriminator]))
+		sm := &Model{Value: subs.Interface()}
+		err := q.Connection.Where(fmt.Sprintf("%s.id in (?)", sm.TableName()), dids...).All(subs.Interface())
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		for i := 0; i < subs.Elem().Len(); i++ {
+			m := &Model{Value: subs.Elem().Index(i).Addr().Interface()}
+			concrete[fmt.Sprint(m.ID())] = m.Value
+		}
+	}
+
+	all := make([]interface{}, len(values))
+	for i, m := range values {
+		all[i] = m.Value
+		if sub, ok := concrete[fmt.Sprint(m.ID())]; ok {
+			all[i] = sub
+		}
+	}
+	return all, nil
+}
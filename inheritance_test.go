@@ -0,0 +1,71 @@
+package pop_test
+
+import (
+	"testing"
+
+	"github.com/markbates/pop"
+	"github.com/markbates/pop/nulls"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Subtype_Create(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		c := &Car{Name: "Beetle", Doors: nulls.NewInt(3)}
+		r.NoError(tx.Create(c))
+		r.Equal("car", c.Type)
+
+		v := &Vehicle{}
+		r.NoError(tx.Find(v, c.ID))
+		r.Equal("car", v.Type)
+		r.Equal("Beetle", v.Name)
+	})
+}
+
+func Test_Subtype_Query(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		r.NoError(tx.Create(&Car{Name: "Beetle"}))
+		r.NoError(tx.Create(&Car{Name: "Mini"}))
+		truck := &Truck{Name: "Actros", Payload: nulls.NewInt(18000)}
+		r.NoError(tx.Create(truck))
+
+		cars := []Car{}
+		r.NoError(tx.Order("name").All(&cars))
+		r.Len(cars, 2)
+		r.Equal("Beetle", cars[0].Name)
+		r.Equal("Mini", cars[1].Name)
+
+		ctx, err := tx.Count(&Truck{})
+		r.NoError(err)
+		r.Equal(1, ctx)
+
+		ctx, err = tx.Count(&Vehicle{})
+		r.NoError(err)
+		r.Equal(3, ctx)
+
+		c := &Car{}
+		r.Error(tx.Find(c, truck.ID))
+	})
+}
+
+func Test_AllSubtypes(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		r.NoError(tx.Create(&Car{Name: "Beetle", Doors: nulls.NewInt(3)}))
+		r.NoError(tx.Create(&Truck{Name: "Actros", Payload: nulls.NewInt(18000)}))
+		r.NoError(tx.Create(&Vehicle{Name: "Bicycle", Type: "bicycle"}))
+
+		vehicles := []Vehicle{}
+		all, err := tx.Order("name").AllSubtypes(&vehicles)
+		r.NoError(err)
+		r.Len(all, 3)
+
+		r.IsType(&Truck{}, all[0])
+		r.Equal(18000, all[0].(*Truck).Payload.Int)
+		r.IsType(&Car{}, all[1])
+		r.Equal(3, all[1].(*Car).Doors.Int)
+		r.IsType(&Vehicle{}, all[2])
+		r.Equal("Bicycle", all[2].(*Vehicle).Name)
+	})
+}
@@ -0,0 +1,314 @@
+package pop
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// QueryEvent describes a statement that was sent to the database.
+type QueryEvent struct {
+	// Connection the statement was sent through
+	Connection *Connection
+	// SQL is the statement, as sent to the database
+	SQL string
+	// Args are the arguments bound to the statement
+	Args []interface{}
+	// Duration of the round trip to the database
+	Duration time.Duration
+	// Err is the error returned by the database, if any
+	Err error
+}
+
+// Instrumenter is called after every statement sent to the database.
+type Instrumenter func(QueryEvent)
+
+var instrumenters = []Instrumenter{}
+var instrumentersMu = sync.RWMutex{}
+
+// AddInstrumenter registers a function to be called with a `QueryEvent`
+// after every statement sent to the database, by any connection.
+// Prepared named statements are reported when they are prepared.
+func AddInstrumenter(fn Instrumenter) {
+	defer instrumentersMu.Unlock()
+	instrumentersMu.Lock()
+	instrumenters = append(instrumenters, fn)
+}
+
+func instrument(e QueryEvent) {
+	instrumentersMu.RLock()
+	fns := instrumenters
+	instrumentersMu.RUnlock()
+	for _, fn := range fns {
+		fn(e)
+	}
+}
+
+// instrumentedStore wraps a store to report every statement
+// going through it.
+type instrumentedStore struct {
+	store
+	conn *Connection
+}
+
+func newInstrumentedStore(c *Connection, s store) store {
+	return &instrumentedStore{store: s, conn: c}
+}
+
+// converters returns the value converters of the dialect.
+func (s *instrumentedStore) converters() []ValueConverter {
+	if s.conn == nil || s.conn.Dialect == nil {
+		return nil
+	}
+	return valueConvertersFor(s.conn.Dialect)
+}
+
+// convert runs the value converters of the dialect on args.
+func (s *instrumentedStore) convert(args []interface{}) []interface{} {
+	return convertValues(s.converters(), args)
+}
+
+// report logs and instruments the statement query, along with the rows
+// it affected when res is set, and ends its span. logArgs are the
+// arguments logged, args the ones given to the instrumenters.
+func (s *instrumentedStore) report(query string, args, logArgs []interface{}, start time.Time, span Span, res sql.Result, err error) {
+	d := time.Since(start)
+	if s.conn != nil && s.conn.capture != nil {
+		s.conn.capture.add(query)
+	}
+	rows := int64(-1)
+	if res != nil && err == nil {
+		if n, rerr := res.RowsAffected(); rerr == nil {
+			rows = n
+		}
+	}
+	if span != nil {
+		if rows >= 0 {
+			span.SetAttribute("db.rows_affected", rows)
+		}
+		span.End(err)
+	}
+	logEntry(s.conn, LogEntry{
+		Level:        LogDebug,
+		SQL:          query,
+		Args:         logArgs,
+		Duration:     d,
+		RowsAffected: rows,
+		Err:          err,
+	})
+	e := QueryEvent{
+		Connection: s.conn,
+		SQL:        query,
+		Args:       args,
+		Duration:   d,
+		Err:        err,
+	}
+	reportSlow(e, logArgs)
+	instrument(e)
+}
+
+func (s *instrumentedStore) Select(dest interface{}, query string, args ...interface{}) error {
+	if err := s.enter(query); err != nil {
+		return err
+	}
+	defer s.leave()
+	span := startStatementSpan(s.conn, query)
+	query = s.tag(query)
+	args = s.convert(args)
+	now := time.Now()
+	st, err := s.execute(query, args, func(st *Statement) error {
+		if ctx, cs, ok := s.contextStore(); ok {
+			return cs.SelectContext(ctx, dest, st.SQL, st.Args...)
+		}
+		return s.store.Select(dest, st.SQL, st.Args...)
+	})
+	s.report(st.SQL, st.Args, st.Args, now, span, nil, err)
+	return timeoutError(err)
+}
+
+func (s *instrumentedStore) Get(dest interface{}, query string, args ...interface{}) error {
+	if err := s.enter(query); err != nil {
+		return err
+	}
+	defer s.leave()
+	span := startStatementSpan(s.conn, query)
+	query = s.tag(query)
+	args = s.convert(args)
+	now := time.Now()
+	st, err := s.execute(query, args, func(st *Statement) error {
+		if ctx, cs, ok := s.contextStore(); ok {
+			return cs.GetContext(ctx, dest, st.SQL, st.Args...)
+		}
+		return s.store.Get(dest, st.SQL, st.Args...)
+	})
+	s.report(st.SQL, st.Args, st.Args, now, span, nil, err)
+	return timeoutError(err)
+}
+
+func (s *instrumentedStore) Queryx(query string, args ...interface{}) (*sqlx.Rows, error) {
+	if err := s.enter(query); err != nil {
+		return nil, err
+	}
+	defer s.leave()
+	span := startStatementSpan(s.conn, query)
+	query = s.tag(query)
+	args = s.convert(args)
+	now := time.Now()
+	var rows *sqlx.Rows
+	st, err := s.execute(query, args, func(st *Statement) error {
+		var err error
+		if ctx, cs, ok := s.contextStore(); ok {
+			rows, err = cs.QueryxContext(ctx, st.SQL, st.Args...)
+		} else {
+			rows, err = s.store.Queryx(st.SQL, st.Args...)
+		}
+		return err
+	})
+	s.report(st.SQL, st.Args, st.Args, now, span, nil, err)
+	return rows, timeoutError(err)
+}
+
+func (s *instrumentedStore) NamedExec(query string, arg interface{}) (sql.Result, error) {
+	if len(s.converters()) > 0 {
+		// the fields of arg are bound here, so they can be converted.
+		q, args, err := s.store.BindNamed(query, arg)
+		if err != nil {
+			return nil, err
+		}
+		return s.Exec(q, args...)
+	}
+	if err := s.enter(query); err != nil {
+		return nil, err
+	}
+	defer s.leave()
+	span := startStatementSpan(s.conn, query)
+	query = s.tag(query)
+	now := time.Now()
+	st, err := s.execute(query, []interface{}{arg}, func(st *Statement) error {
+		var err error
+		if ctx, cs, ok := s.contextStore(); ok {
+			st.Result, err = cs.NamedExecContext(ctx, st.SQL, st.Args[0])
+		} else {
+			st.Result, err = s.store.NamedExec(st.SQL, st.Args[0])
+		}
+		return err
+	})
+	// the fields of arg are not logged, as they can not be redacted.
+	s.report(st.SQL, st.Args, nil, now, span, st.Result, err)
+	return st.Result, timeoutError(err)
+}
+
+func (s *instrumentedStore) Exec(query string, args ...interface{}) (sql.Result, error) {
+	if err := s.enter(query); err != nil {
+		return nil, err
+	}
+	defer s.leave()
+	span := startStatementSpan(s.conn, query)
+	query = s.tag(query)
+	args = s.convert(args)
+	now := time.Now()
+	st, err := s.execute(query, args, func(st *Statement) error {
+		var err error
+		if ctx, cs, ok := s.contextStore(); ok {
+			st.Result, err = cs.ExecContext(ctx, st.SQL, st.Args...)
+		} else {
+			st.Result, err = s.store.Exec(st.SQL, st.Args...)
+		}
+		return err
+	})
+	s.report(st.SQL, st.Args, st.Args, now, span, st.Result, err)
+	return st.Result, timeoutError(err)
+}
+
+func (s *instrumentedStore) PrepareNamed(query string) (*sqlx.NamedStmt, error) {
+	if err := s.enter(query); err != nil {
+		return nil, err
+	}
+	defer s.leave()
+	span := startStatementSpan(s.conn, query)
+	query = s.tag(query)
+	now := time.Now()
+	stmt, err := s.store.PrepareNamed(query)
+	s.report(query, nil, nil, now, span, nil, err)
+	return stmt, timeoutError(err)
+}
+
+func (s *instrumentedStore) Transaction() (*Tx, error) {
+	if s.conn == nil || s.conn.TX != nil {
+		return s.store.Transaction()
+	}
+	if s.conn.chaos != nil {
+		if err := s.conn.chaos.fault(s.conn, "BEGIN"); err != nil {
+			return nil, err
+		}
+	}
+	done, err := s.conn.gate.begin()
+	if err != nil {
+		return nil, err
+	}
+	spanCtx, span := startSpan(s.conn, "pop.transaction")
+	var tx *Tx
+	if ctx, cs, ok := s.contextStore(); ok {
+		tx, err = cs.TransactionContext(ctx)
+	} else {
+		tx, err = s.store.Transaction()
+	}
+	if err != nil {
+		done()
+		if span != nil {
+			span.End(err)
+		}
+		return tx, err
+	}
+	tx.done = done
+	tx.span = span
+	tx.spanCtx = spanCtx
+	return tx, nil
+}
+
+// contextStore returns the context of the connection, and the store to
+// bind the statements to it, if the connection has a context.
+func (s *instrumentedStore) contextStore() (context.Context, contextStore, bool) {
+	if s.conn == nil || s.conn.ctx == nil {
+		return nil, nil, false
+	}
+	cs, ok := s.store.(contextStore)
+	return s.conn.ctx, cs, ok
+}
+
+// tag prefixes query with the application name of the connection, for
+// the dialects which can not set it on the session.
+func (s *instrumentedStore) tag(query string) string {
+	if s.conn == nil {
+		return query
+	}
+	if t, ok := s.conn.Dialect.(queryTagger); ok {
+		if name := s.conn.applicationName(); name != "" {
+			return t.tagQuery(name, query)
+		}
+	}
+	return query
+}
+
+// enter lets the statement query through the gate of the connection,
+// unless it is shutting down, or a failure is injected into it.
+func (s *instrumentedStore) enter(query string) error {
+	if s.conn == nil {
+		return nil
+	}
+	if s.conn.chaos != nil {
+		if err := s.conn.chaos.fault(s.conn, query); err != nil {
+			return err
+		}
+	}
+	return s.conn.gate.enter(s.conn.TX != nil)
+}
+
+func (s *instrumentedStore) leave() {
+	if s.conn != nil {
+		s.conn.gate.leave()
+	}
+}
@@ -0,0 +1,219 @@
+package pop
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/markbates/pop/columns"
+	"github.com/pkg/errors"
+)
+
+// JSONOptions controls how `Marshal` and `MarshalPage` encode models, for
+// the APIs returning them as they are loaded:
+//
+//	opts := pop.JSONOptions{OmitZeroAssociations: true, PaginationKey: "meta"}
+//	b, err := opts.MarshalPage(users, q.Paginator)
+type JSONOptions struct {
+	// OmitZeroAssociations leaves out the association fields holding
+	// their zero value, such as a has_one association which was not eager
+	// loaded, instead of encoding them as empty objects.
+	OmitZeroAssociations bool
+	// DataKey is the key of the models in a page, "data" by default.
+	DataKey string
+	// PaginationKey is the key the pagination metadata is nested under in
+	// a page. If it is empty, the metadata sits next to the models.
+	PaginationKey string
+}
+
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// Marshal encodes v, a model or a slice of models, to JSON.
+func (o JSONOptions) Marshal(v interface{}) ([]byte, error) {
+	if !o.OmitZeroAssociations {
+		return json.Marshal(v)
+	}
+	return marshalWithoutZeroAssociations(reflect.ValueOf(v))
+}
+
+// MarshalPage encodes the models of a page along with its pagination
+// metadata, nested or not depending on `PaginationKey`:
+//
+//	{"data": [...], "pagination": {"page": 1, "per_page": 20, ...}}
+//	{"data": [...], "page": 1, "per_page": 20, ...}
+func (o JSONOptions) MarshalPage(models interface{}, p *Paginator) ([]byte, error) {
+	data, err := o.Marshal(models)
+	if err != nil {
+		return nil, err
+	}
+	key := o.DataKey
+	if key == "" {
+		key = "data"
+	}
+	if p == nil {
+		p = &Paginator{}
+	}
+	meta, err := json.Marshal(p)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	page := &jsonObject{}
+	page.add(key, data)
+	if o.PaginationKey != "" {
+		page.add(o.PaginationKey, meta)
+		return page.bytes(), nil
+	}
+	err = eachJSONField(meta, func(k string, v json.RawMessage) {
+		page.add(k, v)
+	})
+	return page.bytes(), err
+}
+
+// marshalWithoutZeroAssociations encodes v as encoding/json does, leaving
+// out the association fields of the structs holding their zero value.
+// The types with their own MarshalJSON are encoded by it.
+func marshalWithoutZeroAssociations(v reflect.Value) ([]byte, error) {
+	if !v.IsValid() {
+		return []byte("null"), nil
+	}
+	t := v.Type()
+	if t.Implements(jsonMarshalerType) {
+		return json.Marshal(v.Interface())
+	}
+	if v.CanAddr() && reflect.PtrTo(t).Implements(jsonMarshalerType) {
+		return json.Marshal(v.Addr().Interface())
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return []byte("null"), nil
+		}
+		return marshalWithoutZeroAssociations(v.Elem())
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return []byte("null"), nil
+		}
+		if t.Elem().Kind() == reflect.Uint8 {
+			return json.Marshal(v.Interface())
+		}
+		bb := &bytes.Buffer{}
+		bb.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			b, err := marshalWithoutZeroAssociations(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			if i > 0 {
+				bb.WriteByte(',')
+			}
+			bb.Write(b)
+		}
+		bb.WriteByte(']')
+		return bb.Bytes(), nil
+	case reflect.Struct:
+		return marshalStructWithoutZeroAssociations(v)
+	}
+	return json.Marshal(v.Interface())
+}
+
+func marshalStructWithoutZeroAssociations(v reflect.Value) ([]byte, error) {
+	raw, err := json.Marshal(v.Interface())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	t := v.Type()
+	fields := map[string]json.RawMessage{}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" || sf.Anonymous || !associationTagged(columns.TagsFor(sf)) {
+			continue
+		}
+		key := jsonKey(sf)
+		if key == "" {
+			continue
+		}
+		f := v.Field(i)
+		if f.IsZero() {
+			fields[key] = nil
+			continue
+		}
+		b, err := marshalWithoutZeroAssociations(f)
+		if err != nil {
+			return nil, err
+		}
+		fields[key] = b
+	}
+	if len(fields) == 0 {
+		return raw, nil
+	}
+	obj := &jsonObject{}
+	err = eachJSONField(raw, func(k string, val json.RawMessage) {
+		if b, ok := fields[k]; ok {
+			if b == nil {
+				return
+			}
+			val = b
+		}
+		obj.add(k, val)
+	})
+	return obj.bytes(), err
+}
+
+// jsonKey returns the key of the field in the JSON of its struct, or ""
+// if it is left out.
+func jsonKey(sf reflect.StructField) string {
+	tag := sf.Tag.Get("json")
+	if tag == "-" {
+		return ""
+	}
+	if name := strings.Split(tag, ",")[0]; name != "" {
+		return name
+	}
+	return sf.Name
+}
+
+// jsonObject builds a JSON object, keeping the order of its keys.
+type jsonObject struct {
+	bytes.Buffer
+}
+
+func (o *jsonObject) add(key string, val json.RawMessage) {
+	if o.Len() == 0 {
+		o.WriteByte('{')
+	} else {
+		o.WriteByte(',')
+	}
+	k, _ := json.Marshal(key)
+	o.Write(k)
+	o.WriteByte(':')
+	o.Write(val)
+}
+
+func (o *jsonObject) bytes() []byte {
+	if o.Len() == 0 {
+		return []byte("{}")
+	}
+	return append(o.Bytes(), '}')
+}
+
+// eachJSONField calls fn with the keys and values of the JSON object raw,
+// in order.
+func eachJSONField(raw []byte, fn func(key string, val json.RawMessage)) error {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if _, err := dec.Token(); err != nil {
+		return errors.WithStack(err)
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		var val json.RawMessage
+		if err := dec.Decode(&val); err != nil {
+			return errors.WithStack(err)
+		}
+		fn(tok.(string), val)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package pop_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/markbates/pop"
+	"github.com/markbates/pop/nulls"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_JSONOptions_Marshal(t *testing.T) {
+	r := require.New(t)
+
+	u := User{ID: 1, Name: nulls.NewString("Mark")}
+	b, err := pop.JSONOptions{}.Marshal(u)
+	r.NoError(err)
+	r.Contains(string(b), `"FavoriteSong":{`)
+
+	opts := pop.JSONOptions{OmitZeroAssociations: true}
+	b, err = opts.Marshal(&u)
+	r.NoError(err)
+	r.NotContains(string(b), "FavoriteSong")
+	r.NotContains(string(b), "Books")
+	r.Contains(string(b), `"Name":"Mark"`)
+
+	// the loaded associations are kept, and trimmed in turn.
+	u.Books = Books{{ID: 2, Title: "Pop Book"}}
+	b, err = opts.Marshal([]User{u})
+	r.NoError(err)
+	out := []map[string]interface{}{}
+	r.NoError(json.Unmarshal(b, &out))
+	r.Len(out, 1)
+	books := out[0]["Books"].([]interface{})
+	r.Len(books, 1)
+	book := books[0].(map[string]interface{})
+	r.Equal("Pop Book", book["Title"])
+	r.NotContains(book, "User")
+}
+
+func Test_JSONOptions_MarshalPage(t *testing.T) {
+	r := require.New(t)
+
+	users := []User{{ID: 1}}
+	p := pop.NewPaginator(2, 10)
+
+	b, err := pop.JSONOptions{PaginationKey: "pagination"}.MarshalPage(users, p)
+	r.NoError(err)
+	nested := map[string]interface{}{}
+	r.NoError(json.Unmarshal(b, &nested))
+	r.Len(nested["data"], 1)
+	r.Equal(float64(2), nested["pagination"].(map[string]interface{})["page"])
+
+	b, err = pop.JSONOptions{DataKey: "users"}.MarshalPage(users, p)
+	r.NoError(err)
+	flat := map[string]interface{}{}
+	r.NoError(json.Unmarshal(b, &flat))
+	r.Len(flat["users"], 1)
+	r.Equal(float64(2), flat["page"])
+	r.Equal(float64(10), flat["per_page"])
+}
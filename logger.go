@@ -0,0 +1,165 @@
+package pop
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// LogLevel is the level of a `LogEntry`.
+type LogLevel int
+
+const (
+	// LogDebug is the level of the statements sent to the database
+	LogDebug LogLevel = iota
+	// LogInfo is the level of the operations, such as creating a database
+	LogInfo
+	// LogWarn is the level of the warnings, such as a possible N+1 query
+	LogWarn
+	// LogError is the level of the failures pop recovers from
+	LogError
+)
+
+func (l LogLevel) String() string {
+	switch l {
+	case LogDebug:
+		return "debug"
+	case LogInfo:
+		return "info"
+	case LogWarn:
+		return "warn"
+	case LogError:
+		return "error"
+	}
+	return fmt.Sprintf("LogLevel(%d)", int(l))
+}
+
+// LogEntry is a statement or a message logged by pop. The statements are
+// logged once the database answered them.
+type LogEntry struct {
+	Level LogLevel
+	// SQL is the statement, empty for the other messages
+	SQL string
+	// Args are the arguments bound to the statement, after `LogRedactor`
+	Args []interface{}
+	// Duration of the round trip to the database
+	Duration time.Duration
+	// RowsAffected is the number of rows changed by the statement, or -1
+	// if it is not known, as for the queries
+	RowsAffected int64
+	// Err is the error returned by the database, if any
+	Err error
+	// Message is the text of the entries which are not statements
+	Message string
+}
+
+// Logger logs the statements run by pop, and its other messages, such as
+// the warnings of `DebugExplain`.
+type Logger interface {
+	Log(LogEntry)
+}
+
+// LoggerFunc is a function used as a `Logger`:
+//
+//	c = c.WithLogger(pop.LoggerFunc(func(e pop.LogEntry) {
+//		zl.Debug().Str("sql", e.SQL).Dur("duration", e.Duration).Int64("rows", e.RowsAffected).Msg(e.Message)
+//	}))
+type LoggerFunc func(LogEntry)
+
+// Log calls f(e).
+func (f LoggerFunc) Log(e LogEntry) {
+	f(e)
+}
+
+// debugLogger hands the entries over to `Log`, which prints them when
+// `Debug` is set. The warnings and errors are printed without it too.
+type debugLogger struct{}
+
+func (debugLogger) Log(e LogEntry) {
+	s := e.Message
+	if e.SQL != "" {
+		if s != "" {
+			s += ": "
+		}
+		s += e.SQL
+	}
+	if e.Level >= LogWarn && !Debug {
+		// the arguments are left out, as Log would redact them.
+		logger.Println(s)
+		return
+	}
+	Log(s, e.Args...)
+}
+
+var defaultLogger Logger = debugLogger{}
+var defaultLoggerMu = sync.RWMutex{}
+
+// SetLogger sets the logger of the connections which have none of their
+// own, see `Connection.WithLogger`. The default one prints the entries
+// with `Log` when `Debug` is set; SetLogger(nil) restores it.
+func SetLogger(l Logger) {
+	if l == nil {
+		l = debugLogger{}
+	}
+	defer defaultLoggerMu.Unlock()
+	defaultLoggerMu.Lock()
+	defaultLogger = l
+}
+
+// WithLogger returns a copy of the connection logging with l, as do the
+// transactions started from it, rather than with the logger set by
+// `SetLogger`.
+func (c *Connection) WithLogger(l Logger) *Connection {
+	cn := *c
+	cn.logger = l
+	if is, ok := c.Store.(*instrumentedStore); ok {
+		cn.Store = newInstrumentedStore(&cn, is.store)
+	}
+	return &cn
+}
+
+// quietly returns a copy of the connection which does not log its
+// statements, see `Query.Quiet`.
+func (c *Connection) quietly() *Connection {
+	if c.quiet {
+		return c
+	}
+	cn := *c
+	cn.quiet = true
+	if is, ok := c.Store.(*instrumentedStore); ok {
+		cn.Store = newInstrumentedStore(&cn, is.store)
+	}
+	return &cn
+}
+
+// logEntry logs e with the logger of the connection c, which may be nil.
+func logEntry(c *Connection, e LogEntry) {
+	var l Logger
+	if c != nil {
+		if c.quiet && e.SQL != "" && e.Level == LogDebug {
+			return
+		}
+		l = c.logger
+	}
+	if l == nil {
+		defaultLoggerMu.RLock()
+		l = defaultLogger
+		defaultLoggerMu.RUnlock()
+	}
+	if _, ok := l.(debugLogger); !ok && LogRedactor != nil && len(e.Args) > 0 {
+		// Log redacts the arguments itself.
+		e.Args = LogRedactor(e.SQL, e.Args)
+	}
+	l.Log(e)
+}
+
+// logMessage logs a message other than a statement.
+func logMessage(c *Connection, level LogLevel, msg string) {
+	logEntry(c, LogEntry{Level: level, Message: msg, RowsAffected: -1})
+}
+
+// logSQL logs a statement sent to the database without going through a
+// connection, such as the ones creating the databases.
+func logSQL(c *Connection, query string, args ...interface{}) {
+	logEntry(c, LogEntry{Level: LogDebug, SQL: query, Args: args, RowsAffected: -1})
+}
@@ -0,0 +1,101 @@
+package pop_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/markbates/pop"
+	"github.com/stretchr/testify/require"
+)
+
+type entries struct {
+	mu   sync.Mutex
+	logs []pop.LogEntry
+}
+
+func (e *entries) Log(l pop.LogEntry) {
+	defer e.mu.Unlock()
+	e.mu.Lock()
+	e.logs = append(e.logs, l)
+}
+
+func Test_WithLogger(t *testing.T) {
+	transaction(func(tx *pop.Connection) {
+		r := require.New(t)
+
+		l := &entries{}
+		c := tx.WithLogger(l)
+
+		r.NoError(c.Create(&Book{Title: "Emma"}))
+		r.NotEmpty(l.logs)
+		e := l.logs[len(l.logs)-1]
+		r.Equal(pop.LogDebug, e.Level)
+		r.Contains(e.SQL, "INSERT INTO books")
+		r.Equal(int64(1), e.RowsAffected)
+		r.NoError(e.Err)
+		r.True(e.Duration > 0)
+
+		l.logs = nil
+		r.NoError(c.Where("title = ?", "Emma").All(&Books{}))
+		r.Len(l.logs, 1)
+		r.Contains(l.logs[0].SQL, "FROM books")
+		r.Equal([]interface{}{"Emma"}, l.logs[0].Args)
+		r.Equal(int64(-1), l.logs[0].RowsAffected)
+
+		l.logs = nil
+		r.Error(c.RawQuery("select * from missing_table").All(&Books{}))
+		r.Len(l.logs, 1)
+		r.Error(l.logs[0].Err)
+
+		l.logs = nil
+		r.NoError(c.Quiet().Where("title = ?", "Emma").All(&Books{}))
+		r.Len(l.logs, 0)
+
+		// the other connections are not affected
+		r.NoError(tx.Where("title = ?", "Emma").All(&Books{}))
+		r.Len(l.logs, 0)
+	})
+}
+
+func Test_WithLogger_Transaction(t *testing.T) {
+	r := require.New(t)
+
+	l := &entries{}
+	err := PDB.WithLogger(l).Rollback(func(tx *pop.Connection) {
+		r.NoError(tx.Create(&Book{Title: "Emma"}))
+	})
+	r.NoError(err)
+	r.NotEmpty(l.logs)
+}
+
+func Test_SetLogger(t *testing.T) {
+	r := require.New(t)
+
+	l := &entries{}
+	pop.SetLogger(l)
+	oldRedactor := pop.LogRedactor
+	pop.LogRedactor = pop.RedactColumns("isbn")
+	defer func() {
+		pop.SetLogger(nil)
+		pop.LogRedactor = oldRedactor
+	}()
+
+	transaction(func(tx *pop.Connection) {
+		r.NoError(tx.Where("isbn = ?", "PB1").All(&Books{}))
+	})
+	r.NotEmpty(l.logs)
+	found := false
+	for _, e := range l.logs {
+		if len(e.Args) == 1 {
+			found = true
+			r.Equal(pop.Redacted, e.Args[0])
+		}
+	}
+	r.True(found)
+}
+
+func Test_LogLevel_String(t *testing.T) {
+	r := require.New(t)
+	r.Equal("debug", pop.LogDebug.String())
+	r.Equal("warn", pop.LogWarn.String())
+}
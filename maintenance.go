@@ -0,0 +1,47 @@
+package pop
+
+import (
+	"github.com/pkg/errors"
+)
+
+// MaintenanceResult is the outcome of a maintenance statement.
+type MaintenanceResult struct {
+	// Table is the table maintained, empty for the whole database
+	Table string
+	// Statement is the statement that was run
+	Statement string
+	// Message is what the database reported, if anything
+	Message string
+}
+
+// maintainer is implemented by the dialects able to run routine
+// maintenance.
+type maintainer interface {
+	maintain(c *Connection, tables []string) ([]MaintenanceResult, error)
+}
+
+// Maintain runs the routine maintenance of the database, on the given
+// tables or on all of them: VACUUM ANALYZE on PostgreSQL, OPTIMIZE TABLE
+// on MySQL, and an integrity check followed by ANALYZE and VACUUM on
+// SQLite. It is meant to be run from a scheduled job, outside of a
+// transaction:
+//
+//	results, err := c.Maintain("users", "orders")
+//
+// An error is returned if SQLite finds the database corrupted.
+func (c *Connection) Maintain(tables ...string) ([]MaintenanceResult, error) {
+	m, ok := c.Dialect.(maintainer)
+	if !ok {
+		return nil, errors.Errorf("%s does not support maintenance", c.Dialect.Details().Dialect)
+	}
+	if c.TX != nil {
+		return nil, errors.New("maintenance can not run in a transaction")
+	}
+	var results []MaintenanceResult
+	err := c.timeFunc("Maintain", func() error {
+		var err error
+		results, err = m.maintain(c, tables)
+		return err
+	})
+	return results, err
+}
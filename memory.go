@@ -0,0 +1,740 @@
+package pop
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"math/rand"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/jmoiron/sqlx/reflectx"
+	"github.com/markbates/going/randx"
+	"github.com/markbates/pop/columns"
+	"github.com/markbates/pop/fizz"
+	"github.com/markbates/pop/nulls"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// NewMemoryConnection returns a connection keeping its records in memory,
+// so that the unit tests of code using pop can run without a database:
+//
+//	c := pop.NewMemoryConnection()
+//	err := c.Create(&User{Name: "Mark"})
+//	err = c.Where("name = ?", "Mark").First(&user)
+//
+// It supports Create, Update, Destroy, Find, First, Last, All, Count and
+// Exists, with Where clauses comparing a column to a value (=, <>, <, <=,
+// >, >=, IN, IS NULL and IS NOT NULL) joined with AND, and Order clauses
+// on columns. A rollback restores the records as they were when the
+// transaction started, but the transactions are not isolated from each
+// other. Any other SQL, such as raw queries, joins, OR conditions, group
+// by, `Each` or migrations, fails with an error saying it is not supported.
+func NewMemoryConnection() *Connection {
+	db := &memoryDB{tables: map[string]*memoryTable{}}
+	c := &Connection{
+		ID:      randx.String(30),
+		Dialect: &memory{ConnectionDetails: &ConnectionDetails{Dialect: "memory", Database: "memory", Options: map[string]string{}}, db: db},
+		gate:    &gate{},
+	}
+	c.Store = newInstrumentedStore(c, &memoryStore{db: db})
+	return c
+}
+
+// memoryTable holds the rows of a table, the structs of its models.
+type memoryTable struct {
+	rows   []reflect.Value
+	nextID int64
+}
+
+type memoryDB struct {
+	mu     sync.Mutex
+	tables map[string]*memoryTable
+}
+
+func (db *memoryDB) table(name string) *memoryTable {
+	t, ok := db.tables[name]
+	if !ok {
+		t = &memoryTable{}
+		db.tables[name] = t
+	}
+	return t
+}
+
+func (db *memoryDB) snapshot() map[string]*memoryTable {
+	defer db.mu.Unlock()
+	db.mu.Lock()
+	snap := map[string]*memoryTable{}
+	for name, t := range db.tables {
+		snap[name] = &memoryTable{rows: append([]reflect.Value{}, t.rows...), nextID: t.nextID}
+	}
+	return snap
+}
+
+func (db *memoryDB) restore(snap map[string]*memoryTable) {
+	defer db.mu.Unlock()
+	db.mu.Lock()
+	db.tables = snap
+}
+
+// errMemorySQL is returned by the memory store for the SQL statements it
+// is given, which it can not run.
+func errMemorySQL(query string) error {
+	return errors.Errorf("SQL statements are not supported by the memory connection: %s", query)
+}
+
+// memoryStore is the store of the memory connections, and of their
+// transactions: it runs no SQL.
+type memoryStore struct {
+	db *memoryDB
+	// tx and snap are the transaction of the store, and the records
+	// restored by its rollback
+	tx   *Tx
+	snap map[string]*memoryTable
+}
+
+func (s *memoryStore) Select(dest interface{}, query string, args ...interface{}) error {
+	return errMemorySQL(query)
+}
+
+func (s *memoryStore) Get(dest interface{}, query string, args ...interface{}) error {
+	return errMemorySQL(query)
+}
+
+func (s *memoryStore) Queryx(query string, args ...interface{}) (*sqlx.Rows, error) {
+	return nil, errMemorySQL(query)
+}
+
+func (s *memoryStore) NamedExec(query string, arg interface{}) (sql.Result, error) {
+	return nil, errMemorySQL(query)
+}
+
+func (s *memoryStore) BindNamed(query string, arg interface{}) (string, []interface{}, error) {
+	return "", nil, errMemorySQL(query)
+}
+
+func (s *memoryStore) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return nil, errMemorySQL(query)
+}
+
+func (s *memoryStore) PrepareNamed(query string) (*sqlx.NamedStmt, error) {
+	return nil, errMemorySQL(query)
+}
+
+func (s *memoryStore) Transaction() (*Tx, error) {
+	if s.tx != nil {
+		return s.tx, nil
+	}
+	ts := &memoryStore{db: s.db, snap: s.db.snapshot()}
+	ts.tx = &Tx{ID: rand.Int(), store: ts}
+	return ts.tx, nil
+}
+
+func (s *memoryStore) Rollback() error {
+	if s.snap != nil {
+		s.db.restore(s.snap)
+	}
+	return nil
+}
+
+func (s *memoryStore) Commit() error {
+	return nil
+}
+
+func (s *memoryStore) Close() error {
+	return nil
+}
+
+// memory is the dialect of the memory connections, which reads and writes
+// the records of the models in memory.
+type memory struct {
+	ConnectionDetails *ConnectionDetails
+	db                *memoryDB
+}
+
+func (m *memory) Quote(key string) string {
+	return key
+}
+
+func (m *memory) URL() string {
+	return "memory://"
+}
+
+func (m *memory) MigrationURL() string {
+	return m.URL()
+}
+
+func (m *memory) Details() *ConnectionDetails {
+	return m.ConnectionDetails
+}
+
+func (m *memory) TranslateSQL(sql string) string {
+	return sql
+}
+
+func (m *memory) Create(s store, model *Model, cols columns.Columns) error {
+	defer m.db.mu.Unlock()
+	m.db.mu.Lock()
+	t := m.db.table(model.TableName())
+	switch keyType := model.PrimaryKeyType(); keyType {
+	case "int", "int64":
+		t.nextID++
+		model.setID(t.nextID)
+	case "UUID":
+		if model.ID() == emptyUUID {
+			u, err := uuid.NewV4()
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			model.setID(u)
+		}
+	case "string":
+		if fmt.Sprint(model.ID()) == "" {
+			return errors.Errorf("%s can not be created without an id", model.TableName())
+		}
+	default:
+		return errors.Errorf("can not use %s as a primary key type!", keyType)
+	}
+	if _, ok := t.find(model.ID()); ok {
+		return errors.Errorf("%s %v already exists", model.TableName(), model.ID())
+	}
+	v := reflect.Indirect(reflect.ValueOf(model.Value))
+	row := reflect.New(v.Type()).Elem()
+	row.Set(v)
+	// the columns which are not written are left empty, as in a table.
+	for name := range columns.ColumnsForStruct(model.Value, model.TableName()).Cols {
+		if c, ok := cols.Cols[name]; name != "id" && (!ok || !c.Writeable) {
+			if f, ok := memoryField(row, name); ok {
+				f.Set(reflect.Zero(f.Type()))
+			}
+		}
+	}
+	t.rows = append(t.rows, row)
+	return nil
+}
+
+func (m *memory) Update(s store, model *Model, cols columns.Columns) error {
+	defer m.db.mu.Unlock()
+	m.db.mu.Lock()
+	model.rowsAffected = 0
+	t := m.db.table(model.TableName())
+	i, ok := t.find(model.ID())
+	if !ok {
+		return nil
+	}
+	v := reflect.Indirect(reflect.ValueOf(model.Value))
+	row := reflect.New(v.Type()).Elem()
+	row.Set(t.rows[i])
+	if model.checkLockVersion {
+		lv, _ := memoryField(row, "lock_version")
+		nv, _ := memoryField(v, "lock_version")
+		if lv.Int() != nv.Int()-1 {
+			return nil
+		}
+	}
+	if model.checkNotDeleted {
+		if da, ok := memoryField(row, "deleted_at"); ok && da.Interface().(nulls.Time).Valid {
+			return nil
+		}
+	}
+	for name, c := range cols.Cols {
+		if !c.Writeable {
+			continue
+		}
+		from, ok := memoryField(v, name)
+		to, _ := memoryField(row, name)
+		if ok {
+			to.Set(from)
+		}
+	}
+	t.rows[i] = row
+	model.rowsAffected = 1
+	return nil
+}
+
+func (m *memory) Destroy(s store, model *Model) error {
+	defer m.db.mu.Unlock()
+	m.db.mu.Lock()
+	model.rowsAffected = 0
+	t := m.db.table(model.TableName())
+	if i, ok := t.find(model.ID()); ok {
+		t.rows = append(t.rows[:i:i], t.rows[i+1:]...)
+		model.rowsAffected = 1
+	}
+	return nil
+}
+
+func (m *memory) SelectOne(s store, model *Model, query Query) error {
+	rows, err := m.query(model, query)
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return errors.WithStack(sql.ErrNoRows)
+	}
+	reflect.Indirect(reflect.ValueOf(model.Value)).Set(rows[0])
+	return nil
+}
+
+func (m *memory) SelectMany(s store, models *Model, query Query) error {
+	rows, err := m.query(models, query)
+	if err != nil {
+		return err
+	}
+	v := reflect.Indirect(reflect.ValueOf(models.Value))
+	res := reflect.MakeSlice(v.Type(), 0, len(rows))
+	for _, row := range rows {
+		if v.Type().Elem().Kind() == reflect.Ptr {
+			row = row.Addr()
+		}
+		res = reflect.Append(res, row)
+	}
+	v.Set(res)
+	return nil
+}
+
+// rowCounter is implemented by the dialects counting the records of a
+// query themselves, rather than with a SQL statement.
+type rowCounter interface {
+	count(query Query, model *Model, field string) (int, error)
+}
+
+// count implements rowCounter.
+func (m *memory) count(query Query, model *Model, field string) (int, error) {
+	query.limitResults = 0
+	rows, err := m.query(model, query)
+	if err != nil {
+		return 0, err
+	}
+	if field == "*" {
+		return len(rows), nil
+	}
+	n := 0
+	for _, row := range rows {
+		f, ok := memoryField(row, memoryColumn(field))
+		if !ok {
+			return 0, errors.Errorf("%s has no column %s", model.TableName(), field)
+		}
+		if memoryValue(f.Interface()) != nil {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// query returns copies of the rows of the table of the model matching
+// the query, in its order.
+func (m *memory) query(model *Model, query Query) ([]reflect.Value, error) {
+	if err := memorySupports(query); err != nil {
+		return nil, err
+	}
+	sq := newSQLBuilder(query, model)
+	wc := sq.whereClauses()
+	if sq.err != nil {
+		return nil, sq.err
+	}
+	conds := []memoryCondition{}
+	for _, c := range wc {
+		cs, err := memoryConditions(c)
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, cs...)
+	}
+
+	m.db.mu.Lock()
+	t := m.db.table(model.TableName())
+	rows := make([]reflect.Value, 0, len(t.rows))
+	for _, row := range t.rows {
+		cp := reflect.New(row.Type()).Elem()
+		cp.Set(row)
+		rows = append(rows, cp)
+	}
+	m.db.mu.Unlock()
+
+	matched := rows[:0]
+	for _, row := range rows {
+		ok, err := memoryMatch(row, conds)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matched = append(matched, row)
+		}
+	}
+
+	order := query.orderClauses
+	if query.CursorPaginator != nil {
+		order = clauses{{Fragment: "id asc"}}
+	}
+	if err := memorySort(matched, order); err != nil {
+		return nil, err
+	}
+
+	offset, limit := 0, query.limitResults
+	if query.Paginator != nil {
+		offset, limit = query.Paginator.Offset, query.Paginator.PerPage
+	}
+	if query.CursorPaginator != nil {
+		offset, limit = 0, query.CursorPaginator.Size+1
+	}
+	if offset > len(matched) {
+		offset = len(matched)
+	}
+	matched = matched[offset:]
+	if limit > 0 && limit < len(matched) {
+		matched = matched[:limit]
+	}
+	return matched, nil
+}
+
+// memorySupports returns an error if the query uses a feature the memory
+// connections do not support.
+func memorySupports(q Query) error {
+	unsupported := ""
+	switch {
+	case q.RawSQL.Fragment != "":
+		unsupported = "RawQuery"
+	case len(q.joinClauses) > 0 || len(q.belongsToThroughClauses) > 0:
+		unsupported = "Join"
+	case len(q.groupClauses) > 0 || len(q.havingClauses) > 0:
+		unsupported = "GroupBy"
+	case len(q.fromClauses) > 0:
+		unsupported = "From"
+	case len(q.selectSubqueries) > 0 || len(q.selectExprs) > 0:
+		unsupported = "SelectExpr"
+	case q.distinct || len(q.distinctOn) > 0:
+		unsupported = "Distinct"
+	}
+	if unsupported != "" {
+		return errors.Errorf("%s is not supported by the memory connection", unsupported)
+	}
+	return nil
+}
+
+// memoryCondition compares the column of a row with values.
+type memoryCondition struct {
+	column string
+	op     string
+	values []interface{}
+}
+
+var memoryConditionX = regexp.MustCompile("(?i)^(?:[\\w\"`]+\\.)?[\"`]?(\\w+)[\"`]?\\s*(=|<>|!=|<=|>=|<|>|NOT IN|IN|IS NOT NULL|IS NULL)\\s*(.*)$")
+var memoryAndX = regexp.MustCompile(`(?i)\s+AND\s+`)
+var memoryOrX = regexp.MustCompile(`(?i)\bOR\b`)
+
+// memoryConditions parses the comparisons of a where clause.
+func memoryConditions(c clause) ([]memoryCondition, error) {
+	fragment, args, err := expandSubqueries(c.Fragment, c.Arguments)
+	if err == nil {
+		fragment, args, err = bindArgs("where", fragment, args)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if memoryOrX.MatchString(fragment) {
+		return nil, errors.Errorf("the memory connection can not evaluate %q: OR is not supported", c.Fragment)
+	}
+	conds := []memoryCondition{}
+	for _, part := range memoryAndX.Split(memoryUngroup(fragment), -1) {
+		part = memoryUngroup(part)
+		m := memoryConditionX.FindStringSubmatch(part)
+		if m == nil {
+			return nil, errors.Errorf("the memory connection can not evaluate %q", part)
+		}
+		cond := memoryCondition{column: m[1], op: strings.ToUpper(m[2])}
+		rest := strings.TrimSpace(m[3])
+		n := strings.Count(rest, "?")
+		switch cond.op {
+		case "IS NULL", "IS NOT NULL":
+			if rest != "" {
+				return nil, errors.Errorf("the memory connection can not evaluate %q", part)
+			}
+		case "IN", "NOT IN":
+			if !strings.HasPrefix(rest, "(") || strings.Trim(rest, "(?, )") != "" || n == 0 {
+				return nil, errors.Errorf("the memory connection can not evaluate %q", part)
+			}
+		default:
+			if rest != "?" {
+				return nil, errors.Errorf("the memory connection can not evaluate %q", part)
+			}
+		}
+		if n > len(args) {
+			return nil, &PlaceholderError{"where", c.Fragment, n, len(args)}
+		}
+		cond.values, args = args[:n], args[n:]
+		conds = append(conds, cond)
+	}
+	return conds, nil
+}
+
+// memoryUngroup removes the parentheses around s.
+func memoryUngroup(s string) string {
+	s = strings.TrimSpace(s)
+	for strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") && strings.Count(s, "(") == strings.Count(s, ")") {
+		inner := s[1 : len(s)-1]
+		if strings.Index(inner, ")") < strings.Index(inner, "(") {
+			break
+		}
+		s = strings.TrimSpace(inner)
+	}
+	return s
+}
+
+func memoryMatch(row reflect.Value, conds []memoryCondition) (bool, error) {
+	for _, c := range conds {
+		f, ok := memoryField(row, c.column)
+		if !ok {
+			return false, errors.Errorf("%s has no column %s", row.Type(), c.column)
+		}
+		v := memoryValue(f.Interface())
+		match := false
+		switch c.op {
+		case "IS NULL":
+			match = v == nil
+		case "IS NOT NULL":
+			match = v != nil
+		case "IN", "NOT IN":
+			for _, val := range c.values {
+				if cmp, ok := memoryCompare(v, memoryValue(val)); ok && cmp == 0 {
+					match = true
+				}
+			}
+			if c.op == "NOT IN" && v != nil {
+				match = !match
+			}
+		default:
+			cmp, ok := memoryCompare(v, memoryValue(c.values[0]))
+			if !ok {
+				break
+			}
+			switch c.op {
+			case "=":
+				match = cmp == 0
+			case "<>", "!=":
+				match = cmp != 0
+			case "<":
+				match = cmp < 0
+			case "<=":
+				match = cmp <= 0
+			case ">":
+				match = cmp > 0
+			case ">=":
+				match = cmp >= 0
+			}
+		}
+		if !match {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+var memoryOrderX = regexp.MustCompile("(?i)^(?:[\\w\"`]+\\.)?[\"`]?(\\w+)[\"`]?(?:\\s+(ASC|DESC))?$")
+
+// memorySort sorts rows by the columns of the order clauses; the rows
+// stay in their order of creation otherwise.
+func memorySort(rows []reflect.Value, order clauses) error {
+	type key struct {
+		column string
+		desc   bool
+	}
+	keys := []key{}
+	for _, c := range order {
+		for _, part := range strings.Split(c.Fragment, ",") {
+			m := memoryOrderX.FindStringSubmatch(strings.TrimSpace(part))
+			if m == nil {
+				return errors.Errorf("the memory connection can not order by %q", part)
+			}
+			keys = append(keys, key{column: m[1], desc: strings.EqualFold(m[2], "desc")})
+		}
+	}
+	for _, rk := range keys {
+		if len(rows) > 0 {
+			if _, ok := memoryField(rows[0], rk.column); !ok {
+				return errors.Errorf("%s has no column %s", rows[0].Type(), rk.column)
+			}
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		for _, k := range keys {
+			a, _ := memoryField(rows[i], k.column)
+			b, _ := memoryField(rows[j], k.column)
+			cmp, ok := memoryCompare(memoryValue(a.Interface()), memoryValue(b.Interface()))
+			if !ok || cmp == 0 {
+				continue
+			}
+			return (cmp < 0) != k.desc
+		}
+		return false
+	})
+	return nil
+}
+
+// find returns the index of the row whose id is id.
+func (t *memoryTable) find(id interface{}) (int, bool) {
+	want := memoryValue(id)
+	for i, row := range t.rows {
+		f, ok := memoryField(row, "id")
+		if !ok {
+			continue
+		}
+		if cmp, ok := memoryCompare(memoryValue(f.Interface()), want); ok && cmp == 0 {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// memoryColumn returns the column name of a possibly qualified and
+// quoted column.
+func memoryColumn(name string) string {
+	if i := strings.LastIndex(name, "."); i != -1 {
+		name = name[i+1:]
+	}
+	return strings.Trim(name, "\"`")
+}
+
+// memoryField returns the field of the struct row mapped to column.
+func memoryField(row reflect.Value, column string) (reflect.Value, bool) {
+	fi, ok := fieldMapper.TypeMap(row.Type()).Names[column]
+	if !ok {
+		return reflect.Value{}, false
+	}
+	return reflectx.FieldByIndexes(row, fi.Index), true
+}
+
+// memoryValue returns the value v is stored as in a database: nil, an
+// int64, a float64, a bool, a []byte, a string or a time.Time.
+func memoryValue(v interface{}) interface{} {
+	if vr, ok := v.(driver.Valuer); ok {
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+			return nil
+		}
+		dv, err := vr.Value()
+		if err != nil {
+			return v
+		}
+		v = dv
+	}
+	dv, err := driver.DefaultParameterConverter.ConvertValue(v)
+	if err != nil {
+		return v
+	}
+	if u, ok := dv.(uint64); ok {
+		return int64(u)
+	}
+	return dv
+}
+
+// memoryCompare compares the values a and b, returned by memoryValue. It
+// returns false if they can not be compared, as a NULL can not.
+func memoryCompare(a, b interface{}) (int, bool) {
+	if a == nil || b == nil {
+		return 0, false
+	}
+	switch av := a.(type) {
+	case int64:
+		switch bv := b.(type) {
+		case int64:
+			return compareFloats(float64(av), float64(bv)), true
+		case float64:
+			return compareFloats(float64(av), bv), true
+		}
+	case float64:
+		switch bv := b.(type) {
+		case int64:
+			return compareFloats(av, float64(bv)), true
+		case float64:
+			return compareFloats(av, bv), true
+		}
+	case bool:
+		if bv, ok := b.(bool); ok {
+			if av == bv {
+				return 0, true
+			}
+			if !av {
+				return -1, true
+			}
+			return 1, true
+		}
+	case string:
+		switch bv := b.(type) {
+		case string:
+			return strings.Compare(av, bv), true
+		case []byte:
+			return strings.Compare(av, string(bv)), true
+		}
+	case []byte:
+		switch bv := b.(type) {
+		case []byte:
+			return bytes.Compare(av, bv), true
+		case string:
+			return strings.Compare(string(av), bv), true
+		}
+	case time.Time:
+		if bv, ok := b.(time.Time); ok {
+			switch {
+			case av.Before(bv):
+				return -1, true
+			case av.After(bv):
+				return 1, true
+			}
+			return 0, true
+		}
+	}
+	return 0, false
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func (m *memory) CreateDB() error {
+	return nil
+}
+
+func (m *memory) DropDB() error {
+	defer m.db.mu.Unlock()
+	m.db.mu.Lock()
+	m.db.tables = map[string]*memoryTable{}
+	return nil
+}
+
+func (m *memory) DumpSchema(w io.Writer) error {
+	return errors.New("the memory connection has no schema to dump")
+}
+
+func (m *memory) LoadSchema(r io.Reader) error {
+	return errors.New("the memory connection can not load a schema")
+}
+
+func (m *memory) FizzTranslator() fizz.Translator {
+	return nil
+}
+
+func (m *memory) Lock(fn func() error) error {
+	return fn()
+}
+
+func (m *memory) TruncateAll(tx *Connection) error {
+	return m.DropDB()
+}
+
+func (m *memory) Capabilities() Capabilities {
+	return Capabilities{}
+}
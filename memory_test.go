@@ -0,0 +1,152 @@
+package pop_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/markbates/pop"
+	"github.com/markbates/pop/nulls"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Memory_CRUD(t *testing.T) {
+	r := require.New(t)
+	c := pop.NewMemoryConnection()
+
+	u := &User{Name: nulls.NewString("Mark"), Email: "mark@example.com"}
+	r.NoError(c.Create(u))
+	r.Equal(1, u.ID)
+	r.False(u.CreatedAt.IsZero())
+
+	found := &User{}
+	r.NoError(c.Find(found, u.ID))
+	r.Equal("Mark", found.Name.String)
+	r.Equal("mark@example.com", found.Email)
+
+	found.Name = nulls.NewString("Mark Bates")
+	r.NoError(c.Update(found))
+	r.NoError(c.Find(u, found.ID))
+	r.Equal("Mark Bates", u.Name.String)
+
+	r.NoError(c.Destroy(u))
+	err := c.Find(found, u.ID)
+	r.Equal(sql.ErrNoRows, errors.Cause(err))
+
+	s := &Song{Title: "Lose Yourself"}
+	r.NoError(c.Create(s))
+	r.NotEqual("00000000-0000-0000-0000-000000000000", s.ID.String())
+}
+
+func Test_Memory_Where(t *testing.T) {
+	r := require.New(t)
+	c := pop.NewMemoryConnection()
+
+	for _, name := range []string{"Mark", "Larry", "Mark", "Joe"} {
+		r.NoError(c.Create(&User{Name: nulls.NewString(name), Alive: nulls.NewBool(name != "Joe")}))
+	}
+	r.NoError(c.Create(&User{}))
+
+	users := Users{}
+	r.NoError(c.Where("name = ?", "Mark").All(&users))
+	r.Len(users, 2)
+
+	r.NoError(c.Where("name = ?", "Mark").Where("id > ?", 1).All(&users))
+	r.Len(users, 1)
+	r.Equal(3, users[0].ID)
+
+	r.NoError(c.Where("name in (?)", []string{"Larry", "Joe"}).All(&users))
+	r.Len(users, 2)
+
+	r.NoError(c.Where("alive = ? AND name <> ?", true, "Mark").All(&users))
+	r.Len(users, 1)
+	r.Equal("Larry", users[0].Name.String)
+
+	r.NoError(c.Where("name IS NULL").All(&users))
+	r.Len(users, 1)
+
+	n, err := c.Where("name IS NOT NULL").Count(&User{})
+	r.NoError(err)
+	r.Equal(4, n)
+
+	ok, err := c.Where("name = ?", "Nobody").Exists(&User{})
+	r.NoError(err)
+	r.False(ok)
+}
+
+func Test_Memory_Order_Limit_Paginate(t *testing.T) {
+	r := require.New(t)
+	c := pop.NewMemoryConnection()
+
+	for _, name := range []string{"Carl", "Alice", "Bob", "Dave"} {
+		r.NoError(c.Create(&User{Name: nulls.NewString(name)}))
+	}
+
+	users := Users{}
+	r.NoError(c.Order("name desc").Limit(2).All(&users))
+	r.Len(users, 2)
+	r.Equal("Dave", users[0].Name.String)
+	r.Equal("Carl", users[1].Name.String)
+
+	q := c.Order("name asc").Paginate(2, 2)
+	r.NoError(q.All(&users))
+	r.Len(users, 2)
+	r.Equal("Carl", users[0].Name.String)
+
+	u := User{}
+	r.NoError(c.Order("name").First(&u))
+	r.Equal("Alice", u.Name.String)
+	r.NoError(c.Last(&u))
+	r.Equal("Dave", u.Name.String)
+}
+
+func Test_Memory_SoftDelete(t *testing.T) {
+	r := require.New(t)
+	c := pop.NewMemoryConnection()
+
+	n := &Note{Title: "Draft"}
+	r.NoError(c.Create(n))
+	r.NoError(c.Destroy(n))
+
+	notes := []Note{}
+	r.NoError(c.All(&notes))
+	r.Len(notes, 0)
+	r.NoError(c.Unscoped().All(&notes))
+	r.Len(notes, 1)
+	r.True(notes[0].DeletedAt.Valid)
+}
+
+func Test_Memory_Transaction(t *testing.T) {
+	r := require.New(t)
+	c := pop.NewMemoryConnection()
+
+	r.NoError(c.Create(&User{Name: nulls.NewString("Kept")}))
+	c.Rollback(func(tx *pop.Connection) {
+		r.NoError(tx.Create(&User{Name: nulls.NewString("Rolled back")}))
+		n, err := tx.Count(&User{})
+		r.NoError(err)
+		r.Equal(2, n)
+	})
+	err := c.Transaction(func(tx *pop.Connection) error {
+		return tx.Create(&User{Name: nulls.NewString("Committed")})
+	})
+	r.NoError(err)
+
+	users := Users{}
+	r.NoError(c.Order("id").All(&users))
+	r.Len(users, 2)
+	r.Equal("Kept", users[0].Name.String)
+	r.Equal("Committed", users[1].Name.String)
+}
+
+func Test_Memory_Unsupported(t *testing.T) {
+	r := require.New(t)
+	c := pop.NewMemoryConnection()
+
+	users := Users{}
+	r.Error(c.RawQuery("select * from users").All(&users))
+	r.Error(c.Where("name = ? OR name = ?", "a", "b").All(&users))
+	r.Error(c.Where("lower(name) = ?", "a").All(&users))
+	r.Error(c.Q().Join("books b", "b.user_id = users.id").All(&users))
+	r.Error(c.Q().GroupBy("name").All(&users))
+}
@@ -0,0 +1,73 @@
+package pop
+
+import "database/sql"
+
+// Statement is a statement sent to the database, as seen by the
+// middlewares of its connection, see `Connection.Use`.
+type Statement struct {
+	// Connection the statement is sent through
+	Connection *Connection
+	// SQL is the statement, which the middlewares can rewrite. The named
+	// statements still hold their `:name` placeholders.
+	SQL string
+	// Args are the arguments bound to the statement. A named statement,
+	// sent with `NamedExec`, has the struct or map holding its values as
+	// its single argument.
+	Args []interface{}
+	// Result of the statements changing rows, once they ran
+	Result sql.Result
+}
+
+// Executor sends a statement to the database.
+type Executor interface {
+	Execute(s *Statement) error
+}
+
+// ExecutorFunc is a function used as an `Executor`.
+type ExecutorFunc func(s *Statement) error
+
+// Execute calls f(s).
+func (f ExecutorFunc) Execute(s *Statement) error {
+	return f(s)
+}
+
+// Middleware wraps the execution of the statements of a connection: it
+// returns the `Executor` to use instead of next, which sends the statement
+// to the database.
+type Middleware func(next Executor) Executor
+
+// Use adds middlewares to the connection, and to the transactions started
+// from it afterwards. They wrap every statement sent to the database, in
+// the order they were added, the first one being the outermost:
+//
+//	c.Use(func(next pop.Executor) pop.Executor {
+//		return pop.ExecutorFunc(func(s *pop.Statement) error {
+//			s.SQL = "/* tenant " + tenant + " */ " + s.SQL
+//			start := time.Now()
+//			err := next.Execute(s)
+//			metrics.Observe(s.SQL, time.Since(start))
+//			return err
+//		})
+//	})
+//
+// The statements are logged and instrumented as they were sent to next.
+// Use must be called before the connection is used concurrently.
+func (c *Connection) Use(mws ...Middleware) {
+	all := make([]Middleware, 0, len(c.middlewares)+len(mws))
+	all = append(all, c.middlewares...)
+	c.middlewares = append(all, mws...)
+}
+
+// execute sends the statement query through the middlewares of the
+// connection, to run. It returns the statement as it was sent.
+func (s *instrumentedStore) execute(query string, args []interface{}, run func(st *Statement) error) (*Statement, error) {
+	st := &Statement{Connection: s.conn, SQL: query, Args: args}
+	if s.conn == nil || len(s.conn.middlewares) == 0 {
+		return st, run(st)
+	}
+	var e Executor = ExecutorFunc(run)
+	for i := len(s.conn.middlewares) - 1; i >= 0; i-- {
+		e = s.conn.middlewares[i](e)
+	}
+	return st, e.Execute(st)
+}
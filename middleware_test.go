@@ -0,0 +1,102 @@
+package pop_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/markbates/pop"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// trace returns a middleware recording its calls in calls.
+func trace(name string, calls *[]string) pop.Middleware {
+	return func(next pop.Executor) pop.Executor {
+		return pop.ExecutorFunc(func(s *pop.Statement) error {
+			*calls = append(*calls, name+" "+strings.ToUpper(strings.Fields(s.SQL)[0]))
+			err := next.Execute(s)
+			*calls = append(*calls, name+" done")
+			return err
+		})
+	}
+}
+
+func Test_Use(t *testing.T) {
+	transaction(func(tx *pop.Connection) {
+		r := require.New(t)
+
+		calls := []string{}
+		tx.Use(trace("a", &calls), trace("b", &calls))
+		r.NoError(tx.Create(&Book{Title: "Emma"}))
+		r.Equal([]string{"a INSERT", "b INSERT", "b done", "a done"}, calls)
+
+		calls = calls[:0]
+		count, err := tx.Where("title = ?", "Emma").Count(&Book{})
+		r.NoError(err)
+		r.Equal(1, count)
+		r.Equal([]string{"a SELECT", "b SELECT", "b done", "a done"}, calls)
+	})
+}
+
+func Test_Use_Rewrite(t *testing.T) {
+	transaction(func(tx *pop.Connection) {
+		r := require.New(t)
+
+		l := &entries{}
+		c := tx.WithLogger(l)
+		c.Use(func(next pop.Executor) pop.Executor {
+			return pop.ExecutorFunc(func(s *pop.Statement) error {
+				s.SQL = "/* tenant 42 */ " + s.SQL
+				return next.Execute(s)
+			})
+		})
+		r.NoError(c.Create(&Book{Title: "Emma"}))
+		r.Len(l.logs, 1)
+		r.True(strings.HasPrefix(l.logs[0].SQL, "/* tenant 42 */ INSERT"))
+		r.Equal(int64(1), l.logs[0].RowsAffected)
+
+		books := Books{}
+		r.NoError(c.Where("title = ?", "Emma").All(&books))
+		r.Len(books, 1)
+	})
+}
+
+func Test_Use_Error(t *testing.T) {
+	transaction(func(tx *pop.Connection) {
+		r := require.New(t)
+
+		tx.Use(func(next pop.Executor) pop.Executor {
+			return pop.ExecutorFunc(func(s *pop.Statement) error {
+				if strings.HasPrefix(s.SQL, "DELETE") {
+					return errors.New("deletes are not allowed")
+				}
+				return next.Execute(s)
+			})
+		})
+		b := &Book{Title: "Emma"}
+		r.NoError(tx.Create(b))
+		err := tx.Destroy(b)
+		r.Error(err)
+		r.Contains(err.Error(), "deletes are not allowed")
+	})
+}
+
+func Test_Use_Transaction(t *testing.T) {
+	r := require.New(t)
+
+	calls := []string{}
+	c := PDB.WithLogger(&entries{})
+	c.Use(trace("a", &calls))
+	err := c.Rollback(func(tx *pop.Connection) {
+		_, err := tx.Count(&Books{})
+		r.NoError(err)
+	})
+	r.NoError(err)
+	r.Equal([]string{"a SELECT", "a done"}, calls)
+
+	// the middlewares of a copy are its own.
+	calls = calls[:0]
+	_, err = PDB.Count(&Books{})
+	r.NoError(err)
+	r.Empty(calls)
+}
@@ -39,6 +39,10 @@ func (fm *MigrationBox) findMigrations() error {
 			return nil
 		}
 		m := matches[0]
+		b, err := fm.Box.MustBytes(p)
+		if err != nil {
+			return errors.WithStack(err)
+		}
 		mf := Migration{
 			Path:      p,
 			Version:   m[1],
@@ -61,6 +65,7 @@ func (fm *MigrationBox) findMigrations() error {
 				}
 				return nil
 			},
+			DependsOn: migrationDependencies(string(b)),
 		}
 		fm.Migrations[mf.Direction] = append(fm.Migrations[mf.Direction], mf)
 		return nil
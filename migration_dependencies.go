@@ -0,0 +1,133 @@
+package pop
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// the directives declaring the dependencies of a migration, on lines of
+// their own:
+//
+//	-- pop:depends_on 20180301100000 20180302100000
+//	-- pop:independent
+var migrationDirective = regexp.MustCompile(`(?m)^[ \t]*--[ \t]*pop:(depends_on|independent)\b(.*)$\n?`)
+
+// migrationDependencies returns the versions the migration of content
+// depends on, nil if it does not declare them.
+func migrationDependencies(content string) []string {
+	var deps []string
+	for _, m := range migrationDirective.FindAllStringSubmatch(content, -1) {
+		if deps == nil {
+			deps = []string{}
+		}
+		if m[1] == "depends_on" {
+			deps = append(deps, strings.Fields(strings.Replace(m[2], ",", " ", -1))...)
+		}
+	}
+	return deps
+}
+
+// stripMigrationDirectives removes the dependency directives of content,
+// which fizz does not understand.
+func stripMigrationDirectives(content string) string {
+	return migrationDirective.ReplaceAllString(content, "")
+}
+
+// migrationSkipped is the error of the migrations not run because a
+// migration failed.
+var migrationSkipped = errors.New("skipped")
+
+// upConcurrently applies the pending migrations mfs, sorted by version,
+// running up to m.Concurrency of them at once. A migration starts once the
+// ones it depends on are applied: the migrations declaring no dependencies
+// depend on all of the migrations before them. Once a migration fails, no
+// other migration starts, and the error of the first one failing, by
+// version, is returned.
+func (m Migrator) upConcurrently(mfs Migrations) error {
+	done := map[string]chan struct{}{}
+	for _, mi := range mfs {
+		done[mi.Version] = make(chan struct{})
+	}
+	waits := make([][]chan struct{}, len(mfs))
+	for i, mi := range mfs {
+		if mi.DependsOn == nil {
+			for _, prev := range mfs[:i] {
+				waits[i] = append(waits[i], done[prev.Version])
+			}
+			continue
+		}
+		for _, v := range mi.DependsOn {
+			if v >= mi.Version {
+				return errors.Errorf("migration %s can not depend on the later migration %s", mi.Version, v)
+			}
+			if ch, ok := done[v]; ok {
+				waits[i] = append(waits[i], ch)
+				continue
+			}
+			exists, err := m.Connection.Where("version = ?", v).Exists("schema_migration")
+			if err != nil {
+				return errors.Wrapf(err, "problem checking for migration version %s", v)
+			}
+			if !exists {
+				return errors.Errorf("migration %s depends on the unknown migration %s", mi.Version, v)
+			}
+		}
+	}
+
+	errs := make([]error, len(mfs))
+	sem := make(chan struct{}, m.Concurrency)
+	stop := make(chan struct{})
+	var once sync.Once
+	wg := &sync.WaitGroup{}
+	for i, mi := range mfs {
+		wg.Add(1)
+		go func(i int, mi Migration) {
+			defer wg.Done()
+			defer close(done[mi.Version])
+			for _, ch := range waits[i] {
+				<-ch
+			}
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			select {
+			case <-stop:
+				errs[i] = migrationSkipped
+				return
+			default:
+			}
+			if errs[i] = m.upOne(mi); errs[i] != nil {
+				once.Do(func() { close(stop) })
+			}
+		}(i, mi)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil && err != migrationSkipped {
+			return err
+		}
+	}
+	return nil
+}
+
+// upOne applies the migration mi, and records it.
+func (m Migrator) upOne(mi Migration) error {
+	err := m.migrationTransaction(func(tx *Connection) error {
+		start := time.Now()
+		err := mi.Run(tx)
+		if err != nil {
+			return err
+		}
+		err = recordMigration(tx, mi.Version, time.Since(start))
+		return errors.Wrapf(err, "problem inserting migration version %s", mi.Version)
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	fmt.Printf("> %s\n", mi.Name)
+	return nil
+}
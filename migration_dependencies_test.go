@@ -0,0 +1,95 @@
+// +build !nosqlite,!appengine,!appenginevm
+
+package pop
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_migrationDependencies(t *testing.T) {
+	r := require.New(t)
+
+	r.Nil(migrationDependencies(`create_table("users", func(t) {})`))
+	r.Equal([]string{}, migrationDependencies("-- pop:independent\nCREATE INDEX ..."))
+	content := "-- pop:depends_on 20180301100000, 20180302100000\n-- pop:depends_on 20180303100000\nCREATE INDEX ..."
+	r.Equal([]string{"20180301100000", "20180302100000", "20180303100000"}, migrationDependencies(content))
+	r.Equal("CREATE INDEX ...", stripMigrationDirectives(content))
+}
+
+func Test_Migrator_Up_Concurrency(t *testing.T) {
+	r := require.New(t)
+
+	dir, err := ioutil.TempDir("", "pop")
+	r.NoError(err)
+	defer os.RemoveAll(dir)
+
+	c, err := NewConnection(&ConnectionDetails{Dialect: "sqlite3", Database: filepath.Join(dir, "concurrency.sqlite")})
+	r.NoError(err)
+	r.NoError(c.Open())
+	defer c.Close()
+
+	mu := &sync.Mutex{}
+	events := []string{}
+	runner := func(d time.Duration, fail bool) func(Migration, *Connection) error {
+		return func(mf Migration, tx *Connection) error {
+			mu.Lock()
+			events = append(events, "start "+mf.Version)
+			mu.Unlock()
+			time.Sleep(d)
+			mu.Lock()
+			events = append(events, "end "+mf.Version)
+			mu.Unlock()
+			if fail {
+				return errors.New("boom")
+			}
+			return nil
+		}
+	}
+	index := func(e string) int {
+		for i, x := range events {
+			if x == e {
+				return i
+			}
+		}
+		return -1
+	}
+
+	m := NewMigrator(c)
+	m.Concurrency = 3
+	m.Migrations["up"] = Migrations{
+		{Version: "1", Name: "one", Runner: runner(10*time.Millisecond, false)},
+		{Version: "2", Name: "two", Runner: runner(0, false), DependsOn: []string{"1"}},
+		{Version: "3", Name: "three", Runner: runner(0, false), DependsOn: []string{}},
+		{Version: "4", Name: "four", Runner: runner(0, false)},
+	}
+	r.NoError(m.Up())
+	r.Len(events, 8)
+	r.True(index("end 1") < index("start 2"))
+	for _, v := range []string{"1", "2", "3"} {
+		r.True(index("end "+v) < index("start 4"))
+	}
+	count, err := c.Count("schema_migration")
+	r.NoError(err)
+	r.Equal(4, count)
+
+	events = []string{}
+	m.Migrations["up"] = append(m.Migrations["up"],
+		Migration{Version: "5", Name: "five", Runner: runner(0, true), DependsOn: []string{"1"}},
+		Migration{Version: "6", Name: "six", Runner: runner(0, false), DependsOn: []string{"5"}},
+	)
+	err = m.Up()
+	r.Error(err)
+	r.Contains(err.Error(), "boom")
+	r.Equal([]string{"start 5", "end 5"}, events)
+
+	m.Migrations["up"] = Migrations{{Version: "7", Name: "seven", Runner: runner(0, false), DependsOn: []string{"0"}}}
+	r.Error(m.Up())
+}
@@ -0,0 +1,153 @@
+package pop
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/markbates/pop/fizz"
+	"github.com/markbates/pop/nulls"
+	"github.com/pkg/errors"
+)
+
+// migrationRecord is a row of the schema_migration table. The rows of the
+// migrations applied before pop recorded their history only have a
+// version.
+type migrationRecord struct {
+	Version   string       `db:"version"`
+	AppliedAt nulls.Time   `db:"applied_at"`
+	AppliedBy nulls.String `db:"applied_by"`
+	Duration  nulls.Int64  `db:"duration_ms"`
+}
+
+// upgradeSchemaMigrations adds the history columns to a schema_migration
+// table created by an older version of pop. It is only run by Up and
+// Down, so that reading the status does not change the schema.
+func (m Migrator) upgradeSchemaMigrations() error {
+	c := m.Connection
+	existing, err := schemaMigrationColumns(c)
+	if err != nil {
+		return errors.Wrap(err, "could not read the columns of the schema migration table")
+	}
+	for _, col := range schemaMigrations.Columns[1:] {
+		if existing[col.Name] {
+			continue
+		}
+		stmt, err := c.Dialect.FizzTranslator().AddColumn(fizz.Table{Name: schemaMigrations.Name, Columns: []fizz.Column{col}})
+		if err != nil {
+			return errors.Wrapf(err, "could not build SQL for the %s column of the schema migration table", col.Name)
+		}
+		if _, err = c.Store.Exec(stmt); err != nil {
+			return errors.Wrap(err, stmt)
+		}
+	}
+	return nil
+}
+
+// schemaMigrationColumns returns the names of the columns of the
+// schema_migration table. They are read from the schema metadata, as a
+// failing query would abort the transaction on PostgreSQL.
+func schemaMigrationColumns(c *Connection) (map[string]bool, error) {
+	names := []string{}
+	var err error
+	switch c.Dialect.Details().Dialect {
+	case "sqlite3":
+		err = c.RawQuery("SELECT name FROM pragma_table_info(?)", schemaMigrations.Name).All(&names)
+	case "mysql":
+		err = c.RawQuery("SELECT column_name FROM information_schema.columns WHERE table_schema = DATABASE() AND table_name = ?", schemaMigrations.Name).All(&names)
+	default:
+		err = c.RawQuery("SELECT column_name FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = ?", schemaMigrations.Name).All(&names)
+	}
+	if err != nil {
+		return nil, err
+	}
+	cols := map[string]bool{}
+	for _, n := range names {
+		cols[n] = true
+	}
+	return cols, nil
+}
+
+// recordMigration inserts the schema_migration row of a migration applied
+// in d.
+func recordMigration(tx *Connection, version string, d time.Duration) error {
+	return tx.RawQuery("insert into schema_migration (version, applied_at, applied_by, duration_ms) values (?, ?, ?, ?)",
+		version, time.Now(), migrationAppliedBy(), int64(d/time.Millisecond)).Exec()
+}
+
+// migrationAppliedBy returns the user and the host applying migrations,
+// e.g. "deploy@web-1".
+func migrationAppliedBy() string {
+	name := os.Getenv("USER")
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	host, _ := os.Hostname()
+	return name + "@" + host
+}
+
+// VerboseStatus prints out the status of the migrations, along with when,
+// by whom and in how long the applied ones were run.
+func (m Migrator) VerboseStatus() error {
+	return m.status(os.Stdout, true)
+}
+
+func (m Migrator) status(out io.Writer, verbose bool) error {
+	err := m.CreateSchemaMigrations()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	// the history columns are missing until Up or Down upgrades the table.
+	existing, err := schemaMigrationColumns(m.Connection)
+	if err != nil {
+		return errors.Wrapf(err, "problem with migration")
+	}
+	cols := []string{"version"}
+	for _, col := range schemaMigrations.Columns[1:] {
+		if existing[col.Name] {
+			cols = append(cols, col.Name)
+		}
+	}
+	records := []migrationRecord{}
+	if err = m.Connection.Store.Select(&records, fmt.Sprintf("select %s from schema_migration", strings.Join(cols, ", "))); err != nil {
+		return errors.Wrapf(err, "problem with migration")
+	}
+	applied := map[string]migrationRecord{}
+	for _, r := range records {
+		applied[r.Version] = r
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', tabwriter.TabIndent)
+	if verbose {
+		fmt.Fprintln(w, "Version\tName\tStatus\tApplied At\tApplied By\tDuration\t")
+	} else {
+		fmt.Fprintln(w, "Version\tName\tStatus\t")
+	}
+	for _, mf := range m.Migrations["up"] {
+		r, ok := applied[mf.Version]
+		state := "Pending"
+		if ok {
+			state = "Applied"
+		}
+		if !verbose {
+			fmt.Fprintf(w, "%s\t%s\t%s\t\n", mf.Version, mf.Name, state)
+			continue
+		}
+		at, by, d := "", "", ""
+		if r.AppliedAt.Valid {
+			at = r.AppliedAt.Time.Format(time.RFC3339)
+		}
+		if r.AppliedBy.Valid {
+			by = r.AppliedBy.String
+		}
+		if r.Duration.Valid {
+			d = (time.Duration(r.Duration.Int64) * time.Millisecond).String()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t\n", mf.Version, mf.Name, state, at, by, d)
+	}
+	return w.Flush()
+}
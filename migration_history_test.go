@@ -0,0 +1,69 @@
+// +build !nosqlite,!appengine,!appenginevm
+
+package pop
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Migrator_History(t *testing.T) {
+	r := require.New(t)
+
+	dir, err := ioutil.TempDir("", "pop")
+	r.NoError(err)
+	defer os.RemoveAll(dir)
+
+	c, err := NewConnection(&ConnectionDetails{Dialect: "sqlite3", Database: filepath.Join(dir, "history.sqlite")})
+	r.NoError(err)
+	r.NoError(c.Open())
+	defer c.Close()
+
+	// a schema_migration table created by an older version of pop.
+	_, err = c.Store.Exec(`CREATE TABLE "schema_migration" ("version" TEXT NOT NULL)`)
+	r.NoError(err)
+	_, err = c.Store.Exec(`INSERT INTO "schema_migration" ("version") VALUES ('1')`)
+	r.NoError(err)
+
+	m := NewMigrator(c)
+	noop := func(Migration, *Connection) error { return nil }
+	m.Migrations["up"] = Migrations{
+		{Version: "1", Name: "old", Direction: "up", Runner: noop},
+		{Version: "2", Name: "new", Direction: "up", Runner: noop},
+	}
+
+	// reading the status does not upgrade the table.
+	out := &bytes.Buffer{}
+	r.NoError(m.status(out, true))
+	r.Contains(out.String(), "Applied")
+	cols, err := schemaMigrationColumns(c)
+	r.NoError(err)
+	r.Equal(map[string]bool{"version": true}, cols)
+
+	r.NoError(m.Up())
+	cols, err = schemaMigrationColumns(c)
+	r.NoError(err)
+	r.Len(cols, 4)
+
+	records := []migrationRecord{}
+	r.NoError(c.Store.Select(&records, "select version, applied_at, applied_by, duration_ms from schema_migration order by version"))
+	r.Len(records, 2)
+	r.False(records[0].AppliedAt.Valid)
+	r.True(records[1].AppliedAt.Valid)
+	r.Equal(migrationAppliedBy(), records[1].AppliedBy.String)
+	r.True(records[1].Duration.Valid)
+
+	out.Reset()
+	r.NoError(m.status(out, true))
+	r.Contains(out.String(), "Applied By")
+	r.Contains(out.String(), migrationAppliedBy())
+
+	out.Reset()
+	r.NoError(m.status(out, false))
+	r.NotContains(out.String(), "Applied By")
+}
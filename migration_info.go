@@ -16,6 +16,15 @@ type Migration struct {
 	Type string
 	// Runner function to run/execute the migration
 	Runner func(Migration, *Connection) error
+	// DependsOn are the versions of the migrations this one depends on,
+	// which `Migrator.Up` applies before it when it runs migrations
+	// concurrently. A nil DependsOn depends on all of the migrations
+	// before this one, an empty one on none of them. Migration files
+	// declare them on lines of their own:
+	//
+	//	-- pop:depends_on 20180301100000 20180302100000
+	//	-- pop:independent
+	DependsOn []string
 }
 
 // Run the migration. Returns an error if there is
@@ -0,0 +1,143 @@
+package pop
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// LintIssue is a dangerous operation of a pending migration, which can
+// lock or rewrite a table, or fail once the table has rows.
+type LintIssue struct {
+	Migration Migration
+	// Statement is the SQL statement of the operation.
+	Statement string
+	// Message tells what is dangerous about the operation.
+	Message string
+}
+
+func (i LintIssue) String() string {
+	return fmt.Sprintf("%s_%s: %s\n\t%s", i.Migration.Version, i.Migration.Name, i.Message, i.Statement)
+}
+
+// lintRule matches the statements of the dialects it applies to, all of
+// them if there are none, unless they match skip.
+type lintRule struct {
+	dialects []string
+	match    *regexp.Regexp
+	skip     *regexp.Regexp
+	message  string
+}
+
+// the first group of the match of a rule is the table of the statement,
+// which is not checked if the migration creates it.
+var lintRules = []lintRule{
+	{
+		match:   regexp.MustCompile(`(?is)^ALTER TABLE\s+(\S+)\s+ADD COLUMN\b.*\bNOT NULL\b`),
+		skip:    regexp.MustCompile(`(?i)\bDEFAULT\b`),
+		message: "adds a NOT NULL column without a default, which fails if the table has rows",
+	},
+	{
+		dialects: []string{"postgres"},
+		match:    regexp.MustCompile(`(?is)^ALTER TABLE\s+(\S+)\s+.*\bALTER COLUMN\s+\S+\s+TYPE\b`),
+		message:  "changes the type of a column, which rewrites the table under an exclusive lock",
+	},
+	{
+		dialects: []string{"mysql"},
+		match:    regexp.MustCompile(`(?is)^ALTER TABLE\s+(\S+)\s+(MODIFY|CHANGE)\b`),
+		message:  "modifies a column, which copies the table unless the change can be made in place",
+	},
+	{
+		dialects: []string{"postgres"},
+		match:    regexp.MustCompile(`(?is)^CREATE\s+(?:UNIQUE\s+)?INDEX\s+.*?\bON\s+(\S+)`),
+		skip:     regexp.MustCompile(`(?i)\bCONCURRENTLY\b`),
+		message:  "creates an index without CONCURRENTLY, which blocks the writes to the table while it is built",
+	},
+}
+
+var lintCreateTable = regexp.MustCompile(`(?is)^CREATE TABLE\s+(?:IF NOT EXISTS\s+)?(\S+)`)
+
+// lintSQL returns the issues of the statements of content for dialect.
+func lintSQL(dialect string, mf Migration, content string) []LintIssue {
+	issues := []LintIssue{}
+	created := map[string]bool{}
+	for _, stmt := range strings.Split(content, ";") {
+		stmt = strings.TrimSpace(stmt)
+		if m := lintCreateTable.FindStringSubmatch(stmt); m != nil {
+			created[lintTableName(m[1])] = true
+			continue
+		}
+		for _, r := range lintRules {
+			if len(r.dialects) > 0 && !containsString(r.dialects, dialect) {
+				continue
+			}
+			m := r.match.FindStringSubmatch(stmt)
+			if m == nil || created[lintTableName(m[1])] || (r.skip != nil && r.skip.MatchString(stmt)) {
+				continue
+			}
+			issues = append(issues, LintIssue{Migration: mf, Statement: stmt, Message: r.message})
+		}
+	}
+	return issues
+}
+
+func lintTableName(name string) string {
+	return strings.Trim(name, "\"`(")
+}
+
+// lint returns the issues of the pending up migrations, whose content is
+// read with open.
+func (m Migrator) lint(open func(Migration) (io.Reader, error)) ([]LintIssue, error) {
+	if err := m.CreateSchemaMigrations(); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	c := m.Connection
+	mfs := m.Migrations["up"]
+	sort.Sort(mfs)
+	issues := []LintIssue{}
+	for _, mf := range mfs {
+		exists, err := c.Where("version = ?", mf.Version).Exists("schema_migration")
+		if err != nil {
+			return nil, errors.Wrapf(err, "problem checking for migration version %s", mf.Version)
+		}
+		if exists {
+			continue
+		}
+		r, err := open(mf)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		content, err := migrationContent(mf, c, r)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error processing %s", mf.Path)
+		}
+		issues = append(issues, lintSQL(c.Dialect.Details().Dialect, mf, content)...)
+	}
+	return issues, nil
+}
+
+// Lint returns the dangerous operations of the pending migrations, such
+// as the NOT NULL columns added without a default, the column type
+// changes rewriting a table, or the indexes built without CONCURRENTLY
+// on PostgreSQL. The migrations are not run.
+func (fm FileMigrator) Lint() ([]LintIssue, error) {
+	return fm.lint(func(mf Migration) (io.Reader, error) {
+		b, err := ioutil.ReadFile(mf.Path)
+		return bytes.NewReader(b), err
+	})
+}
+
+// Lint returns the dangerous operations of the pending migrations, see
+// `FileMigrator.Lint`.
+func (fm MigrationBox) Lint() ([]LintIssue, error) {
+	return fm.lint(func(mf Migration) (io.Reader, error) {
+		b, err := fm.Box.MustBytes(mf.Path)
+		return bytes.NewReader(b), err
+	})
+}
@@ -0,0 +1,39 @@
+package pop
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_lintSQL(t *testing.T) {
+	r := require.New(t)
+	mf := Migration{Version: "20180101000000", Name: "users"}
+
+	content := `CREATE TABLE "users" (
+"id" SERIAL PRIMARY KEY,
+"name" VARCHAR (255) NOT NULL
+);
+CREATE INDEX "users_name_idx" ON "users" (name);
+ALTER TABLE "users" ADD COLUMN "email" VARCHAR (255) NOT NULL;
+ALTER TABLE "posts" ADD COLUMN "title" VARCHAR (255) NOT NULL;
+ALTER TABLE "posts" ADD COLUMN "status" VARCHAR (255) NOT NULL DEFAULT 'draft';
+ALTER TABLE "posts" ADD COLUMN "body" text;
+ALTER TABLE "posts" ALTER COLUMN "title" TYPE text;
+CREATE UNIQUE INDEX "posts_title_idx" ON "posts" (title);
+CREATE INDEX CONCURRENTLY posts_status_idx ON posts (status);`
+
+	issues := lintSQL("postgres", mf, content)
+	r.Len(issues, 3)
+	r.Equal(`ALTER TABLE "posts" ADD COLUMN "title" VARCHAR (255) NOT NULL`, issues[0].Statement)
+	r.Contains(issues[0].Message, "NOT NULL column without a default")
+	r.Contains(issues[1].Message, "changes the type")
+	r.Contains(issues[2].Message, "without CONCURRENTLY")
+	r.Equal(mf, issues[0].Migration)
+
+	issues = lintSQL("mysql", mf, "ALTER TABLE posts MODIFY title text NOT NULL;\nCREATE INDEX posts_title_idx ON posts (title);")
+	r.Len(issues, 1)
+	r.Contains(issues[0].Message, "copies the table")
+
+	r.Empty(lintSQL("sqlite3", mf, `CREATE INDEX "posts_title_idx" ON "posts" (title);`))
+}
@@ -6,7 +6,6 @@ import (
 	"path/filepath"
 	"regexp"
 	"sort"
-	"text/tabwriter"
 	"time"
 
 	"github.com/pkg/errors"
@@ -36,6 +35,10 @@ type Migrator struct {
 	Connection *Connection
 	SchemaPath string
 	Migrations map[string]Migrations
+	// Concurrency is the number of migrations Up applies at once, see
+	// `Migration.DependsOn`. The migrations are applied one at a time
+	// unless it is greater than 1.
+	Concurrency int
 }
 
 // Up runs pending "up" migrations and applies them to the database.
@@ -44,26 +47,23 @@ func (m Migrator) Up() error {
 	return m.exec(func() error {
 		mfs := m.Migrations["up"]
 		sort.Sort(mfs)
+		pending := Migrations{}
 		for _, mi := range mfs {
 			exists, err := c.Where("version = ?", mi.Version).Exists("schema_migration")
 			if err != nil {
 				return errors.Wrapf(err, "problem checking for migration version %s", mi.Version)
 			}
-			if exists {
-				continue
+			if !exists {
+				pending = append(pending, mi)
 			}
-			err = c.Transaction(func(tx *Connection) error {
-				err := mi.Run(tx)
-				if err != nil {
-					return err
-				}
-				_, err = tx.Store.Exec(fmt.Sprintf("insert into schema_migration (version) values ('%s')", mi.Version))
-				return errors.Wrapf(err, "problem inserting migration version %s", mi.Version)
-			})
-			if err != nil {
-				return errors.WithStack(err)
+		}
+		if m.Concurrency > 1 {
+			return m.upConcurrently(pending)
+		}
+		for _, mi := range pending {
+			if err := m.upOne(mi); err != nil {
+				return err
 			}
-			fmt.Printf("> %s\n", mi.Name)
 		}
 		return nil
 	})
@@ -93,7 +93,7 @@ func (m Migrator) Down(step int) error {
 			if err != nil || !exists {
 				return errors.Wrapf(err, "problem checking for migration version %s", mi.Version)
 			}
-			err = c.Transaction(func(tx *Connection) error {
+			err = m.migrationTransaction(func(tx *Connection) error {
 				err := mi.Run(tx)
 				if err != nil {
 					return err
@@ -111,6 +111,16 @@ func (m Migrator) Down(step int) error {
 	})
 }
 
+// migrationTransaction runs fn in a transaction, except on TiDB where DDL
+// statements can not be part of a transaction.
+func (m Migrator) migrationTransaction(fn func(tx *Connection) error) error {
+	c := m.Connection
+	if c.Dialect.Details().TiDB() {
+		return fn(c)
+	}
+	return c.Transaction(fn)
+}
+
 // Reset the database by runing the down migrations followed by the up migrations.
 func (m Migrator) Reset() error {
 	err := m.Down(-1)
@@ -133,7 +143,7 @@ func (m Migrator) CreateSchemaMigrations() error {
 		return nil
 	}
 
-	return c.Transaction(func(tx *Connection) error {
+	return m.migrationTransaction(func(tx *Connection) error {
 		smSQL, err := c.Dialect.FizzTranslator().CreateTable(schemaMigrations)
 		if err != nil {
 			return errors.Wrap(err, "could not build SQL for schema migration table")
@@ -148,24 +158,7 @@ func (m Migrator) CreateSchemaMigrations() error {
 
 // Status prints out the status of applied/pending migrations.
 func (m Migrator) Status() error {
-	err := m.CreateSchemaMigrations()
-	if err != nil {
-		return errors.WithStack(err)
-	}
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', tabwriter.TabIndent)
-	fmt.Fprintln(w, "Version\tName\tStatus\t")
-	for _, mf := range m.Migrations["up"] {
-		exists, err := m.Connection.Where("version = ?", mf.Version).Exists("schema_migration")
-		if err != nil {
-			return errors.Wrapf(err, "problem with migration")
-		}
-		state := "Pending"
-		if exists {
-			state = "Applied"
-		}
-		fmt.Fprintf(w, "%s\t%s\t%s\t\n", mf.Version, mf.Name, state)
-	}
-	return w.Flush()
+	return m.status(os.Stdout, false)
 }
 
 // DumpMigrationSchema will generate a file of the current database schema
@@ -196,6 +189,9 @@ func (m Migrator) exec(fn func() error) error {
 	if err != nil {
 		return errors.Wrap(err, "Migrator: problem creating schema migrations")
 	}
+	if err = m.upgradeSchemaMigrations(); err != nil {
+		return errors.Wrap(err, "Migrator: problem upgrading schema migrations")
+	}
 	return fn()
 }
 
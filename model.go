@@ -40,10 +40,22 @@ type Model struct {
 	Value
 	tableName string
 	As        string
+	// batched models have their uniqueness checked all at once
+	batched bool
+	// rowsAffected is the number of rows changed by the last update or
+	// delete of the model
+	rowsAffected int64
+	// checkLockVersion makes the update of the model match its row by
+	// its lock_version too
+	checkLockVersion bool
+	// checkNotDeleted makes the update of the model only match its row
+	// if it is not soft deleted yet
+	checkNotDeleted bool
 }
 
-// ID returns the ID of the Model. All models must have an `ID` field this is
-// of type `int`,`int64` or of type `uuid.UUID`.
+// ID returns the ID of the Model. All models must have an `ID` field of an
+// integer or string type, such as `int64` or `type SKU string`, or of type
+// `uuid.UUID`.
 func (m *Model) ID() interface{} {
 	fbn, err := m.fieldByName("ID")
 	if err != nil {
@@ -55,13 +67,28 @@ func (m *Model) ID() interface{} {
 	return fbn.Interface()
 }
 
-// PrimaryKeyType gives the primary key type of the `Model`.
+// PrimaryKeyType gives the primary key type of the `Model`: "int" or
+// "int64" for the integer types, "string" for the string types, and "UUID"
+// for `uuid.UUID`. Typed ids, such as `type UserID int64`, are reported by
+// their underlying type.
 func (m *Model) PrimaryKeyType() string {
 	fbn, err := m.fieldByName("ID")
 	if err != nil {
 		return "int"
 	}
-	return fbn.Type().Name()
+	return primaryKeyType(fbn.Type())
+}
+
+func primaryKeyType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Int:
+		return "int"
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "int64"
+	case reflect.String:
+		return "string"
+	}
+	return t.Name()
 }
 
 // TableNameAble interface allows for the customize table mapping
@@ -128,7 +155,11 @@ func (m *Model) typeName(t reflect.Type) string {
 }
 
 func (m *Model) fieldByName(s string) (reflect.Value, error) {
-	el := reflect.ValueOf(m.Value).Elem()
+	v := reflect.ValueOf(m.Value)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, errors.Errorf("Model %T is not a pointer to a struct", m.Value)
+	}
+	el := v.Elem()
 	fbn := el.FieldByName(s)
 	if !fbn.IsValid() {
 		return fbn, errors.Errorf("Model does not have a field named %s", s)
@@ -146,10 +177,12 @@ func (m *Model) setID(i interface{}) {
 	if err == nil {
 		v := reflect.ValueOf(i)
 		switch fbn.Kind() {
-		case reflect.Int, reflect.Int64:
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 			fbn.SetInt(v.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			fbn.SetUint(uint64(v.Int()))
 		default:
-			fbn.Set(reflect.ValueOf(i))
+			fbn.Set(v.Convert(fbn.Type()))
 		}
 	}
 }
@@ -168,14 +201,15 @@ func (m *Model) touchUpdatedAt() {
 	}
 }
 
-func (m *Model) whereID() string {
-	id := m.ID()
-	var value string
-	switch id.(type) {
-	case int, int64:
-		value = fmt.Sprintf("%s.id = %d", m.TableName(), id)
-	default:
-		value = fmt.Sprintf("%s.id ='%s'", m.TableName(), id)
+// whereID matches the model by its id, bound as the named parameter
+// ":id" so it goes through the value converters.
+func (m *Model) whereID(q quoter) string {
+	where := fmt.Sprintf("%s = :id", q.Quote(m.TableName()+".id"))
+	if m.checkLockVersion {
+		where += " AND " + m.whereLockVersion(q)
+	}
+	if m.checkNotDeleted {
+		where += " AND " + q.Quote(m.TableName()+".deleted_at") + " IS NULL"
 	}
-	return value
+	return where
 }
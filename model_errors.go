@@ -0,0 +1,195 @@
+package pop
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/markbates/inflect"
+	"github.com/markbates/pop/columns"
+)
+
+// ModelError describes why a value can not be used as a model.
+type ModelError struct {
+	// Model is the Go type of the model
+	Model string
+	// Field is the name of the offending field, if any
+	Field string
+	// Reason describes the problem
+	Reason string
+}
+
+func (e *ModelError) Error() string {
+	if e.Field != "" {
+		return fmt.Sprintf("model %s, field %s: %s", e.Model, e.Field, e.Reason)
+	}
+	return fmt.Sprintf("model %s: %s", e.Model, e.Reason)
+}
+
+// ModelErrors collects all of the problems found on a model.
+type ModelErrors []*ModelError
+
+func (e ModelErrors) Error() string {
+	xs := make([]string, 0, len(e))
+	for _, err := range e {
+		xs = append(xs, err.Error())
+	}
+	return strings.Join(xs, "; ")
+}
+
+// ValidateModel checks that model can be used with pop, and returns the
+// problems found as `ModelErrors`: a model must be a pointer to a struct,
+// with an ID field of an integer or string type, or uuid.UUID, exported fields for
+// all of its db columns, no column mapped twice, and well formed
+// associations. It is meant to be used in init functions or tests.
+//
+//	func init() {
+//		if err := pop.ValidateModel(&User{}); err != nil {
+//			log.Fatal(err)
+//		}
+//	}
+func ValidateModel(model interface{}) error {
+	if err := checkModel(model); err != nil {
+		return ModelErrors{err.(*ModelError)}
+	}
+	t := reflect.TypeOf(model).Elem()
+	errs := ModelErrors{}
+	errs = append(errs, modelTypeErrors(t)...)
+
+	if f, ok := t.FieldByName("ID"); !ok {
+		errs = append(errs, &ModelError{Model: t.String(), Reason: "does not have an ID field"})
+	} else if n, kt := f.Type.String(), primaryKeyType(f.Type); kt != "int" && kt != "int64" && kt != "string" && n != "uuid.UUID" {
+		errs = append(errs, &ModelError{Model: t.String(), Field: "ID", Reason: fmt.Sprintf("is a %s, the primary key must be an integer, a string or a uuid.UUID", n)})
+	} else if seq := columns.TagsFor(f).Find("sequence"); !seq.Empty() && kt != "int" && kt != "int64" {
+		errs = append(errs, &ModelError{Model: t.String(), Field: "ID", Reason: fmt.Sprintf("is a %s, only int ids can be taken from the sequence %s", n, seq.Value)})
+	}
+
+	seen := map[string]string{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tags := columns.TagsFor(f)
+		db := tags.Find("db")
+		if !associationTagged(tags) && !db.Empty() && !db.Ignored() && f.PkgPath == "" {
+			if other, ok := seen[db.Value]; ok {
+				errs = append(errs, &ModelError{Model: t.String(), Field: f.Name, Reason: fmt.Sprintf("maps the column %s, already mapped by %s", db.Value, other)})
+			}
+			seen[db.Value] = f.Name
+		}
+		errs = append(errs, associationErrors(t, f, tags)...)
+		if tag, ok := f.Tag.Lookup("validate"); ok {
+			if _, err := parseValidateTag(tag); err != nil {
+				errs = append(errs, &ModelError{Model: t.String(), Field: f.Name, Reason: err.Error()})
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func associationTagged(tags columns.Tags) bool {
+	for _, name := range []string{"belongs_to", "has_many", "has_one", "many_to_many"} {
+		if !tags.Find(name).Empty() {
+			return true
+		}
+	}
+	return false
+}
+
+func associationErrors(t reflect.Type, f reflect.StructField, tags columns.Tags) ModelErrors {
+	errs := ModelErrors{}
+	ft := f.Type
+	if ft.Kind() == reflect.Ptr {
+		ft = ft.Elem()
+	}
+	if !tags.Find("belongs_to").Empty() {
+		fk := fmt.Sprintf("%sID", inflect.Capitalize(ft.Name()))
+		if _, ok := t.FieldByName(fk); !ok {
+			errs = append(errs, &ModelError{Model: t.String(), Field: f.Name, Reason: fmt.Sprintf("belongs_to needs a %s field", fk)})
+		}
+	}
+	for _, name := range []string{"has_many", "many_to_many"} {
+		if !tags.Find(name).Empty() && ft.Kind() != reflect.Slice && ft.Kind() != reflect.Array {
+			errs = append(errs, &ModelError{Model: t.String(), Field: f.Name, Reason: fmt.Sprintf("%s needs a slice, not a %s", name, f.Type)})
+		}
+	}
+	return errs
+}
+
+// checkModel returns a *ModelError if model is not a non nil pointer to
+// a struct, or has fields pop can not map.
+func checkModel(model interface{}) error {
+	if model == nil {
+		return &ModelError{Model: "nil", Reason: "a model must be a pointer to a struct"}
+	}
+	v := reflect.ValueOf(model)
+	if v.Kind() != reflect.Ptr || v.Type().Elem().Kind() != reflect.Struct {
+		return &ModelError{Model: v.Type().String(), Reason: "a model must be a pointer to a struct"}
+	}
+	if v.IsNil() {
+		return &ModelError{Model: v.Type().String(), Reason: "a model can not be a nil pointer"}
+	}
+	if errs := modelTypeErrors(v.Type().Elem()); len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// checkModels returns a *ModelError if models is not a non nil pointer
+// to a slice of models.
+func checkModels(models interface{}) error {
+	if models == nil {
+		return &ModelError{Model: "nil", Reason: "models must be a pointer to a slice"}
+	}
+	v := reflect.ValueOf(models)
+	if v.Kind() != reflect.Ptr || (v.Type().Elem().Kind() != reflect.Slice && v.Type().Elem().Kind() != reflect.Array) {
+		return &ModelError{Model: v.Type().String(), Reason: "models must be a pointer to a slice"}
+	}
+	if v.IsNil() {
+		return &ModelError{Model: v.Type().String(), Reason: "models can not be a nil pointer"}
+	}
+	el := v.Type().Elem().Elem()
+	if el.Kind() == reflect.Ptr {
+		el = el.Elem()
+	}
+	if el.Kind() != reflect.Struct {
+		return nil
+	}
+	if errs := modelTypeErrors(el); len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+var modelTypeErrorsCache = map[reflect.Type]ModelErrors{}
+var modelTypeErrorsMu = sync.RWMutex{}
+
+// modelTypeErrors returns the fields of the struct t pop can not map,
+// namely the unexported fields with a db tag.
+func modelTypeErrors(t reflect.Type) ModelErrors {
+	modelTypeErrorsMu.RLock()
+	errs, ok := modelTypeErrorsCache[t]
+	modelTypeErrorsMu.RUnlock()
+	if ok {
+		return errs
+	}
+
+	errs = ModelErrors{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath == "" || f.Anonymous {
+			continue
+		}
+		if db, ok := f.Tag.Lookup("db"); ok && db != "-" {
+			errs = append(errs, &ModelError{Model: t.String(), Field: f.Name, Reason: "has a db tag but is not exported"})
+		}
+	}
+
+	modelTypeErrorsMu.Lock()
+	modelTypeErrorsCache[t] = errs
+	modelTypeErrorsMu.Unlock()
+	return errs
+}
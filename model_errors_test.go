@@ -0,0 +1,80 @@
+package pop_test
+
+import (
+	"testing"
+
+	"github.com/markbates/pop"
+	"github.com/stretchr/testify/require"
+)
+
+type unexportedColumn struct {
+	ID   int    `db:"id"`
+	name string `db:"name"`
+}
+
+type badModel struct {
+	ID     float64 `db:"id"`
+	Email  string  `db:"email"`
+	Mail   string  `db:"email"`
+	Author User    `belongs_to:"user"`
+	Books  Book    `has_many:"books"`
+	Ignore string  `db:"-"`
+	Other  string  `db:"-"`
+}
+
+func Test_ValidateModel(t *testing.T) {
+	r := require.New(t)
+
+	r.NoError(pop.ValidateModel(&User{}))
+	r.NoError(pop.ValidateModel(&Book{}))
+	r.NoError(pop.ValidateModel(&Song{}))
+
+	err := pop.ValidateModel(User{})
+	r.Error(err)
+	r.Contains(err.Error(), "model pop_test.User: a model must be a pointer to a struct")
+
+	err = pop.ValidateModel(&unexportedColumn{})
+	r.Error(err)
+	r.Equal("model pop_test.unexportedColumn, field name: has a db tag but is not exported", err.Error())
+
+	err = pop.ValidateModel(&badModel{})
+	r.Error(err)
+	errs, ok := err.(pop.ModelErrors)
+	r.True(ok)
+	r.Len(errs, 4)
+	r.Equal("ID", errs[0].Field)
+	r.Equal("Mail", errs[1].Field)
+	r.Contains(errs[1].Reason, "already mapped by Email")
+	r.Equal("Author", errs[2].Field)
+	r.Contains(errs[2].Reason, "UserID")
+	r.Equal("Books", errs[3].Field)
+}
+
+func Test_ModelErrors_Executors(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		err := tx.Create(User{})
+		r.Error(err)
+		_, ok := err.(*pop.ModelError)
+		r.True(ok)
+
+		var u *User
+		err = tx.Find(u, 1)
+		r.Error(err)
+		r.Contains(err.Error(), "nil pointer")
+
+		r.Error(tx.Update(nil))
+		r.Error(tx.Destroy(&[]string{"a"}))
+		r.NoError(tx.Destroy(&[]User{}))
+
+		var users *Users
+		err = tx.All(users)
+		r.Error(err)
+		r.Contains(err.Error(), "model *pop_test.Users: models can not be a nil pointer")
+		r.Error(tx.All(Users{}))
+
+		err = tx.Create(&unexportedColumn{})
+		r.Error(err)
+		r.Contains(err.Error(), "field name: has a db tag but is not exported")
+	})
+}
@@ -1,11 +1,15 @@
 package pop
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	// Load MySQL Go driver
 	_ "github.com/go-sql-driver/mysql"
@@ -20,41 +24,291 @@ var _ dialect = &mysql{}
 
 type mysql struct {
 	ConnectionDetails *ConnectionDetails
+	version           string
+	versionMu         sync.Mutex
 }
 
 func (m *mysql) Details() *ConnectionDetails {
 	return m.ConnectionDetails
 }
 
+// Quote quotes the identifier key, e.g. "users.name", if it is a reserved
+// word or if the "quote_identifiers" option is set.
+func (m *mysql) Quote(key string) string {
+	return quoteIdentifier(m.ConnectionDetails, "`", "`", key)
+}
+
 func (m *mysql) URL() string {
 	c := m.ConnectionDetails
 	if m.ConnectionDetails.URL != "" {
-		return strings.TrimPrefix(m.ConnectionDetails.URL, "mysql://")
+		return trimMySQLScheme(m.ConnectionDetails.URL)
 	}
-	s := "%s:%s@(%s:%s)/%s?parseTime=true&multiStatements=true&readTimeout=1s"
+	s := "%s:%s@(%s:%s)/%s?parseTime=true&multiStatements=true&readTimeout=1s&clientFoundRows=true"
 	return fmt.Sprintf(s, c.User, c.Password, c.Host, c.Port, c.Database)
 }
 
 func (m *mysql) urlWithoutDb() string {
 	c := m.ConnectionDetails
-	s := "%s:%s@(%s:%s)/?parseTime=true&multiStatements=true&readTimeout=1s"
+	s := "%s:%s@(%s:%s)/?parseTime=true&multiStatements=true&readTimeout=1s&clientFoundRows=true"
 	return fmt.Sprintf(s, c.User, c.Password, c.Host, c.Port)
 }
 
+// tagQuery prefixes query with a comment holding the application name,
+// the MySQL driver having no way to set program_name: it shows up in the
+// process list along with the query.
+func (m *mysql) tagQuery(name string, query string) string {
+	return fmt.Sprintf("/* %s */ %s", strings.Replace(name, "*/", "", -1), query)
+}
+
+func (m *mysql) setApplicationName(c *Connection, name string) error {
+	c.appName = name
+	return nil
+}
+
 func (m *mysql) MigrationURL() string {
 	return m.URL()
 }
 
 func (m *mysql) Create(s store, model *Model, cols columns.Columns) error {
-	return errors.Wrap(genericCreate(s, model, cols), "mysql create")
+	keyType := model.PrimaryKeyType()
+	if (keyType == "int" || keyType == "int64") && m.Details().MariaDB() && mariaDBReturning(m.serverVersion(s)) {
+		// MariaDB 10.5+ can return the generated id, saving the
+		// LAST_INSERT_ID round trip.
+		cols.Remove("id")
+		id := struct {
+			ID int `db:"id"`
+		}{}
+		w := cols.Writeable()
+		query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) RETURNING id", m.Quote(model.TableName()), w.QuotedString(m.Quote), w.SymbolizedString())
+		if err := namedGet(s, &id, query, model.Value); err != nil {
+			return errors.Wrap(err, "mariadb create")
+		}
+		model.setID(id.ID)
+		return nil
+	}
+	return errors.Wrap(genericCreate(s, model, cols, m), "mysql create")
+}
+
+// serverVersion returns the VERSION() of the server, it is only asked
+// once per dialect, unless asking fails. MariaDB versions contain
+// "MariaDB", e.g. "10.5.8-MariaDB-1:10.5.8+maria~focal".
+func (m *mysql) serverVersion(s store) string {
+	m.versionMu.Lock()
+	defer m.versionMu.Unlock()
+	if m.version == "" {
+		if err := s.Get(&m.version, "SELECT VERSION()"); err != nil {
+			m.version = ""
+			logMessage(nil, LogWarn, fmt.Sprintf("could not get the MySQL server version: %s", err))
+		}
+	}
+	return m.version
+}
+
+// knownVersion returns the server version, without asking it: it is
+// "unknown" until serverVersion succeeds.
+func (m *mysql) knownVersion() string {
+	m.versionMu.Lock()
+	defer m.versionMu.Unlock()
+	if m.version == "" {
+		return "unknown"
+	}
+	return m.version
+}
+
+// mariaDBReturning returns true for MariaDB versions supporting
+// INSERT ... RETURNING (10.5 and later).
+func mariaDBReturning(version string) bool {
+	if !strings.Contains(version, "MariaDB") {
+		return false
+	}
+	var major, minor int
+	fmt.Sscanf(version, "%d.%d", &major, &minor)
+	return major > 10 || (major == 10 && minor >= 5)
+}
+
+// upsert updates the row conflicting on any unique index of the table,
+// whatever the conflict columns.
+func (m *mysql) upsert(s store, model *Model, cols, update, conflict []string) error {
+	sets := make([]string, len(update))
+	for i, name := range update {
+		sets[i] = fmt.Sprintf("%s = VALUES(%s)", m.Quote(name), m.Quote(name))
+	}
+	if len(sets) == 0 {
+		sets = []string{fmt.Sprintf("%s = %s", m.Quote(conflict[0]), m.Quote(conflict[0]))}
+	}
+	query := fmt.Sprintf("%s ON DUPLICATE KEY UPDATE %s", upsertInsert(model, cols, m), strings.Join(sets, ", "))
+	_, err := s.NamedExec(query, model.Value)
+	return errors.Wrap(err, "mysql upsert")
 }
 
 func (m *mysql) Update(s store, model *Model, cols columns.Columns) error {
-	return errors.Wrap(genericUpdate(s, model, cols), "mysql update")
+	return errors.Wrap(genericUpdate(s, model, cols, m), "mysql update")
 }
 
 func (m *mysql) Destroy(s store, model *Model) error {
-	return errors.Wrap(genericDestroy(s, model), "mysql destroy")
+	return errors.Wrap(genericDestroy(s, model, m), "mysql destroy")
+}
+
+// nextSequenceValue uses the native sequences of MariaDB and TiDB. On
+// MySQL, a row is inserted in the table standing for the sequence.
+func (m *mysql) nextSequenceValue(s store, name string) (int64, error) {
+	if m.Details().MariaDB() || m.Details().TiDB() {
+		var n int64
+		query := fmt.Sprintf("SELECT NEXTVAL(%s)", name)
+		err := s.Get(&n, query)
+		return n, err
+	}
+	query := fmt.Sprintf("INSERT INTO %s () VALUES ()", name)
+	res, err := s.Exec(query)
+	if err != nil {
+		return 0, err
+	}
+	return res.LastInsertId()
+}
+
+// reserveSequenceValues inserts n rows at once in the table standing for
+// the sequence, when InnoDB gives evenly spaced ids to the rows of a
+// single INSERT, see autoIncrementStep. Otherwise, and on MariaDB and
+// TiDB, one value is asked at a time.
+func (m *mysql) reserveSequenceValues(s store, name string, n int) ([]int64, error) {
+	ns := make([]int64, 0, n)
+	step, ok := int64(0), false
+	if !m.Details().MariaDB() && !m.Details().TiDB() {
+		var err error
+		if step, ok, err = m.autoIncrementStep(s); err != nil {
+			return ns, err
+		}
+	}
+	if !ok {
+		for i := 0; i < n; i++ {
+			v, err := m.nextSequenceValue(s, name)
+			if err != nil {
+				return ns, err
+			}
+			ns = append(ns, v)
+		}
+		return ns, nil
+	}
+	query := fmt.Sprintf("INSERT INTO %s () VALUES ()%s", name, strings.Repeat(", ()", n-1))
+	res, err := s.Exec(query)
+	if err != nil {
+		return ns, err
+	}
+	first, err := res.LastInsertId()
+	if err != nil {
+		return ns, err
+	}
+	for i := 0; i < n; i++ {
+		ns = append(ns, first+int64(i)*step)
+	}
+	return ns, nil
+}
+
+func (m *mysql) replicaLag(ctx context.Context, db *sqlx.DB) (time.Duration, error) {
+	query := "SHOW SLAVE STATUS"
+	logSQL(nil, query)
+	rows, err := db.QueryxContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return 0, errors.New("replication is not configured")
+	}
+	status := map[string]interface{}{}
+	if err := rows.MapScan(status); err != nil {
+		return 0, err
+	}
+	seconds, ok := status["Seconds_Behind_Master"].([]byte)
+	if !ok {
+		return 0, errors.New("replication is not running")
+	}
+	n, err := strconv.Atoi(string(seconds))
+	return time.Duration(n) * time.Second, err
+}
+
+func (m *mysql) maintain(c *Connection, tables []string) ([]MaintenanceResult, error) {
+	if len(tables) == 0 {
+		var err error
+		if tables, err = m.tableNames(c); err != nil {
+			return nil, err
+		}
+	}
+	results := []MaintenanceResult{}
+	for _, t := range tables {
+		query := fmt.Sprintf("OPTIMIZE TABLE %s", m.Quote(t))
+		rows := []struct {
+			Table   string `db:"Table"`
+			Op      string `db:"Op"`
+			MsgType string `db:"Msg_type"`
+			MsgText string `db:"Msg_text"`
+		}{}
+		if err := c.RawQuery(query).All(&rows); err != nil {
+			return results, err
+		}
+		for _, r := range rows {
+			results = append(results, MaintenanceResult{Table: t, Statement: query, Message: fmt.Sprintf("%s: %s", r.MsgType, r.MsgText)})
+		}
+	}
+	return results, nil
+}
+
+const mysqlActiveQueries = `SELECT ID AS pid, COALESCE(USER, '') AS user_name, COALESCE(DB, '') AS database_name, COALESCE(NULLIF(STATE, ''), COMMAND) AS state, COALESCE(INFO, '') AS query, TIME AS seconds
+FROM information_schema.PROCESSLIST
+WHERE COMMAND NOT IN ('Sleep', 'Daemon', 'Binlog Dump') AND ID <> CONNECTION_ID()
+ORDER BY TIME DESC`
+
+const mysqlBlockers = `SELECT waiting_pid AS blocked_pid, COALESCE(waiting_query, '') AS blocked_query, blocking_pid AS blocking_pid, COALESCE(blocking_query, '') AS blocking_query, wait_age_secs AS seconds
+FROM sys.innodb_lock_waits
+ORDER BY wait_age_secs DESC`
+
+func (m *mysql) activeQueries(ctx context.Context, db *sqlx.DB) ([]ActiveQuery, error) {
+	return selectActivity(ctx, db, mysqlActiveQueries)
+}
+
+func (m *mysql) blockers(ctx context.Context, db *sqlx.DB) ([]Blocker, error) {
+	return selectBlockers(ctx, db, mysqlBlockers)
+}
+
+func (m *mysql) cancelQuery(ctx context.Context, db *sqlx.DB, pid int64) error {
+	// KILL does not take placeholders, pid being an int it is safe to format.
+	query := fmt.Sprintf("KILL QUERY %d", pid)
+	logSQL(nil, query)
+	_, err := db.ExecContext(ctx, query)
+	return errors.WithStack(err)
+}
+
+const mysqlIndexes = `SELECT table_name AS table_name, index_name AS index_name, GROUP_CONCAT(column_name ORDER BY seq_in_index) AS columns
+FROM information_schema.statistics
+WHERE table_schema = DATABASE()
+GROUP BY table_name, index_name`
+
+const mysqlForeignKeys = `SELECT table_name AS table_name, constraint_name AS index_name, GROUP_CONCAT(column_name ORDER BY ordinal_position) AS columns
+FROM information_schema.key_column_usage
+WHERE table_schema = DATABASE() AND referenced_table_name IS NOT NULL
+GROUP BY table_name, constraint_name`
+
+// mysqlUnusedIndexes needs the performance schema, which is enabled by
+// default since MySQL 5.6.
+const mysqlUnusedIndexes = `SELECT u.object_name AS table_name, u.index_name AS index_name, '' AS columns
+FROM performance_schema.table_io_waits_summary_by_index_usage u
+WHERE u.object_schema = DATABASE() AND u.index_name IS NOT NULL AND u.index_name != 'PRIMARY' AND u.count_star = 0
+AND NOT EXISTS (
+  SELECT 1 FROM information_schema.statistics s
+  WHERE s.table_schema = u.object_schema AND s.table_name = u.object_name AND s.index_name = u.index_name AND s.non_unique = 0
+)
+ORDER BY u.object_name, u.index_name`
+
+func (m *mysql) indexes(c *Connection) ([]IndexInfo, error) {
+	return indexRows(c, mysqlIndexes)
+}
+
+func (m *mysql) foreignKeys(c *Connection) ([]IndexInfo, error) {
+	return indexRows(c, mysqlForeignKeys)
+}
+
+func (m *mysql) unusedIndexes(c *Connection) ([]IndexInfo, error) {
+	return indexRows(c, mysqlUnusedIndexes)
 }
 
 func (m *mysql) SelectOne(s store, model *Model, query Query) error {
@@ -74,7 +328,7 @@ func (m *mysql) CreateDB() error {
 	}
 	defer db.Close()
 	query := fmt.Sprintf("CREATE DATABASE `%s` DEFAULT COLLATE `utf8_general_ci`", deets.Database)
-	Log(query)
+	logSQL(nil, query)
 
 	_, err = db.Exec(query)
 	if err != nil {
@@ -94,7 +348,7 @@ func (m *mysql) DropDB() error {
 	}
 	defer db.Close()
 	query := fmt.Sprintf("DROP DATABASE `%s`", deets.Database)
-	Log(query)
+	logSQL(nil, query)
 
 	_, err = db.Exec(query)
 	if err != nil {
@@ -110,10 +364,32 @@ func (m *mysql) TranslateSQL(sql string) string {
 }
 
 func (m *mysql) FizzTranslator() fizz.Translator {
-	t := translators.NewMySQL(m.URL(), m.Details().Database)
+	var t *translators.MySQL
+	switch {
+	case m.Details().MariaDB():
+		t = translators.NewMariaDB(m.URL(), m.Details().Database)
+	case m.Details().TiDB():
+		t = translators.NewTiDB(m.URL(), m.Details().Database)
+	default:
+		t = translators.NewMySQL(m.URL(), m.Details().Database)
+	}
+	t.UUIDBinary = m.uuidBinary()
 	return t
 }
 
+// uuidBinary returns true if the "uuid_binary" option is set: the UUIDs
+// are then stored as BINARY(16), and sent to the database as bytes.
+func (m *mysql) uuidBinary() bool {
+	return m.Details().Options["uuid_binary"] == "true"
+}
+
+func (m *mysql) convertValue(v interface{}) (interface{}, bool) {
+	if m.uuidBinary() {
+		return UUIDBytes(v)
+	}
+	return v, false
+}
+
 func (m *mysql) Lock(fn func() error) error {
 	return fn()
 }
@@ -124,7 +400,7 @@ func (m *mysql) DumpSchema(w io.Writer) error {
 	if deets.Port == "socket" {
 		cmd = exec.Command("mysqldump", "-d", "-S", deets.Host, "-u", deets.User, fmt.Sprintf("--password=%s", deets.Password), deets.Database)
 	}
-	Log(strings.Join(cmd.Args, " "))
+	logMessage(nil, LogInfo, strings.Join(cmd.Args, " "))
 	cmd.Stdout = w
 	cmd.Stderr = os.Stderr
 
@@ -137,6 +413,22 @@ func (m *mysql) DumpSchema(w io.Writer) error {
 	return nil
 }
 
+func (m *mysql) backupCommand(tables []string) *exec.Cmd {
+	deets := m.Details()
+	args := []string{"-h", deets.Host, "-P", deets.Port}
+	if deets.Port == "socket" {
+		args = []string{"-S", deets.Host}
+	}
+	args = append(args, "-u", deets.User, fmt.Sprintf("--password=%s", deets.Password), deets.Database)
+	return exec.Command("mysqldump", append(args, tables...)...)
+}
+
+func (m *mysql) tableNames(c *Connection) ([]string, error) {
+	tables := []string{}
+	err := c.RawQuery("SELECT table_name FROM information_schema.tables WHERE table_schema = DATABASE() AND table_type = 'BASE TABLE' ORDER BY table_name").All(&tables)
+	return tables, err
+}
+
 func (m *mysql) LoadSchema(r io.Reader) error {
 	deets := m.Details()
 	cmd := exec.Command("mysql", "-u", deets.User, fmt.Sprintf("--password=%s", deets.Password), "-h", deets.Host, "-P", deets.Port, "-D", deets.Database)
@@ -151,7 +443,7 @@ func (m *mysql) LoadSchema(r io.Reader) error {
 		defer in.Close()
 		io.Copy(in, r)
 	}()
-	Log(strings.Join(cmd.Args, " "))
+	logMessage(nil, LogInfo, strings.Join(cmd.Args, " "))
 	err = cmd.Start()
 	if err != nil {
 		return err
@@ -166,6 +458,96 @@ func (m *mysql) LoadSchema(r io.Reader) error {
 	return nil
 }
 
+// insertAll computes the ids of the rows from the id of the first one,
+// returned by LAST_INSERT_ID, stepping by auto_increment_increment. The
+// ids are left alone when they are not evenly spaced, see
+// autoIncrementStep.
+func (m *mysql) insertAll(s store, query string, args []interface{}, n int) ([]int64, error) {
+	step, ok, err := m.autoIncrementStep(s)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.Exec(query, args...)
+	if err != nil || !ok {
+		return nil, err
+	}
+	first, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int64, n)
+	for i := range ids {
+		ids[i] = first + int64(i)*step
+	}
+	return ids, nil
+}
+
+// autoIncrementStep returns the auto_increment_increment of the session,
+// and whether the ids generated by a single INSERT are evenly spaced by
+// it: InnoDB only promises it with the "traditional" and "consecutive"
+// innodb_autoinc_lock_mode, not with the "interleaved" mode, the default
+// of MySQL 8. TiDB makes no such promise.
+func (m *mysql) autoIncrementStep(s store) (int64, bool, error) {
+	if m.Details().TiDB() {
+		return 0, false, nil
+	}
+	v := struct {
+		Increment int64 `db:"increment"`
+		LockMode  int64 `db:"lock_mode"`
+	}{}
+	if err := s.Get(&v, "SELECT @@auto_increment_increment AS increment, @@innodb_autoinc_lock_mode AS lock_mode"); err != nil {
+		return 0, false, errors.Wrap(err, "could not get the auto increment settings")
+	}
+	return v.Increment, v.LockMode != 2, nil
+}
+
+// Capabilities does not report RETURNING: MySQL does not support it, and
+// MariaDB 10.5+ only supports it for INSERT and DELETE. CTEs and lateral
+// joins depend on the version of the server, which is only known once it
+// was asked, see `Connection.Capabilities`.
+func (m *mysql) Capabilities() Capabilities {
+	return mysqlCapabilities(m.Details(), m.knownVersion())
+}
+
+func (m *mysql) serverCapabilities(s store) Capabilities {
+	return mysqlCapabilities(m.Details(), m.serverVersion(s))
+}
+
+// mysqlCapabilities returns the capabilities of a server of version: CTEs
+// came with MySQL 8.0, MariaDB 10.2.1 and TiDB 5.1, lateral joins with
+// MySQL 8.0.14. TiDB versions look like "5.7.25-TiDB-v5.1.0".
+func mysqlCapabilities(cd *ConnectionDetails, version string) Capabilities {
+	if i := strings.Index(version, "-TiDB-v"); i != -1 {
+		version = version[i+len("-TiDB-v"):]
+	}
+	var major, minor, patch int
+	fmt.Sscanf(version, "%d.%d.%d", &major, &minor, &patch)
+	atLeast := func(ma, mi, pa int) bool {
+		if major != ma {
+			return major > ma
+		}
+		if minor != mi {
+			return minor > mi
+		}
+		return patch >= pa
+	}
+	caps := Capabilities{
+		Upsert:     true,
+		Savepoints: true,
+		RowLocks:   true,
+	}
+	switch {
+	case cd.MariaDB():
+		caps.CTE = atLeast(10, 2, 1)
+	case cd.TiDB():
+		caps.CTE = atLeast(5, 1, 0)
+	default:
+		caps.CTE = atLeast(8, 0, 0)
+		caps.LateralJoins = atLeast(8, 0, 14)
+	}
+	return caps
+}
+
 func (m *mysql) TruncateAll(tx *Connection) error {
 	stmts := []struct {
 		Stmt string `db:"stmt"`
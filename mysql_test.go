@@ -0,0 +1,71 @@
+package pop
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/markbates/pop/fizz/translators"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_mariaDBReturning(t *testing.T) {
+	r := require.New(t)
+
+	r.True(mariaDBReturning("10.5.8-MariaDB-1:10.5.8+maria~focal"))
+	r.True(mariaDBReturning("11.0.2-MariaDB"))
+	r.False(mariaDBReturning("10.4.17-MariaDB"))
+	r.False(mariaDBReturning("8.0.22"))
+	r.False(mariaDBReturning(""))
+}
+
+func Test_MySQL_ServerVersion(t *testing.T) {
+	r := require.New(t)
+
+	rec := &statementRecorder{}
+	c, err := NewConnectionWithDB(&ConnectionDetails{Dialect: "mysql", Database: "pop_test"}, sql.OpenDB(rec))
+	r.NoError(err)
+	r.NoError(c.Create(&mockedUser{Name: "Mark"}))
+	r.Len(rec.statements, 1)
+	r.Contains(rec.statements[0].SQL, "INSERT INTO users")
+
+	// the recorder returns no version, it is asked again.
+	rec = &statementRecorder{}
+	c, err = NewConnectionWithDB(&ConnectionDetails{Dialect: "mariadb", Database: "pop_test"}, sql.OpenDB(rec))
+	r.NoError(err)
+	r.NoError(c.Create(&mockedUser{Name: "Mark"}))
+	r.NoError(c.Create(&mockedUser{Name: "Mark"}))
+	r.Len(rec.statements, 4)
+	r.Equal("SELECT VERSION()", rec.statements[0].SQL)
+	r.Equal("SELECT VERSION()", rec.statements[2].SQL)
+}
+
+func Test_MySQL_UUIDBinary(t *testing.T) {
+	r := require.New(t)
+
+	u, err := uuid.NewV4()
+	r.NoError(err)
+
+	m := &mysql{ConnectionDetails: &ConnectionDetails{Dialect: "mysql", Options: map[string]string{}}}
+	r.Equal([]interface{}{u}, convertValues(valueConvertersFor(m), []interface{}{u}))
+	r.False(m.FizzTranslator().(*translators.MySQL).UUIDBinary)
+
+	m.ConnectionDetails.Options["uuid_binary"] = "true"
+	r.Equal([]interface{}{u.Bytes(), 1}, convertValues(valueConvertersFor(m), []interface{}{u, 1}))
+	r.True(m.FizzTranslator().(*translators.MySQL).UUIDBinary)
+}
+
+func Test_MySQL_InsertAll_TiDB(t *testing.T) {
+	r := require.New(t)
+
+	// TiDB does not promise evenly spaced ids, they are left alone.
+	rec := &statementRecorder{}
+	c, err := NewConnectionWithDB(&ConnectionDetails{Dialect: "tidb", Database: "pop_test"}, sql.OpenDB(rec))
+	r.NoError(err)
+	users := []mockedUser{{Name: "Mark"}, {Name: "Ringo"}}
+	r.NoError(c.CreateAll(&users, 10))
+	r.Zero(users[0].ID)
+	r.Zero(users[1].ID)
+	r.Len(rec.statements, 3)
+	r.Contains(rec.statements[1].SQL, "INSERT INTO users")
+}
@@ -0,0 +1,74 @@
+package pop
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// DebugNPlusOne enables, when `Debug` is also enabled, a detector that
+// logs a warning when queries of the same shape are run over and over
+// within a single transaction. This usually means associations are
+// being loaded one record at a time, and should be loaded with `Eager`.
+var DebugNPlusOne = false
+
+// DebugNPlusOneThreshold is the number of times a query shape has to be
+// run within a transaction before `DebugNPlusOne` warns about it.
+var DebugNPlusOneThreshold = 5
+
+func init() {
+	AddInstrumenter(nPlusOne.observe)
+}
+
+var nPlusOne = &nPlusOneDetector{counts: map[string]map[string]int{}}
+
+// nPlusOneDetector counts query shapes per transaction.
+type nPlusOneDetector struct {
+	counts map[string]map[string]int
+	mu     sync.Mutex
+}
+
+var shapeStrings = regexp.MustCompile(`'(?:[^']|'')*'`)
+var shapeNumbers = regexp.MustCompile(`\$\d+|\b\d+\b`)
+var shapeLists = regexp.MustCompile(`\(\s*\?(?:\s*,\s*\?)*\s*\)`)
+
+// queryShape strips the values out of a query so that queries
+// only differing by their arguments end up the same.
+func queryShape(query string) string {
+	s := shapeStrings.ReplaceAllString(query, "?")
+	s = shapeNumbers.ReplaceAllString(s, "?")
+	s = shapeLists.ReplaceAllString(s, "(?)")
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func (d *nPlusOneDetector) observe(e QueryEvent) {
+	if !Debug || !DebugNPlusOne || e.Connection == nil || e.Connection.TX == nil {
+		return
+	}
+	shape := queryShape(e.SQL)
+	if !strings.HasPrefix(strings.ToUpper(shape), "SELECT") {
+		return
+	}
+
+	d.mu.Lock()
+	counts, ok := d.counts[e.Connection.ID]
+	if !ok {
+		counts = map[string]int{}
+		d.counts[e.Connection.ID] = counts
+	}
+	counts[shape]++
+	n := counts[shape]
+	d.mu.Unlock()
+
+	if n == DebugNPlusOneThreshold {
+		logMessage(e.Connection, LogWarn, fmt.Sprintf("WARNING: possible N+1 query, ran %d times in the same transaction, consider using Eager: %s", n, shape))
+	}
+}
+
+// forget drops the counts kept for a finished transaction.
+func (d *nPlusOneDetector) forget(id string) {
+	d.mu.Lock()
+	delete(d.counts, id)
+	d.mu.Unlock()
+}
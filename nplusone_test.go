@@ -0,0 +1,69 @@
+package pop
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_queryShape(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal(queryShape("SELECT * FROM books WHERE user_id = $1 LIMIT 1"), queryShape("SELECT * FROM books WHERE user_id = $2 LIMIT 10"))
+	r.Equal(queryShape("SELECT * FROM books WHERE id in (?, ?)"), queryShape("SELECT * FROM books WHERE id in (?)"))
+	r.Equal(queryShape("SELECT * FROM books WHERE title = 'it''s'"), queryShape("SELECT * FROM books WHERE title = 'pop'"))
+	r.NotEqual(queryShape("SELECT * FROM books WHERE title = ?"), queryShape("SELECT * FROM books WHERE isbn = ?"))
+}
+
+func Test_nPlusOneDetector(t *testing.T) {
+	r := require.New(t)
+
+	logs := []string{}
+	oldLog := Log
+	Log = func(s string, args ...interface{}) {
+		logs = append(logs, s)
+	}
+	Debug = true
+	DebugNPlusOne = true
+	defer func() {
+		Log = oldLog
+		Debug = false
+		DebugNPlusOne = false
+	}()
+
+	d := &nPlusOneDetector{counts: map[string]map[string]int{}}
+	c := &Connection{ID: "tx", TX: &Tx{}}
+	for i := 0; i < 10; i++ {
+		d.observe(QueryEvent{Connection: c, SQL: "SELECT * FROM books WHERE user_id = ?"})
+		d.observe(QueryEvent{Connection: c, SQL: "UPDATE books SET title = ? WHERE id = 1"})
+	}
+	r.Len(logs, 1)
+	r.Contains(logs[0], "ran 5 times")
+
+	d.forget("tx")
+	r.Len(d.counts, 0)
+}
+
+func Test_nPlusOneDetector_NewTransaction(t *testing.T) {
+	r := require.New(t)
+
+	Debug = true
+	DebugNPlusOne = true
+	defer func() {
+		Debug = false
+		DebugNPlusOne = false
+	}()
+
+	c := NewMemoryConnection()
+	tx, err := c.NewTransaction()
+	r.NoError(err)
+	nPlusOne.observe(QueryEvent{Connection: tx, SQL: "SELECT * FROM books WHERE user_id = ?"})
+	nPlusOne.mu.Lock()
+	r.Contains(nPlusOne.counts, tx.ID)
+	nPlusOne.mu.Unlock()
+
+	r.NoError(tx.TX.Commit())
+	nPlusOne.mu.Lock()
+	r.NotContains(nPlusOne.counts, tx.ID)
+	nPlusOne.mu.Unlock()
+}
@@ -0,0 +1,33 @@
+package pop
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// ErrStaleObject is the cause of the errors returned when updating a model
+// with a `LockVersion int` field whose row was updated since it was read:
+// the lock_version of the row no longer matches the one of the model.
+//
+//	if errors.Cause(err) == pop.ErrStaleObject {
+//		// reload the model, and apply the change again
+//	}
+var ErrStaleObject = errors.New("the object was updated by someone else")
+
+// lockVersion returns the `LockVersion int` field of the model, the
+// version of its row which is checked and incremented by its updates.
+func (m *Model) lockVersion() (reflect.Value, bool) {
+	fbn, err := m.fieldByName("LockVersion")
+	if err != nil || fbn.Kind() != reflect.Int {
+		return reflect.Value{}, false
+	}
+	return fbn, true
+}
+
+// whereLockVersion matches the row of the model by the lock_version it
+// had before it was incremented for the update.
+func (m *Model) whereLockVersion(q quoter) string {
+	return fmt.Sprintf("%s = :lock_version - 1", q.Quote(m.TableName()+".lock_version"))
+}
@@ -0,0 +1,50 @@
+package pop_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/markbates/pop"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type Document struct {
+	ID          int       `db:"id"`
+	Title       string    `db:"title"`
+	LockVersion int       `db:"lock_version"`
+	CreatedAt   time.Time `db:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at"`
+}
+
+func Test_OptimisticLock(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		d := &Document{Title: "draft"}
+		r.NoError(tx.Create(d))
+		r.Equal(0, d.LockVersion)
+
+		other := &Document{}
+		r.NoError(tx.Find(other, d.ID))
+
+		d.Title = "final"
+		r.NoError(tx.Update(d))
+		r.Equal(1, d.LockVersion)
+
+		other.Title = "overwritten"
+		err := tx.Update(other)
+		r.Error(err)
+		r.Equal(pop.ErrStaleObject, errors.Cause(err))
+		r.Equal(0, other.LockVersion)
+
+		r.NoError(tx.Reload(other))
+		r.Equal("final", other.Title)
+		other.Title = "merged"
+		r.NoError(tx.Save(other))
+		r.Equal(2, other.LockVersion)
+
+		r.NoError(tx.Reload(d))
+		r.Equal("merged", d.Title)
+		r.Equal(2, d.LockVersion)
+	})
+}
@@ -0,0 +1,139 @@
+package pop
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/markbates/pop/columns"
+	"github.com/pkg/errors"
+)
+
+// partition is a secondary table holding some of the columns of a model,
+// in a 1:1 relation with the model table. The columns are declared with
+// the partition tag, and the secondary table references the model with
+// a "<model>_id" column:
+//
+//	type Article struct {
+//		ID    int    `db:"id"`
+//		Title string `db:"title"`
+//		Body  string `db:"body" partition:"article_bodies"`
+//	}
+type partition struct {
+	Table      string
+	ForeignKey string
+	Columns    []string
+}
+
+// partitions returns the partitions of the model, sorted by table name.
+func (m *Model) partitions() []partition {
+	rv := reflect.Indirect(reflect.ValueOf(m.Value))
+	t := rv.Type()
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	byTable := map[string]*partition{}
+	for i := 0; i < t.NumField(); i++ {
+		tags := columns.TagsFor(t.Field(i))
+		pt := tags.Find("partition")
+		db := tags.Find("db")
+		if pt.Empty() || db.Empty() || db.Ignored() {
+			continue
+		}
+		p, ok := byTable[pt.Value]
+		if !ok {
+			p = &partition{Table: pt.Value, ForeignKey: m.associationName()}
+			byTable[pt.Value] = p
+		}
+		p.Columns = append(p.Columns, db.Value)
+	}
+
+	ps := []partition{}
+	for _, p := range byTable {
+		ps = append(ps, *p)
+	}
+	sort.Slice(ps, func(i, j int) bool { return ps[i].Table < ps[j].Table })
+	return ps
+}
+
+// hasPartitions returns true if the model stores some of its columns
+// in partition tables, which must then be written in a transaction.
+func (c *Connection) hasPartitions(model interface{}) bool {
+	m := &Model{Value: model}
+	return len(m.partitions()) > 0
+}
+
+func (sq *sqlBuilder) buildPartitionJoins(sql string) string {
+	alias := sq.Model.As
+	if alias == "" {
+		alias = strings.Replace(sq.Model.TableName(), ".", "_", -1)
+	}
+	for _, p := range sq.Model.partitions() {
+		sql = fmt.Sprintf("%s LEFT JOIN %s ON %s.%s = %s.id", sql, p.Table, p.Table, p.ForeignKey, alias)
+	}
+	return sql
+}
+
+// writePartitions writes the partition columns of the model, skipping
+// the excluded columns. Rows missing from a partition table are created.
+func (c *Connection) writePartitions(m *Model, update bool, excludeColumns ...string) error {
+	excluded := map[string]bool{}
+	for _, e := range excludeColumns {
+		excluded[e] = true
+	}
+	for _, p := range m.partitions() {
+		cols := []string{}
+		for _, col := range p.Columns {
+			if !excluded[col] {
+				cols = append(cols, col)
+			}
+		}
+		if len(cols) == 0 {
+			continue
+		}
+
+		exists := false
+		if update {
+			rc := &rowCount{}
+			query := c.Dialect.TranslateSQL(fmt.Sprintf("SELECT COUNT(*) AS row_count FROM %s WHERE %s = ?", p.Table, p.ForeignKey))
+			if err := c.Store.Get(rc, query, m.ID()); err != nil {
+				return errors.Wrapf(err, "could not find the %s partition", p.Table)
+			}
+			exists = rc.Count > 0
+		}
+
+		var query string
+		if exists {
+			sets := make([]string, len(cols))
+			for i, col := range cols {
+				sets[i] = fmt.Sprintf("%s = :%s", col, col)
+			}
+			query = fmt.Sprintf("UPDATE %s SET %s WHERE %s = :id", p.Table, strings.Join(sets, ", "), p.ForeignKey)
+		} else {
+			query = fmt.Sprintf("INSERT INTO %s (%s, %s) VALUES (:id, :%s)", p.Table, p.ForeignKey, strings.Join(cols, ", "), strings.Join(cols, ", :"))
+		}
+		if _, err := c.Store.NamedExec(query, m.Value); err != nil {
+			return errors.Wrapf(err, "could not write the %s partition", p.Table)
+		}
+	}
+	return nil
+}
+
+// destroyPartitions deletes the partition rows of the model.
+func (c *Connection) destroyPartitions(m *Model) error {
+	for _, p := range m.partitions() {
+		query := c.Dialect.TranslateSQL(fmt.Sprintf("DELETE FROM %s WHERE %s = ?", p.Table, p.ForeignKey))
+		if _, err := c.Store.Exec(query, m.ID()); err != nil {
+			return errors.Wrapf(err, "could not delete the %s partition", p.Table)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package pop_test
+
+import (
+	"testing"
+
+	"github.com/markbates/pop"
+	"github.com/markbates/pop/nulls"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Partition_Create_Find(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		a := &Article{Title: "Partitions", Body: nulls.NewString("A very long body")}
+		r.NoError(tx.Create(a))
+		r.NotZero(a.ID)
+
+		ctx, err := tx.Q().Where("article_id = ?", a.ID).Count("article_bodies")
+		r.NoError(err)
+		r.Equal(1, ctx)
+
+		fa := &Article{}
+		r.NoError(tx.Find(fa, a.ID))
+		r.Equal("Partitions", fa.Title)
+		r.Equal("A very long body", fa.Body.String)
+
+		articles := Articles{}
+		r.NoError(tx.Where("articles.title = ?", "Partitions").All(&articles))
+		r.Len(articles, 1)
+		r.Equal("A very long body", articles[0].Body.String)
+	})
+}
+
+func Test_Partition_Update(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		a := &Article{Title: "Partitions"}
+		r.NoError(tx.Create(a, "body"))
+
+		ctx, err := tx.Q().Where("article_id = ?", a.ID).Count("article_bodies")
+		r.NoError(err)
+		r.Equal(0, ctx)
+
+		fa := &Article{}
+		r.NoError(tx.Find(fa, a.ID))
+		r.False(fa.Body.Valid)
+
+		a.Body = nulls.NewString("First body")
+		r.NoError(tx.Update(a))
+		r.NoError(tx.Reload(fa))
+		r.Equal("First body", fa.Body.String)
+
+		a.Title = "Updated"
+		a.Body = nulls.NewString("Second body")
+		r.NoError(tx.Update(a))
+		r.NoError(tx.Reload(fa))
+		r.Equal("Updated", fa.Title)
+		r.Equal("Second body", fa.Body.String)
+
+		a.Body = nulls.NewString("Ignored body")
+		r.NoError(tx.Update(a, "body"))
+		r.NoError(tx.Reload(fa))
+		r.Equal("Second body", fa.Body.String)
+	})
+}
+
+func Test_Partition_Destroy(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		a := &Article{Title: "Partitions", Body: nulls.NewString("Body")}
+		r.NoError(tx.Create(a))
+		r.NoError(tx.Destroy(a))
+
+		ctx, err := tx.Q().Where("article_id = ?", a.ID).Count("article_bodies")
+		r.NoError(err)
+		r.Equal(0, ctx)
+
+		ctx, err = tx.Count(&Articles{})
+		r.NoError(err)
+		r.Equal(0, ctx)
+	})
+}
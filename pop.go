@@ -18,6 +18,9 @@ var logger = log.New(os.Stdout, "[POP] ", log.LstdFlags)
 // Log a formatted string to the logger
 var Log = func(s string, args ...interface{}) {
 	if Debug {
+		if LogRedactor != nil && len(args) > 0 {
+			args = LogRedactor(s, args)
+		}
 		if len(args) > 0 {
 			xargs := make([]string, len(args))
 			for i, a := range args {
@@ -35,7 +35,7 @@ func TestSpecificSuites(t *testing.T) {
 	switch os.Getenv("SODA_DIALECT") {
 	case "postgres":
 		suite.Run(t, &PostgreSQLSuite{})
-	case "mysql":
+	case "mysql", "tidb":
 		suite.Run(t, &MySQLSuite{})
 	case "sqlite":
 		suite.Run(t, &SQLiteSuite{})
@@ -56,6 +56,9 @@ func init() {
 
 	pop.MapTableName("Friend", "good_friends")
 	pop.MapTableName("Friends", "good_friends")
+
+	pop.RegisterSubtype(&Vehicle{}, "car", &Car{})
+	pop.RegisterSubtype(&Vehicle{}, "truck", &Truck{})
 }
 
 func transaction(fn func(tx *pop.Connection)) {
@@ -142,6 +145,97 @@ type Song struct {
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 }
 
+type Post struct {
+	ID            int       `db:"id"`
+	Title         string    `db:"title"`
+	CommentsCount int       `db:"comments_count"`
+	Comments      Comments  `has_many:"comments"`
+	CreatedAt     time.Time `db:"created_at"`
+	UpdatedAt     time.Time `db:"updated_at"`
+}
+
+type Posts []Post
+
+type Article struct {
+	ID        int          `db:"id"`
+	Title     string       `db:"title"`
+	Body      nulls.String `db:"body" partition:"article_bodies"`
+	CreatedAt time.Time    `db:"created_at"`
+	UpdatedAt time.Time    `db:"updated_at"`
+}
+
+type Articles []Article
+
+type Vehicle struct {
+	ID        int       `db:"id"`
+	Type      string    `db:"type"`
+	Name      string    `db:"name"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+type Car struct {
+	ID        int       `db:"id"`
+	Type      string    `db:"type"`
+	Name      string    `db:"name"`
+	Doors     nulls.Int `db:"doors"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func (Car) TableName() string {
+	return "vehicles"
+}
+
+type Truck struct {
+	ID        int       `db:"id"`
+	Type      string    `db:"type"`
+	Name      string    `db:"name"`
+	Payload   nulls.Int `db:"payload"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func (Truck) TableName() string {
+	return "vehicles"
+}
+
+type Category struct {
+	ID        int       `db:"id"`
+	Name      string    `db:"name"`
+	ParentID  nulls.Int `db:"parent_id"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+type Categories []Category
+
+type Ranking struct {
+	ID        int       `db:"id"`
+	Order     int       `db:"order"`
+	Group     string    `db:"group"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+type Invoice struct {
+	ID        int       `db:"id" sequence:"invoice_numbers"`
+	Title     string    `db:"title"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+type Comment struct {
+	ID        int       `db:"id"`
+	Body      string    `db:"body"`
+	PostID    nulls.Int `db:"post_id"`
+	Post      Post      `belongs_to:"post" counter_cache:"comments_count"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+type Comments []Comment
+
 type ValidatableCar struct {
 	ID        int64     `db:"id"`
 	Name      string    `db:"name"`
@@ -149,6 +243,30 @@ type ValidatableCar struct {
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// TaggedCar is validated with validate tags only.
+type TaggedCar struct {
+	ID        int64     `db:"id"`
+	Name      string    `db:"name" validate:"required,max=10"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+func (TaggedCar) TableName() string {
+	return "validatable_cars"
+}
+
+// UniqueCar checks the uniqueness of its name.
+type UniqueCar struct {
+	ID        int64     `db:"id"`
+	Name      string    `db:"name" validate:"required,unique"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+func (UniqueCar) TableName() string {
+	return "validatable_cars"
+}
+
 var validationLogs = []string{}
 
 func (v *ValidatableCar) Validate(tx *pop.Connection) (*validate.Errors, error) {
@@ -196,8 +314,15 @@ type CallbacksUser struct {
 
 type CallbacksUsers []CallbacksUser
 
+// currentUserKey holds the user of the request in the context of the
+// connection.
+type currentUserKey struct{}
+
 func (u *CallbacksUser) BeforeSave(tx *pop.Connection) error {
 	u.BeforeS = "BeforeSave"
+	if name, ok := tx.Context().Value(currentUserKey{}).(string); ok {
+		u.BeforeS += " by " + name
+	}
 	return nil
 }
 
@@ -2,13 +2,14 @@ package pop
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
+	"net/url"
 	"os"
 	"os/exec"
-	"strconv"
 	"strings"
-	"sync"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	// Load PostgreSQL Go driver
@@ -24,8 +25,7 @@ import (
 var _ dialect = &postgresql{}
 
 type postgresql struct {
-	translateCache    map[string]string
-	mu                sync.Mutex
+	translateCache    *rebindCache
 	ConnectionDetails *ConnectionDetails
 }
 
@@ -33,6 +33,12 @@ func (p *postgresql) Details() *ConnectionDetails {
 	return p.ConnectionDetails
 }
 
+// Quote quotes the identifier key, e.g. "users.name", if it is a reserved
+// word or if the "quote_identifiers" option is set.
+func (p *postgresql) Quote(key string) string {
+	return quoteIdentifier(p.ConnectionDetails, `"`, `"`, key)
+}
+
 func (p *postgresql) Create(s store, model *Model, cols columns.Columns) error {
 	keyType := model.PrimaryKeyType()
 	switch keyType {
@@ -42,30 +48,139 @@ func (p *postgresql) Create(s store, model *Model, cols columns.Columns) error {
 			ID int `db:"id"`
 		}{}
 		w := cols.Writeable()
-		query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) returning id", model.TableName(), w.String(), w.SymbolizedString())
-		Log(query)
-		stmt, err := s.PrepareNamed(query)
-		if err != nil {
-			return errors.WithStack(err)
-		}
-		err = stmt.Get(&id, model.Value)
+		query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) returning id", p.Quote(model.TableName()), w.QuotedString(p.Quote), w.SymbolizedString())
+		err := namedGet(s, &id, query, model.Value)
 		if err != nil {
 			return errors.WithStack(err)
 		}
 		model.setID(id.ID)
 		return nil
-	case "UUID":
-		return genericCreate(s, model, cols)
+	case "UUID", "string":
+		return genericCreate(s, model, cols, p)
 	}
 	return errors.Errorf("can not use %s as a primary key type!", keyType)
 }
 
+func (p *postgresql) upsert(s store, model *Model, cols, update, conflict []string) error {
+	return onConflictUpsert(s, model, cols, update, conflict, p)
+}
+
 func (p *postgresql) Update(s store, model *Model, cols columns.Columns) error {
-	return genericUpdate(s, model, cols)
+	return genericUpdate(s, model, cols, p)
 }
 
 func (p *postgresql) Destroy(s store, model *Model) error {
-	return genericDestroy(s, model)
+	return genericDestroy(s, model, p)
+}
+
+func (p *postgresql) nextSequenceValue(s store, name string) (int64, error) {
+	var n int64
+	query := "SELECT nextval($1)"
+	err := s.Get(&n, query, name)
+	return n, err
+}
+
+func (p *postgresql) reserveSequenceValues(s store, name string, n int) ([]int64, error) {
+	ns := []int64{}
+	query := "SELECT nextval($1) FROM generate_series(1, $2)"
+	err := s.Select(&ns, query, name, n)
+	return ns, err
+}
+
+// replicaLag is zero when the replica has replayed all it received,
+// otherwise the age of the last transaction it replayed.
+func (p *postgresql) replicaLag(ctx context.Context, db *sqlx.DB) (time.Duration, error) {
+	var seconds float64
+	query := "SELECT CASE WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0 ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0) END"
+	logSQL(nil, query)
+	err := db.GetContext(ctx, &seconds, query)
+	return time.Duration(seconds * float64(time.Second)), err
+}
+
+func (p *postgresql) maintain(c *Connection, tables []string) ([]MaintenanceResult, error) {
+	if len(tables) == 0 {
+		tables = []string{""}
+	}
+	results := []MaintenanceResult{}
+	for _, t := range tables {
+		query := "VACUUM ANALYZE"
+		if t != "" {
+			query = fmt.Sprintf("VACUUM ANALYZE %s", p.Quote(t))
+		}
+		if err := c.RawQuery(query).Exec(); err != nil {
+			return results, err
+		}
+		results = append(results, MaintenanceResult{Table: t, Statement: query})
+	}
+	return results, nil
+}
+
+const postgresActiveQueries = `SELECT pid, COALESCE(usename, '') AS user_name, COALESCE(datname, '') AS database_name, COALESCE(state, '') AS state, COALESCE(query, '') AS query, COALESCE(EXTRACT(EPOCH FROM now() - query_start), 0) AS seconds
+FROM pg_stat_activity
+WHERE state <> 'idle' AND pid <> pg_backend_pid()
+ORDER BY query_start`
+
+const postgresBlockers = `SELECT blocked.pid AS blocked_pid, COALESCE(blocked.query, '') AS blocked_query, blocking.pid AS blocking_pid, COALESCE(blocking.query, '') AS blocking_query, COALESCE(EXTRACT(EPOCH FROM now() - blocked.query_start), 0) AS seconds
+FROM pg_stat_activity blocked
+JOIN pg_stat_activity blocking ON blocking.pid = ANY(pg_blocking_pids(blocked.pid))
+ORDER BY blocked.query_start`
+
+func (p *postgresql) activeQueries(ctx context.Context, db *sqlx.DB) ([]ActiveQuery, error) {
+	return selectActivity(ctx, db, postgresActiveQueries)
+}
+
+func (p *postgresql) blockers(ctx context.Context, db *sqlx.DB) ([]Blocker, error) {
+	return selectBlockers(ctx, db, postgresBlockers)
+}
+
+func (p *postgresql) cancelQuery(ctx context.Context, db *sqlx.DB, pid int64) error {
+	query := "SELECT pg_cancel_backend($1)"
+	logSQL(nil, query, pid)
+	var ok bool
+	if err := db.GetContext(ctx, &ok, query, pid); err != nil {
+		return errors.WithStack(err)
+	}
+	if !ok {
+		return errors.Errorf("could not cancel the query of backend %d", pid)
+	}
+	return nil
+}
+
+const postgresIndexes = `SELECT t.relname AS table_name, i.relname AS index_name, array_to_string(array_agg(a.attname ORDER BY k.n), ',') AS columns
+FROM pg_index x
+JOIN pg_class i ON i.oid = x.indexrelid
+JOIN pg_class t ON t.oid = x.indrelid
+JOIN pg_namespace ns ON ns.oid = t.relnamespace
+JOIN LATERAL unnest(x.indkey::int2[]) WITH ORDINALITY AS k(attnum, n) ON true
+JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum
+WHERE ns.nspname = current_schema() AND x.indexprs IS NULL AND x.indpred IS NULL
+GROUP BY t.relname, i.relname`
+
+const postgresForeignKeys = `SELECT t.relname AS table_name, c.conname AS index_name, array_to_string(array_agg(a.attname ORDER BY k.n), ',') AS columns
+FROM pg_constraint c
+JOIN pg_class t ON t.oid = c.conrelid
+JOIN pg_namespace ns ON ns.oid = t.relnamespace
+JOIN LATERAL unnest(c.conkey) WITH ORDINALITY AS k(attnum, n) ON true
+JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum
+WHERE c.contype = 'f' AND ns.nspname = current_schema()
+GROUP BY t.relname, c.conname`
+
+const postgresUnusedIndexes = `SELECT s.relname AS table_name, s.indexrelname AS index_name, '' AS columns
+FROM pg_stat_user_indexes s
+JOIN pg_index x ON x.indexrelid = s.indexrelid
+WHERE s.idx_scan = 0 AND NOT x.indisunique AND NOT x.indisprimary AND s.schemaname = current_schema()
+ORDER BY s.relname, s.indexrelname`
+
+func (p *postgresql) indexes(c *Connection) ([]IndexInfo, error) {
+	return indexRows(c, postgresIndexes)
+}
+
+func (p *postgresql) foreignKeys(c *Connection) ([]IndexInfo, error) {
+	return indexRows(c, postgresForeignKeys)
+}
+
+func (p *postgresql) unusedIndexes(c *Connection) ([]IndexInfo, error) {
+	return indexRows(c, postgresUnusedIndexes)
 }
 
 func (p *postgresql) SelectOne(s store, model *Model, query Query) error {
@@ -85,7 +200,7 @@ func (p *postgresql) CreateDB() error {
 	}
 	defer db.Close()
 	query := fmt.Sprintf("CREATE DATABASE \"%s\"", deets.Database)
-	Log(query)
+	logSQL(nil, query)
 
 	_, err = db.Exec(query)
 	if err != nil {
@@ -104,7 +219,7 @@ func (p *postgresql) DropDB() error {
 	}
 	defer db.Close()
 	query := fmt.Sprintf("DROP DATABASE \"%s\"", deets.Database)
-	Log(query)
+	logSQL(nil, query)
 
 	_, err = db.Exec(query)
 	if err != nil {
@@ -118,12 +233,33 @@ func (p *postgresql) DropDB() error {
 func (p *postgresql) URL() string {
 	c := p.ConnectionDetails
 	if c.URL != "" {
-		return c.URL
+		return withApplicationName(c.URL, c.ApplicationName())
 	}
 	ssl := defaults.String(c.Options["sslmode"], "disable")
 
 	s := "postgres://%s:%s@%s:%s/%s?sslmode=%s"
-	return fmt.Sprintf(s, c.User, c.Password, c.Host, c.Port, c.Database, ssl)
+	return withApplicationName(fmt.Sprintf(s, c.User, c.Password, c.Host, c.Port, c.Database, ssl), c.ApplicationName())
+}
+
+// withApplicationName adds the application_name parameter to the
+// PostgreSQL connection string u, unless it already has one.
+func withApplicationName(u string, name string) string {
+	if name == "" || strings.Contains(u, "application_name=") {
+		return u
+	}
+	if !strings.Contains(u, "://") {
+		// key/value connection string
+		return fmt.Sprintf("%s application_name='%s'", u, strings.Replace(name, "'", `\'`, -1))
+	}
+	sep := "?"
+	if strings.Contains(u, "?") {
+		sep = "&"
+	}
+	return u + sep + "application_name=" + url.QueryEscape(name)
+}
+
+func (p *postgresql) setApplicationName(c *Connection, name string) error {
+	return c.RawQuery("SELECT set_config('application_name', ?, true)", name).Exec()
 }
 
 func (p *postgresql) urlWithoutDb() string {
@@ -139,28 +275,7 @@ func (p *postgresql) MigrationURL() string {
 }
 
 func (p *postgresql) TranslateSQL(sql string) string {
-	defer p.mu.Unlock()
-	p.mu.Lock()
-
-	if csql, ok := p.translateCache[sql]; ok {
-		return csql
-	}
-	curr := 1
-	out := make([]byte, 0, len(sql))
-	for i := 0; i < len(sql); i++ {
-		if sql[i] == '?' {
-			str := "$" + strconv.Itoa(curr)
-			for _, char := range str {
-				out = append(out, byte(char))
-			}
-			curr++
-		} else {
-			out = append(out, sql[i])
-		}
-	}
-	csql := string(out)
-	p.translateCache[sql] = csql
-	return csql
+	return p.translateCache.rebind(sql)
 }
 
 func (p *postgresql) FizzTranslator() fizz.Translator {
@@ -173,7 +288,7 @@ func (p *postgresql) Lock(fn func() error) error {
 
 func (p *postgresql) DumpSchema(w io.Writer) error {
 	cmd := exec.Command("pg_dump", "-s", fmt.Sprintf("--dbname=%s", p.URL()))
-	Log(strings.Join(cmd.Args, " "))
+	logMessage(nil, LogInfo, strings.Join(cmd.Args, " "))
 	cmd.Stdout = w
 	cmd.Stderr = os.Stderr
 
@@ -186,6 +301,20 @@ func (p *postgresql) DumpSchema(w io.Writer) error {
 	return nil
 }
 
+func (p *postgresql) backupCommand(tables []string) *exec.Cmd {
+	args := []string{"--clean", "--if-exists", fmt.Sprintf("--dbname=%s", p.URL())}
+	for _, t := range tables {
+		args = append(args, "-t", t)
+	}
+	return exec.Command("pg_dump", args...)
+}
+
+func (p *postgresql) tableNames(c *Connection) ([]string, error) {
+	tables := []string{}
+	err := c.RawQuery("SELECT tablename FROM pg_tables WHERE schemaname = current_schema() ORDER BY tablename").All(&tables)
+	return tables, err
+}
+
 func (p *postgresql) LoadSchema(r io.Reader) error {
 	cmd := exec.Command("psql", p.URL())
 	in, err := cmd.StdinPipe()
@@ -196,7 +325,7 @@ func (p *postgresql) LoadSchema(r io.Reader) error {
 		defer in.Close()
 		io.Copy(in, r)
 	}()
-	Log(strings.Join(cmd.Args, " "))
+	logMessage(nil, LogInfo, strings.Join(cmd.Args, " "))
 
 	bb := &bytes.Buffer{}
 	cmd.Stdout = bb
@@ -216,6 +345,22 @@ func (p *postgresql) LoadSchema(r io.Reader) error {
 	return nil
 }
 
+func (p *postgresql) insertAll(s store, query string, args []interface{}, n int) ([]int64, error) {
+	return returningInsertAll(s, query, args, n)
+}
+
+func (p *postgresql) Capabilities() Capabilities {
+	return Capabilities{
+		Returning:    true,
+		Upsert:       true,
+		CTE:          true,
+		Savepoints:   true,
+		LateralJoins: true,
+		RowLocks:     true,
+		DistinctOn:   true,
+	}
+}
+
 func (p *postgresql) TruncateAll(tx *Connection) error {
 	return tx.RawQuery(pgTruncate).Exec()
 }
@@ -223,8 +368,7 @@ func (p *postgresql) TruncateAll(tx *Connection) error {
 func newPostgreSQL(deets *ConnectionDetails) dialect {
 	cd := &postgresql{
 		ConnectionDetails: deets,
-		translateCache:    map[string]string{},
-		mu:                sync.Mutex{},
+		translateCache:    newRebindCache(),
 	}
 	return cd
 }
@@ -0,0 +1,266 @@
+package pop
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"regexp"
+
+	"github.com/markbates/pop/associations"
+	"github.com/markbates/pop/columns"
+	"github.com/pkg/errors"
+)
+
+// batchableConstraint matches the association constraints which can be
+// batched into a single "IN" query, like "id = ?" or "user_id = ?".
+var batchableConstraint = regexp.MustCompile(`^(\w+) = \?$`)
+
+// preloadBatch is a set of associations targeting the same table, through
+// the same column, which are loaded with a single query.
+type preloadBatch struct {
+	target  reflect.Type
+	column  string
+	orderBy string
+	keys    []interface{}
+	loads   []preloadTarget
+	// field is the association the batch loads, when it has scopes
+	field string
+}
+
+// preloadTarget is a single association value to fill from a batch.
+type preloadTarget struct {
+	value interface{}
+	key   interface{}
+}
+
+// Preload eager loads the associations of a slice of models, using a single
+// query for each associated table. Unlike `Load`, the slice can contain
+// models of different types, for instance the result of `AllSubtypes`: the
+// associations sharing a table and a foreign key are batched together,
+// whatever the type of their parent.
+//
+//	all, err := tx.AllSubtypes(&vehicles)
+//	err = tx.Preload(all, "Owner", "Owner.Books")
+//
+// Fields missing from some of the models are skipped for those models.
+// Associations which can not be batched, such as many_to_many, are loaded
+// one by one.
+func (c *Connection) Preload(models interface{}, fields ...string) error {
+	return Q(c).preload(preloadModels(reflect.ValueOf(models)), fields)
+}
+
+// preloadModels flattens v into a list of pointers to models.
+func preloadModels(v reflect.Value) []interface{} {
+	models := []interface{}{}
+	switch v.Kind() {
+	case reflect.Interface:
+		if !v.IsNil() {
+			models = append(models, preloadModels(v.Elem())...)
+		}
+	case reflect.Ptr:
+		if v.IsNil() {
+			break
+		}
+		if el := v.Elem(); el.Kind() == reflect.Slice || el.Kind() == reflect.Array {
+			return preloadModels(el)
+		}
+		models = append(models, v.Interface())
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			el := v.Index(i)
+			if el.Kind() == reflect.Struct && el.CanAddr() {
+				el = el.Addr()
+			}
+			models = append(models, preloadModels(el)...)
+		}
+	}
+	return models
+}
+
+func (q *Query) preload(models []interface{}, eagerFields []string) error {
+	if len(models) == 0 {
+		return nil
+	}
+	fields, nested := splitEagerFields(eagerFields)
+
+	// a field is only loaded on the models having it.
+	found := map[string]bool{}
+	batches := []*preloadBatch{}
+	byKey := map[string]*preloadBatch{}
+	loaded := map[string][]interface{}{}
+	for _, m := range models {
+		t := reflect.Indirect(reflect.ValueOf(m)).Type()
+		modelFields := []string{}
+		for _, f := range fields {
+			if _, ok := t.FieldByName(f); ok {
+				found[f] = true
+				modelFields = append(modelFields, f)
+			}
+		}
+		if len(fields) > 0 && len(modelFields) == 0 {
+			continue
+		}
+
+		for _, f := range preloadFieldNames(t, modelFields) {
+			assos, err := associations.AssociationsForStruct(m, f)
+			if err != nil {
+				return err
+			}
+			for _, association := range assos {
+				if association == associations.SkippedAssociation {
+					continue
+				}
+				condition, args := association.Constraint()
+				match := batchableConstraint.FindStringSubmatch(condition)
+				if match == nil || len(args) != 1 {
+					value, err := q.eagerAssociation(association, f)
+					if err != nil {
+						return err
+					}
+					if value != nil {
+						loaded[f] = append(loaded[f], value)
+					}
+					continue
+				}
+
+				value := association.Interface()
+				loaded[f] = append(loaded[f], value)
+
+				b := &preloadBatch{
+					target: modelType(reflect.TypeOf(value)),
+					column: match[1],
+				}
+				if s, ok := association.(associations.AssociationSortable); ok {
+					b.orderBy = s.OrderBy()
+				}
+				if len(q.eagerScopes[q.eagerPrefix+f]) > 0 {
+					b.field = f
+				}
+				key := fmt.Sprintf("%s:%s:%s:%s", b.target, b.column, b.orderBy, b.field)
+				if byKey[key] == nil {
+					byKey[key] = b
+					batches = append(batches, b)
+				}
+				b = byKey[key]
+				b.keys = append(b.keys, args[0])
+				b.loads = append(b.loads, preloadTarget{value: value, key: preloadKey(args[0])})
+			}
+		}
+	}
+
+	for _, f := range fields {
+		if !found[f] {
+			return errors.Errorf("field %s does not exist in any of the models", f)
+		}
+	}
+
+	err := q.eachEager(len(batches), func(i int) error {
+		return q.loadBatch(batches[i])
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, f := range fields {
+		if len(nested[f]) == 0 || len(loaded[f]) == 0 {
+			continue
+		}
+		if err := q.nestedQuery(f).preload(preloadModels(reflect.ValueOf(loaded[f])), nested[f]); err != nil {
+			return errors.Wrapf(err, "could not load associations of %s", f)
+		}
+	}
+	return nil
+}
+
+// preloadFieldNames returns the association fields of t to load: fields,
+// or all of them if fields is empty.
+func preloadFieldNames(t reflect.Type, fields []string) []string {
+	if len(fields) > 0 {
+		return fields
+	}
+	names := []string{}
+	for i := 0; i < t.NumField(); i++ {
+		if associations.IsAssociationField(t.Field(i)) {
+			names = append(names, t.Field(i).Name)
+		}
+	}
+	return names
+}
+
+// loadBatch runs a single query for all the keys of the batch, then
+// dispatches the rows to the associations they belong to.
+func (q *Query) loadBatch(b *preloadBatch) error {
+	keys := []interface{}{}
+	seen := map[string]bool{}
+	for _, k := range b.keys {
+		// the keys are sent as they are, so they go through the value
+		// converters, but are compared normalized.
+		if s := fmt.Sprint(preloadKey(k)); !seen[s] {
+			seen[s] = true
+			keys = append(keys, k)
+		}
+	}
+
+	rows := reflect.New(reflect.SliceOf(b.target))
+	query := Q(q.Connection).Where(fmt.Sprintf("%s in (?)", b.column), keys...)
+	query = q.scoped(b.field, query)
+	if b.orderBy != "" {
+		query = query.Order(b.orderBy)
+	}
+	if err := query.All(rows.Interface()); err != nil {
+		return errors.WithStack(err)
+	}
+
+	field, ok := columnField(b.target, b.column)
+	if !ok {
+		return errors.Errorf("%s does not have a field for the column %s", b.target.Name(), b.column)
+	}
+	byKey := map[string][]reflect.Value{}
+	for i := 0; i < rows.Elem().Len(); i++ {
+		row := rows.Elem().Index(i)
+		k := fmt.Sprint(preloadKey(row.FieldByIndex(field.Index).Interface()))
+		byKey[k] = append(byKey[k], row)
+	}
+
+	for _, l := range b.loads {
+		matches := byKey[fmt.Sprint(l.key)]
+		value := reflect.ValueOf(l.value).Elem()
+		switch value.Kind() {
+		case reflect.Slice:
+			value.Set(reflect.MakeSlice(value.Type(), 0, len(matches)))
+			for _, row := range matches {
+				if value.Type().Elem().Kind() == reflect.Ptr {
+					row = row.Addr()
+				}
+				value.Set(reflect.Append(value, row))
+			}
+		case reflect.Struct:
+			if len(matches) > 0 {
+				value.Set(matches[0])
+			}
+		}
+	}
+	return nil
+}
+
+// preloadKey normalizes a key, so nullable types and plain values holding
+// the same id match each other.
+func preloadKey(k interface{}) interface{} {
+	if v, ok := k.(driver.Valuer); ok {
+		if dv, err := v.Value(); err == nil {
+			return dv
+		}
+	}
+	return k
+}
+
+// columnField finds the field of t mapped to the column.
+func columnField(t reflect.Type, column string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if columns.TagsFor(f).Find("db").Value == column {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
@@ -0,0 +1,139 @@
+package pop_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/markbates/pop"
+	"github.com/markbates/pop/nulls"
+	"github.com/stretchr/testify/require"
+)
+
+// Review is another model belonging to a user, next to Book.
+type Review struct {
+	ID     int       `db:"id"`
+	UserID nulls.Int `db:"user_id"`
+	User   User      `belongs_to:"user"`
+}
+
+// countSelects counts the selects on table sent through the connection c,
+// with a middleware which goes away along with c.
+func countSelects(c *pop.Connection, table string) *int {
+	count := 0
+	c.Use(func(next pop.Executor) pop.Executor {
+		return pop.ExecutorFunc(func(s *pop.Statement) error {
+			if strings.HasPrefix(s.SQL, "SELECT") && strings.Contains(s.SQL, "FROM "+table+" ") {
+				count++
+			}
+			return next.Execute(s)
+		})
+	})
+	return &count
+}
+
+func Test_Preload_Heterogeneous(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		u1 := &User{Name: nulls.NewString("Mark")}
+		r.NoError(tx.Create(u1))
+		u2 := &User{Name: nulls.NewString("Ringo")}
+		r.NoError(tx.Create(u2))
+		r.NoError(tx.Create(&Book{Title: "Pop", Isbn: "PB1", UserID: nulls.NewInt(u2.ID)}))
+
+		book := &Book{Title: "Buffalo", UserID: nulls.NewInt(u1.ID)}
+		models := []interface{}{
+			book,
+			&Review{UserID: nulls.NewInt(u2.ID)},
+			&Review{UserID: nulls.NewInt(u1.ID)},
+			&Review{},
+		}
+
+		users := countSelects(tx, "users")
+		r.NoError(tx.Preload(models, "User", "User.Books"))
+		r.Equal(1, *users)
+
+		r.Equal("Mark", book.User.Name.String)
+		r.Len(book.User.Books, 0)
+		review := models[1].(*Review)
+		r.Equal("Ringo", review.User.Name.String)
+		r.Len(review.User.Books, 1)
+		r.Equal("Pop", review.User.Books[0].Title)
+		r.Equal("Mark", models[2].(*Review).User.Name.String)
+		r.Equal(0, models[3].(*Review).User.ID)
+	})
+}
+
+func Test_Preload_Load_Interfaces(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		u := &User{Name: nulls.NewString("Mark")}
+		r.NoError(tx.Create(u))
+
+		models := []interface{}{&Book{UserID: nulls.NewInt(u.ID)}, &Review{UserID: nulls.NewInt(u.ID)}}
+		r.NoError(tx.Load(&models, "User"))
+		r.Equal("Mark", models[0].(*Book).User.Name.String)
+		r.Equal("Mark", models[1].(*Review).User.Name.String)
+	})
+}
+
+func Test_Preload_Missing_Field(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		models := []interface{}{&Book{}, &Review{}}
+		r.Error(tx.Preload(models, "Owner"))
+	})
+}
+
+func Test_EagerCache(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		for _, name := range []string{"Mark", "Ringo", "Paul"} {
+			u := &User{Name: nulls.NewString(name)}
+			r.NoError(tx.Create(u))
+			r.NoError(tx.Create(&Book{Title: name + " 1", Isbn: "PB1", UserID: nulls.NewInt(u.ID)}))
+			r.NoError(tx.Create(&Book{Title: name + " 2", Isbn: "PB2", UserID: nulls.NewInt(u.ID)}))
+		}
+
+		books := countSelects(tx, "books")
+		users := countSelects(tx, "users")
+		all := Users{}
+		r.NoError(tx.EagerCache("Books", "Books.User").Order("name asc").All(&all))
+		r.Equal(1, *books)
+		r.Equal(2, *users)
+
+		r.Len(all, 3)
+		r.Equal("Mark", all[0].Name.String)
+		r.Len(all[0].Books, 2)
+		r.Equal("Mark 1", all[0].Books[0].Title)
+		r.Equal("Mark", all[0].Books[0].User.Name.String)
+		r.Len(all[2].Books, 2)
+		r.Equal("Ringo", all[2].Books[1].User.Name.String)
+
+		err := tx.EagerCache("Nope").All(&all)
+		r.Error(err)
+		r.Contains(err.Error(), "field Nope does not exist")
+	})
+}
+
+func Test_SetEagerMode_Cache(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		for _, name := range []string{"Mark", "Ringo"} {
+			u := &User{Name: nulls.NewString(name)}
+			r.NoError(tx.Create(u))
+			r.NoError(tx.Create(&Book{Title: name, Isbn: "PB1", UserID: nulls.NewInt(u.ID)}))
+		}
+
+		pop.SetEagerMode(pop.EagerCache)
+		defer pop.SetEagerMode(pop.EagerDefault)
+
+		users := countSelects(tx, "users")
+		all := Books{}
+		r.NoError(tx.Eager("User").All(&all))
+		r.Equal(1, *users)
+		r.Len(all, 2)
+		for _, b := range all {
+			r.Equal(b.Title, b.User.Name.String)
+		}
+	})
+}
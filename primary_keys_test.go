@@ -0,0 +1,99 @@
+package pop_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/markbates/pop"
+	"github.com/markbates/pop/nulls"
+	"github.com/stretchr/testify/require"
+)
+
+// SKU is a business key.
+type SKU string
+
+type Product struct {
+	ID        SKU       `db:"id"`
+	Name      string    `db:"name"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// UserID is a typed id.
+type UserID int64
+
+type TypedUser struct {
+	ID        UserID       `db:"id"`
+	Name      nulls.String `db:"name"`
+	CreatedAt time.Time    `db:"created_at"`
+	UpdatedAt time.Time    `db:"updated_at"`
+}
+
+func (TypedUser) TableName() string {
+	return "users"
+}
+
+func Test_PrimaryKeyType(t *testing.T) {
+	r := require.New(t)
+	r.Equal("int", (&pop.Model{Value: &User{}}).PrimaryKeyType())
+	r.Equal("UUID", (&pop.Model{Value: &Song{}}).PrimaryKeyType())
+	r.Equal("string", (&pop.Model{Value: &Product{}}).PrimaryKeyType())
+	r.Equal("int64", (&pop.Model{Value: &TypedUser{}}).PrimaryKeyType())
+
+	r.NoError(pop.ValidateModel(&Product{}))
+	r.NoError(pop.ValidateModel(&TypedUser{}))
+}
+
+func Test_String_PrimaryKey(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		err := tx.Create(&Product{Name: "Nameless"})
+		r.Error(err)
+		r.Contains(err.Error(), "products can not be created without an id")
+
+		// the key looks like a number, but is not one.
+		p := &Product{ID: "00042", Name: "Pop"}
+		r.NoError(tx.Create(p))
+
+		found := &Product{}
+		r.NoError(tx.Find(found, "00042"))
+		r.Equal(SKU("00042"), found.ID)
+		r.Equal("Pop", found.Name)
+		r.Error(tx.Find(found, 42))
+
+		// Save updates an existing key, and creates a new one.
+		p.Name = "Soda"
+		r.NoError(tx.Save(p))
+		r.NoError(tx.Save(&Product{ID: "SKU-2", Name: "Buffalo"}))
+		products := []Product{}
+		r.NoError(tx.Order("id asc").All(&products))
+		r.Len(products, 2)
+		r.Equal("Soda", products[0].Name)
+		r.Equal(SKU("SKU-2"), products[1].ID)
+
+		r.NoError(tx.Destroy(p))
+		ct, err := tx.Count(&Product{})
+		r.NoError(err)
+		r.Equal(1, ct)
+	})
+}
+
+func Test_Typed_PrimaryKey(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		u := &TypedUser{Name: nulls.NewString("Mark")}
+		r.NoError(tx.Create(u))
+		r.NotZero(u.ID)
+
+		found := &TypedUser{}
+		r.NoError(tx.Find(found, u.ID))
+		r.Equal(u.ID, found.ID)
+		r.NoError(tx.Find(found, int(u.ID)))
+
+		found.Name = nulls.NewString("Ringo")
+		r.NoError(tx.Save(found))
+		r.NoError(tx.Reload(u))
+		r.Equal("Ringo", u.Name.String)
+		r.NoError(tx.Destroy(u))
+	})
+}
@@ -0,0 +1,132 @@
+package pop
+
+import (
+	"io"
+	"time"
+)
+
+// Progress is an event of a long operation, such as `CreateAll`,
+// `EachBatch`, `Backup` or `Restore`, which can drive a progress bar or
+// a log line.
+type Progress struct {
+	// Operation is the name of the operation, e.g. "CreateAll".
+	Operation string
+	// Table is the table being processed, if the operation knows it.
+	Table string
+	// Rows is the number of rows processed so far.
+	Rows int64
+	// TotalRows is the number of rows to process, 0 if it is not known.
+	TotalRows int64
+	// Bytes is the number of bytes read or written so far, by the
+	// operations reading or writing a stream.
+	Bytes int64
+	// Elapsed is the time since the start of the operation.
+	Elapsed time.Duration
+	// Done is true for the last event of the operation.
+	Done bool
+}
+
+// ETA returns the estimated time left, 0 when the total is not known.
+func (p Progress) ETA() time.Duration {
+	if p.TotalRows == 0 || p.Rows == 0 || p.Rows >= p.TotalRows {
+		return 0
+	}
+	return time.Duration(float64(p.Elapsed) * float64(p.TotalRows-p.Rows) / float64(p.Rows))
+}
+
+// ProgressFunc receives the progress of the long operations of a
+// connection.
+type ProgressFunc func(p Progress)
+
+// ProgressEvery is the number of rows between the events of the
+// operations processing rows one at a time, such as `Backup`.
+var ProgressEvery int64 = 1000
+
+// WithProgress returns a copy of the connection reporting the progress of
+// its long operations to fn:
+//
+//	err := c.WithProgress(func(p pop.Progress) {
+//		fmt.Printf("\r%d/%d rows, %s left", p.Rows, p.TotalRows, p.ETA())
+//	}).CreateAll(&users, 1000)
+func (c *Connection) WithProgress(fn ProgressFunc) *Connection {
+	cn := *c
+	cn.progress = fn
+	if is, ok := c.Store.(*instrumentedStore); ok {
+		cn.Store = newInstrumentedStore(&cn, is.store)
+	}
+	return &cn
+}
+
+// progressReporter reports the progress of an operation to the
+// ProgressFunc of a connection, if it has one.
+type progressReporter struct {
+	fn       ProgressFunc
+	p        Progress
+	start    time.Time
+	reported int64
+}
+
+func (c *Connection) newProgress(operation string, totalRows int64) *progressReporter {
+	return &progressReporter{
+		fn:    c.progress,
+		p:     Progress{Operation: operation, TotalRows: totalRows},
+		start: time.Now(),
+	}
+}
+
+// add counts n more processed rows, and reports them if ProgressEvery
+// rows were processed since the last event.
+func (r *progressReporter) add(n int64) {
+	r.p.Rows += n
+	if r.p.Rows-r.reported >= ProgressEvery {
+		r.report()
+	}
+}
+
+// report sends an event with the current progress.
+func (r *progressReporter) report() {
+	if r.fn == nil {
+		return
+	}
+	r.reported = r.p.Rows
+	r.p.Elapsed = time.Since(r.start)
+	r.fn(r.p)
+}
+
+// done sends the last event of the operation.
+func (r *progressReporter) done() {
+	r.p.Done = true
+	r.report()
+}
+
+// reader counts the bytes read from rd.
+func (r *progressReporter) reader(rd io.Reader) io.Reader {
+	return progressReader{r: rd, p: &r.p}
+}
+
+// writer counts the bytes written to w.
+func (r *progressReporter) writer(w io.Writer) io.Writer {
+	return progressWriter{w: w, p: &r.p}
+}
+
+type progressReader struct {
+	r io.Reader
+	p *Progress
+}
+
+func (pr progressReader) Read(b []byte) (int, error) {
+	n, err := pr.r.Read(b)
+	pr.p.Bytes += int64(n)
+	return n, err
+}
+
+type progressWriter struct {
+	w io.Writer
+	p *Progress
+}
+
+func (pw progressWriter) Write(b []byte) (int, error) {
+	n, err := pw.w.Write(b)
+	pw.p.Bytes += int64(n)
+	return n, err
+}
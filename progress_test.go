@@ -0,0 +1,77 @@
+package pop_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/markbates/pop"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Progress_ETA(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal(3*time.Second, pop.Progress{Rows: 25, TotalRows: 100, Elapsed: time.Second}.ETA())
+	r.Equal(time.Duration(0), pop.Progress{Rows: 25, Elapsed: time.Second}.ETA())
+	r.Equal(time.Duration(0), pop.Progress{Rows: 100, TotalRows: 100, Elapsed: time.Second}.ETA())
+}
+
+func Test_WithProgress_CreateAll(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		events := []pop.Progress{}
+		ptx := tx.WithProgress(func(p pop.Progress) {
+			events = append(events, p)
+		})
+
+		labels := []Label{{Name: "a"}, {Name: "b"}, {Name: "c"}, {Name: "d"}, {Name: "e"}}
+		r.NoError(ptx.CreateAll(&labels, 2))
+		r.Len(events, 3)
+		for i, rows := range []int64{2, 4, 5} {
+			r.Equal("CreateAll", events[i].Operation)
+			r.Equal("labels", events[i].Table)
+			r.Equal(rows, events[i].Rows)
+			r.Equal(int64(5), events[i].TotalRows)
+			r.Equal(i == 2, events[i].Done)
+		}
+
+		events = events[:0]
+		batch := []Label{}
+		r.NoError(ptx.EachBatch(&batch, 2, func(interface{}) error { return nil }))
+		r.Len(events, 3)
+		r.Equal(int64(5), events[2].Rows)
+		r.True(events[2].Done)
+
+		events = events[:0]
+		r.NoError(tx.CreateAll(&[]Label{{Name: "f"}}, 1))
+		r.Len(events, 0)
+	})
+}
+
+func Test_WithProgress_Backup(t *testing.T) {
+	r := require.New(t)
+	oldEvery := pop.ProgressEvery
+	pop.ProgressEvery = 2
+	defer func() { pop.ProgressEvery = oldEvery }()
+
+	transaction(func(tx *pop.Connection) {
+		labels := []Label{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+		r.NoError(tx.CreateAll(&labels, 10))
+
+		events := []pop.Progress{}
+		bb := &bytes.Buffer{}
+		err := tx.WithProgress(func(p pop.Progress) {
+			events = append(events, p)
+		}).Backup(bb, pop.BackupOptions{Tables: []string{"labels"}, Native: true})
+		r.NoError(err)
+
+		r.Len(events, 3)
+		r.Equal(int64(2), events[0].Rows)
+		last := events[len(events)-1]
+		r.Equal("Backup", last.Operation)
+		r.Equal(int64(3), last.Rows)
+		r.Equal(int64(bb.Len()), last.Bytes)
+		r.True(last.Done)
+	})
+}
@@ -1,6 +1,9 @@
 package pop
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // Query is the main value that is used to build up a query
 // to be executed against the `Connection`.
@@ -9,6 +12,14 @@ type Query struct {
 	limitResults            int
 	eager                   bool
 	eagerFields             []string
+	eagerContinue           bool
+	eagerMaxDepth           int
+	eagerAllowed            []string
+	eagerMode               EagerMode
+	eagerScopes             map[string][]ScopeFunc
+	eagerPrefix             string
+	eagerErr                error
+	eagerParallel           int
 	whereClauses            clauses
 	orderClauses            clauses
 	fromClauses             fromClauses
@@ -16,7 +27,24 @@ type Query struct {
 	joinClauses             joinClauses
 	groupClauses            groupClauses
 	havingClauses           havingClauses
+	asOfSystemTime          time.Time
+	hints                   []string
+	indexHints              []string
+	unscoped                bool
+	lockMode                string
+	lockWait                string
+	partitionAt             time.Time
+	partitionFrom           time.Time
+	partitionTo             time.Time
+	subqueryModel           *Model
+	subqueryColumns         []string
+	selectSubqueries        []selectSubquery
+	selectExprs             clauses
+	distinct                bool
+	distinctOn              []string
+	preallocate             int
 	Paginator               *Paginator
+	CursorPaginator         *CursorPaginator
 	Connection              *Connection
 }
 
@@ -32,11 +60,31 @@ func (q *Query) Clone(targetQ *Query) {
 	targetQ.joinClauses = q.joinClauses
 	targetQ.groupClauses = q.groupClauses
 	targetQ.havingClauses = q.havingClauses
+	targetQ.asOfSystemTime = q.asOfSystemTime
+	targetQ.hints = q.hints
+	targetQ.indexHints = q.indexHints
+	targetQ.unscoped = q.unscoped
+	targetQ.lockMode = q.lockMode
+	targetQ.lockWait = q.lockWait
+	targetQ.partitionAt = q.partitionAt
+	targetQ.partitionFrom = q.partitionFrom
+	targetQ.partitionTo = q.partitionTo
+	targetQ.subqueryModel = q.subqueryModel
+	targetQ.subqueryColumns = q.subqueryColumns
+	targetQ.selectSubqueries = q.selectSubqueries
+	targetQ.selectExprs = q.selectExprs
+	targetQ.distinct = q.distinct
+	targetQ.distinctOn = q.distinctOn
+	targetQ.preallocate = q.preallocate
 
 	if q.Paginator != nil {
 		paginator := *q.Paginator
 		targetQ.Paginator = &paginator
 	}
+	if q.CursorPaginator != nil {
+		cp := *q.CursorPaginator
+		targetQ.CursorPaginator = &cp
+	}
 
 	if q.Connection != nil {
 		connection := *q.Connection
@@ -55,9 +103,11 @@ func (c *Connection) RawQuery(stmt string, args ...interface{}) *Query {
 
 // RawQuery will override the query building feature of Pop and will use
 // whatever query you want to execute against the `Connection`. You can continue
-// to use the `?` argument syntax.
+// to use the `?` argument syntax, or named `:name` placeholders with a map
+// or struct argument, see `Query.Where`.
 //
 //	q.RawQuery("select * from foo where id = ?", 1)
+//	q.RawQuery("select * from users where email = :email", map[string]interface{}{"email": e})
 func (q *Query) RawQuery(stmt string, args ...interface{}) *Query {
 	q.RawSQL = &clause{stmt, args}
 	return q
@@ -85,11 +135,46 @@ func (q *Query) Eager(fields ...string) *Query {
 	return q
 }
 
+// EagerContinue makes eager loading of a slice carry on past the elements
+// whose associations can not be loaded, for example because an associated
+// record is missing. An `EagerError` is collected for each of those elements
+// and all of them are returned together as `EagerErrors` once every element
+// has been processed.
+//
+// 	q.Eager().EagerContinue().All(&users)
+func (q *Query) EagerContinue() *Query {
+	q.eagerContinue = true
+	return q
+}
+
+// EagerMaxDepth limits how deep nested eager fields can go. For example
+// with a maximum depth of 2, "Books.User" can be loaded but
+// "Books.User.Books" returns an error. This is useful when the eager
+// fields come from user input, see `ParseIncludes`.
+//
+// 	q.EagerMaxDepth(2).Eager(fields...).All(&users)
+func (q *Query) EagerMaxDepth(n int) *Query {
+	q.eagerMaxDepth = n
+	return q
+}
+
+// EagerAllow restricts the eager fields that can be loaded to the given
+// paths, and the paths leading to them. Loading anything else returns an
+// error. When `Eager` is called without fields, only the allowed fields
+// are loaded.
+//
+// 	q.EagerAllow("Books.User", "FavoriteSong").Eager(fields...).All(&users)
+func (q *Query) EagerAllow(fields ...string) *Query {
+	q.eagerAllowed = append(q.eagerAllowed, fields...)
+	return q
+}
+
 // Where will append a where clause to the query. You may use `?` in place of
 // arguments.
 //
 // 	c.Where("id = ?", 1)
 // 	q.Where("id in (?)", 1, 2, 3)
+// 	c.Where("name = :name", map[string]interface{}{"name": "Mark"})
 func (c *Connection) Where(stmt string, args ...interface{}) *Query {
 	return Q(c).Where(stmt, args...)
 }
@@ -99,6 +184,11 @@ func (c *Connection) Where(stmt string, args ...interface{}) *Query {
 //
 // 	q.Where("id = ?", 1)
 // 	q.Where("id in (?)", 1, 2, 3)
+//
+// Named `:name` placeholders take their values from a single map or struct
+// argument, the struct fields being found by their `db` tag:
+//
+// 	q.Where("name = :name AND alive = :alive", user)
 func (q *Query) Where(stmt string, args ...interface{}) *Query {
 	if q.RawSQL.Fragment != "" {
 		fmt.Println("Warning: Query is setup to use raw SQL")
@@ -143,6 +233,7 @@ func Q(c *Connection) *Query {
 	return &Query{
 		RawSQL:     &clause{},
 		Connection: c,
+		eagerMode:  currentEagerMode(),
 	}
 }
 
@@ -153,6 +244,13 @@ func (q Query) ToSQL(model *Model, addColumns ...string) (string, []interface{})
 	return sb.String(), sb.Args()
 }
 
+// toSQL is like ToSQL, but also returns the errors found while building
+// the query, such as a clause with the wrong number of arguments.
+func (q Query) toSQL(model *Model, addColumns ...string) (string, []interface{}, error) {
+	sb := q.toSQLBuilder(model, addColumns...)
+	return sb.String(), sb.Args(), sb.Err()
+}
+
 // ToSQLBuilder returns a new `SQLBuilder` that can be used to generate SQL,
 // get arguments, and more.
 func (q Query) toSQLBuilder(model *Model, addColumns ...string) *sqlBuilder {
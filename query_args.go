@@ -0,0 +1,135 @@
+package pop
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// PlaceholderError is returned when the number of arguments given to a
+// clause of a query does not match the number of its `?` placeholders.
+type PlaceholderError struct {
+	// Clause is the kind of clause: select, where, join, having, order or
+	// raw query
+	Clause string
+	// Fragment is the SQL fragment of the clause
+	Fragment string
+	// Placeholders is the number of placeholders in the fragment
+	Placeholders int
+	// Arguments is the number of arguments provided
+	Arguments int
+}
+
+func (e *PlaceholderError) Error() string {
+	return fmt.Sprintf("%s clause %q has %d placeholders, but %d arguments were provided", e.Clause, e.Fragment, e.Placeholders, e.Arguments)
+}
+
+// placeholders returns the positions of the `?` placeholders of the
+// fragment, skipping the quoted strings.
+func placeholders(fragment string) []int {
+	positions := []int{}
+	var quote rune
+	for i, r := range fragment {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '?':
+			positions = append(positions, i)
+		}
+	}
+	return positions
+}
+
+// bindArgs checks the arguments of a clause against its placeholders, and
+// expands the placeholders of an `in (?)` bound to several values: a slice
+// argument, or the extra arguments of a single `in (?)`.
+//
+//	bindArgs("where", "id in (?)", []interface{}{1, 2, 3})
+//	// "id in (?, ?, ?)", []interface{}{1, 2, 3}
+//
+// The other slices, and the slices implementing `driver.Valuer` such as
+// the PostgreSQL arrays, are bound as a single value.
+func bindArgs(kind string, fragment string, args []interface{}) (string, []interface{}, error) {
+	fragment, args, err := bindNamed(kind, fragment, args)
+	if err != nil {
+		return fragment, args, err
+	}
+	positions := placeholders(fragment)
+	ins := inPlaceholders(fragment, positions)
+	counts := make([]int, len(positions))
+	bound := make([]interface{}, 0, len(args))
+
+	switch {
+	case len(positions) == len(args):
+		for i, arg := range args {
+			counts[i] = 1
+			if !ins[i] || !expandable(arg) {
+				bound = append(bound, arg)
+				continue
+			}
+			v := reflect.ValueOf(arg)
+			if v.Len() == 0 {
+				return fragment, args, errors.Errorf("%s clause %q got an empty slice for placeholder %d", kind, fragment, i+1)
+			}
+			counts[i] = v.Len()
+			for j := 0; j < v.Len(); j++ {
+				bound = append(bound, v.Index(j).Interface())
+			}
+		}
+	case len(args) > len(positions) && len(ins) == 1:
+		in := -1
+		for i := range ins {
+			in = i
+		}
+		for i := range counts {
+			counts[i] = 1
+		}
+		counts[in] = len(args) - len(positions) + 1
+		bound = append(bound, args...)
+	default:
+		return fragment, args, &PlaceholderError{kind, fragment, len(positions), len(args)}
+	}
+
+	expanded := fragment
+	for i := len(positions) - 1; i >= 0; i-- {
+		if counts[i] > 1 {
+			p := positions[i]
+			expanded = expanded[:p] + strings.Repeat("?, ", counts[i]-1) + expanded[p:]
+		}
+	}
+	return expanded, bound, nil
+}
+
+// inPlaceholders returns the indexes of the placeholders at positions
+// written as `in (?)`.
+func inPlaceholders(fragment string, positions []int) map[int]bool {
+	ins := map[int]bool{}
+	for _, loc := range inRegex.FindAllStringIndex(fragment, -1) {
+		for i, p := range positions {
+			if p >= loc[0] && p < loc[1] {
+				ins[i] = true
+			}
+		}
+	}
+	return ins
+}
+
+// expandable returns true if arg is a list of values, rather than a
+// value the driver binds as a whole like []byte or a `driver.Valuer`.
+func expandable(arg interface{}) bool {
+	if arg == nil {
+		return false
+	}
+	if _, ok := arg.(driver.Valuer); ok {
+		return false
+	}
+	v := reflect.ValueOf(arg)
+	return v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8
+}
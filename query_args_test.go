@@ -0,0 +1,79 @@
+package pop
+
+import (
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_bindArgs(t *testing.T) {
+	r := require.New(t)
+
+	f, args, err := bindArgs("where", "name = ? AND email = ?", []interface{}{"Mark", "mark@example.com"})
+	r.NoError(err)
+	r.Equal("name = ? AND email = ?", f)
+	r.Equal([]interface{}{"Mark", "mark@example.com"}, args)
+
+	f, args, err = bindArgs("where", "name = ? AND id in (?)", []interface{}{"Mark", 1, 2, 3})
+	r.NoError(err)
+	r.Equal("name = ? AND id in (?, ?, ?)", f)
+	r.Equal([]interface{}{"Mark", 1, 2, 3}, args)
+
+	f, args, err = bindArgs("where", "id in (?) AND name = ?", []interface{}{[]int{1, 2}, "Mark"})
+	r.NoError(err)
+	r.Equal("id in (?, ?) AND name = ?", f)
+	r.Equal([]interface{}{1, 2, "Mark"}, args)
+
+	f, args, err = bindArgs("where", "name = '?' AND data = ?", []interface{}{[]byte("x")})
+	r.NoError(err)
+	r.Equal("name = '?' AND data = ?", f)
+	r.Equal([]interface{}{[]byte("x")}, args)
+
+	_, _, err = bindArgs("where", "id in (?)", []interface{}{[]int{}})
+	r.Error(err)
+}
+
+func Test_bindArgs_Slices(t *testing.T) {
+	r := require.New(t)
+
+	// only the slices of an in (?) are lists of values.
+	tags := pq.StringArray{"a", "b"}
+	f, args, err := bindArgs("where", "tags = ?", []interface{}{tags})
+	r.NoError(err)
+	r.Equal("tags = ?", f)
+	r.Equal([]interface{}{tags}, args)
+
+	f, args, err = bindArgs("where", "tags && ?", []interface{}{[]string{"a", "b"}})
+	r.NoError(err)
+	r.Equal("tags && ?", f)
+	r.Equal([]interface{}{[]string{"a", "b"}}, args)
+
+	// a Valuer is a single value, even in an in (?).
+	f, args, err = bindArgs("where", "tags in (?)", []interface{}{tags})
+	r.NoError(err)
+	r.Equal("tags in (?)", f)
+	r.Equal([]interface{}{tags}, args)
+}
+
+func Test_bindArgs_Mismatch(t *testing.T) {
+	r := require.New(t)
+
+	_, _, err := bindArgs("where", "name = ? AND email = ?", []interface{}{"Mark"})
+	r.Error(err)
+	perr, ok := err.(*PlaceholderError)
+	r.True(ok)
+	r.Equal("where", perr.Clause)
+	r.Equal(2, perr.Placeholders)
+	r.Equal(1, perr.Arguments)
+	r.Equal(`where clause "name = ? AND email = ?" has 2 placeholders, but 1 arguments were provided`, err.Error())
+
+	_, _, err = bindArgs("where", "name = ?", []interface{}{"Mark", "Ringo"})
+	r.Error(err)
+
+	_, _, err = bindArgs("having", "count(*) > 1", []interface{}{1})
+	r.Error(err)
+
+	_, _, err = bindArgs("where", "a in (?) AND b in (?)", []interface{}{1, 2, 3})
+	r.Error(err)
+}
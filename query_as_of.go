@@ -0,0 +1,29 @@
+package pop
+
+import (
+	"fmt"
+	"time"
+)
+
+// AsOfSystemTime makes a CockroachDB query read the data as it was at
+// the given time. Reading slightly stale data lets CockroachDB serve the
+// query from the closest replica (follower reads), without contending
+// with writes. Other dialects ignore it.
+//
+//	q.AsOfSystemTime(time.Now().Add(-10 * time.Second)).All(&users)
+func (q *Query) AsOfSystemTime(t time.Time) *Query {
+	if q.RawSQL.Fragment != "" {
+		fmt.Println("Warning: Query is setup to use raw SQL")
+		return q
+	}
+	q.asOfSystemTime = t
+	return q
+}
+
+func (sq *sqlBuilder) buildAsOfSystemTimeClause(sql string) string {
+	t := sq.Query.asOfSystemTime
+	if _, ok := sq.Query.Connection.Dialect.(*cockroach); !ok || t.IsZero() {
+		return sql
+	}
+	return fmt.Sprintf("%s AS OF SYSTEM TIME '%s'", sql, t.UTC().Format("2006-01-02 15:04:05.999999"))
+}
@@ -0,0 +1,103 @@
+package pop
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Condition is a boolean expression of a WHERE clause, built with `Cond`,
+// and combined with `And`, `Or` and `Not`, which parenthesize their
+// operands:
+//
+//	cond := pop.And(
+//		pop.Cond("active = ?", true),
+//		pop.Or(pop.Cond("role = ?", "admin"), pop.Not(pop.Cond("email LIKE ?", "%@example.com"))),
+//	)
+//	err := c.WhereCond(cond).All(&users)
+//	// WHERE ((active = ?) AND ((role = ?) OR NOT (email LIKE ?)))
+type Condition struct {
+	fragment string
+	args     []interface{}
+	// op is the operator joining the operands of an And or an Or, so that
+	// nested ones with the same operator are flattened.
+	op string
+	// grouped is true if the fragment needs no parentheses to be an
+	// operand.
+	grouped bool
+}
+
+// Cond returns the condition of an SQL fragment, with one argument per
+// `?` placeholder. A slice argument fills an `in (?)` placeholder.
+func Cond(stmt string, args ...interface{}) Condition {
+	return Condition{fragment: stmt, args: args}
+}
+
+// And returns the condition true when all of conds are. Without
+// conditions, it is always true.
+func And(conds ...Condition) Condition {
+	return combine("AND", "1 = 1", conds)
+}
+
+// Or returns the condition true when any of conds is. Without conditions,
+// it is always false.
+func Or(conds ...Condition) Condition {
+	return combine("OR", "1 = 0", conds)
+}
+
+// Not returns the negation of cond.
+func Not(cond Condition) Condition {
+	return Condition{fragment: "NOT " + cond.group(), args: cond.args, grouped: true}
+}
+
+func combine(op string, empty string, conds []Condition) Condition {
+	switch len(conds) {
+	case 0:
+		return Condition{fragment: empty, args: []interface{}{}, grouped: true}
+	case 1:
+		return conds[0]
+	}
+	fragments := make([]string, 0, len(conds))
+	args := []interface{}{}
+	for _, c := range conds {
+		if c.op == op {
+			fragments = append(fragments, c.fragment)
+		} else {
+			fragments = append(fragments, c.group())
+		}
+		args = append(args, c.args...)
+	}
+	return Condition{fragment: strings.Join(fragments, " "+op+" "), args: args, op: op}
+}
+
+// group returns the fragment of the condition as an operand.
+func (c Condition) group() string {
+	if c.grouped {
+		return c.fragment
+	}
+	return "(" + c.fragment + ")"
+}
+
+// ToSQL returns the SQL fragment of the condition, with `?` placeholders,
+// and its arguments.
+func (c Condition) ToSQL() (string, []interface{}) {
+	return c.fragment, c.args
+}
+
+// WhereCond adds the condition cond to the query, see `Query.WhereCond`.
+func (c *Connection) WhereCond(cond Condition) *Query {
+	return Q(c).WhereCond(cond)
+}
+
+// WhereCond adds the condition cond to the query. It is parenthesized, so
+// that it can be mixed with the clauses of `Where`:
+//
+//	q.Where("deleted = ?", false).WhereCond(pop.Or(pop.Cond("a = ?", 1), pop.Cond("b = ?", 2)))
+//	// WHERE deleted = ? AND ((a = ?) OR (b = ?))
+func (q *Query) WhereCond(cond Condition) *Query {
+	if q.RawSQL.Fragment != "" {
+		fmt.Println("Warning: Query is setup to use raw SQL")
+		return q
+	}
+	q.whereClauses = append(q.whereClauses, clause{"(" + cond.fragment + ")", cond.args})
+	return q
+}
@@ -0,0 +1,69 @@
+package pop_test
+
+import (
+	"testing"
+
+	"github.com/markbates/pop"
+	"github.com/markbates/pop/nulls"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Conditions(t *testing.T) {
+	r := require.New(t)
+
+	cond := pop.And(
+		pop.Cond("a = ?", 1),
+		pop.Or(pop.Cond("b = ?", 2), pop.Not(pop.Cond("c = ? OR d = ?", 3, 4))),
+		pop.And(pop.Cond("e = ?", 5), pop.Cond("f = ?", 6)),
+	)
+	q, args := cond.ToSQL()
+	r.Equal("(a = ?) AND ((b = ?) OR NOT (c = ? OR d = ?)) AND (e = ?) AND (f = ?)", q)
+	r.Equal([]interface{}{1, 2, 3, 4, 5, 6}, args)
+
+	q, args = pop.Not(pop.Or(pop.Cond("a = ?", 1), pop.Cond("b = ?", 2))).ToSQL()
+	r.Equal("NOT ((a = ?) OR (b = ?))", q)
+	r.Equal([]interface{}{1, 2}, args)
+
+	q, _ = pop.And().ToSQL()
+	r.Equal("1 = 1", q)
+	q, _ = pop.Or().ToSQL()
+	r.Equal("1 = 0", q)
+	q, _ = pop.Or(pop.Cond("a = ?", 1)).ToSQL()
+	r.Equal("a = ?", q)
+}
+
+func Test_WhereCond_ToSQL(t *testing.T) {
+	r := require.New(t)
+
+	c, err := pop.NewConnection(&pop.ConnectionDetails{Dialect: "postgres", Database: "pop_test"})
+	r.NoError(err)
+
+	cond := pop.Or(pop.Cond("name = ?", "Mark"), pop.Cond("id IN (?)", []int{1, 2}))
+	q, args := c.Where("alive = ?", true).WhereCond(cond).ToSQL(&pop.Model{Value: &User{}}, "id")
+	r.Equal("SELECT id FROM users AS users WHERE alive = $1 AND ((name = $2) OR (id IN ($3, $4)))", q)
+	r.Equal([]interface{}{true, "Mark", 1, 2}, args)
+}
+
+func Test_WhereCond(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		for _, name := range []string{"Mark", "Joe", "Jane"} {
+			r.NoError(tx.Create(&User{Name: nulls.NewString(name), Alive: nulls.NewBool(name != "Jane")}))
+		}
+
+		users := []User{}
+		err := tx.WhereCond(pop.And(
+			pop.Cond("alive = ?", true),
+			pop.Or(pop.Cond("name = ?", "Mark"), pop.Cond("name = ?", "Jane")),
+		)).All(&users)
+		r.NoError(err)
+		r.Len(users, 1)
+		r.Equal("Mark", users[0].Name.String)
+
+		users = []User{}
+		err = tx.WhereCond(pop.Not(pop.Cond("name IN (?)", []string{"Mark", "Joe"}))).All(&users)
+		r.NoError(err)
+		r.Len(users, 1)
+		r.Equal("Jane", users[0].Name.String)
+	})
+}
@@ -0,0 +1,58 @@
+package pop
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Distinct removes the duplicate rows from the results of the query,
+// typically made of a few columns of a table:
+//
+//	type City struct {
+//		Name string `db:"city"`
+//	}
+//
+//	func (City) TableName() string { return "users" }
+//
+//	err := c.Q().Distinct().All(&cities)
+func (q *Query) Distinct() *Query {
+	q.distinct = true
+	return q
+}
+
+// DistinctOn keeps the first row of each group of rows with the same
+// values of columns, in the order of the query, which must start with
+// these columns:
+//
+//	// the last login of each user
+//	err := c.Q().DistinctOn("user_id").Order("user_id, created_at desc").All(&logins)
+//
+// It is supported by PostgreSQL and CockroachDB only, see
+// `Capabilities.DistinctOn`: the query fails on the other databases.
+func (q *Query) DistinctOn(columns ...string) *Query {
+	q.distinctOn = append(q.distinctOn, columns...)
+	return q
+}
+
+// buildDistinct returns the DISTINCT modifier of the select list, if any.
+func (sq *sqlBuilder) buildDistinct() string {
+	q := sq.Query
+	if len(q.distinctOn) > 0 {
+		if !q.Connection.Dialect.Capabilities().DistinctOn {
+			if sq.err == nil {
+				sq.err = errors.Errorf("DISTINCT ON is not supported by %s", q.Connection.Dialect.Details().Dialect)
+			}
+			return ""
+		}
+		cols := make([]string, len(q.distinctOn))
+		for i, c := range q.distinctOn {
+			cols[i] = q.Connection.Dialect.Quote(c)
+		}
+		return "DISTINCT ON (" + strings.Join(cols, ", ") + ") "
+	}
+	if q.distinct {
+		return "DISTINCT "
+	}
+	return ""
+}
@@ -2,7 +2,10 @@ package pop
 
 import "fmt"
 
-// Having will append a HAVING clause to the query
+// Having will append a HAVING clause to the query, filtering the groups
+// of `GroupBy` on aggregates. Several clauses are joined with AND.
+//
+//	q.GroupBy("user_id").Having("count(*) > ?", 5)
 func (q *Query) Having(condition string, args ...interface{}) *Query {
 	if q.RawSQL.Fragment != "" {
 		fmt.Println("Warning: Query is setup to use raw SQL")
@@ -0,0 +1,69 @@
+package pop
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Hint adds an optimizer hint comment to the query, such as the ones used
+// by MySQL or the pg_hint_plan PostgreSQL extension. On MySQL, hints are
+// placed right after the SELECT keyword; on other dialects, they are placed
+// at the head of the query.
+//
+//	q.Hint("/*+ IndexScan(users users_email_idx) */").All(&users)
+func (q *Query) Hint(hint string) *Query {
+	if q.RawSQL.Fragment != "" {
+		fmt.Println("Warning: Query is setup to use raw SQL")
+		return q
+	}
+	q.hints = append(q.hints, hint)
+	return q
+}
+
+// UseIndex adds a MySQL USE INDEX hint for the table of the model, to
+// suggest the indexes the planner should pick from. Other dialects ignore it.
+//
+//	q.UseIndex("users_email_idx").Where("email = ?", email).First(&u)
+func (q *Query) UseIndex(indexes ...string) *Query {
+	return q.indexHint("USE INDEX", indexes)
+}
+
+// ForceIndex adds a MySQL FORCE INDEX hint for the table of the model, so
+// a table scan is only used if none of the indexes can be. Other dialects
+// ignore it.
+func (q *Query) ForceIndex(indexes ...string) *Query {
+	return q.indexHint("FORCE INDEX", indexes)
+}
+
+// IgnoreIndex adds a MySQL IGNORE INDEX hint for the table of the model, so
+// the planner does not use the indexes. Other dialects ignore it.
+func (q *Query) IgnoreIndex(indexes ...string) *Query {
+	return q.indexHint("IGNORE INDEX", indexes)
+}
+
+func (q *Query) indexHint(kind string, indexes []string) *Query {
+	if q.RawSQL.Fragment != "" {
+		fmt.Println("Warning: Query is setup to use raw SQL")
+		return q
+	}
+	q.indexHints = append(q.indexHints, fmt.Sprintf("%s (%s)", kind, strings.Join(indexes, ", ")))
+	return q
+}
+
+func (sq *sqlBuilder) buildHints(sql string) string {
+	if len(sq.Query.hints) == 0 {
+		return sql
+	}
+	hints := strings.Join(sq.Query.hints, " ")
+	if _, ok := sq.Query.Connection.Dialect.(*mysql); ok {
+		return strings.Replace(sql, "SELECT ", fmt.Sprintf("SELECT %s ", hints), 1)
+	}
+	return fmt.Sprintf("%s %s", hints, sql)
+}
+
+func (sq *sqlBuilder) buildIndexHints() string {
+	if _, ok := sq.Query.Connection.Dialect.(*mysql); !ok {
+		return ""
+	}
+	return strings.Join(sq.Query.indexHints, " ")
+}
@@ -0,0 +1,71 @@
+package pop
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LockForUpdate locks the rows read by the query until the end of the
+// transaction, so that the other transactions can neither update nor lock
+// them:
+//
+//	err := c.Transaction(func(tx *pop.Connection) error {
+//		a := &Account{}
+//		if err := tx.Q().LockForUpdate().Find(a, id); err != nil {
+//			return err
+//		}
+//		a.Balance -= amount
+//		return tx.Update(a)
+//	})
+//
+// The dialects without row locks, such as SQLite, ignore it.
+func (q *Query) LockForUpdate() *Query {
+	return q.lockRows("UPDATE")
+}
+
+// LockForShare locks the rows read by the query until the end of the
+// transaction, so that the other transactions can read and share-lock
+// them, but not update them. The dialects without row locks, such as
+// SQLite, ignore it.
+func (q *Query) LockForShare() *Query {
+	return q.lockRows("SHARE")
+}
+
+// NoWait makes a locking query fail at once, instead of waiting, if one of
+// its rows is locked by another transaction. MySQL supports it since 8.0.
+func (q *Query) NoWait() *Query {
+	q.lockWait = "NOWAIT"
+	return q
+}
+
+// SkipLocked makes a locking query skip the rows locked by other
+// transactions, which lets workers take jobs from a table concurrently:
+//
+//	tx.Where("state = ?", "queued").Order("id asc").Limit(10).LockForUpdate().SkipLocked().All(&jobs)
+//
+// MySQL supports it since 8.0.
+func (q *Query) SkipLocked() *Query {
+	q.lockWait = "SKIP LOCKED"
+	return q
+}
+
+func (q *Query) lockRows(mode string) *Query {
+	if q.RawSQL.Fragment != "" {
+		fmt.Println("Warning: Query is setup to use raw SQL")
+		return q
+	}
+	q.lockMode = mode
+	return q
+}
+
+func (sq *sqlBuilder) buildLockClause(sql string) string {
+	q := sq.Query
+	if q.lockMode == "" || !q.Connection.Dialect.Capabilities().RowLocks {
+		return sql
+	}
+	if _, ok := q.Connection.Dialect.(*mysql); ok && q.lockMode == "SHARE" && q.lockWait == "" {
+		// FOR SHARE is only understood by MySQL 8.0.
+		return sql + " LOCK IN SHARE MODE"
+	}
+	return strings.TrimSpace(fmt.Sprintf("%s FOR %s %s", sql, q.lockMode, q.lockWait))
+}
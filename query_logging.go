@@ -0,0 +1,70 @@
+package pop
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Quiet disables the debug logging of the statements run by the query,
+// to keep noisy hot loops out of the logs. Statements still go through
+// the instrumenters.
+//
+//	for _, id := range ids {
+//		err := c.Quiet().Find(&u, id)
+//	}
+func (c *Connection) Quiet() *Query {
+	return Q(c).Quiet()
+}
+
+// Quiet disables the debug logging of the statements run by the query,
+// to keep noisy hot loops out of the logs. Statements still go through
+// the instrumenters.
+func (q *Query) Quiet() *Query {
+	if q.Connection != nil {
+		q.Connection = q.Connection.quietly()
+	}
+	return q
+}
+
+// Redacted replaces the sensitive arguments in the logs.
+const Redacted = "[REDACTED]"
+
+// LogRedactor is called with every statement logged by `Log`, along with
+// its arguments, and returns the arguments to log instead. It can be used
+// to mask sensitive values, such as passwords or tokens. See
+// `RedactColumns` for a redactor based on column names.
+var LogRedactor func(sql string, args []interface{}) []interface{}
+
+var placeholderRx = regexp.MustCompile(`\?|\$\d+`)
+var comparedColumnRx = regexp.MustCompile(`(?i)(\w+)\s*(?:=|<>|!=|<=|>=|<|>|\s+like)\s*$`)
+
+// RedactColumns returns a `LogRedactor` masking the arguments compared to
+// the given columns, such as `password = ?` or `users.token = $2`.
+//
+//	pop.LogRedactor = pop.RedactColumns("password", "token")
+func RedactColumns(columns ...string) func(string, []interface{}) []interface{} {
+	sensitive := map[string]bool{}
+	for _, c := range columns {
+		sensitive[strings.ToLower(c)] = true
+	}
+	return func(sql string, args []interface{}) []interface{} {
+		redacted := make([]interface{}, len(args))
+		copy(redacted, args)
+		for i, loc := range placeholderRx.FindAllStringIndex(sql, -1) {
+			n := i
+			if p := sql[loc[0]:loc[1]]; p != "?" {
+				n, _ = strconv.Atoi(p[1:])
+				n--
+			}
+			if n < 0 || n >= len(redacted) {
+				continue
+			}
+			m := comparedColumnRx.FindStringSubmatch(sql[:loc[0]])
+			if m != nil && sensitive[strings.ToLower(m[1])] {
+				redacted[n] = Redacted
+			}
+		}
+		return redacted
+	}
+}
@@ -0,0 +1,50 @@
+package pop
+
+import (
+	"bytes"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RedactColumns(t *testing.T) {
+	r := require.New(t)
+
+	redact := RedactColumns("password", "token")
+
+	args := redact("SELECT * FROM users WHERE email = ? AND password = ?", []interface{}{"mark@example.com", "secret"})
+	r.Equal([]interface{}{"mark@example.com", Redacted}, args)
+
+	args = redact("SELECT * FROM users WHERE users.token=$2 AND email = $1", []interface{}{"mark@example.com", "secret"})
+	r.Equal([]interface{}{"mark@example.com", Redacted}, args)
+
+	args = redact("SELECT * FROM users WHERE Password LIKE ? AND id in (?, ?)", []interface{}{"secret", 1, 2})
+	r.Equal([]interface{}{Redacted, 1, 2}, args)
+
+	original := []interface{}{"secret"}
+	redact("SELECT * FROM users WHERE password = ?", original)
+	r.Equal([]interface{}{"secret"}, original)
+}
+
+func Test_Log_LogRedactor(t *testing.T) {
+	r := require.New(t)
+
+	buf := &bytes.Buffer{}
+	oldLogger := logger
+	logger = log.New(buf, "", 0)
+	Debug = true
+	Color = false
+	LogRedactor = RedactColumns("password")
+	defer func() {
+		logger = oldLogger
+		Debug = false
+		Color = true
+		LogRedactor = nil
+	}()
+
+	Log("SELECT * FROM users WHERE email = ? AND password = ?", "mark@example.com", "secret")
+	r.Contains(buf.String(), `"mark@example.com"`)
+	r.Contains(buf.String(), Redacted)
+	r.NotContains(buf.String(), "secret")
+}
@@ -0,0 +1,138 @@
+package pop
+
+import (
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// bindNamed replaces the `:name` placeholders of a clause by `?` ones,
+// when its only argument is a map or a struct holding their values:
+//
+//	c.RawQuery("select * from users where email = :email", map[string]interface{}{"email": e})
+//	c.Where("name = :name AND alive = :alive", user)
+//
+// The struct fields are found by their `db` column name. A slice value
+// fills an `in (:ids)` list. The `::` casts of PostgreSQL are left as is.
+func bindNamed(kind string, fragment string, args []interface{}) (string, []interface{}, error) {
+	if len(args) != 1 || len(placeholders(fragment)) > 0 {
+		return fragment, args, nil
+	}
+	lookup := namedValues(args[0])
+	if lookup == nil {
+		return fragment, args, nil
+	}
+	names, positions := namedParams(fragment)
+	if len(names) == 0 {
+		return fragment, args, nil
+	}
+	bound := make([]interface{}, 0, len(names))
+	for _, name := range names {
+		v, ok := lookup(name)
+		if !ok {
+			return fragment, args, errors.Errorf("%s clause %q has no value for the named parameter :%s", kind, fragment, name)
+		}
+		bound = append(bound, v)
+	}
+	expanded := fragment
+	for i := len(positions) - 1; i >= 0; i-- {
+		p := positions[i]
+		expanded = expanded[:p] + "?" + expanded[p+len(names[i])+1:]
+	}
+	return expanded, bound, nil
+}
+
+// namedParams returns the names and positions of the `:name` placeholders
+// of the fragment, skipping the quoted strings and the `::` casts.
+func namedParams(fragment string) ([]string, []int) {
+	names := []string{}
+	positions := []int{}
+	var quote byte
+	for i := 0; i < len(fragment); i++ {
+		b := fragment[i]
+		switch {
+		case quote != 0:
+			if b == quote {
+				quote = 0
+			}
+		case b == '\'' || b == '"' || b == '`':
+			quote = b
+		case b == ':':
+			if i+1 < len(fragment) && fragment[i+1] == ':' {
+				i++
+				continue
+			}
+			j := i + 1
+			for j < len(fragment) && isNameByte(fragment[j], j == i+1) {
+				j++
+			}
+			if j > i+1 {
+				names = append(names, fragment[i+1:j])
+				positions = append(positions, i)
+				i = j - 1
+			}
+		}
+	}
+	return names, positions
+}
+
+func isNameByte(b byte, first bool) bool {
+	switch {
+	case b == '_', b >= 'a' && b <= 'z', b >= 'A' && b <= 'Z':
+		return true
+	case b >= '0' && b <= '9':
+		return !first
+	}
+	return false
+}
+
+// namedValues returns the function looking up the named values held by
+// arg, a map with string keys or a struct, or nil for any other value.
+func namedValues(arg interface{}) func(string) (interface{}, bool) {
+	if arg == nil {
+		return nil
+	}
+	v := reflect.ValueOf(arg)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil
+		}
+		return func(name string) (interface{}, bool) {
+			mv := v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key()))
+			if !mv.IsValid() {
+				return nil, false
+			}
+			return mv.Interface(), true
+		}
+	case reflect.Struct:
+		if v.Type() == timeType || reflect.PtrTo(v.Type()).Implements(valuerType) {
+			return nil
+		}
+		tm := fieldMapper.TypeMap(v.Type())
+		return func(name string) (interface{}, bool) {
+			fi, ok := tm.Names[name]
+			if !ok {
+				return nil, false
+			}
+			f := v
+			for _, i := range fi.Index {
+				if f.Kind() == reflect.Ptr {
+					if f.IsNil() {
+						return nil, true
+					}
+					f = f.Elem()
+				}
+				f = f.Field(i)
+			}
+			return f.Interface(), true
+		}
+	}
+	return nil
+}
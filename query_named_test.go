@@ -0,0 +1,41 @@
+package pop
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_bindNamed(t *testing.T) {
+	r := require.New(t)
+
+	f, args, err := bindArgs("raw query", "select * from users where email = :email and name <> :name", []interface{}{map[string]interface{}{"email": "mark@example.com", "name": "Mark"}})
+	r.NoError(err)
+	r.Equal("select * from users where email = ? and name <> ?", f)
+	r.Equal([]interface{}{"mark@example.com", "Mark"}, args)
+
+	type person struct {
+		Name  string `db:"name"`
+		Alive bool   `db:"alive"`
+	}
+	f, args, err = bindArgs("where", "name = :name AND alive = :alive AND name <> :name", []interface{}{&person{Name: "Mark", Alive: true}})
+	r.NoError(err)
+	r.Equal("name = ? AND alive = ? AND name <> ?", f)
+	r.Equal([]interface{}{"Mark", true, "Mark"}, args)
+
+	f, args, err = bindArgs("where", "id in (:ids) AND created_at::date = :day AND t = '12:30'", []interface{}{map[string]interface{}{"ids": []int{1, 2}, "day": "2018-01-01"}})
+	r.NoError(err)
+	r.Equal("id in (?, ?) AND created_at::date = ? AND t = '12:30'", f)
+	r.Equal([]interface{}{1, 2, "2018-01-01"}, args)
+
+	_, _, err = bindArgs("where", "name = :name", []interface{}{map[string]interface{}{}})
+	r.Error(err)
+	r.Contains(err.Error(), ":name")
+
+	// positional placeholders are left alone
+	m := map[string]interface{}{"a": 1}
+	f, args, err = bindArgs("where", "data = ? AND x = ':a'", []interface{}{m})
+	r.NoError(err)
+	r.Equal("data = ? AND x = ':a'", f)
+	r.Equal([]interface{}{m}, args)
+}
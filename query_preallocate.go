@@ -0,0 +1,59 @@
+package pop
+
+import "reflect"
+
+// maxLimitPreallocation caps the models allocated up front for the limit
+// of a query, which may be far beyond the number of results.
+const maxLimitPreallocation = 1000
+
+// Preallocate hints that the query returns about n models, so that `All`
+// makes room for them in the slice at once rather than growing it as the
+// rows come:
+//
+//	c.Where("account_id = ?", id).Preallocate(5000).All(&events)
+//
+// The queries with a limit, or paginated, make room for it, up to 1000
+// models, without the hint.
+func (q *Query) Preallocate(n int) *Query {
+	q.preallocate = n
+	return q
+}
+
+// sizeHint returns the number of models the query is expected to return,
+// or 0 if it is not known.
+func (q Query) sizeHint() int {
+	if q.preallocate > 0 {
+		return q.preallocate
+	}
+	n := q.limitResults
+	if q.Paginator != nil {
+		n = q.Paginator.PerPage
+	}
+	if n > maxLimitPreallocation {
+		return maxLimitPreallocation
+	}
+	return n
+}
+
+// preallocate makes room for n more elements in the slice dest points to.
+// It returns the function setting a nil slice back to nil if nothing was
+// added to it, as it would be without the preallocation.
+func preallocate(dest interface{}, n int) func() {
+	v := reflect.ValueOf(dest)
+	if n <= 0 || v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Slice {
+		return func() {}
+	}
+	s := v.Elem()
+	if s.Cap()-s.Len() >= n {
+		return func() {}
+	}
+	wasNil := s.IsNil()
+	grown := reflect.MakeSlice(s.Type(), s.Len(), s.Len()+n)
+	reflect.Copy(grown, s)
+	s.Set(grown)
+	return func() {
+		if wasNil && s.Len() == 0 {
+			s.Set(reflect.Zero(s.Type()))
+		}
+	}
+}
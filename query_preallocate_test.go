@@ -0,0 +1,44 @@
+package pop_test
+
+import (
+	"testing"
+
+	"github.com/markbates/pop"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Preallocate(t *testing.T) {
+	transaction(func(tx *pop.Connection) {
+		r := require.New(t)
+
+		for _, title := range []string{"Emma", "Persuasion", "Sanditon"} {
+			r.NoError(tx.Create(&Book{Title: title}))
+		}
+
+		books := Books{}
+		r.NoError(tx.Order("title").Preallocate(50).All(&books))
+		r.Len(books, 3)
+		r.Equal(50, cap(books))
+		r.Equal("Sanditon", books[2].Title)
+
+		books = Books{}
+		r.NoError(tx.Order("title").Limit(2).All(&books))
+		r.Len(books, 2)
+		r.Equal(2, cap(books))
+
+		books = Books{}
+		r.NoError(tx.Order("title").Paginate(1, 10).All(&books))
+		r.Len(books, 3)
+		r.Equal(10, cap(books))
+	})
+}
+
+func Test_Preallocate_NoResults(t *testing.T) {
+	transaction(func(tx *pop.Connection) {
+		r := require.New(t)
+
+		var books Books
+		r.NoError(tx.Where("title = ?", "Lady Susan").Preallocate(50).All(&books))
+		r.Nil(books)
+	})
+}
@@ -0,0 +1,88 @@
+package pop
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/markbates/pop/columns"
+)
+
+// the alias ending a select expression, e.g. "... AS rank".
+var exprAlias = regexp.MustCompile("(?i)\\s+AS\\s+[\"`]?(\\w+)[\"`]?\\s*$")
+
+// SelectExpr adds the SQL expression expr, such as a window function, to
+// the columns selected by the query. Its arguments fill its `?`
+// placeholders. An expression named with AS replaces the column of the
+// model of the same name, typically a read only field:
+//
+//	type Player struct {
+//		ID     int `db:"id"`
+//		TeamID int `db:"team_id"`
+//		Score  int `db:"score"`
+//		Rank   int `db:"rank" rw:"r"`
+//	}
+//
+//	err := c.Q().SelectExpr("row_number() over (partition by team_id order by score desc) as rank").All(&players)
+//
+// The columns of the results the model does not have are ignored, so that
+// an expression can also serve the ordering of an outer query.
+func (q *Query) SelectExpr(expr string, args ...interface{}) *Query {
+	if q.RawSQL.Fragment != "" {
+		fmt.Println("Warning: Query is setup to use raw SQL")
+		return q
+	}
+	q.selectExprs = append(q.selectExprs, clause{expr, args})
+	return q
+}
+
+// buildSelectExprs returns the expressions selected by the query, and
+// removes the model columns they replace from rc.
+func (sq *sqlBuilder) buildSelectExprs(rc *columns.ReadableColumns) []string {
+	exprs := []string{}
+	for _, e := range sq.Query.selectExprs {
+		if m := exprAlias.FindStringSubmatch(e.Fragment); m != nil {
+			delete(rc.Cols, m[1])
+		}
+		expr, args := sq.bind("select", e.Fragment, e.Arguments)
+		sq.args = append(sq.args, args...)
+		exprs = append(exprs, strings.TrimSpace(expr))
+	}
+	return exprs
+}
+
+// store returns the store the results of the query are read from, which
+// tolerates the columns the model does not have if the query selects
+// expressions.
+func (q *Query) store() store {
+	s := q.Connection.Store
+	if len(q.selectExprs) == 0 {
+		return s
+	}
+	if us, ok := s.(unsafeStore); ok {
+		return us.unsafe()
+	}
+	return s
+}
+
+// unsafeStore is implemented by the stores able to scan rows into structs
+// missing some of their columns.
+type unsafeStore interface {
+	unsafe() store
+}
+
+func (db *dB) unsafe() store {
+	return &dB{DB: db.DB.Unsafe()}
+}
+
+func (tx *Tx) unsafe() store {
+	return &Tx{ID: tx.ID, Tx: tx.Tx.Unsafe()}
+}
+
+func (s *instrumentedStore) unsafe() store {
+	us, ok := s.store.(unsafeStore)
+	if !ok {
+		return s
+	}
+	return &instrumentedStore{store: us.unsafe(), conn: s.conn}
+}
@@ -0,0 +1,133 @@
+package pop
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/markbates/pop/columns"
+	"github.com/pkg/errors"
+)
+
+// selectSubquery is a column of the results computed by a subquery.
+type selectSubquery struct {
+	As    string
+	Query *Query
+}
+
+// Subquery makes the query a subquery selecting the columns of the table
+// of model, which can then be given as an argument to `Where`, `Having`
+// or `Join`, in place of a `?` placeholder written in parentheses:
+//
+//	authors := tx.Where("published = ?", true).Subquery(&Book{}, "user_id")
+//	err := tx.Where("id IN (?)", authors).All(&users)
+//
+// The arguments of the subquery are merged with the ones of the query. A
+// `RawQuery` can be used as a subquery as is.
+func (q *Query) Subquery(model interface{}, columns ...string) *Query {
+	q.subqueryModel = &Model{Value: model}
+	q.subqueryColumns = columns
+	return q
+}
+
+// SelectSubquery adds a column named as to the results of the query, which
+// is computed by the subquery sub. It replaces the column of the model of
+// the same name, typically a read only field:
+//
+//	type User struct {
+//		ID         int `db:"id"`
+//		BooksCount int `db:"books_count" rw:"r"`
+//	}
+//
+//	count := tx.RawQuery("SELECT count(*) FROM books WHERE books.user_id = users.id")
+//	err := tx.Q().SelectSubquery("books_count", count).All(&users)
+func (q *Query) SelectSubquery(as string, sub *Query) *Query {
+	if q.RawSQL.Fragment != "" {
+		fmt.Println("Warning: Query is setup to use raw SQL")
+		return q
+	}
+	q.selectSubqueries = append(q.selectSubqueries, selectSubquery{As: as, Query: sub})
+	return q
+}
+
+// subquerySQL returns the SQL of sub, with `?` placeholders, and its
+// arguments.
+func subquerySQL(sub *Query) (string, []interface{}, error) {
+	m := sub.subqueryModel
+	if m == nil {
+		if sub.RawSQL.Fragment == "" {
+			return "", nil, errors.New("a query must be made a subquery with Subquery to be used as an argument")
+		}
+		m = &Model{}
+	}
+	sb := newSQLBuilder(*sub, m, sub.subqueryColumns...)
+	sql := sb.untranslated()
+	return sql, sb.args, sb.err
+}
+
+// expandSubqueries replaces the placeholders of fragment bound to a
+// subquery with its SQL, and the subquery with its arguments.
+func expandSubqueries(fragment string, args []interface{}) (string, []interface{}, error) {
+	found := false
+	for _, arg := range args {
+		if _, ok := arg.(*Query); ok {
+			found = true
+		}
+	}
+	if !found {
+		return fragment, args, nil
+	}
+	positions := placeholders(fragment)
+	if len(positions) != len(args) {
+		return fragment, args, &PlaceholderError{"subquery", fragment, len(positions), len(args)}
+	}
+	sqls := make([]string, len(args))
+	bound := make([]interface{}, 0, len(args))
+	for i, arg := range args {
+		sub, ok := arg.(*Query)
+		if !ok {
+			bound = append(bound, arg)
+			continue
+		}
+		sql, subArgs, err := subquerySQL(sub)
+		if err != nil {
+			return fragment, args, err
+		}
+		sqls[i] = sql
+		bound = append(bound, subArgs...)
+	}
+	expanded := fragment
+	for i := len(positions) - 1; i >= 0; i-- {
+		if sqls[i] != "" {
+			expanded = expanded[:positions[i]] + sqls[i] + expanded[positions[i]+1:]
+		}
+	}
+	return expanded, bound, nil
+}
+
+// buildSelectSubqueries returns the columns of the subqueries selected by
+// the query, and removes the model columns they replace from rc.
+func (sq *sqlBuilder) buildSelectSubqueries(rc *columns.ReadableColumns) []string {
+	subs := []string{}
+	for _, s := range sq.Query.selectSubqueries {
+		delete(rc.Cols, s.As)
+		sql, args, err := subquerySQL(s.Query)
+		if err != nil {
+			if sq.err == nil {
+				sq.err = err
+			}
+			continue
+		}
+		sq.args = append(sq.args, args...)
+		subs = append(subs, fmt.Sprintf("(%s) AS %s", sql, sq.Query.Connection.Dialect.Quote(s.As)))
+	}
+	return subs
+}
+
+// joinSelect joins the columns of the model and the ones of the
+// subqueries in the select list.
+func joinSelect(cols string, subs []string) string {
+	if cols != "" {
+		subs = append([]string{cols}, subs...)
+	}
+	return strings.Join(subs, ", ")
+}
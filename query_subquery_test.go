@@ -0,0 +1,121 @@
+package pop_test
+
+import (
+	"testing"
+
+	"github.com/markbates/pop"
+	"github.com/markbates/pop/nulls"
+	"github.com/stretchr/testify/require"
+)
+
+type UserBooksCount struct {
+	ID         int          `db:"id"`
+	Name       nulls.String `db:"name"`
+	BooksCount int          `db:"books_count" rw:"r"`
+}
+
+func (UserBooksCount) TableName() string {
+	return "users"
+}
+
+func Test_Subquery_ToSQL(t *testing.T) {
+	r := require.New(t)
+
+	c, err := pop.NewConnection(&pop.ConnectionDetails{Dialect: "postgres", Database: "pop_test"})
+	r.NoError(err)
+
+	authors := c.Where("title = ?", "Pop").Subquery(&Book{}, "user_id")
+	q, args := c.Where("name = ?", "Mark").Where("id IN (?)", authors).Where("alive = ?", true).ToSQL(&pop.Model{Value: &User{}}, "id")
+	r.Equal("SELECT id FROM users AS users WHERE name = $1 AND id IN (SELECT user_id FROM books AS books WHERE title = $2) AND alive = $3", q)
+	r.Equal([]interface{}{"Mark", "Pop", true}, args)
+
+	count := c.RawQuery("SELECT count(*) FROM books WHERE books.user_id = users.id AND books.title <> ?", "Draft")
+	q, args = c.Where("name = ?", "Mark").SelectSubquery("books_count", count).ToSQL(&pop.Model{Value: &UserBooksCount{}})
+	r.Equal("SELECT users.id, users.name, (SELECT count(*) FROM books WHERE books.user_id = users.id AND books.title <> $1) AS books_count FROM users AS users WHERE name = $2", q)
+	r.Equal([]interface{}{"Draft", "Mark"}, args)
+
+	_, err = c.Where("id IN (?)", c.Where("title = ?", "Pop")).Count(&User{})
+	r.Error(err)
+}
+
+func Test_Subquery(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		mark := &User{Name: nulls.NewString("Mark")}
+		r.NoError(tx.Create(mark))
+		r.NoError(tx.Create(&User{Name: nulls.NewString("Larry")}))
+		for _, title := range []string{"Pop", "Buffalo"} {
+			r.NoError(tx.Create(&Book{Title: title, Isbn: title, UserID: nulls.NewInt(mark.ID)}))
+		}
+
+		users := Users{}
+		authors := tx.Where("title = ?", "Pop").Subquery(&Book{}, "user_id")
+		r.NoError(tx.Where("id IN (?)", authors).All(&users))
+		r.Len(users, 1)
+		r.Equal("Mark", users[0].Name.String)
+
+		counts := []UserBooksCount{}
+		count := tx.RawQuery("SELECT count(*) FROM books WHERE books.user_id = users.id")
+		r.NoError(tx.Order("name asc").SelectSubquery("books_count", count).All(&counts))
+		r.Len(counts, 2)
+		r.Equal(0, counts[0].BooksCount)
+		r.Equal(2, counts[1].BooksCount)
+	})
+}
+
+type UserShout struct {
+	ID    int          `db:"id"`
+	Name  nulls.String `db:"name"`
+	Shout string       `db:"shout" rw:"r"`
+}
+
+func (UserShout) TableName() string {
+	return "users"
+}
+
+func Test_SelectExpr_ToSQL(t *testing.T) {
+	r := require.New(t)
+
+	c, err := pop.NewConnection(&pop.ConnectionDetails{Dialect: "postgres", Database: "pop_test"})
+	r.NoError(err)
+
+	q, args := c.Where("name = ?", "Mark").SelectExpr("row_number() over (partition by alive order by name) as rank").ToSQL(&pop.Model{Value: &User{}}, "id")
+	r.Equal("SELECT id, row_number() over (partition by alive order by name) as rank FROM users AS users WHERE name = $1", q)
+	r.Equal([]interface{}{"Mark"}, args)
+
+	q, args = c.Where("name = ?", "Mark").SelectExpr("upper(name) || ? AS shout", "!").ToSQL(&pop.Model{Value: &UserShout{}})
+	r.Equal("SELECT users.id, users.name, upper(name) || $1 AS shout FROM users AS users WHERE name = $2", q)
+	r.Equal([]interface{}{"!", "Mark"}, args)
+}
+
+func Test_SelectExpr(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		for _, name := range []string{"Mark", "Joseph"} {
+			r.NoError(tx.Create(&User{Name: nulls.NewString(name)}))
+		}
+
+		shouts := []UserShout{}
+		r.NoError(tx.Q().SelectExpr("upper(name) AS shout").Order("name").All(&shouts))
+		r.Len(shouts, 2)
+		r.Equal("JOSEPH", shouts[0].Shout)
+		r.Equal("Joseph", shouts[0].Name.String)
+
+		// the model does not map name_length
+		users := Users{}
+		r.NoError(tx.Q().SelectExpr("length(name) AS name_length").Order("name_length asc").All(&users))
+		r.Len(users, 2)
+		r.Equal("Mark", users[0].Name.String)
+
+		u := &User{}
+		r.NoError(tx.Q().SelectExpr("length(name) AS name_length").Order("name_length desc").First(u))
+		r.Equal("Joseph", u.Name.String)
+
+		names := []string{}
+		r.NoError(tx.Q().SelectExpr("length(name) AS name_length").Order("name_length asc").Each(u, func(interface{}) error {
+			names = append(names, u.Name.String)
+			return nil
+		}))
+		r.Equal([]string{"Mark", "Joseph"}, names)
+	})
+}
@@ -3,8 +3,11 @@ package pop_test
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/markbates/pop"
+	"github.com/markbates/pop/nulls"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 )
 
@@ -14,19 +17,19 @@ func Test_Where(t *testing.T) {
 
 	q := PDB.Where("id = ?", 1)
 	sql, _ := q.ToSQL(m)
-	a.Equal(ts("SELECT enemies.A FROM enemies AS enemies WHERE id = ?"), sql)
+	a.Equal(ts("SELECT enemies.a FROM enemies AS enemies WHERE id = ?"), sql)
 
 	q.Where("first_name = ? and last_name = ?", "Mark", "Bates")
 	sql, _ = q.ToSQL(m)
-	a.Equal(ts("SELECT enemies.A FROM enemies AS enemies WHERE id = ? AND first_name = ? and last_name = ?"), sql)
+	a.Equal(ts("SELECT enemies.a FROM enemies AS enemies WHERE id = ? AND first_name = ? and last_name = ?"), sql)
 
 	q = PDB.Where("name = ?", "Mark 'Awesome' Bates")
 	sql, _ = q.ToSQL(m)
-	a.Equal(ts("SELECT enemies.A FROM enemies AS enemies WHERE name = ?"), sql)
+	a.Equal(ts("SELECT enemies.a FROM enemies AS enemies WHERE name = ?"), sql)
 
 	q = PDB.Where("name = ?", "'; truncate users; --")
 	sql, _ = q.ToSQL(m)
-	a.Equal(ts("SELECT enemies.A FROM enemies AS enemies WHERE name = ?"), sql)
+	a.Equal(ts("SELECT enemies.a FROM enemies AS enemies WHERE name = ?"), sql)
 }
 
 func Test_Where_In(t *testing.T) {
@@ -54,11 +57,11 @@ func Test_Order(t *testing.T) {
 	m := &pop.Model{Value: &Enemy{}}
 	q := PDB.Order("id desc")
 	sql, _ := q.ToSQL(m)
-	a.Equal(ts("SELECT enemies.A FROM enemies AS enemies ORDER BY id desc"), sql)
+	a.Equal(ts("SELECT enemies.a FROM enemies AS enemies ORDER BY id desc"), sql)
 
 	q.Order("name desc")
 	sql, _ = q.ToSQL(m)
-	a.Equal(ts("SELECT enemies.A FROM enemies AS enemies ORDER BY id desc, name desc"), sql)
+	a.Equal(ts("SELECT enemies.a FROM enemies AS enemies ORDER BY id desc, name desc"), sql)
 }
 
 func Test_GroupBy(t *testing.T) {
@@ -68,29 +71,29 @@ func Test_GroupBy(t *testing.T) {
 	q := PDB.Q()
 	q.GroupBy("A")
 	sql, _ := q.ToSQL(m)
-	a.Equal(ts("SELECT enemies.A FROM enemies AS enemies GROUP BY A"), sql)
+	a.Equal(ts("SELECT enemies.a FROM enemies AS enemies GROUP BY A"), sql)
 
 	q = PDB.Q()
 	q.GroupBy("A", "B")
 	sql, _ = q.ToSQL(m)
-	a.Equal(ts("SELECT enemies.A FROM enemies AS enemies GROUP BY A, B"), sql)
+	a.Equal(ts("SELECT enemies.a FROM enemies AS enemies GROUP BY A, B"), sql)
 
 	q = PDB.Q()
 	q.GroupBy("A", "B").Having("enemies.A=?", "test")
 	sql, _ = q.ToSQL(m)
 	if PDB.Dialect.Details().Dialect == "postgres" {
-		a.Equal(ts("SELECT enemies.A FROM enemies AS enemies GROUP BY A, B HAVING enemies.A=$1"), sql)
+		a.Equal(ts("SELECT enemies.a FROM enemies AS enemies GROUP BY A, B HAVING enemies.A=$1"), sql)
 	} else {
-		a.Equal(ts("SELECT enemies.A FROM enemies AS enemies GROUP BY A, B HAVING enemies.A=?"), sql)
+		a.Equal(ts("SELECT enemies.a FROM enemies AS enemies GROUP BY A, B HAVING enemies.A=?"), sql)
 	}
 
 	q = PDB.Q()
 	q.GroupBy("A", "B").Having("enemies.A=?", "test").Having("enemies.B=enemies.A")
 	sql, _ = q.ToSQL(m)
 	if PDB.Dialect.Details().Dialect == "postgres" {
-		a.Equal(ts("SELECT enemies.A FROM enemies AS enemies GROUP BY A, B HAVING enemies.A=$1 AND enemies.B=enemies.A"), sql)
+		a.Equal(ts("SELECT enemies.a FROM enemies AS enemies GROUP BY A, B HAVING enemies.A=$1 AND enemies.B=enemies.A"), sql)
 	} else {
-		a.Equal(ts("SELECT enemies.A FROM enemies AS enemies GROUP BY A, B HAVING enemies.A=? AND enemies.B=enemies.A"), sql)
+		a.Equal(ts("SELECT enemies.a FROM enemies AS enemies GROUP BY A, B HAVING enemies.A=? AND enemies.B=enemies.A"), sql)
 	}
 }
 
@@ -210,3 +213,311 @@ func Test_ToSQL_RawQuery(t *testing.T) {
 		a.Equal(args, []interface{}{"random", "query"})
 	})
 }
+
+func Test_AsOfSystemTime(t *testing.T) {
+	a := require.New(t)
+
+	at := time.Date(2018, 2, 15, 9, 30, 0, 0, time.UTC)
+
+	c, err := pop.NewConnection(&pop.ConnectionDetails{Dialect: "cockroach", Database: "pop_test"})
+	a.NoError(err)
+	q, args := c.Where("name = ?", "Mark").AsOfSystemTime(at).ToSQL(&pop.Model{Value: &User{}}, "id")
+	a.Equal("SELECT id FROM users AS users AS OF SYSTEM TIME '2018-02-15 09:30:00' WHERE name = $1", q)
+	a.Equal([]interface{}{"Mark"}, args)
+
+	c, err = pop.NewConnection(&pop.ConnectionDetails{Dialect: "postgres", Database: "pop_test"})
+	a.NoError(err)
+	q, _ = c.Where("name = ?", "Mark").AsOfSystemTime(at).ToSQL(&pop.Model{Value: &User{}}, "id")
+	a.Equal("SELECT id FROM users AS users WHERE name = $1", q)
+}
+
+func Test_Hint(t *testing.T) {
+	a := require.New(t)
+
+	c, err := pop.NewConnection(&pop.ConnectionDetails{Dialect: "postgres", Database: "pop_test"})
+	a.NoError(err)
+	q, _ := c.Where("email = ?", "mark@example.com").Hint("/*+ IndexScan(users users_email_idx) */").ToSQL(&pop.Model{Value: &User{}}, "id")
+	a.Equal("/*+ IndexScan(users users_email_idx) */ SELECT id FROM users AS users WHERE email = $1", q)
+
+	c, err = pop.NewConnection(&pop.ConnectionDetails{Dialect: "mysql", Database: "pop_test"})
+	a.NoError(err)
+	q, _ = c.Where("email = ?", "mark@example.com").Hint("/*+ MAX_EXECUTION_TIME(1000) */").ToSQL(&pop.Model{Value: &User{}}, "id")
+	a.Equal("SELECT /*+ MAX_EXECUTION_TIME(1000) */ id FROM users AS users WHERE email = ?", q)
+}
+
+func Test_UseIndex(t *testing.T) {
+	a := require.New(t)
+
+	c, err := pop.NewConnection(&pop.ConnectionDetails{Dialect: "mysql", Database: "pop_test"})
+	a.NoError(err)
+	q, _ := c.Where("email = ?", "mark@example.com").UseIndex("users_email_idx").IgnoreIndex("a_idx", "b_idx").ToSQL(&pop.Model{Value: &User{}}, "id")
+	a.Equal("SELECT id FROM users AS users USE INDEX (users_email_idx) IGNORE INDEX (a_idx, b_idx) WHERE email = ?", q)
+
+	q, _ = c.Q().ForceIndex("users_email_idx").ToSQL(&pop.Model{Value: &User{}}, "id")
+	a.Equal("SELECT id FROM users AS users FORCE INDEX (users_email_idx)", q)
+
+	c, err = pop.NewConnection(&pop.ConnectionDetails{Dialect: "postgres", Database: "pop_test"})
+	a.NoError(err)
+	q, _ = c.Where("email = ?", "mark@example.com").UseIndex("users_email_idx").ToSQL(&pop.Model{Value: &User{}}, "id")
+	a.Equal("SELECT id FROM users AS users WHERE email = $1", q)
+}
+
+func Test_LockForUpdate(t *testing.T) {
+	a := require.New(t)
+
+	c, err := pop.NewConnection(&pop.ConnectionDetails{Dialect: "postgres", Database: "pop_test"})
+	a.NoError(err)
+	q, _ := c.Where("name = ?", "Mark").Limit(1).LockForUpdate().ToSQL(&pop.Model{Value: &User{}}, "id")
+	a.Equal("SELECT id FROM users AS users WHERE name = $1 LIMIT 1 FOR UPDATE", q)
+
+	q, _ = c.Q().LockForShare().NoWait().ToSQL(&pop.Model{Value: &User{}}, "id")
+	a.Equal("SELECT id FROM users AS users FOR SHARE NOWAIT", q)
+
+	q, _ = c.Q().LockForUpdate().SkipLocked().ToSQL(&pop.Model{Value: &User{}}, "id")
+	a.Equal("SELECT id FROM users AS users FOR UPDATE SKIP LOCKED", q)
+
+	c, err = pop.NewConnection(&pop.ConnectionDetails{Dialect: "mysql", Database: "pop_test"})
+	a.NoError(err)
+	q, _ = c.Q().LockForShare().ToSQL(&pop.Model{Value: &User{}}, "id")
+	a.Equal("SELECT id FROM users AS users LOCK IN SHARE MODE", q)
+
+	q, _ = c.Q().LockForShare().SkipLocked().ToSQL(&pop.Model{Value: &User{}}, "id")
+	a.Equal("SELECT id FROM users AS users FOR SHARE SKIP LOCKED", q)
+
+	c, err = pop.NewConnection(&pop.ConnectionDetails{Dialect: "sqlite3", Database: "pop_test"})
+	a.NoError(err)
+	q, _ = c.Q().LockForUpdate().ToSQL(&pop.Model{Value: &User{}}, "id")
+	a.Equal("SELECT id FROM users AS users", q)
+}
+
+func Test_LockForUpdate_Count(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		r.NoError(tx.Create(&User{Name: nulls.NewString("Mark")}))
+		u := &User{}
+		r.NoError(tx.Q().LockForUpdate().First(u))
+		ct, err := tx.Q().LockForUpdate().Count(&User{})
+		r.NoError(err)
+		r.Equal(1, ct)
+	})
+}
+
+func Test_Hint_Count(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		r.NoError(tx.Create(&User{Name: nulls.NewString("Mark")}))
+		ctx, err := tx.Q().Hint("/* a hint */").UseIndex("users_name_idx").Count(&User{})
+		r.NoError(err)
+		r.Equal(1, ctx)
+	})
+}
+
+func Test_Quiet(t *testing.T) {
+	r := require.New(t)
+
+	logs := []string{}
+	oldLog := pop.Log
+	pop.Log = func(s string, args ...interface{}) {
+		logs = append(logs, s)
+	}
+	defer func() {
+		pop.Log = oldLog
+	}()
+
+	transaction(func(tx *pop.Connection) {
+		r.NoError(tx.Quiet().Where("title = ?", "Pop").All(&Books{}))
+		_, err := tx.Quiet().Count(&Books{})
+		r.NoError(err)
+		r.Len(logs, 0)
+
+		r.NoError(tx.Where("title = ?", "Pop").All(&Books{}))
+		r.Len(logs, 1)
+	})
+}
+
+func Test_Where_PlaceholderError(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		err := tx.Where("name = ? AND email = ?", "Mark").All(&Users{})
+		r.Error(err)
+		perr, ok := errors.Cause(err).(*pop.PlaceholderError)
+		r.True(ok)
+		r.Equal("where", perr.Clause)
+		r.Equal("name = ? AND email = ?", perr.Fragment)
+
+		_, err = tx.Where("name = ?").Count(&User{})
+		r.Error(err)
+
+		err = tx.RawQuery("UPDATE users SET name = ? WHERE id = ?", "Mark").Exec()
+		r.Error(err)
+
+		err = tx.RawQuery("UPDATE users SET name = ?").Exec()
+		r.Error(err)
+		_, ok = errors.Cause(err).(*pop.PlaceholderError)
+		r.True(ok)
+	})
+}
+
+func Test_Where_Named(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		u := &User{Name: nulls.NewString("Mark"), Email: "mark@example.com", Alive: nulls.NewBool(true)}
+		r.NoError(tx.Create(u))
+		r.NoError(tx.Create(&User{Name: nulls.NewString("Ringo"), Email: "ringo@example.com"}))
+
+		users := Users{}
+		r.NoError(tx.RawQuery("select * from users where email = :email", map[string]interface{}{"email": "mark@example.com"}).All(&users))
+		r.Len(users, 1)
+		r.Equal(u.ID, users[0].ID)
+
+		users = Users{}
+		r.NoError(tx.Where("name = :name AND alive = :alive", u).All(&users))
+		r.Len(users, 1)
+		r.Equal(u.ID, users[0].ID)
+
+		err := tx.RawQuery("UPDATE users SET bio = :bio WHERE id = :id", map[string]interface{}{"bio": "drummer", "id": u.ID}).Exec()
+		r.NoError(err)
+		r.NoError(tx.Find(u, u.ID))
+		r.Equal("drummer", u.Bio.String)
+	})
+}
+
+func Test_Where_In_With_Other_Placeholders(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		u1 := &User{Name: nulls.NewString("Mark")}
+		r.NoError(tx.Create(u1))
+		u2 := &User{Name: nulls.NewString("Ringo")}
+		r.NoError(tx.Create(u2))
+
+		users := Users{}
+		r.NoError(tx.Where("name = ?", "Mark").Where("id in (?)", u1.ID, u2.ID).All(&users))
+		r.Len(users, 1)
+		r.Equal(u1.ID, users[0].ID)
+
+		users = Users{}
+		r.NoError(tx.Where("id in (?) AND name <> ?", []int{u1.ID, u2.ID}, "Mark").All(&users))
+		r.Len(users, 1)
+		r.Equal(u2.ID, users[0].ID)
+	})
+}
+
+func Test_UpdateAll(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		for _, name := range []string{"Mark", "Ringo", "John"} {
+			r.NoError(tx.Create(&User{Name: nulls.NewString(name), Bio: nulls.NewString("beatle")}))
+		}
+
+		n, err := tx.Where("name in (?)", "Mark", "Ringo").UpdateAll(&User{}, map[string]interface{}{
+			"bio":   "drummer",
+			"alive": true,
+		})
+		r.NoError(err)
+		r.Equal(2, n)
+
+		ct, err := tx.Where("bio = ?", "drummer").Where("alive = ?", true).Count(&User{})
+		r.NoError(err)
+		r.Equal(2, ct)
+
+		n, err = tx.UpdateAll(&User{}, map[string]interface{}{"bio": "musician"})
+		r.NoError(err)
+		r.Equal(3, n)
+	})
+}
+
+func Test_UpdateAll_Errors(t *testing.T) {
+	r := require.New(t)
+	_, err := PDB.Q().UpdateAll(&User{}, map[string]interface{}{})
+	r.Error(err)
+	_, err = PDB.Q().UpdateAll(&User{}, map[string]interface{}{"bio = 'x', name": "y"})
+	r.Error(err)
+	_, err = PDB.Limit(1).UpdateAll(&User{}, map[string]interface{}{"bio": "x"})
+	r.Error(err)
+	_, err = PDB.Q().Join("books", "books.user_id = users.id").UpdateAll(&User{}, map[string]interface{}{"bio": "x"})
+	r.Error(err)
+}
+
+func Test_Distinct_ToSQL(t *testing.T) {
+	r := require.New(t)
+
+	c, err := pop.NewConnection(&pop.ConnectionDetails{Dialect: "postgres", Database: "pop_test"})
+	r.NoError(err)
+	q, _ := c.Q().Distinct().ToSQL(&pop.Model{Value: &User{}}, "name")
+	r.Equal("SELECT DISTINCT name FROM users AS users", q)
+
+	q, _ = c.Q().DistinctOn("user_id").Order("user_id, id desc").ToSQL(&pop.Model{Value: &Book{}}, "id", "user_id")
+	r.Equal("SELECT DISTINCT ON (user_id) id, user_id FROM books AS books ORDER BY user_id, id desc", q)
+
+	c, err = pop.NewConnection(&pop.ConnectionDetails{Dialect: "mysql", Database: "pop_test"})
+	r.NoError(err)
+	_, err = c.Q().DistinctOn("user_id").Count(&Book{})
+	r.Error(err)
+	r.Contains(err.Error(), "DISTINCT ON is not supported by mysql")
+}
+
+type UserName struct {
+	Name nulls.String `db:"name"`
+}
+
+func (UserName) TableName() string {
+	return "users"
+}
+
+func Test_Distinct(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		for _, name := range []string{"Mark", "Mark", "Joe"} {
+			r.NoError(tx.Create(&User{Name: nulls.NewString(name)}))
+		}
+		names := []UserName{}
+		r.NoError(tx.Q().Distinct().Order("name").All(&names))
+		r.Len(names, 2)
+		r.Equal("Joe", names[0].Name.String)
+
+		ct, err := tx.Q().Distinct().Count(&UserName{})
+		r.NoError(err)
+		r.Equal(2, ct)
+	})
+}
+
+type NameCount struct {
+	Name  nulls.String `db:"name"`
+	Count int          `db:"count" rw:"r"`
+}
+
+func (NameCount) TableName() string {
+	return "users"
+}
+
+func Test_Having(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		for _, name := range []string{"Mark", "Mark", "Mark", "Joe", "Joe", "Jane"} {
+			r.NoError(tx.Create(&User{Name: nulls.NewString(name)}))
+		}
+
+		counts := []NameCount{}
+		err := tx.Q().SelectExpr("count(*) AS count").GroupBy("name").Having("count(*) > ?", 1).Order("name").All(&counts)
+		r.NoError(err)
+		r.Len(counts, 2)
+		r.Equal("Joe", counts[0].Name.String)
+		r.Equal(2, counts[0].Count)
+		r.Equal("Mark", counts[1].Name.String)
+		r.Equal(3, counts[1].Count)
+
+		ct, err := tx.Q().SelectExpr("count(*) AS count").GroupBy("name").Having("count(*) > ?", 2).Count(&NameCount{})
+		r.NoError(err)
+		r.Equal(1, ct)
+	})
+}
+
+func Test_Having_WithoutGroupBy(t *testing.T) {
+	r := require.New(t)
+
+	c, err := pop.NewConnection(&pop.ConnectionDetails{Dialect: "postgres", Database: "pop_test"})
+	r.NoError(err)
+	q, args := c.Q().Having("count(*) > ?", 1).ToSQL(&pop.Model{Value: &User{}}, "count(*) AS count")
+	r.Equal("SELECT count(*) AS count FROM users AS users HAVING count(*) > $1", q)
+	r.Equal([]interface{}{1}, args)
+}
@@ -0,0 +1,87 @@
+package pop
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var columnNameRx = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// UpdateAll updates the columns of every row of the table of model with
+// a single UPDATE statement, and returns the number of rows it changed.
+// See `Query.UpdateAll`.
+func (c *Connection) UpdateAll(model interface{}, values map[string]interface{}) (int, error) {
+	return Q(c).UpdateAll(model, values)
+}
+
+// UpdateAll updates the columns of the rows matching the query with a
+// single UPDATE statement, without loading them, and returns the number
+// of rows it changed. The keys of values are column names:
+//
+//	n, err := tx.Where("published_at < ?", cutoff).
+//		UpdateAll(&Post{}, map[string]interface{}{"status": "archived"})
+//
+// The callbacks of the model are not run, and updated_at is only set if
+// it is one of values. Only the Where clauses of the query are used, the
+// queries with joins, groups or a limit return an error.
+func (q *Query) UpdateAll(model interface{}, values map[string]interface{}) (int, error) {
+	if err := checkModel(model); err != nil {
+		return 0, err
+	}
+	if len(values) == 0 {
+		return 0, errors.New("UpdateAll needs at least one column to update")
+	}
+	switch {
+	case q.RawSQL.Fragment != "":
+		return 0, errors.New("UpdateAll can not be used with a raw query")
+	case len(q.joinClauses) > 0, len(q.belongsToThroughClauses) > 0, len(q.fromClauses) > 0:
+		return 0, errors.New("UpdateAll can not be used with joins")
+	case len(q.groupClauses) > 0, len(q.havingClauses) > 0:
+		return 0, errors.New("UpdateAll can not be used with groups")
+	case q.limitResults > 0, q.Paginator != nil, q.CursorPaginator != nil:
+		return 0, errors.New("UpdateAll can not be used with a limit")
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		if !columnNameRx.MatchString(name) {
+			return 0, errors.Errorf("%q is not a column name", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	quote := q.Connection.Dialect.Quote
+	sets := make([]string, len(names))
+	args := make([]interface{}, len(names))
+	for i, name := range names {
+		sets[i] = fmt.Sprintf("%s = ?", quote(name))
+		args[i] = values[name]
+	}
+
+	m := &Model{Value: model}
+	sb := newSQLBuilder(*q, m)
+	where := sb.buildWhereClauses("")
+	if sb.err != nil {
+		return 0, sb.err
+	}
+	args = append(args, sb.args...)
+
+	stmt := fmt.Sprintf("UPDATE %s SET %s%s", quote(m.TableName()), strings.Join(sets, ", "), where)
+	stmt = q.Connection.Dialect.TranslateSQL(stmt)
+
+	count := int64(0)
+	err := q.Connection.timeFunc("UpdateAll", func() error {
+		res, err := q.Connection.Store.Exec(stmt, args...)
+		if err != nil {
+			return err
+		}
+		count, err = res.RowsAffected()
+		return err
+	})
+	return int(count), err
+}
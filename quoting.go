@@ -0,0 +1,51 @@
+package pop
+
+import "strings"
+
+// quoter quotes identifiers, such as table and column names.
+type quoter interface {
+	Quote(key string) string
+}
+
+// reservedWords are the SQL keywords which can not be used as bare
+// identifiers on at least one of the supported databases.
+var reservedWords = map[string]bool{}
+
+func init() {
+	for _, w := range strings.Fields(`
+		add all alter analyze and any as asc between both by case cast check
+		collate column constraint create cross current_date current_time
+		current_timestamp current_user database default delete desc distinct
+		do drop else end except exists false fetch for foreign from full grant
+		group having in index inner insert intersect interval into is join key
+		keys leading left like limit localtime localtimestamp natural not null
+		offset on or order outer primary range references returning right row
+		rows select session_user set some table then to trailing true union
+		unique update user using values when where window with`) {
+		reservedWords[w] = true
+	}
+}
+
+// quoteIdentifier quotes each part of the dotted identifier key with
+// open and close, if it is a reserved word or if the connection quotes
+// all of its identifiers. Parts which are already quoted, and "*", are
+// left as they are.
+//
+//	quoteIdentifier(cd, "`", "`", "users.order") // users.`order`
+func quoteIdentifier(cd *ConnectionDetails, open string, close string, key string) string {
+	always := cd != nil && cd.QuoteIdentifiers()
+	if !always && !strings.Contains(key, ".") && !reservedWords[strings.ToLower(key)] {
+		// the common case, a bare identifier left as is.
+		return key
+	}
+	parts := strings.Split(key, ".")
+	for i, p := range parts {
+		if p == "" || p == "*" || strings.HasPrefix(p, open) {
+			continue
+		}
+		if always || reservedWords[strings.ToLower(p)] {
+			parts[i] = open + strings.Replace(p, close, close+close, -1) + close
+		}
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,22 @@
+package pop
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_quoteIdentifier(t *testing.T) {
+	r := require.New(t)
+
+	cd := &ConnectionDetails{}
+	r.Equal("users.name", quoteIdentifier(cd, `"`, `"`, "users.name"))
+	r.Equal(`users."order"`, quoteIdentifier(cd, `"`, `"`, "users.order"))
+	r.Equal("`group`", quoteIdentifier(cd, "`", "`", "group"))
+	r.Equal("users.*", quoteIdentifier(cd, `"`, `"`, "users.*"))
+
+	cd.Options = map[string]string{"quote_identifiers": "true"}
+	r.Equal(`"public"."userProfiles"`, quoteIdentifier(cd, `"`, `"`, "public.userProfiles"))
+	r.Equal(`"we""ird"`, quoteIdentifier(cd, `"`, `"`, `we"ird`))
+	r.Equal(`"users"."name"`, quoteIdentifier(cd, `"`, `"`, `"users".name`))
+}
@@ -0,0 +1,216 @@
+package pop
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/markbates/pop/columns"
+	"github.com/pkg/errors"
+)
+
+// RangePartition is the partition of a table partitioned by ranges of
+// time, holding the rows whose partition key is in [From, To). The
+// partition key is declared with the partition_by tag of a time.Time
+// field, whose value is the size of the partitions, day, month or year:
+//
+//	type Event struct {
+//		ID        int       `db:"id"`
+//		CreatedAt time.Time `db:"created_at" partition_by:"month"`
+//	}
+type RangePartition struct {
+	// Table is the partitioned table, e.g. "events".
+	Table string
+	// Name is the name of the partition, e.g. "events_2018_03".
+	Name string
+	// Column is the partition key of the table, e.g. "created_at".
+	Column string
+	From   time.Time
+	To     time.Time
+}
+
+// RangePartitionHook is called by `Create` with the partition of the
+// models of range partitioned tables before inserting them, so that it
+// can create the partition if it is missing. It is not set by default;
+// `CreateRangePartition` creates PostgreSQL partitions:
+//
+//	pop.RangePartitionHook = pop.CreateRangePartition
+var RangePartitionHook func(c *Connection, p RangePartition) error
+
+// CreateRangePartition creates the PostgreSQL partition p of its table,
+// unless it exists.
+func CreateRangePartition(c *Connection, p RangePartition) error {
+	if _, ok := c.Dialect.(*postgresql); !ok {
+		return errors.Errorf("range partitions can not be created on %s", c.Dialect.Details().Dialect)
+	}
+	exists := false
+	if err := c.Store.Get(&exists, "SELECT to_regclass($1) IS NOT NULL", c.Dialect.Quote(p.Name)); err != nil {
+		return errors.WithStack(err)
+	}
+	if exists {
+		return nil
+	}
+	stmt := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s PARTITION OF %s FOR VALUES FROM ('%s') TO ('%s')",
+		c.Dialect.Quote(p.Name), c.Dialect.Quote(p.Table), p.From.Format(time.RFC3339), p.To.Format(time.RFC3339))
+	_, err := c.Store.Exec(stmt)
+	return errors.Wrap(err, stmt)
+}
+
+// rangePartitionKey returns the partition key field of the model, if it
+// is range partitioned.
+func (m *Model) rangePartitionKey() (reflect.StructField, bool) {
+	if _, ok := m.Value.(string); ok {
+		return reflect.StructField{}, false
+	}
+	t := indirectType(reflect.TypeOf(m.Value))
+	if t.Kind() != reflect.Struct {
+		return reflect.StructField{}, false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if by := t.Field(i).Tag.Get("partition_by"); by != "" {
+			return t.Field(i), true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// rangePartition returns the partition of the model table holding the
+// rows whose partition key is at.
+func (m *Model) rangePartition(at time.Time) (RangePartition, error) {
+	f, _ := m.rangePartitionKey()
+	by := f.Tag.Get("partition_by")
+	p := RangePartition{Table: m.TableName(), Column: columns.TagsFor(f).Find("db").Value}
+	at = at.UTC()
+	switch by {
+	case "day":
+		p.From = time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC)
+		p.To = p.From.AddDate(0, 0, 1)
+		p.Name = p.Table + p.From.Format("_2006_01_02")
+	case "month":
+		p.From = time.Date(at.Year(), at.Month(), 1, 0, 0, 0, 0, time.UTC)
+		p.To = p.From.AddDate(0, 1, 0)
+		p.Name = p.Table + p.From.Format("_2006_01")
+	case "year":
+		p.From = time.Date(at.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+		p.To = p.From.AddDate(1, 0, 0)
+		p.Name = p.Table + p.From.Format("_2006")
+	default:
+		return p, errors.Errorf("invalid partition_by %q of %s, it must be day, month or year", by, p.Table)
+	}
+	return p, nil
+}
+
+// ensureRangePartition runs RangePartitionHook with the partition of the
+// model being created.
+func (c *Connection) ensureRangePartition(m *Model) error {
+	return c.ensureRangePartitions([]*Model{m})
+}
+
+// ensureRangePartitions runs RangePartitionHook once with each of the
+// partitions of the models being created.
+func (c *Connection) ensureRangePartitions(ms []*Model) error {
+	if RangePartitionHook == nil {
+		return nil
+	}
+	seen := map[string]bool{}
+	for _, m := range ms {
+		key, ok := m.rangePartitionKey()
+		if !ok {
+			return nil
+		}
+		f, err := m.fieldByName(key.Name)
+		if err != nil {
+			return err
+		}
+		at, ok := f.Interface().(time.Time)
+		if !ok {
+			return errors.Errorf("the partition key %s of %s is not a time.Time", key.Name, m.TableName())
+		}
+		p, err := m.rangePartition(at)
+		if err != nil {
+			return err
+		}
+		if seen[p.Name] {
+			continue
+		}
+		seen[p.Name] = true
+		if err := RangePartitionHook(c, p); err != nil {
+			return errors.WithStack(err)
+		}
+	}
+	return nil
+}
+
+// InPartition returns a query constrained to a partition, see
+// `Query.InPartition`.
+func (c *Connection) InPartition(at time.Time) *Query {
+	return Q(c).InPartition(at)
+}
+
+// InPartition constrains the query to the rows of the partition holding
+// the rows whose partition key is at, so that the database only reads
+// this partition:
+//
+//	err := c.InPartition(time.Now()).Where("kind = ?", "login").All(&events)
+func (q *Query) InPartition(at time.Time) *Query {
+	q.partitionAt = at
+	return q
+}
+
+// PartitionRange returns a query constrained to a range of time, see
+// `Query.PartitionRange`.
+func (c *Connection) PartitionRange(from, to time.Time) *Query {
+	return Q(c).PartitionRange(from, to)
+}
+
+// PartitionRange constrains the query to the rows whose partition key is
+// in [from, to), so that the database only reads the partitions of this
+// range.
+func (q *Query) PartitionRange(from, to time.Time) *Query {
+	q.partitionFrom, q.partitionTo = from, to
+	return q
+}
+
+// partitionClause returns the clause of the partitions the query is
+// constrained to.
+func (sq *sqlBuilder) partitionClause() (clause, bool) {
+	q := sq.Query
+	if q.partitionAt.IsZero() && q.partitionFrom.IsZero() && q.partitionTo.IsZero() {
+		return clause{}, false
+	}
+	key, ok := sq.Model.rangePartitionKey()
+	if !ok {
+		if sq.err == nil {
+			sq.err = errors.Errorf("%s has no partition_by field", sq.Model.TableName())
+		}
+		return clause{}, false
+	}
+	from, to := q.partitionFrom, q.partitionTo
+	if !q.partitionAt.IsZero() {
+		p, err := sq.Model.rangePartition(q.partitionAt)
+		if err != nil {
+			if sq.err == nil {
+				sq.err = err
+			}
+			return clause{}, false
+		}
+		from, to = p.From, p.To
+	}
+	col := columns.TagsFor(key).Find("db").Value
+	alias := sq.Model.As
+	if alias == "" {
+		alias = strings.Replace(sq.Model.TableName(), ".", "_", -1)
+	}
+	fragments := []string{}
+	args := []interface{}{}
+	if !from.IsZero() {
+		fragments = append(fragments, fmt.Sprintf("%s.%s >= ?", alias, col))
+		args = append(args, from)
+	}
+	if !to.IsZero() {
+		fragments = append(fragments, fmt.Sprintf("%s.%s < ?", alias, col))
+		args = append(args, to)
+	}
+	return clause{Fragment: strings.Join(fragments, " AND "), Arguments: args}, true
+}
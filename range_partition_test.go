@@ -0,0 +1,95 @@
+package pop_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/markbates/pop"
+	"github.com/stretchr/testify/require"
+)
+
+type Event struct {
+	ID         int       `db:"id"`
+	Name       string    `db:"name"`
+	OccurredAt time.Time `db:"occurred_at" partition_by:"month"`
+	CreatedAt  time.Time `db:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at"`
+}
+
+func Test_RangePartitionHook(t *testing.T) {
+	r := require.New(t)
+
+	partitions := []pop.RangePartition{}
+	pop.RangePartitionHook = func(c *pop.Connection, p pop.RangePartition) error {
+		partitions = append(partitions, p)
+		return nil
+	}
+	defer func() { pop.RangePartitionHook = nil }()
+
+	transaction(func(tx *pop.Connection) {
+		at := time.Date(2018, 3, 15, 10, 0, 0, 0, time.UTC)
+		r.NoError(tx.Create(&Event{Name: "login", OccurredAt: at}))
+		r.NoError(tx.Create(&Label{Name: "not partitioned"}))
+
+		r.Len(partitions, 1)
+		r.Equal(pop.RangePartition{
+			Table:  "events",
+			Name:   "events_2018_03",
+			Column: "occurred_at",
+			From:   time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC),
+			To:     time.Date(2018, 4, 1, 0, 0, 0, 0, time.UTC),
+		}, partitions[0])
+
+		// the models of CreateAll get their partitions once.
+		partitions = partitions[:0]
+		r.NoError(tx.CreateAll(&[]Event{
+			{Name: "login", OccurredAt: at},
+			{Name: "logout", OccurredAt: at.Add(time.Hour)},
+			{Name: "login", OccurredAt: at.AddDate(0, 1, 0)},
+		}, 10))
+		r.Len(partitions, 2)
+		r.Equal("events_2018_03", partitions[0].Name)
+		r.Equal("events_2018_04", partitions[1].Name)
+
+		partitions = partitions[:0]
+		r.NoError(tx.Upsert(&Event{ID: 100, Name: "login", OccurredAt: at}, "id"))
+		r.Len(partitions, 1)
+		r.Equal("events_2018_03", partitions[0].Name)
+	})
+}
+
+func Test_InPartition(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		for _, at := range []time.Time{
+			time.Date(2018, 2, 28, 23, 0, 0, 0, time.UTC),
+			time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2018, 3, 31, 23, 0, 0, 0, time.UTC),
+			time.Date(2018, 4, 1, 0, 0, 0, 0, time.UTC),
+		} {
+			r.NoError(tx.Create(&Event{Name: at.String(), OccurredAt: at}))
+		}
+
+		events := []Event{}
+		r.NoError(tx.InPartition(time.Date(2018, 3, 15, 0, 0, 0, 0, time.UTC)).Order("occurred_at asc").All(&events))
+		r.Len(events, 2)
+		r.Equal(3, int(events[0].OccurredAt.Month()))
+		r.Equal(3, int(events[1].OccurredAt.Month()))
+
+		events = []Event{}
+		r.NoError(tx.PartitionRange(time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC), time.Time{}).All(&events))
+		r.Len(events, 3)
+
+		r.Error(tx.InPartition(time.Now()).All(&[]Label{}))
+	})
+}
+
+func Test_InPartition_ToSQL(t *testing.T) {
+	r := require.New(t)
+
+	c, err := pop.NewConnection(&pop.ConnectionDetails{Dialect: "postgres", Database: "pop_test"})
+	r.NoError(err)
+	q, args := c.InPartition(time.Date(2018, 3, 15, 0, 0, 0, 0, time.UTC)).Where("name = ?", "login").ToSQL(&pop.Model{Value: &Event{}}, "id")
+	r.Equal("SELECT id FROM events AS events WHERE events.occurred_at >= $1 AND events.occurred_at < $2 AND name = $3", q)
+	r.Equal([]interface{}{time.Date(2018, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2018, 4, 1, 0, 0, 0, 0, time.UTC), "login"}, args)
+}
@@ -0,0 +1,80 @@
+package pop
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// RebindCacheSize is the number of statements whose `$N` version the
+// postgres and cockroach dialects keep, so the queries built over and
+// over are only rebound once. The cache is emptied once it is full; 0
+// disables it.
+var RebindCacheSize = 1000
+
+// Rebind returns query with its `?` placeholders replaced by the ones of
+// the dialect of the connection, as `sqlx.DB.Rebind` does, for the raw
+// statements run without pop, such as through `Connection.Store`:
+//
+//	query := c.Rebind("SELECT * FROM users WHERE id = ? AND email = ?")
+//	// SELECT * FROM users WHERE id = $1 AND email = $2 with postgres
+func (c *Connection) Rebind(query string) string {
+	return c.Dialect.TranslateSQL(query)
+}
+
+// rebindCache keeps the `$N` version of the statements of a dialect.
+type rebindCache struct {
+	mu  sync.RWMutex
+	sql map[string]string
+}
+
+func newRebindCache() *rebindCache {
+	return &rebindCache{sql: map[string]string{}}
+}
+
+// rebind returns query with `$N` placeholders, from the cache if it was
+// rebound before. A nil cache rebinds every statement.
+func (rc *rebindCache) rebind(query string) string {
+	if strings.IndexByte(query, '?') < 0 {
+		return query
+	}
+	if rc == nil {
+		return rebindDollar(query)
+	}
+	rc.mu.RLock()
+	s, ok := rc.sql[query]
+	rc.mu.RUnlock()
+	if ok {
+		return s
+	}
+	s = rebindDollar(query)
+	if size := RebindCacheSize; size > 0 {
+		rc.mu.Lock()
+		if len(rc.sql) >= size {
+			rc.sql = map[string]string{}
+		}
+		rc.sql[query] = s
+		rc.mu.Unlock()
+	}
+	return s
+}
+
+// rebindDollar replaces the `?` placeholders of query by `$1`, `$2`...
+func rebindDollar(query string) string {
+	b := strings.Builder{}
+	b.Grow(len(query) + 8)
+	n := 1
+	start := 0
+	for i := 0; i < len(query); i++ {
+		if query[i] != '?' {
+			continue
+		}
+		b.WriteString(query[start:i])
+		b.WriteByte('$')
+		b.WriteString(strconv.Itoa(n))
+		n++
+		start = i + 1
+	}
+	b.WriteString(query[start:])
+	return b.String()
+}
@@ -0,0 +1,64 @@
+package pop
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_rebindDollar(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal("SELECT 1", rebindDollar("SELECT 1"))
+	r.Equal("id = $1", rebindDollar("id = ?"))
+	r.Equal("a = $1 AND b in ($2, $3)", rebindDollar("a = ? AND b in (?, ?)"))
+	r.Equal("$1$2", rebindDollar("??"))
+}
+
+func Test_rebindCache(t *testing.T) {
+	r := require.New(t)
+
+	defer func(n int) { RebindCacheSize = n }(RebindCacheSize)
+	RebindCacheSize = 2
+
+	rc := newRebindCache()
+	r.Equal("a = $1", rc.rebind("a = ?"))
+	r.Equal("b = $1", rc.rebind("b = ?"))
+	r.Len(rc.sql, 2)
+	r.Equal("a = $1", rc.rebind("a = ?"))
+
+	// the cache is emptied once full.
+	r.Equal("c = $1", rc.rebind("c = ?"))
+	r.Len(rc.sql, 1)
+
+	// statements without placeholders are not kept.
+	r.Equal("SELECT 1", rc.rebind("SELECT 1"))
+	r.Len(rc.sql, 1)
+
+	RebindCacheSize = 0
+	r.Equal("d = $1", rc.rebind("d = ?"))
+	r.Len(rc.sql, 1)
+
+	var nilCache *rebindCache
+	r.Equal("e = $1", nilCache.rebind("e = ?"))
+}
+
+func Test_Connection_Rebind(t *testing.T) {
+	r := require.New(t)
+
+	c := &Connection{Dialect: newPostgreSQL(&ConnectionDetails{Dialect: "postgres"})}
+	r.Equal("SELECT * FROM users WHERE id = $1 AND email = $2", c.Rebind("SELECT * FROM users WHERE id = ? AND email = ?"))
+	c = &Connection{Dialect: newMySQL(&ConnectionDetails{Dialect: "mysql"})}
+	r.Equal("SELECT * FROM users WHERE id = ?", c.Rebind("SELECT * FROM users WHERE id = ?"))
+}
+
+func Benchmark_rebindCache(b *testing.B) {
+	rc := newRebindCache()
+	query := "SELECT users.id, users.name FROM users AS users WHERE users.email = ? AND users.alive = ? ORDER BY users.name LIMIT 20"
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			rc.rebind(query)
+		}
+	})
+}
@@ -0,0 +1,139 @@
+package pop
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/markbates/pop/columns"
+	"github.com/markbates/pop/fizz"
+	"github.com/markbates/pop/fizz/translators"
+	"github.com/pkg/errors"
+)
+
+var _ dialect = &redshift{}
+
+// redshift is the Amazon Redshift flavor of the PostgreSQL dialect.
+type redshift struct {
+	*postgresql
+}
+
+func (r *redshift) URL() string {
+	c := r.ConnectionDetails
+	if strings.HasPrefix(c.URL, "redshift://") {
+		return withApplicationName("postgres://"+strings.TrimPrefix(c.URL, "redshift://"), c.ApplicationName())
+	}
+	return r.postgresql.URL()
+}
+
+func (r *redshift) MigrationURL() string {
+	return r.URL()
+}
+
+// Create does not use RETURNING, which Redshift does not support. Redshift
+// has no way of returning a generated IDENTITY value either, so the ID of a
+// model with an integer primary key is left untouched: use UUID primary
+// keys for models that must be used after being created.
+func (r *redshift) Create(s store, model *Model, cols columns.Columns) error {
+	keyType := model.PrimaryKeyType()
+	switch keyType {
+	case "int", "int64":
+		cols.Remove("id")
+		w := cols.Writeable()
+		query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", r.Quote(model.TableName()), w.QuotedString(r.Quote), w.SymbolizedString())
+		_, err := s.NamedExec(query, model.Value)
+		return errors.Wrap(err, "redshift create")
+	case "UUID", "string":
+		return errors.Wrap(genericCreate(s, model, cols, r), "redshift create")
+	}
+	return errors.Errorf("can not use %s as a primary key type!", keyType)
+}
+
+func (r *redshift) nextSequenceValue(s store, name string) (int64, error) {
+	return 0, errors.New("Redshift does not support sequences")
+}
+
+func (r *redshift) reserveSequenceValues(s store, name string, n int) ([]int64, error) {
+	return nil, errors.New("Redshift does not support sequences")
+}
+
+// maintain runs VACUUM and ANALYZE separately, since Redshift can not
+// combine them.
+func (r *redshift) maintain(c *Connection, tables []string) ([]MaintenanceResult, error) {
+	if len(tables) == 0 {
+		tables = []string{""}
+	}
+	results := []MaintenanceResult{}
+	for _, t := range tables {
+		for _, op := range []string{"VACUUM", "ANALYZE"} {
+			query := op
+			if t != "" {
+				query = fmt.Sprintf("%s %s", op, r.Quote(t))
+			}
+			if err := c.RawQuery(query).Exec(); err != nil {
+				return results, err
+			}
+			results = append(results, MaintenanceResult{Table: t, Statement: query})
+		}
+	}
+	return results, nil
+}
+
+func (r *redshift) activeQueries(ctx context.Context, db *sqlx.DB) ([]ActiveQuery, error) {
+	return nil, errors.New("Redshift does not support query monitoring")
+}
+
+func (r *redshift) blockers(ctx context.Context, db *sqlx.DB) ([]Blocker, error) {
+	return nil, errors.New("Redshift does not support query monitoring")
+}
+
+func (r *redshift) cancelQuery(ctx context.Context, db *sqlx.DB, pid int64) error {
+	return errors.New("Redshift does not support query monitoring")
+}
+
+func (r *redshift) FizzTranslator() fizz.Translator {
+	return translators.NewRedshift()
+}
+
+func (r *redshift) DumpSchema(w io.Writer) error {
+	return errors.New("dumping the schema is not supported on Redshift")
+}
+
+// TruncateAll truncates the tables one by one, as Redshift does not
+// support the anonymous code block used for PostgreSQL.
+func (r *redshift) TruncateAll(tx *Connection) error {
+	tables := []struct {
+		Name string `db:"tablename"`
+	}{}
+	err := tx.RawQuery("SELECT tablename FROM pg_tables WHERE schemaname = 'public'").All(&tables)
+	if err != nil {
+		return err
+	}
+	for _, t := range tables {
+		if err := tx.RawQuery(fmt.Sprintf("TRUNCATE TABLE \"%s\"", t.Name)).Exec(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertAll leaves the ids alone: as with Create, Redshift can not return
+// the generated IDENTITY values.
+func (r *redshift) insertAll(s store, query string, args []interface{}, n int) ([]int64, error) {
+	_, err := s.Exec(query, args...)
+	return nil, err
+}
+
+// Capabilities reports what Redshift kept from PostgreSQL 8.0: neither
+// RETURNING, ON CONFLICT, savepoints nor lateral joins are supported.
+func (r *redshift) Capabilities() Capabilities {
+	return Capabilities{CTE: true}
+}
+
+func newRedshift(deets *ConnectionDetails) dialect {
+	return &redshift{
+		postgresql: newPostgreSQL(deets).(*postgresql),
+	}
+}
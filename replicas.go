@@ -0,0 +1,255 @@
+package pop
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/markbates/going/defaults"
+	"github.com/markbates/going/randx"
+	"github.com/pkg/errors"
+)
+
+var readQuery = regexp.MustCompile(`(?is)^\s*(/\*.*?\*/\s*)*select\b`)
+var writingRead = regexp.MustCompile(`(?i)\bfor\s+(update|share|no\s+key\s+update|key\s+share)\b|\bnextval\s*\(|\block\s+in\s+share\s+mode\b`)
+
+// isRead returns true if the query can be sent to a replica.
+func isRead(query string) bool {
+	return readQuery.MatchString(query) && !writingRead.MatchString(query)
+}
+
+// replica is a read-only copy of the database.
+type replica struct {
+	dialect dialect
+	store   *dB
+	// ejected is set to 1 when the replica lags too much to be read.
+	ejected int32
+}
+
+func (r *replica) isEjected() bool {
+	return atomic.LoadInt32(&r.ejected) == 1
+}
+
+func (r *replica) setEjected(b bool) {
+	var v int32
+	if b {
+		v = 1
+	}
+	atomic.StoreInt32(&r.ejected, v)
+}
+
+// readSession remembers the last write made through a connection, so the
+// reads following it go to the primary.
+type readSession struct {
+	window    time.Duration
+	mu        sync.Mutex
+	lastWrite time.Time
+}
+
+func newReadSession(cd *ConnectionDetails) *readSession {
+	return &readSession{window: cd.ReadYourWritesWindow()}
+}
+
+func (rs *readSession) wrote() {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.lastWrite = time.Now()
+}
+
+func (rs *readSession) sticky() bool {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	return !rs.lastWrite.IsZero() && time.Since(rs.lastWrite) < rs.window
+}
+
+// routedStore sends the reads to the replicas which are not ejected, in
+// turn, and everything else, including transactions, to the primary it
+// embeds.
+type routedStore struct {
+	store
+	replicas []*replica
+	next     *uint32
+	session  *readSession
+}
+
+// route returns the store the query must be sent to.
+func (s *routedStore) route(query string) store {
+	if !isRead(query) {
+		s.session.wrote()
+		return s.store
+	}
+	if len(s.replicas) == 0 || s.session.sticky() {
+		return s.store
+	}
+	n := int(atomic.AddUint32(s.next, 1))
+	for i := 0; i < len(s.replicas); i++ {
+		if r := s.replicas[(n+i)%len(s.replicas)]; !r.isEjected() {
+			return r.store
+		}
+	}
+	return s.store
+}
+
+func (s *routedStore) Select(dest interface{}, query string, args ...interface{}) error {
+	return s.route(query).Select(dest, query, args...)
+}
+
+func (s *routedStore) Get(dest interface{}, query string, args ...interface{}) error {
+	return s.route(query).Get(dest, query, args...)
+}
+
+func (s *routedStore) Queryx(query string, args ...interface{}) (*sqlx.Rows, error) {
+	return s.route(query).Queryx(query, args...)
+}
+
+func (s *routedStore) NamedExec(query string, arg interface{}) (sql.Result, error) {
+	s.session.wrote()
+	return s.store.NamedExec(query, arg)
+}
+
+func (s *routedStore) Exec(query string, args ...interface{}) (sql.Result, error) {
+	s.session.wrote()
+	return s.store.Exec(query, args...)
+}
+
+func (s *routedStore) Transaction() (*Tx, error) {
+	s.session.wrote()
+	return s.store.Transaction()
+}
+
+func (s *routedStore) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return s.route(query).(contextStore).SelectContext(ctx, dest, query, args...)
+}
+
+func (s *routedStore) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return s.route(query).(contextStore).GetContext(ctx, dest, query, args...)
+}
+
+func (s *routedStore) QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error) {
+	return s.route(query).(contextStore).QueryxContext(ctx, query, args...)
+}
+
+func (s *routedStore) NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error) {
+	s.session.wrote()
+	return s.store.(contextStore).NamedExecContext(ctx, query, arg)
+}
+
+func (s *routedStore) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	s.session.wrote()
+	return s.store.(contextStore).ExecContext(ctx, query, args...)
+}
+
+func (s *routedStore) TransactionContext(ctx context.Context) (*Tx, error) {
+	s.session.wrote()
+	return s.store.(contextStore).TransactionContext(ctx)
+}
+
+func (s *routedStore) Close() error {
+	err := s.store.Close()
+	for _, r := range s.replicas {
+		if rerr := r.store.Close(); err == nil {
+			err = rerr
+		}
+	}
+	return err
+}
+
+// Session returns a connection sharing the pools of c, with its own
+// read-your-writes state: after a write made through the session, its
+// reads go to the primary for the "read_your_writes" window, instead of
+// to replicas which may not have the write yet. Other sessions keep
+// reading from the replicas. It is meant to be called once per request:
+//
+//	tx := db.Session()
+//	err := tx.Create(&user)
+//	err = tx.Find(&user, user.ID) // read from the primary
+//
+// Connections without replicas, and transactions, return themselves. A
+// session shares the pools of c, so it must not be closed.
+func (c *Connection) Session() *Connection {
+	rs := c.routedStore()
+	if rs == nil || c.TX != nil {
+		return c
+	}
+	cn := *c
+	cn.ID = randx.String(30)
+	cn.Elapsed = 0
+	cn.Store = newInstrumentedStore(&cn, &routedStore{
+		store:    rs.store,
+		replicas: rs.replicas,
+		next:     rs.next,
+		session:  newReadSession(c.Dialect.Details()),
+	})
+	return &cn
+}
+
+// routedStore returns the store routing the queries of c to its replicas,
+// or nil if it has none.
+func (c *Connection) routedStore() *routedStore {
+	is, ok := c.Store.(*instrumentedStore)
+	if !ok {
+		return nil
+	}
+	rs, _ := is.store.(*routedStore)
+	return rs
+}
+
+// lagProber is implemented by the dialects able to tell how far behind
+// its primary a replica is.
+type lagProber interface {
+	replicaLag(ctx context.Context, db *sqlx.DB) (time.Duration, error)
+}
+
+// ReplicaStatus is the state of a replica, as reported by `ReplicaLag`.
+type ReplicaStatus struct {
+	// Host of the replica, or its database file for SQLite
+	Host string
+	// Lag is how far behind the primary the replica is
+	Lag time.Duration
+	// Ejected is true if the replica no longer receives reads
+	Ejected bool
+	// Err is the error returned while probing the replica, if any
+	Err error
+}
+
+// ReplicaLag probes the replicas of the connection, with
+// pg_last_xact_replay_timestamp on PostgreSQL and SHOW SLAVE STATUS on
+// MySQL, and reports how far behind the primary each of them is.
+//
+// When the "max_replica_lag" option is set, the replicas lagging more, or
+// failing to report their lag, are ejected: they stop receiving reads until
+// a later probe finds them caught up. It is meant to be called periodically:
+//
+//	for range time.Tick(10 * time.Second) {
+//		statuses, err := db.ReplicaLag(ctx)
+//	}
+func (c *Connection) ReplicaLag(ctx context.Context) ([]ReplicaStatus, error) {
+	rs := c.routedStore()
+	if rs == nil {
+		return []ReplicaStatus{}, nil
+	}
+	max := c.Dialect.Details().MaxReplicaLag()
+	statuses := make([]ReplicaStatus, 0, len(rs.replicas))
+	for _, r := range rs.replicas {
+		d := r.dialect.Details()
+		st := ReplicaStatus{Host: defaults.String(d.Host, d.Database)}
+		if lp, ok := r.dialect.(lagProber); ok {
+			st.Lag, st.Err = lp.replicaLag(ctx, r.store.DB)
+		} else {
+			st.Err = errors.Errorf("%s does not report replication lag", d.Dialect)
+		}
+		if ctx.Err() != nil {
+			return statuses, errors.WithStack(ctx.Err())
+		}
+		if max > 0 {
+			r.setEjected(st.Err != nil || st.Lag > max)
+		}
+		st.Ejected = r.isEjected()
+		statuses = append(statuses, st)
+	}
+	return statuses, nil
+}
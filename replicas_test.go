@@ -0,0 +1,179 @@
+// +build !nosqlite,!appengine,!appenginevm
+
+package pop
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_isRead(t *testing.T) {
+	r := require.New(t)
+	r.True(isRead("SELECT * FROM users"))
+	r.True(isRead("  select count(*) from users"))
+	r.False(isRead("INSERT INTO users (name) VALUES (?) RETURNING id"))
+	r.False(isRead("SELECT * FROM users WHERE id = ? FOR UPDATE"))
+	r.False(isRead("SELECT nextval($1)"))
+	r.False(isRead("UPDATE users SET name = ?"))
+}
+
+// replicaDB creates a widgets table in a new sqlite database, holding a
+// single row named after the database.
+func replicaDB(r *require.Assertions, dir string, name string) string {
+	path := filepath.Join(dir, name+".sqlite")
+	c, err := NewConnection(&ConnectionDetails{Dialect: "sqlite3", Database: path})
+	r.NoError(err)
+	r.NoError(c.Open())
+	defer c.Close()
+	r.NoError(c.RawQuery("CREATE TABLE widgets (id INTEGER PRIMARY KEY, name TEXT)").Exec())
+	r.NoError(c.RawQuery("INSERT INTO widgets (name) VALUES (?)", name).Exec())
+	return path
+}
+
+func Test_Replicas_ReadYourWrites(t *testing.T) {
+	r := require.New(t)
+
+	dir, err := ioutil.TempDir("", "pop")
+	r.NoError(err)
+	defer os.RemoveAll(dir)
+
+	c, err := NewConnection(&ConnectionDetails{
+		Dialect:  "sqlite3",
+		Database: replicaDB(r, dir, "primary"),
+		Replicas: []*ConnectionDetails{{Database: replicaDB(r, dir, "replica")}},
+		Options:  map[string]string{"read_your_writes": "1h"},
+	})
+	r.NoError(err)
+	r.NoError(c.Open())
+	defer c.Close()
+
+	names := func(c *Connection) []string {
+		names := []string{}
+		r.NoError(c.RawQuery("SELECT name FROM widgets ORDER BY id").All(&names))
+		return names
+	}
+
+	r.Equal([]string{"replica"}, names(c))
+
+	s := c.Session()
+	r.NoError(s.RawQuery("INSERT INTO widgets (name) VALUES (?)", "written").Exec())
+	r.Equal([]string{"primary", "written"}, names(s))
+
+	// other sessions still read from the replica.
+	r.Equal([]string{"replica"}, names(c))
+	r.Equal([]string{"replica"}, names(c.Session()))
+
+	// transactions always use the primary.
+	r.NoError(c.Transaction(func(tx *Connection) error {
+		r.Equal([]string{"primary", "written"}, names(tx))
+		return nil
+	}))
+}
+
+func Test_Replicas_Session_Settings(t *testing.T) {
+	r := require.New(t)
+
+	dir, err := ioutil.TempDir("", "pop")
+	r.NoError(err)
+	defer os.RemoveAll(dir)
+
+	c, err := NewConnection(&ConnectionDetails{
+		Dialect:  "sqlite3",
+		Database: replicaDB(r, dir, "primary"),
+		Replicas: []*ConnectionDetails{{Database: replicaDB(r, dir, "replica")}},
+	})
+	r.NoError(err)
+	r.NoError(c.Open())
+	defer c.Close()
+
+	// the sessions keep the settings of their connection.
+	logged := 0
+	c = c.WithLogger(LoggerFunc(func(e LogEntry) { logged++ }))
+	executed := 0
+	c.Use(func(next Executor) Executor {
+		return ExecutorFunc(func(s *Statement) error {
+			executed++
+			return next.Execute(s)
+		})
+	})
+	s := c.Session()
+	r.NotEqual(c.ID, s.ID)
+	names := []string{}
+	r.NoError(s.RawQuery("SELECT name FROM widgets").All(&names))
+	r.Equal(1, logged)
+	r.Equal(1, executed)
+}
+
+func Test_Replicas_Window(t *testing.T) {
+	r := require.New(t)
+
+	dir, err := ioutil.TempDir("", "pop")
+	r.NoError(err)
+	defer os.RemoveAll(dir)
+
+	c, err := NewConnection(&ConnectionDetails{
+		Dialect:  "sqlite3",
+		Database: replicaDB(r, dir, "primary"),
+		Replicas: []*ConnectionDetails{{Database: replicaDB(r, dir, "replica")}},
+		Options:  map[string]string{"read_your_writes": "0s"},
+	})
+	r.NoError(err)
+	r.NoError(c.Open())
+	defer c.Close()
+
+	r.NoError(c.RawQuery("INSERT INTO widgets (name) VALUES (?)", "written").Exec())
+	names := []string{}
+	r.NoError(c.RawQuery("SELECT name FROM widgets").All(&names))
+	r.Equal([]string{"replica"}, names)
+}
+
+func Test_ReplicaLag_Eject(t *testing.T) {
+	r := require.New(t)
+
+	dir, err := ioutil.TempDir("", "pop")
+	r.NoError(err)
+	defer os.RemoveAll(dir)
+
+	c, err := NewConnection(&ConnectionDetails{
+		Dialect:  "sqlite3",
+		Database: replicaDB(r, dir, "primary"),
+		Replicas: []*ConnectionDetails{{Database: replicaDB(r, dir, "replica")}},
+		Options:  map[string]string{"max_replica_lag": "1s"},
+	})
+	r.NoError(err)
+	r.NoError(c.Open())
+	defer c.Close()
+
+	names := []string{}
+	r.NoError(c.RawQuery("SELECT name FROM widgets").All(&names))
+	r.Equal([]string{"replica"}, names)
+
+	// SQLite can not report its lag, so the replica is ejected.
+	statuses, err := c.ReplicaLag(context.Background())
+	r.NoError(err)
+	r.Len(statuses, 1)
+	r.Equal(filepath.Join(dir, "replica.sqlite"), statuses[0].Host)
+	r.Error(statuses[0].Err)
+	r.True(statuses[0].Ejected)
+
+	names = []string{}
+	r.NoError(c.RawQuery("SELECT name FROM widgets").All(&names))
+	r.Equal([]string{"primary"}, names)
+}
+
+func Test_ReplicaLag_NoReplicas(t *testing.T) {
+	r := require.New(t)
+	c, err := NewConnection(&ConnectionDetails{Dialect: "sqlite3", Database: ":memory:"})
+	r.NoError(err)
+	r.NoError(c.Open())
+	defer c.Close()
+
+	statuses, err := c.ReplicaLag(context.Background())
+	r.NoError(err)
+	r.Len(statuses, 0)
+}
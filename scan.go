@@ -0,0 +1,303 @@
+package pop
+
+import (
+	"database/sql"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/jmoiron/sqlx/reflectx"
+)
+
+// selectModels is store.Select for a slice of models, scanned with
+// scanModels.
+func selectModels(s store, models *Model, query string, args []interface{}) error {
+	if scanPlanType(models.Value) == nil {
+		return s.Select(models.Value, query, args...)
+	}
+	rows, err := s.Queryx(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	if err := scanModels(rows, models.Value); err != nil {
+		return scanError(rows, models, err)
+	}
+	return nil
+}
+
+// getModel is store.Get for a model.
+func getModel(s store, model *Model, query string, args []interface{}) error {
+	if t := reflect.TypeOf(model.Value); t.Kind() != reflect.Ptr || !isModelStruct(t.Elem()) {
+		return s.Get(model.Value, query, args...)
+	}
+	rows, err := s.Queryx(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		if err = rows.Err(); err != nil {
+			return err
+		}
+		return sql.ErrNoRows
+	}
+	if err := rows.StructScan(model.Value); err != nil {
+		return scanError(rows, model, err)
+	}
+	return nil
+}
+
+// scanModels is sqlx.StructScan for a slice of models. The results are
+// scanned straight into the fields of the models when they all map to a
+// field reached without going through a pointer, which saves boxing the
+// fields in reflect values for every row. The other results are left to
+// sqlx.
+func scanModels(rows *sqlx.Rows, dest interface{}) error {
+	t := scanPlanType(dest)
+	if t == nil {
+		return sqlx.StructScan(rows, dest)
+	}
+	cols, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	plan := scanPlanFor(rows.Mapper, t, cols)
+	if plan == nil {
+		return sqlx.StructScan(rows, dest)
+	}
+
+	direct := reflect.ValueOf(dest).Elem()
+	isPtr := direct.Type().Elem().Kind() == reflect.Ptr
+	values := make([]interface{}, len(plan))
+	for rows.Next() {
+		n := direct.Len()
+		if n == direct.Cap() {
+			grown := reflect.MakeSlice(direct.Type(), n, 2*n+8)
+			reflect.Copy(grown, direct)
+			direct.Set(grown)
+		}
+		direct.SetLen(n + 1)
+		e := direct.Index(n)
+		var base unsafe.Pointer
+		if isPtr {
+			vp := reflect.New(t)
+			e.Set(vp)
+			base = unsafe.Pointer(vp.Pointer())
+		} else {
+			// the capacity of the slice may hold older models.
+			e.Set(reflect.Zero(t))
+			base = unsafe.Pointer(e.UnsafeAddr())
+		}
+		for i := range plan {
+			values[i] = plan[i].dest(base)
+		}
+		if err := rows.Scan(values...); err != nil {
+			direct.SetLen(n)
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// scanPlanType returns the type of the models of dest, a pointer to a
+// slice of structs or struct pointers, or nil if dest is something else.
+func scanPlanType(dest interface{}) reflect.Type {
+	t := reflect.TypeOf(dest)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Slice || reflect.ValueOf(dest).IsNil() {
+		return nil
+	}
+	et := t.Elem().Elem()
+	if et.Kind() == reflect.Ptr {
+		et = et.Elem()
+	}
+	if !isModelStruct(et) {
+		return nil
+	}
+	return et
+}
+
+// isModelStruct returns true if t is a struct scanned field by field,
+// rather than as a single value like time.Time or a sql.Scanner.
+func isModelStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != timeType && !reflect.PtrTo(t).Implements(scannerType)
+}
+
+// scanKind is the type of the field a result is scanned into, for the
+// types scanned without reflection.
+type scanKind int
+
+const (
+	scanOther scanKind = iota
+	scanString
+	scanBool
+	scanInt
+	scanInt8
+	scanInt16
+	scanInt32
+	scanInt64
+	scanUint
+	scanUint8
+	scanUint16
+	scanUint32
+	scanUint64
+	scanFloat32
+	scanFloat64
+	scanBytes
+	scanTime
+)
+
+// scanField is the field of a model a result is scanned into.
+type scanField struct {
+	offset uintptr
+	kind   scanKind
+	typ    reflect.Type
+}
+
+// dest returns the pointer to the field of the model at base.
+func (f scanField) dest(base unsafe.Pointer) interface{} {
+	p := unsafe.Pointer(uintptr(base) + f.offset)
+	switch f.kind {
+	case scanString:
+		return (*string)(p)
+	case scanBool:
+		return (*bool)(p)
+	case scanInt:
+		return (*int)(p)
+	case scanInt8:
+		return (*int8)(p)
+	case scanInt16:
+		return (*int16)(p)
+	case scanInt32:
+		return (*int32)(p)
+	case scanInt64:
+		return (*int64)(p)
+	case scanUint:
+		return (*uint)(p)
+	case scanUint8:
+		return (*uint8)(p)
+	case scanUint16:
+		return (*uint16)(p)
+	case scanUint32:
+		return (*uint32)(p)
+	case scanUint64:
+		return (*uint64)(p)
+	case scanFloat32:
+		return (*float32)(p)
+	case scanFloat64:
+		return (*float64)(p)
+	case scanBytes:
+		return (*[]byte)(p)
+	case scanTime:
+		return (*time.Time)(p)
+	}
+	return reflect.NewAt(f.typ, p).Interface()
+}
+
+var rawBytesType = reflect.TypeOf(sql.RawBytes{})
+
+// scanKindOf returns the kind of field of type t. The named types are
+// scanned as their underlying type, unless they are scanners.
+func scanKindOf(t reflect.Type) scanKind {
+	if t == timeType {
+		return scanTime
+	}
+	if reflect.PtrTo(t).Implements(scannerType) || t == rawBytesType {
+		return scanOther
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return scanString
+	case reflect.Bool:
+		return scanBool
+	case reflect.Int:
+		return scanInt
+	case reflect.Int8:
+		return scanInt8
+	case reflect.Int16:
+		return scanInt16
+	case reflect.Int32:
+		return scanInt32
+	case reflect.Int64:
+		return scanInt64
+	case reflect.Uint:
+		return scanUint
+	case reflect.Uint8:
+		return scanUint8
+	case reflect.Uint16:
+		return scanUint16
+	case reflect.Uint32:
+		return scanUint32
+	case reflect.Uint64:
+		return scanUint64
+	case reflect.Float32:
+		return scanFloat32
+	case reflect.Float64:
+		return scanFloat64
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return scanBytes
+		}
+	}
+	return scanOther
+}
+
+type scanPlanKey struct {
+	mapper  *reflectx.Mapper
+	t       reflect.Type
+	columns string
+}
+
+var scanPlans = map[scanPlanKey][]scanField{}
+var scanPlansMutex = &sync.RWMutex{}
+
+// scanPlanFor returns the fields of the models of type t the columns are
+// scanned into, or nil if a column has no field, or one of the fields is
+// behind a pointer.
+func scanPlanFor(m *reflectx.Mapper, t reflect.Type, cols []string) []scanField {
+	key := scanPlanKey{mapper: m, t: t, columns: strings.Join(cols, ",")}
+	scanPlansMutex.RLock()
+	plan, ok := scanPlans[key]
+	scanPlansMutex.RUnlock()
+	if ok {
+		return plan
+	}
+
+	plan = newScanPlan(m, t, cols)
+	scanPlansMutex.Lock()
+	scanPlans[key] = plan
+	scanPlansMutex.Unlock()
+	return plan
+}
+
+func newScanPlan(m *reflectx.Mapper, t reflect.Type, cols []string) []scanField {
+	if m == nil {
+		m = fieldMapper
+	}
+	tm := m.TypeMap(t)
+	plan := make([]scanField, len(cols))
+	for i, c := range cols {
+		fi := tm.GetByPath(c)
+		if fi == nil {
+			return nil
+		}
+		var offset uintptr
+		ft := t
+		for j, index := range fi.Index {
+			if ft.Kind() != reflect.Struct {
+				return nil
+			}
+			f := ft.Field(index)
+			offset += f.Offset
+			ft = f.Type
+			if j < len(fi.Index)-1 && ft.Kind() == reflect.Ptr {
+				return nil
+			}
+		}
+		plan[i] = scanField{offset: offset, kind: scanKindOf(ft), typ: ft}
+	}
+	return plan
+}
@@ -0,0 +1,96 @@
+package pop
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/markbates/pop/columns"
+)
+
+// scanErrorRegex matches the errors returned by database/sql when a
+// column can not be converted to its destination.
+var scanErrorRegex = regexp.MustCompile(`Scan error on column index (\d+)`)
+
+// ScanError is returned when a column of a row can not be scanned into the
+// field of a model, for instance a string column into an int field.
+type ScanError struct {
+	// Table is the table of the model
+	Table string
+	// Column is the name of the column which could not be scanned
+	Column string
+	// Field is the name of the field mapped to the column, if any
+	Field string
+	// ID is the primary key of the row, if it could be read
+	ID interface{}
+	// Err is the error returned by the driver
+	Err error
+}
+
+func (e *ScanError) Error() string {
+	target := e.Column
+	if e.Field != "" {
+		target = fmt.Sprintf("%s into field %s", e.Column, e.Field)
+	}
+	if e.ID != nil {
+		return fmt.Sprintf("could not scan %s.%s (id %v): %s", e.Table, target, e.ID, e.Err)
+	}
+	return fmt.Sprintf("could not scan %s.%s: %s", e.Table, target, e.Err)
+}
+
+// scanError turns the scan error err, returned while scanning the
+// current row of rows, into a *ScanError naming the column which could
+// not be scanned and the primary key of the row. Other errors are returned
+// untouched.
+func scanError(rows *sqlx.Rows, model *Model, err error) error {
+	match := scanErrorRegex.FindStringSubmatch(err.Error())
+	if match == nil {
+		return err
+	}
+	index, _ := strconv.Atoi(match[1])
+	t := modelType(reflect.TypeOf(model.Value))
+	if t.Kind() != reflect.Struct {
+		return err
+	}
+	se := &ScanError{Table: model.TableName(), Err: err}
+
+	cols, cerr := rows.Columns()
+	if cerr != nil || index >= len(cols) {
+		return se
+	}
+	se.Column = cols[index]
+	if f, ok := columnField(t, se.Column); ok {
+		se.Field = f.Name
+	}
+
+	// the row is still the current one, it can be scanned again into
+	// plain values.
+	values, verr := rows.SliceScan()
+	if verr != nil {
+		return se
+	}
+	id := idColumn(t)
+	for i, col := range cols {
+		if col == id {
+			se.ID = values[i]
+			if b, ok := se.ID.([]byte); ok {
+				se.ID = string(b)
+			}
+		}
+	}
+	return se
+}
+
+// idColumn returns the column of the ID field of the model type t.
+func idColumn(t reflect.Type) string {
+	f, ok := t.FieldByName("ID")
+	if !ok {
+		return "id"
+	}
+	if name := columns.TagsFor(f).Find("db").Value; name != "" {
+		return name
+	}
+	return columns.ColumnName(f.Name)
+}
@@ -0,0 +1,66 @@
+package pop_test
+
+import (
+	"testing"
+
+	"github.com/markbates/pop"
+	"github.com/markbates/pop/nulls"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type intNamedUser struct {
+	ID   int `db:"id"`
+	Name int `db:"name"`
+}
+
+func (intNamedUser) TableName() string {
+	return "users"
+}
+
+func Test_ScanError(t *testing.T) {
+	transaction(func(tx *pop.Connection) {
+		r := require.New(t)
+
+		user := User{Name: nulls.NewString("Mark")}
+		r.NoError(tx.Create(&user))
+
+		u := intNamedUser{}
+		err := tx.Find(&u, user.ID)
+		r.Error(err)
+		se, ok := errors.Cause(err).(*pop.ScanError)
+		r.True(ok)
+		r.Equal("users", se.Table)
+		r.Equal("name", se.Column)
+		r.Equal("Name", se.Field)
+		r.EqualValues(user.ID, se.ID)
+		r.Contains(err.Error(), "could not scan users.name into field Name (id ")
+
+		users := []intNamedUser{}
+		err = tx.All(&users)
+		r.Error(err)
+		se, ok = errors.Cause(err).(*pop.ScanError)
+		r.True(ok)
+		r.Equal("Name", se.Field)
+		r.EqualValues(user.ID, se.ID)
+	})
+}
+
+func Test_ScanError_Once(t *testing.T) {
+	transaction(func(tx *pop.Connection) {
+		r := require.New(t)
+
+		user := User{Name: nulls.NewString("Mark")}
+		r.NoError(tx.Create(&user))
+
+		// the error is built from the failing row, the query is not run again.
+		l := &entries{}
+		c := tx.WithLogger(l)
+		err := c.Where("id = ?", user.ID).Each(&intNamedUser{}, func(interface{}) error { return nil })
+		se, ok := errors.Cause(err).(*pop.ScanError)
+		r.True(ok)
+		r.Equal("Name", se.Field)
+		r.EqualValues(user.ID, se.ID)
+		r.Len(l.logs, 1)
+	})
+}
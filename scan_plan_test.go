@@ -0,0 +1,44 @@
+package pop
+
+import (
+	"reflect"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+)
+
+type scanStatus string
+
+type scanAddress struct {
+	City string `db:"city"`
+}
+
+type scanModel struct {
+	ID        int64      `db:"id"`
+	Status    scanStatus `db:"status"`
+	CreatedAt time.Time  `db:"created_at"`
+	scanAddress
+	Parent *scanAddress `db:"parent"`
+}
+
+func Test_newScanPlan(t *testing.T) {
+	r := require.New(t)
+
+	mt := reflect.TypeOf(scanModel{})
+	plan := newScanPlan(fieldMapper, mt, []string{"id", "status", "created_at", "city"})
+	r.Len(plan, 4)
+	r.Equal(scanInt64, plan[0].kind)
+	r.Equal(scanString, plan[1].kind)
+	r.Equal(scanTime, plan[2].kind)
+	r.Equal(scanString, plan[3].kind)
+
+	m := scanModel{}
+	*plan[3].dest(unsafe.Pointer(&m)).(*string) = "Paris"
+	r.Equal("Paris", m.City)
+
+	// the fields behind a pointer are left to sqlx
+	r.Nil(newScanPlan(fieldMapper, mt, []string{"id", "parent.city"}))
+	r.Nil(newScanPlan(fieldMapper, mt, []string{"id", "unknown"}))
+}
@@ -0,0 +1,47 @@
+package pop_test
+
+import (
+	"testing"
+
+	"github.com/markbates/pop"
+	"github.com/markbates/pop/nulls"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_All_Scan(t *testing.T) {
+	transaction(func(tx *pop.Connection) {
+		r := require.New(t)
+
+		r.NoError(tx.Create(&Book{Title: "Emma", Isbn: "PB1", Description: "A novel"}))
+		r.NoError(tx.Create(&Book{Title: "Persuasion", Isbn: "PB2", Description: "A novel"}))
+
+		// the models past the length of the slice are not kept
+		books := Books{{Title: "stale", User: User{Name: nulls.NewString("Mark")}}}[:0]
+		r.NoError(tx.Order("title").All(&books))
+		r.Len(books, 2)
+		r.Equal("Emma", books[0].Title)
+		r.Equal("PB1", books[0].Isbn)
+		r.False(books[0].CreatedAt.IsZero())
+		r.Equal(User{}, books[0].User)
+		r.Equal("Persuasion", books[1].Title)
+
+		ptrs := []*Book{}
+		r.NoError(tx.Order("title").All(&ptrs))
+		r.Len(ptrs, 2)
+		r.Equal("Persuasion", ptrs[1].Title)
+		r.Equal(books[1].ID, ptrs[1].ID)
+	})
+}
+
+func Test_All_Scan_UnknownColumn(t *testing.T) {
+	transaction(func(tx *pop.Connection) {
+		r := require.New(t)
+
+		r.NoError(tx.Create(&Book{Title: "Emma"}))
+
+		books := Books{}
+		err := tx.RawQuery("select id, title as name from books").All(&books)
+		r.Error(err)
+		r.Contains(err.Error(), "missing destination name name")
+	})
+}
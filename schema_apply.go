@@ -0,0 +1,295 @@
+package pop
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/markbates/pop/fizz"
+	"github.com/pkg/errors"
+)
+
+// SchemaChange is a change of a `SchemaPlan`.
+type SchemaChange struct {
+	// Description describes the change, e.g. "add column users.age".
+	Description string
+	// SQL is the statement making the change.
+	SQL string
+	// Destructive is true for the changes dropping a table or a column.
+	Destructive bool
+}
+
+// SchemaPlan is the list of changes bringing the database to the schema
+// of a definition, see `Connection.PlanSchema`.
+type SchemaPlan struct {
+	Changes []SchemaChange
+}
+
+// Destructive returns true if the plan drops a table or a column.
+func (p *SchemaPlan) Destructive() bool {
+	for _, ch := range p.Changes {
+		if ch.Destructive {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *SchemaPlan) String() string {
+	if len(p.Changes) == 0 {
+		return "No changes, the database is up to date."
+	}
+	lines := []string{}
+	for _, ch := range p.Changes {
+		mark := "+"
+		if ch.Destructive {
+			mark = "-"
+		}
+		lines = append(lines, fmt.Sprintf("%s %s", mark, ch.Description))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// SchemaPlanOptions are the options of `Connection.PlanSchema`.
+type SchemaPlanOptions struct {
+	// AllowDrop plans the drops of the tables and columns which are not
+	// in the definition. They are left alone otherwise.
+	AllowDrop bool
+}
+
+// PlanSchema compares the schema definition read from r, written with
+// `create_table` and `add_index` fizz statements, with the live database,
+// and returns the changes bringing the database to the definition:
+//
+//	f, err := os.Open("schema.fizz")
+//	plan, err := c.PlanSchema(f, pop.SchemaPlanOptions{})
+//	fmt.Println(plan)
+//	err = c.ApplySchema(plan)
+//
+// Missing tables, columns and indexes are added. The types and options of
+// existing columns, and the foreign keys of existing tables, are not
+// compared. The schema_migration table is ignored.
+func (c *Connection) PlanSchema(r io.Reader, opts SchemaPlanOptions) (*SchemaPlan, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	def := &schemaDefinition{tables: map[string]*fizz.Table{}}
+	raw, err := fizz.AString(string(b), def)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read the schema definition")
+	}
+	if strings.TrimSpace(raw) != "" {
+		return nil, errors.New("raw SQL can not be used in a schema definition")
+	}
+	tl, ok := c.Dialect.(tableLister)
+	if !ok {
+		return nil, errors.Errorf("%s can not list its tables", c.Dialect.Details().Dialect)
+	}
+	plan := &SchemaPlan{Changes: []SchemaChange{}}
+	err = c.timeFunc("PlanSchema", func() error {
+		live, err := tl.tableNames(c)
+		if err != nil {
+			return errors.Wrap(err, "could not list the tables")
+		}
+		liveIndexes := map[string]bool{}
+		if ic, ok := c.Dialect.(indexCatalog); ok {
+			indexes, err := ic.indexes(c)
+			if err != nil {
+				return errors.Wrap(err, "could not list the indexes")
+			}
+			for _, i := range indexes {
+				liveIndexes[i.Name] = true
+			}
+		}
+		return def.diff(plan, c, live, liveIndexes, opts)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// ApplySchema runs the changes of plan in a transaction.
+func (c *Connection) ApplySchema(plan *SchemaPlan) error {
+	apply := func(tx *Connection) error {
+		for _, ch := range plan.Changes {
+			if err := tx.RawQuery(ch.SQL).Exec(); err != nil {
+				return errors.Wrap(err, ch.Description)
+			}
+		}
+		return nil
+	}
+	return c.timeFunc("ApplySchema", func() error {
+		if c.Dialect.Details().TiDB() {
+			return apply(c)
+		}
+		return c.Transaction(apply)
+	})
+}
+
+// schemaDefinition is a fizz translator recording the tables and indexes
+// of a schema definition, instead of translating them.
+type schemaDefinition struct {
+	order  []string
+	tables map[string]*fizz.Table
+}
+
+// diff adds to plan the changes from the live tables and indexes to the
+// definition.
+func (d *schemaDefinition) diff(plan *SchemaPlan, c *Connection, live []string, liveIndexes map[string]bool, opts SchemaPlanOptions) error {
+	tr := c.Dialect.FizzTranslator()
+	add := func(desc string, destructive bool, sql string, err error) error {
+		if err != nil {
+			return errors.Wrap(err, desc)
+		}
+		plan.Changes = append(plan.Changes, SchemaChange{Description: desc, SQL: sql, Destructive: destructive})
+		return nil
+	}
+	exists := map[string]bool{}
+	for _, name := range live {
+		exists[name] = true
+	}
+
+	for _, name := range d.order {
+		t := d.tables[name]
+		if !exists[name] {
+			create := *t
+			create.Indexes = nil
+			sql, err := tr.CreateTable(create)
+			if err := add("create table "+name, false, sql, err); err != nil {
+				return err
+			}
+		} else {
+			cols, err := c.tableColumns(name)
+			if err != nil {
+				return err
+			}
+			wanted := map[string]bool{}
+			for _, col := range t.Columns {
+				wanted[col.Name] = true
+				if _, ok := cols[col.Name]; ok {
+					continue
+				}
+				sql, err := tr.AddColumn(fizz.Table{Name: name, Columns: []fizz.Column{col}})
+				if err := add(fmt.Sprintf("add column %s.%s", name, col.Name), false, sql, err); err != nil {
+					return err
+				}
+			}
+			if opts.AllowDrop {
+				extra := []string{}
+				for col := range cols {
+					if !wanted[col] {
+						extra = append(extra, col)
+					}
+				}
+				sort.Strings(extra)
+				for _, col := range extra {
+					sql, err := tr.DropColumn(fizz.Table{Name: name, Columns: []fizz.Column{{Name: col}}})
+					if err := add(fmt.Sprintf("drop column %s.%s", name, col), true, sql, err); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		for _, i := range t.Indexes {
+			if liveIndexes[i.Name] {
+				continue
+			}
+			sql, err := tr.AddIndex(fizz.Table{Name: name, Indexes: []fizz.Index{i}})
+			if err := add(fmt.Sprintf("add index %s on %s", i.Name, name), false, sql, err); err != nil {
+				return err
+			}
+		}
+	}
+
+	if opts.AllowDrop {
+		for _, name := range live {
+			if _, ok := d.tables[name]; ok || name == schemaMigrations.Name {
+				continue
+			}
+			sql, err := tr.DropTable(fizz.Table{Name: name})
+			if err := add("drop table "+name, true, sql, err); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (d *schemaDefinition) table(name string) (*fizz.Table, error) {
+	t, ok := d.tables[name]
+	if !ok {
+		return nil, errors.Errorf("table %s is not created by the schema definition", name)
+	}
+	return t, nil
+}
+
+func (d *schemaDefinition) CreateTable(t fizz.Table) (string, error) {
+	if _, ok := d.tables[t.Name]; ok {
+		return "", errors.Errorf("table %s is created twice", t.Name)
+	}
+	d.order = append(d.order, t.Name)
+	d.tables[t.Name] = &t
+	return "", nil
+}
+
+func (d *schemaDefinition) AddIndex(t fizz.Table) (string, error) {
+	dt, err := d.table(t.Name)
+	if err != nil {
+		return "", err
+	}
+	dt.Indexes = append(dt.Indexes, t.Indexes...)
+	return "", nil
+}
+
+func (d *schemaDefinition) AddForeignKey(t fizz.Table) (string, error) {
+	dt, err := d.table(t.Name)
+	if err != nil {
+		return "", err
+	}
+	dt.ForeignKeys = append(dt.ForeignKeys, t.ForeignKeys...)
+	return "", nil
+}
+
+func (d *schemaDefinition) notDeclarative(op string) (string, error) {
+	return "", errors.Errorf("%s can not be used in a schema definition, which only creates tables and indexes", op)
+}
+
+func (d *schemaDefinition) DropTable(fizz.Table) (string, error) {
+	return d.notDeclarative("drop_table")
+}
+
+func (d *schemaDefinition) RenameTable([]fizz.Table) (string, error) {
+	return d.notDeclarative("rename_table")
+}
+
+func (d *schemaDefinition) AddColumn(fizz.Table) (string, error) {
+	return d.notDeclarative("add_column")
+}
+
+func (d *schemaDefinition) ChangeColumn(fizz.Table) (string, error) {
+	return d.notDeclarative("change_column")
+}
+
+func (d *schemaDefinition) DropColumn(fizz.Table) (string, error) {
+	return d.notDeclarative("drop_column")
+}
+
+func (d *schemaDefinition) RenameColumn(fizz.Table) (string, error) {
+	return d.notDeclarative("rename_column")
+}
+
+func (d *schemaDefinition) DropIndex(fizz.Table) (string, error) {
+	return d.notDeclarative("drop_index")
+}
+
+func (d *schemaDefinition) RenameIndex(fizz.Table) (string, error) {
+	return d.notDeclarative("rename_index")
+}
+
+func (d *schemaDefinition) DropForeignKey(fizz.Table) (string, error) {
+	return d.notDeclarative("drop_foreign_key")
+}
@@ -0,0 +1,99 @@
+// +build !nosqlite,!appengine,!appenginevm
+
+package pop
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const schemaDefinitionFizz = `
+create_table("widgets", func(t) {
+	t.Column("name", "string", {})
+	t.Column("weight", "integer", {"null": true})
+})
+add_index("widgets", "name", {})
+
+create_table("gadgets", func(t) {
+	t.Column("label", "string", {})
+})
+`
+
+func Test_PlanSchema(t *testing.T) {
+	r := require.New(t)
+
+	dir, err := ioutil.TempDir("", "pop")
+	r.NoError(err)
+	defer os.RemoveAll(dir)
+
+	c, err := NewConnection(&ConnectionDetails{Dialect: "sqlite3", Database: filepath.Join(dir, "schema.sqlite")})
+	r.NoError(err)
+	r.NoError(c.Open())
+	defer c.Close()
+
+	_, err = c.Store.Exec(`CREATE TABLE "widgets" ("id" INTEGER PRIMARY KEY AUTOINCREMENT, "name" TEXT NOT NULL, "color" TEXT, "created_at" DATETIME NOT NULL, "updated_at" DATETIME NOT NULL)`)
+	r.NoError(err)
+	_, err = c.Store.Exec(`CREATE TABLE "legacy" ("id" INTEGER PRIMARY KEY AUTOINCREMENT)`)
+	r.NoError(err)
+
+	plan, err := c.PlanSchema(strings.NewReader(schemaDefinitionFizz), SchemaPlanOptions{})
+	r.NoError(err)
+	descs := []string{}
+	for _, ch := range plan.Changes {
+		descs = append(descs, ch.Description)
+	}
+	r.Equal([]string{
+		"add column widgets.weight",
+		"add index widgets_name_idx on widgets",
+		"create table gadgets",
+	}, descs)
+	r.False(plan.Destructive())
+
+	r.NoError(c.ApplySchema(plan))
+
+	plan, err = c.PlanSchema(strings.NewReader(schemaDefinitionFizz), SchemaPlanOptions{})
+	r.NoError(err)
+	r.Len(plan.Changes, 0)
+
+	plan, err = c.PlanSchema(strings.NewReader(schemaDefinitionFizz), SchemaPlanOptions{AllowDrop: true})
+	r.NoError(err)
+	descs = []string{}
+	for _, ch := range plan.Changes {
+		descs = append(descs, ch.Description)
+	}
+	r.Equal([]string{"drop column widgets.color", "drop table legacy"}, descs)
+	r.True(plan.Destructive())
+
+	r.NoError(c.ApplySchema(plan))
+	cols, err := c.tableColumns("widgets")
+	r.NoError(err)
+	r.NotContains(cols, "color")
+	cols, err = c.tableColumns("legacy")
+	r.NoError(err)
+	r.Nil(cols)
+}
+
+func Test_PlanSchema_NotDeclarative(t *testing.T) {
+	r := require.New(t)
+
+	dir, err := ioutil.TempDir("", "pop")
+	r.NoError(err)
+	defer os.RemoveAll(dir)
+
+	c, err := NewConnection(&ConnectionDetails{Dialect: "sqlite3", Database: filepath.Join(dir, "schema.sqlite")})
+	r.NoError(err)
+	r.NoError(c.Open())
+	defer c.Close()
+
+	_, err = c.PlanSchema(strings.NewReader(`drop_table("widgets")`), SchemaPlanOptions{})
+	r.Error(err)
+	r.Contains(err.Error(), "drop_table can not be used in a schema definition")
+
+	_, err = c.PlanSchema(strings.NewReader(`raw("DELETE FROM widgets")`), SchemaPlanOptions{})
+	r.Error(err)
+}
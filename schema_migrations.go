@@ -8,6 +8,9 @@ var schemaMigrations = fizz.Table{
 	Name: "schema_migration",
 	Columns: []fizz.Column{
 		{Name: "version", ColType: "string"},
+		{Name: "applied_at", ColType: "timestamp", Options: fizz.Options{"null": true}},
+		{Name: "applied_by", ColType: "string", Options: fizz.Options{"null": true}},
+		{Name: "duration_ms", ColType: "integer", Options: fizz.Options{"null": true}},
 	},
 	Indexes: []fizz.Index{
 		{Name: "version_idx", Columns: []string{"version"}, Unique: true},
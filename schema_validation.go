@@ -0,0 +1,267 @@
+package pop
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/markbates/pop/columns"
+	"github.com/pkg/errors"
+)
+
+var registeredModels = []interface{}{}
+var registeredModelsMu = sync.Mutex{}
+
+// RegisterModel registers models to be checked by `ValidateSchema`.
+// It is meant to be called from init functions:
+//
+//	func init() {
+//		pop.RegisterModel(&User{}, &Book{})
+//	}
+func RegisterModel(models ...interface{}) {
+	registeredModelsMu.Lock()
+	defer registeredModelsMu.Unlock()
+	registeredModels = append(registeredModels, models...)
+}
+
+// RegisteredModels returns the models registered with `RegisterModel`.
+func RegisteredModels() []interface{} {
+	registeredModelsMu.Lock()
+	defer registeredModelsMu.Unlock()
+	return append([]interface{}{}, registeredModels...)
+}
+
+// ValidateSchema compares the columns of the models with the live schema
+// of the database, and returns the missing tables, missing columns and
+// type mismatches as `ModelErrors`. If no models are given, the registered
+// ones are checked. It is meant to be called at boot, so that a model out
+// of sync with its migrations fails early instead of at the first scan:
+//
+//	if err := c.ValidateSchema(); err != nil {
+//		log.Fatal(err)
+//	}
+//
+// Only the clear-cut type mismatches are reported, such as a string field
+// mapped to an integer column; fields implementing their own scanning are
+// not checked.
+func (c *Connection) ValidateSchema(models ...interface{}) error {
+	if len(models) == 0 {
+		models = RegisteredModels()
+	}
+	errs := ModelErrors{}
+	for _, model := range models {
+		if err := ValidateModel(model); err != nil {
+			errs = append(errs, err.(ModelErrors)...)
+			continue
+		}
+		merrs, err := c.schemaErrors(model)
+		if err != nil {
+			return err
+		}
+		errs = append(errs, merrs...)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// schemaErrors checks the fields of a single model against its tables.
+func (c *Connection) schemaErrors(model interface{}) (ModelErrors, error) {
+	m := &Model{Value: model}
+	t := reflect.TypeOf(model).Elem()
+	errs := ModelErrors{}
+	tables := map[string]map[string]string{}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tags := columns.TagsFor(f)
+		db := tags.Find("db")
+		if f.PkgPath != "" || db.Empty() || db.Ignored() || associationTagged(tags) || !tags.Find("select").Empty() {
+			continue
+		}
+		table := m.TableName()
+		if p := tags.Find("partition"); !p.Empty() {
+			table = p.Value
+		}
+
+		cols, ok := tables[table]
+		if !ok {
+			var err error
+			cols, err = c.tableColumns(table)
+			if err != nil {
+				return errs, err
+			}
+			tables[table] = cols
+			if cols == nil {
+				errs = append(errs, &ModelError{Model: t.String(), Reason: fmt.Sprintf("table %s does not exist", table)})
+			}
+		}
+		if cols == nil {
+			continue
+		}
+
+		dbType, ok := cols[db.Value]
+		if !ok {
+			errs = append(errs, &ModelError{Model: t.String(), Field: f.Name, Reason: fmt.Sprintf("column %s is missing from table %s", db.Value, table)})
+			continue
+		}
+		if !compatibleKinds(fieldKind(f.Type), columnKind(dbType)) {
+			errs = append(errs, &ModelError{Model: t.String(), Field: f.Name, Reason: fmt.Sprintf("is a %s, but column %s.%s is a %s", f.Type, table, db.Value, dbType)})
+		}
+	}
+	return errs, nil
+}
+
+// tableColumns returns the database types of the columns of table, by
+// column name, or nil if the table does not exist.
+func (c *Connection) tableColumns(table string) (map[string]string, error) {
+	ok, err := tableExists(c, table)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not tell whether %s exists", table)
+	}
+	if !ok {
+		return nil, nil
+	}
+	rows, err := c.Store.Queryx(fmt.Sprintf("SELECT * FROM %s WHERE 1 = 0", table))
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read the columns of %s", table)
+	}
+	defer rows.Close()
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read the columns of %s", table)
+	}
+	cols := map[string]string{}
+	for _, ct := range types {
+		cols[ct.Name()] = ct.DatabaseTypeName()
+	}
+	return cols, nil
+}
+
+// tableExists returns true if there is a table or a view named table.
+// The other dialects, the memory one, report every table as existing.
+func tableExists(c *Connection, table string) (bool, error) {
+	var query string
+	switch c.Dialect.Details().Dialect {
+	case "sqlite3":
+		query = "SELECT count(*) FROM sqlite_master WHERE type IN ('table', 'view') AND name = ?"
+	case "mysql":
+		query = "SELECT count(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?"
+	case "postgres", "cockroach":
+		query = "SELECT count(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = ?"
+	default:
+		return true, nil
+	}
+	n := 0
+	err := c.Store.Get(&n, c.Dialect.TranslateSQL(query), table)
+	return n > 0, err
+}
+
+// columnKind maps a database type name to a broad kind of value: int,
+// float, string, bool, time, uuid, json or bytes. Unknown types map to "",
+// and so does NUMERIC, which SQLite uses for values of any kind.
+func columnKind(dbType string) string {
+	t := strings.ToUpper(strings.TrimSpace(dbType))
+	if i := strings.Index(t, "("); i != -1 {
+		t = strings.TrimSpace(t[:i])
+	}
+	t = strings.TrimPrefix(t, "UNSIGNED ")
+	switch t {
+	case "INT", "INTEGER", "BIGINT", "SMALLINT", "TINYINT", "MEDIUMINT", "INT2", "INT4", "INT8", "SERIAL", "BIGSERIAL", "SMALLSERIAL", "YEAR":
+		return "int"
+	case "REAL", "FLOAT", "DOUBLE", "DOUBLE PRECISION", "DECIMAL", "FLOAT4", "FLOAT8", "MONEY":
+		return "float"
+	case "VARCHAR", "CHAR", "CHARACTER", "CHARACTER VARYING", "TEXT", "TINYTEXT", "MEDIUMTEXT", "LONGTEXT", "BPCHAR", "NAME", "CITEXT", "STRING", "ENUM", "SET":
+		return "string"
+	case "BOOL", "BOOLEAN":
+		return "bool"
+	case "TIMESTAMP", "TIMESTAMPTZ", "DATETIME", "DATE", "TIME", "TIMETZ":
+		return "time"
+	case "UUID":
+		return "uuid"
+	case "JSON", "JSONB":
+		return "json"
+	case "BLOB", "TINYBLOB", "MEDIUMBLOB", "LONGBLOB", "BYTEA", "BYTES", "BINARY", "VARBINARY":
+		return "bytes"
+	}
+	return ""
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// nullsKinds maps the types of the nulls package to the kind of value
+// they hold.
+var nullsKinds = map[string]string{
+	"nulls.Int":       "int",
+	"nulls.Int32":     "int",
+	"nulls.Int64":     "int",
+	"nulls.UInt32":    "int",
+	"nulls.Float32":   "float",
+	"nulls.Float64":   "float",
+	"nulls.String":    "string",
+	"nulls.Bool":      "bool",
+	"nulls.Time":      "time",
+	"nulls.UUID":      "uuid",
+	"nulls.ByteSlice": "bytes",
+}
+
+// fieldKind maps the type of a field to the kind of column it can be
+// scanned from, or "" if it can not be told.
+func fieldKind(t reflect.Type) string {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return "time"
+	}
+	if t.String() == "uuid.UUID" {
+		return "uuid"
+	}
+	if k, ok := nullsKinds[t.String()]; ok {
+		return k
+	}
+	if t.PkgPath() != "" && t.Kind() == reflect.Struct {
+		return ""
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "int"
+	case reflect.Float32, reflect.Float64:
+		return "float"
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "bool"
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "bytes"
+		}
+	}
+	return ""
+}
+
+// compatibleKinds returns false if a field of the kind field can not be
+// scanned from a column of the kind column.
+func compatibleKinds(field string, column string) bool {
+	if field == "" || column == "" || field == column {
+		return true
+	}
+	accepted := map[string][]string{
+		"int":    {"bool"},
+		"float":  {"int"},
+		"string": {"int", "float", "bool", "time", "uuid", "json", "bytes"},
+		"bool":   {"int"},
+		"uuid":   {"string", "bytes"},
+		"bytes":  {"string", "uuid", "json"},
+	}
+	for _, k := range accepted[field] {
+		if k == column {
+			return true
+		}
+	}
+	return false
+}
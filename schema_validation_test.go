@@ -0,0 +1,73 @@
+package pop_test
+
+import (
+	"testing"
+
+	"github.com/markbates/pop"
+	"github.com/stretchr/testify/require"
+)
+
+type outdatedUser struct {
+	ID       int    `db:"id"`
+	Name     int    `db:"name"`
+	Nickname string `db:"nickname"`
+}
+
+func (outdatedUser) TableName() string {
+	return "users"
+}
+
+type missingTable struct {
+	ID int `db:"id"`
+}
+
+func Test_ValidateSchema(t *testing.T) {
+	transaction(func(tx *pop.Connection) {
+		r := require.New(t)
+
+		r.NoError(tx.ValidateSchema(&User{}, &Book{}, &Song{}, &Article{}))
+
+		err := tx.ValidateSchema(&outdatedUser{})
+		r.Error(err)
+		errs, ok := err.(pop.ModelErrors)
+		r.True(ok)
+		r.Len(errs, 2)
+		r.Contains(errs[0].Error(), "model pop_test.outdatedUser, field Name: is a int, but column users.name is a ")
+		r.Equal("model pop_test.outdatedUser, field Nickname: column nickname is missing from table users", errs[1].Error())
+	})
+}
+
+func Test_ValidateSchema_MissingTable(t *testing.T) {
+	r := require.New(t)
+
+	err := PDB.ValidateSchema(&missingTable{})
+	r.Error(err)
+	r.Equal("model pop_test.missingTable: table missing_tables does not exist", err.Error())
+}
+
+func Test_ValidateSchema_Registered(t *testing.T) {
+	r := require.New(t)
+
+	pop.RegisterModel(&User{}, &Book{})
+	r.NoError(PDB.ValidateSchema())
+	r.Contains(pop.RegisteredModels(), &Book{})
+}
+
+type brokenView struct {
+	ID int `db:"id"`
+}
+
+func Test_ValidateSchema_QueryError(t *testing.T) {
+	if PDB.Dialect.Details().Dialect != "sqlite3" {
+		t.Skip("only SQLite creates views of missing tables")
+	}
+	transaction(func(tx *pop.Connection) {
+		r := require.New(t)
+		r.NoError(tx.RawQuery("CREATE VIEW broken_views AS SELECT id FROM nowhere").Exec())
+		err := tx.ValidateSchema(&brokenView{})
+		r.Error(err)
+		_, ok := err.(pop.ModelErrors)
+		r.False(ok)
+		r.Contains(err.Error(), "could not read the columns of broken_views")
+	})
+}
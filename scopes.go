@@ -3,7 +3,8 @@ package pop
 // ScopeFunc applies a custom operation on a given `Query`
 type ScopeFunc func(q *Query) *Query
 
-// Scope the query by using a `ScopeFunc`
+// Scope the query by using `ScopeFunc`s, applied in order, so that the
+// filters used across an application are defined once:
 //
 //	func ByName(name string) ScopeFunc {
 //		return func(q *Query) *Query {
@@ -11,12 +12,45 @@ type ScopeFunc func(q *Query) *Query
 //		}
 //	}
 //
-//	q.Scope(ByName("mark").Where("id = ?", 1).First(&User{})
-func (q *Query) Scope(sf ScopeFunc) *Query {
-	return sf(q)
+//	q.Scope(ByName("mark"), Published()).Where("id = ?", 1).First(&User{})
+func (q *Query) Scope(scopes ...ScopeFunc) *Query {
+	for _, sf := range scopes {
+		q = sf(q)
+	}
+	return q
 }
 
-// Scope the query by using a `ScopeFunc`
+// Scopes returns a `ScopeFunc` applying scopes in order, which combines
+// them into a single reusable scope.
+//
+//	func Visible(userID int) pop.ScopeFunc {
+//		return pop.Scopes(Published(), OwnedBy(userID))
+//	}
+func Scopes(scopes ...ScopeFunc) ScopeFunc {
+	return func(q *Query) *Query {
+		return q.Scope(scopes...)
+	}
+}
+
+// Where returns a `ScopeFunc` adding a where clause to the query.
+//
+//	q.EagerWith("Books", pop.Where("published = ?", true))
+func Where(stmt string, args ...interface{}) ScopeFunc {
+	return func(q *Query) *Query {
+		return q.Where(stmt, args...)
+	}
+}
+
+// Order returns a `ScopeFunc` adding an order clause to the query.
+//
+//	q.EagerWith("Books", pop.Order("published_at desc"))
+func Order(stmt string) ScopeFunc {
+	return func(q *Query) *Query {
+		return q.Order(stmt)
+	}
+}
+
+// Scope the query by using `ScopeFunc`s, applied in order.
 //
 //	func ByName(name string) ScopeFunc {
 //		return func(q *Query) *Query {
@@ -24,7 +58,7 @@ func (q *Query) Scope(sf ScopeFunc) *Query {
 //		}
 //	}
 //
-//	c.Scope(ByName("mark")).First(&User{})
-func (c *Connection) Scope(sf ScopeFunc) *Query {
-	return Q(c).Scope(sf)
+//	c.Scope(ByName("mark"), Published()).First(&User{})
+func (c *Connection) Scope(scopes ...ScopeFunc) *Query {
+	return Q(c).Scope(scopes...)
 }
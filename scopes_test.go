@@ -9,7 +9,7 @@ import (
 
 func Test_Scopes(t *testing.T) {
 	r := require.New(t)
-	oql := "SELECT enemies.A FROM enemies AS enemies"
+	oql := "SELECT enemies.a FROM enemies AS enemies"
 
 	m := &pop.Model{Value: &Enemy{}}
 
@@ -24,3 +24,22 @@ func Test_Scopes(t *testing.T) {
 	s, _ = q.ToSQL(m)
 	r.Equal(ts(oql+" WHERE id = ?"), s)
 }
+
+func Test_Scopes_Variadic(t *testing.T) {
+	r := require.New(t)
+	m := &pop.Model{Value: &Enemy{}}
+
+	byID := func(id int) pop.ScopeFunc {
+		return func(q *pop.Query) *pop.Query {
+			return q.Where("id = ?", id)
+		}
+	}
+	latest := pop.Scopes(pop.Order("id desc"), pop.Where("a IS NOT NULL"))
+
+	s, args := PDB.Scope(byID(1), latest).ToSQL(m)
+	r.Equal(ts("SELECT enemies.a FROM enemies AS enemies WHERE id = ? AND a IS NOT NULL ORDER BY id desc"), s)
+	r.Equal([]interface{}{1}, args)
+
+	s, _ = PDB.Scope().ToSQL(m)
+	r.Equal("SELECT enemies.a FROM enemies AS enemies", s)
+}
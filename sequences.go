@@ -0,0 +1,142 @@
+package pop
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/markbates/pop/columns"
+	"github.com/pkg/errors"
+)
+
+// sequencer is implemented by the dialects supporting sequences.
+type sequencer interface {
+	nextSequenceValue(s store, name string) (int64, error)
+	reserveSequenceValues(s store, name string, n int) ([]int64, error)
+}
+
+// NextSequenceValue returns the next value of a sequence, created with
+// the create_sequence fizz helper.
+//
+//	n, err := c.NextSequenceValue("invoice_numbers")
+//
+// MySQL and SQLite have no sequences: they are emulated with a table,
+// whose rows are the values handed out.
+func (c *Connection) NextSequenceValue(name string) (int64, error) {
+	sq, ok := c.Dialect.(sequencer)
+	if !ok {
+		return 0, errors.Errorf("%s does not support sequences", c.Dialect.Details().Dialect)
+	}
+	var n int64
+	err := c.timeFunc("NextSequenceValue", func() error {
+		var err error
+		n, err = sq.nextSequenceValue(c.Store, name)
+		return errors.Wrapf(err, "could not get the next value of %s", name)
+	})
+	return n, err
+}
+
+// ReserveSequenceValues reserves n values of a sequence in a single round
+// trip where the database allows it, so a batch of models can get their
+// ids before being inserted. The values are not always consecutive.
+//
+//	ns, err := c.ReserveSequenceValues("invoice_numbers", 500)
+func (c *Connection) ReserveSequenceValues(name string, n int) ([]int64, error) {
+	sq, ok := c.Dialect.(sequencer)
+	if !ok {
+		return nil, errors.Errorf("%s does not support sequences", c.Dialect.Details().Dialect)
+	}
+	if n <= 0 {
+		return []int64{}, nil
+	}
+	var ns []int64
+	err := c.timeFunc("ReserveSequenceValues", func() error {
+		var err error
+		ns, err = sq.reserveSequenceValues(c.Store, name, n)
+		if err == nil && len(ns) != n {
+			err = errors.Errorf("got %d values instead of %d", len(ns), n)
+		}
+		return errors.Wrapf(err, "could not reserve %d values of %s", n, name)
+	})
+	return ns, err
+}
+
+// AssignIDs sets the ids of a slice of models from the sequence of their
+// ID field, reserving them with `ReserveSequenceValues`. The models which
+// already have an id are left alone, and keep it when they are created.
+//
+//	invoices := []Invoice{{Title: "A"}, {Title: "B"}}
+//	err := c.AssignIDs(&invoices)
+func (c *Connection) AssignIDs(models interface{}) error {
+	if err := checkModels(models); err != nil {
+		return err
+	}
+	v := reflect.Indirect(reflect.ValueOf(models))
+	zero := []*Model{}
+	for i := 0; i < v.Len(); i++ {
+		el := v.Index(i)
+		if el.Kind() != reflect.Ptr {
+			el = el.Addr()
+		}
+		m := &Model{Value: el.Interface()}
+		if kt := m.PrimaryKeyType(); kt != "int" && kt != "int64" {
+			return errors.Errorf("%s has a %s id, only int ids can be taken from a sequence", m.TableName(), kt)
+		}
+		if fmt.Sprint(m.ID()) == "0" {
+			zero = append(zero, m)
+		}
+	}
+	if len(zero) == 0 {
+		return nil
+	}
+	name := zero[0].sequence()
+	if name == "" {
+		return errors.Errorf("%s does not take its ids from a sequence", zero[0].TableName())
+	}
+	ns, err := c.ReserveSequenceValues(name, len(zero))
+	if err != nil {
+		return err
+	}
+	for i, m := range zero {
+		m.setID(ns[i])
+	}
+	return nil
+}
+
+// sequence returns the name of the sequence the ids of the model are
+// taken from, set with the sequence tag of its ID field:
+//
+//	type Invoice struct {
+//		ID int `db:"id" sequence:"invoice_numbers"`
+//	}
+func (m *Model) sequence() string {
+	t := reflect.Indirect(reflect.ValueOf(m.Value)).Type()
+	if t.Kind() != reflect.Struct {
+		return ""
+	}
+	f, ok := t.FieldByName("ID")
+	if !ok {
+		return ""
+	}
+	return columns.TagsFor(f).Find("sequence").Value
+}
+
+// createFromSequence inserts a model whose id comes from the sequence
+// name. An id already set on the model is kept.
+func (c *Connection) createFromSequence(m *Model, cols columns.Columns, name string) error {
+	if kt := m.PrimaryKeyType(); kt != "int" && kt != "int64" {
+		return errors.Errorf("%s can not take its %s id from the sequence %s", m.TableName(), kt, name)
+	}
+	if fmt.Sprint(m.ID()) == "0" {
+		id, err := c.NextSequenceValue(name)
+		if err != nil {
+			return err
+		}
+		m.setID(id)
+	}
+
+	w := cols.Writeable()
+	w.Add("id")
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", c.Dialect.Quote(m.TableName()), w.QuotedString(c.Dialect.Quote), w.SymbolizedString())
+	_, err := c.Store.NamedExec(query, m.Value)
+	return errors.WithStack(err)
+}
@@ -0,0 +1,100 @@
+package pop_test
+
+import (
+	"testing"
+
+	"github.com/markbates/pop"
+	"github.com/satori/go.uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NextSequenceValue(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		n, err := tx.NextSequenceValue("invoice_numbers")
+		r.NoError(err)
+		r.True(n >= 1000)
+
+		m, err := tx.NextSequenceValue("invoice_numbers")
+		r.NoError(err)
+		r.Equal(n+1, m)
+	})
+}
+
+func Test_Create_Sequence(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		n, err := tx.NextSequenceValue("invoice_numbers")
+		r.NoError(err)
+
+		i := &Invoice{Title: "First"}
+		r.NoError(tx.Create(i))
+		r.Equal(int(n+1), i.ID)
+
+		fi := &Invoice{}
+		r.NoError(tx.Find(fi, i.ID))
+		r.Equal("First", fi.Title)
+
+		// a preset id is kept, and does not use up a value.
+		p := &Invoice{ID: 42, Title: "Preset"}
+		r.NoError(tx.Create(p))
+		r.Equal(42, p.ID)
+
+		m, err := tx.NextSequenceValue("invoice_numbers")
+		r.NoError(err)
+		r.Equal(n+2, m)
+	})
+}
+
+type uuidInvoice struct {
+	ID    uuid.UUID `db:"id" sequence:"invoice_numbers"`
+	Title string    `db:"title"`
+}
+
+func Test_ValidateModel_Sequence(t *testing.T) {
+	r := require.New(t)
+	r.NoError(pop.ValidateModel(&Invoice{}))
+
+	err := pop.ValidateModel(&uuidInvoice{})
+	r.Error(err)
+	r.Contains(err.Error(), "only int ids can be taken from the sequence invoice_numbers")
+}
+
+func Test_ReserveSequenceValues(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		ns, err := tx.ReserveSequenceValues("invoice_numbers", 3)
+		r.NoError(err)
+		r.Len(ns, 3)
+		r.Equal(ns[0]+1, ns[1])
+		r.Equal(ns[1]+1, ns[2])
+
+		n, err := tx.NextSequenceValue("invoice_numbers")
+		r.NoError(err)
+		r.Equal(ns[2]+1, n)
+
+		ns, err = tx.ReserveSequenceValues("invoice_numbers", 0)
+		r.NoError(err)
+		r.Len(ns, 0)
+	})
+}
+
+func Test_AssignIDs(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		invoices := []Invoice{{Title: "A"}, {ID: 42, Title: "B"}, {Title: "C"}}
+		r.NoError(tx.AssignIDs(&invoices))
+		r.NotZero(invoices[0].ID)
+		r.Equal(42, invoices[1].ID)
+		r.Equal(invoices[0].ID+1, invoices[2].ID)
+
+		for i := range invoices {
+			r.NoError(tx.Create(&invoices[i]))
+		}
+		ids := []int{}
+		r.NoError(tx.RawQuery("select id from invoices order by id").All(&ids))
+		r.Equal([]int{42, invoices[0].ID, invoices[2].ID}, ids)
+
+		r.Error(tx.AssignIDs(&[]User{{}}))
+	})
+}
@@ -0,0 +1,137 @@
+package pop
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrShuttingDown is returned for the queries and transactions started
+// after `CloseAll` or `Connection.Shutdown` was called.
+var ErrShuttingDown = errors.New("the connection is shutting down")
+
+// gate tracks the queries and transactions in flight on a pool, so it can
+// be closed once they are done. A connection shares the gate of its pool
+// with its transactions and sessions.
+type gate struct {
+	mu      sync.Mutex
+	closing bool
+	queries int
+	txs     int
+}
+
+// enter lets a query in, unless the pool is closing: only the queries of
+// the transactions already open are let in then.
+func (g *gate) enter(inTx bool) error {
+	if g == nil {
+		return nil
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.closing && !inTx {
+		return ErrShuttingDown
+	}
+	g.queries++
+	return nil
+}
+
+func (g *gate) leave() {
+	if g == nil {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.queries--
+}
+
+// begin lets a transaction in, unless the pool is closing, and returns
+// the function to call once it is committed or rolled back.
+func (g *gate) begin() (func(), error) {
+	if g == nil {
+		return func() {}, nil
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.closing {
+		return nil, ErrShuttingDown
+	}
+	g.txs++
+	once := sync.Once{}
+	return func() {
+		once.Do(func() {
+			g.mu.Lock()
+			defer g.mu.Unlock()
+			g.txs--
+		})
+	}, nil
+}
+
+func (g *gate) close() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.closing = true
+}
+
+func (g *gate) idle() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.queries == 0 && g.txs == 0
+}
+
+// Shutdown closes the connection gracefully: it stops accepting new
+// queries and transactions, waits for the ones in flight, including the
+// queries of the open transactions, then closes the pool. If ctx is done
+// first, the pool is closed anyway and the error of ctx is returned.
+func (c *Connection) Shutdown(ctx context.Context) error {
+	if c.Store == nil {
+		return nil
+	}
+	var err error
+	if c.gate != nil {
+		c.gate.close()
+		tick := time.NewTicker(10 * time.Millisecond)
+		defer tick.Stop()
+	wait:
+		for !c.gate.idle() {
+			select {
+			case <-ctx.Done():
+				err = errors.WithStack(ctx.Err())
+				break wait
+			case <-tick.C:
+			}
+		}
+	}
+	if cerr := c.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// CloseAll shuts down all of the open `Connections`, as `Shutdown` does,
+// which is meant to be called when the application is stopping:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+//	defer cancel()
+//	err := pop.CloseAll(ctx)
+func CloseAll(ctx context.Context) error {
+	wg := sync.WaitGroup{}
+	mu := sync.Mutex{}
+	var err error
+	for _, c := range Connections {
+		wg.Add(1)
+		go func(c *Connection) {
+			defer wg.Done()
+			if serr := c.Shutdown(ctx); serr != nil {
+				mu.Lock()
+				if err == nil {
+					err = errors.Wrapf(serr, "couldn't shut down %s", c.Dialect.Details().Database)
+				}
+				mu.Unlock()
+			}
+		}(c)
+	}
+	wg.Wait()
+	return err
+}
@@ -0,0 +1,82 @@
+// +build !nosqlite,!appengine,!appenginevm
+
+package pop
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func shutdownConnection(r *require.Assertions, dir string) *Connection {
+	c, err := NewConnection(&ConnectionDetails{Dialect: "sqlite3", Database: filepath.Join(dir, "shutdown.sqlite")})
+	r.NoError(err)
+	r.NoError(c.Open())
+	r.NoError(c.RawQuery("CREATE TABLE widgets (id INTEGER PRIMARY KEY, name TEXT)").Exec())
+	return c
+}
+
+func Test_Shutdown_WaitsForTransactions(t *testing.T) {
+	r := require.New(t)
+
+	dir, err := ioutil.TempDir("", "pop")
+	r.NoError(err)
+	defer os.RemoveAll(dir)
+
+	c := shutdownConnection(r, dir)
+	tx, err := c.NewTransaction()
+	r.NoError(err)
+
+	done := make(chan error)
+	go func() {
+		done <- c.Shutdown(context.Background())
+	}()
+	for {
+		c.gate.mu.Lock()
+		closing := c.gate.closing
+		c.gate.mu.Unlock()
+		if closing {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// new queries and transactions are refused...
+	err = c.RawQuery("INSERT INTO widgets (name) VALUES ('new')").Exec()
+	r.Equal(ErrShuttingDown, errors.Cause(err))
+	_, err = c.NewTransaction()
+	r.Equal(ErrShuttingDown, errors.Cause(err))
+
+	// ...but the open transaction can finish its work.
+	r.NoError(tx.RawQuery("INSERT INTO widgets (name) VALUES ('in flight')").Exec())
+	select {
+	case <-done:
+		r.Fail("the pool was closed before the transaction was over")
+	case <-time.After(50 * time.Millisecond):
+	}
+	r.NoError(tx.TX.Commit())
+	r.NoError(<-done)
+}
+
+func Test_Shutdown_Deadline(t *testing.T) {
+	r := require.New(t)
+
+	dir, err := ioutil.TempDir("", "pop")
+	r.NoError(err)
+	defer os.RemoveAll(dir)
+
+	c := shutdownConnection(r, dir)
+	_, err = c.NewTransaction()
+	r.NoError(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err = c.Shutdown(ctx)
+	r.Equal(context.DeadlineExceeded, errors.Cause(err))
+}
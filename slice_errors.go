@@ -0,0 +1,32 @@
+package pop
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SliceError describes a failure to write a single element of a slice.
+type SliceError struct {
+	// Index of the element in the slice
+	Index int
+	// ID is the primary key of the element
+	ID interface{}
+	// Err is the underlying error
+	Err error
+}
+
+func (e *SliceError) Error() string {
+	return fmt.Sprintf("writing element %d (id %v): %s", e.Index, e.ID, e.Err)
+}
+
+// SliceErrors collects all of the errors raised while writing a slice of
+// models.
+type SliceErrors []*SliceError
+
+func (e SliceErrors) Error() string {
+	xs := make([]string, 0, len(e))
+	for _, err := range e {
+		xs = append(xs, err.Error())
+	}
+	return strings.Join(xs, "; ")
+}
@@ -0,0 +1,53 @@
+package pop
+
+import (
+	"fmt"
+	"time"
+)
+
+// SlowQueryFunc is called with the statements which ran for longer than
+// the slow query threshold of their connection.
+type SlowQueryFunc func(QueryEvent)
+
+// WithSlowQueries returns a copy of the connection calling fn with the
+// statements running for threshold or longer, as do the transactions
+// started from it:
+//
+//	c = c.WithSlowQueries(200*time.Millisecond, func(e pop.QueryEvent) {
+//		metrics.SlowQuery(e.SQL, e.Duration)
+//	})
+//
+// When fn is nil, the slow statements are logged as warnings instead, see
+// `Logger`. The threshold defaults to the "slow_query_threshold" option
+// of the connection; a zero threshold disables the detection.
+func (c *Connection) WithSlowQueries(threshold time.Duration, fn SlowQueryFunc) *Connection {
+	cn := *c
+	cn.slowThreshold = threshold
+	cn.slowQuery = fn
+	if is, ok := c.Store.(*instrumentedStore); ok {
+		cn.Store = newInstrumentedStore(&cn, is.store)
+	}
+	return &cn
+}
+
+// reportSlow hands the event over to the slow query function of its
+// connection, or logs it with logArgs, if the statement was slow.
+func reportSlow(e QueryEvent, logArgs []interface{}) {
+	c := e.Connection
+	if c == nil || c.slowThreshold <= 0 || e.Duration < c.slowThreshold {
+		return
+	}
+	if c.slowQuery != nil {
+		c.slowQuery(e)
+		return
+	}
+	logEntry(c, LogEntry{
+		Level:        LogWarn,
+		SQL:          e.SQL,
+		Args:         logArgs,
+		Duration:     e.Duration,
+		RowsAffected: -1,
+		Err:          e.Err,
+		Message:      fmt.Sprintf("slow query, ran for %s", e.Duration),
+	})
+}
@@ -0,0 +1,99 @@
+package pop_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/markbates/pop"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WithSlowQueries(t *testing.T) {
+	transaction(func(tx *pop.Connection) {
+		r := require.New(t)
+
+		slow := []pop.QueryEvent{}
+		c := tx.WithSlowQueries(time.Nanosecond, func(e pop.QueryEvent) {
+			slow = append(slow, e)
+		})
+		r.NoError(c.Where("title = ?", "Emma").All(&Books{}))
+		r.Len(slow, 1)
+		r.Contains(slow[0].SQL, "FROM books")
+		r.Equal([]interface{}{"Emma"}, slow[0].Args)
+		r.True(slow[0].Duration > 0)
+
+		slow = slow[:0]
+		c = tx.WithSlowQueries(time.Hour, func(e pop.QueryEvent) {
+			slow = append(slow, e)
+		})
+		r.NoError(c.Where("title = ?", "Emma").All(&Books{}))
+		r.Len(slow, 0)
+	})
+}
+
+func Test_WithSlowQueries_Log(t *testing.T) {
+	transaction(func(tx *pop.Connection) {
+		r := require.New(t)
+
+		l := &entries{}
+		c := tx.WithLogger(l).WithSlowQueries(time.Nanosecond, nil)
+		r.NoError(c.Where("title = ?", "Emma").All(&Books{}))
+
+		warnings := 0
+		for _, e := range l.logs {
+			if e.Level == pop.LogWarn {
+				warnings++
+				r.Contains(e.SQL, "FROM books")
+				r.Contains(e.Message, "slow query")
+			}
+		}
+		r.Equal(1, warnings)
+	})
+}
+
+func Test_ConnectionDetails_SlowQueryThreshold(t *testing.T) {
+	r := require.New(t)
+
+	cd := &pop.ConnectionDetails{}
+	r.Equal(time.Duration(0), cd.SlowQueryThreshold())
+	cd.Options = map[string]string{"slow_query_threshold": "250ms"}
+	r.Equal(250*time.Millisecond, cd.SlowQueryThreshold())
+}
+
+func Test_WithSlowQueries_Log_Quiet(t *testing.T) {
+	transaction(func(tx *pop.Connection) {
+		r := require.New(t)
+
+		l := &entries{}
+		c := tx.WithLogger(l).WithSlowQueries(time.Nanosecond, nil)
+		r.NoError(c.Quiet().Where("title = ?", "Emma").All(&Books{}))
+
+		r.Len(l.logs, 1)
+		r.Equal(pop.LogWarn, l.logs[0].Level)
+		r.Contains(l.logs[0].SQL, "FROM books")
+	})
+}
+
+func Test_WithSlowQueries_Log_Named(t *testing.T) {
+	transaction(func(tx *pop.Connection) {
+		r := require.New(t)
+
+		b := &Book{Title: "Emma", Isbn: "1"}
+		r.NoError(tx.Create(b))
+
+		l := &entries{}
+		c := tx.WithLogger(l).WithSlowQueries(time.Nanosecond, nil)
+		b.Title = "Persuasion"
+		r.NoError(c.Update(b))
+
+		warnings := 0
+		for _, e := range l.logs {
+			if e.Level == pop.LogWarn {
+				warnings++
+				r.Contains(e.SQL, "UPDATE books")
+				r.NotContains(e.Args, b)
+			}
+		}
+		r.Equal(1, warnings)
+	})
+}
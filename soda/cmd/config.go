@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/markbates/pop"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Tools for working with the database configuration",
+}
+
+var configCheckConnect bool
+
+var configCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Checks every connection of the database.yml file, without touching any data.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		problems, err := pop.CheckConfigFile(configCheckConnect)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		for _, p := range problems {
+			fmt.Println(p)
+		}
+		if len(problems) > 0 {
+			return errors.Errorf("found %d problems", len(problems))
+		}
+		fmt.Println("The configuration looks good!")
+		return nil
+	},
+}
+
+func init() {
+	configCheckCmd.Flags().BoolVar(&configCheckConnect, "connect", false, "Also connects to every database")
+	configCmd.AddCommand(configCheckCmd)
+	RootCmd.AddCommand(configCmd)
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var countersCmd = &cobra.Command{
+	Use:   "counters",
+	Short: "Tools for working with counter cache columns",
+}
+
+var recountOptions = struct {
+	parent string
+	column string
+	child  string
+	fk     string
+}{}
+
+var countersRecountCmd = &cobra.Command{
+	Use:   "recount",
+	Short: "Recomputes a counter cache column from the rows of the child table.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		o := recountOptions
+		if o.parent == "" || o.column == "" || o.child == "" || o.fk == "" {
+			return errors.New("--parent, --column, --child and --fk are all required")
+		}
+		c := getConn()
+		if err := c.Open(); err != nil {
+			return errors.WithStack(err)
+		}
+		return c.RecountCounterCache(o.parent, o.column, o.child, o.fk)
+	},
+}
+
+func init() {
+	countersRecountCmd.Flags().StringVar(&recountOptions.parent, "parent", "", "The table holding the counter column (ex: posts)")
+	countersRecountCmd.Flags().StringVar(&recountOptions.column, "column", "", "The counter column (ex: comments_count)")
+	countersRecountCmd.Flags().StringVar(&recountOptions.child, "child", "", "The table being counted (ex: comments)")
+	countersRecountCmd.Flags().StringVar(&recountOptions.fk, "fk", "", "The column of the child table pointing at the parent (ex: post_id)")
+	countersCmd.AddCommand(countersRecountCmd)
+	RootCmd.AddCommand(countersCmd)
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/markbates/pop"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var credentialsCmd = &cobra.Command{
+	Use:   "credentials",
+	Short: "Tools for working with the encrypted credentials file",
+}
+
+var credentialsKeyCmd = &cobra.Command{
+	Use:   "key",
+	Short: "Generates a new key for the credentials file.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key, err := pop.NewCredentialsKey()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("export %s=%s\n", pop.CredentialsKeyEnv, key)
+		return nil
+	},
+}
+
+var credentialsSetCmd = &cobra.Command{
+	Use:   "set [name] [value]",
+	Short: "Sets a credential in the credentials file, encrypted with the key of $POP_CREDENTIALS_KEY.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			return errors.New("You must supply the name and the value of the credential")
+		}
+		return pop.SetCredential(args[0], args[1])
+	},
+}
+
+func init() {
+	credentialsCmd.AddCommand(credentialsKeyCmd)
+	credentialsCmd.AddCommand(credentialsSetCmd)
+	RootCmd.AddCommand(credentialsCmd)
+}
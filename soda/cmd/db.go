@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/markbates/pop"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Tools for operating your database",
+}
+
+var maintainTables string
+
+var dbMaintainCmd = &cobra.Command{
+	Use:   "maintain",
+	Short: "Runs the routine maintenance of the database (VACUUM ANALYZE, OPTIMIZE TABLE, integrity_check).",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c := getConn()
+		if err := c.Open(); err != nil {
+			return errors.WithStack(err)
+		}
+		tables := []string{}
+		if maintainTables != "" {
+			tables = strings.Split(maintainTables, ",")
+		}
+		results, err := c.Maintain(tables...)
+		for _, r := range results {
+			if r.Message != "" {
+				fmt.Printf("%s: %s\n", r.Statement, r.Message)
+			} else {
+				fmt.Println(r.Statement)
+			}
+		}
+		return err
+	},
+}
+
+var dbIndexReportCmd = &cobra.Command{
+	Use:   "index-report",
+	Short: "Lists the unused and duplicate indexes, and the foreign keys without an index.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c := getConn()
+		if err := c.Open(); err != nil {
+			return errors.WithStack(err)
+		}
+		report, err := c.IndexReport()
+		if err != nil {
+			return err
+		}
+		sections := []struct {
+			title   string
+			indexes []pop.IndexInfo
+		}{
+			{"Unused indexes", report.Unused},
+			{"Duplicate indexes", report.Duplicates},
+			{"Foreign keys without an index", report.MissingForeignKeyIndexes},
+		}
+		for _, s := range sections {
+			fmt.Printf("%s: %d\n", s.title, len(s.indexes))
+			for _, i := range s.indexes {
+				fmt.Printf("  %s\n", i)
+			}
+		}
+		return nil
+	},
+}
+
+var backupOptions = struct {
+	out      string
+	tables   string
+	native   bool
+	compress bool
+	progress bool
+}{}
+
+var dbBackupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Backs up the database with pg_dump or mysqldump, or in the native format of pop.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if backupOptions.out == "" {
+			return errors.New("you must give the file to write the backup to with --out")
+		}
+		c := getConn()
+		if err := c.Open(); err != nil {
+			return errors.WithStack(err)
+		}
+		f, err := os.Create(backupOptions.out)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		defer f.Close()
+
+		var w io.Writer = f
+		if backupOptions.compress || strings.HasSuffix(backupOptions.out, ".gz") {
+			gz := gzip.NewWriter(f)
+			defer gz.Close()
+			w = gz
+		}
+		if backupOptions.progress {
+			c = c.WithProgress(printProgress)
+		}
+		opts := pop.BackupOptions{Native: backupOptions.native}
+		if backupOptions.tables != "" {
+			opts.Tables = strings.Split(backupOptions.tables, ",")
+		}
+		return c.Backup(w, opts)
+	},
+}
+
+var restoreIn string
+var restoreProgress bool
+
+var dbRestoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Restores a backup made with the backup command.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if restoreIn == "" {
+			return errors.New("you must give the backup to restore with --in")
+		}
+		c := getConn()
+		if err := c.Open(); err != nil {
+			return errors.WithStack(err)
+		}
+		f, err := os.Open(restoreIn)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		defer f.Close()
+
+		// compressed backups are told apart by the gzip magic number.
+		br := bufio.NewReader(f)
+		var r io.Reader = br
+		if magic, _ := br.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+			gz, err := gzip.NewReader(br)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			defer gz.Close()
+			r = gz
+		}
+		if restoreProgress {
+			c = c.WithProgress(printProgress)
+		}
+		return c.Restore(r)
+	},
+}
+
+// printProgress prints the progress of an operation on a single line of
+// stderr.
+func printProgress(p pop.Progress) {
+	fmt.Fprintf(os.Stderr, "\r%s %s: %d rows, %d bytes in %s", p.Operation, p.Table, p.Rows, p.Bytes, p.Elapsed.Round(time.Second))
+	if p.Done {
+		fmt.Fprintln(os.Stderr)
+	}
+}
+
+func init() {
+	dbCmd.AddCommand(dbIndexReportCmd)
+	dbMaintainCmd.Flags().StringVar(&maintainTables, "tables", "", "A comma separated list of the tables to maintain, all of them by default")
+	dbCmd.AddCommand(dbMaintainCmd)
+	dbBackupCmd.Flags().StringVarP(&backupOptions.out, "out", "o", "", "The file to write the backup to")
+	dbBackupCmd.Flags().StringVar(&backupOptions.tables, "tables", "", "A comma separated list of the tables to back up, all of them by default")
+	dbBackupCmd.Flags().BoolVar(&backupOptions.native, "native", false, "Always use the native format, even if pg_dump or mysqldump is installed")
+	dbBackupCmd.Flags().BoolVarP(&backupOptions.compress, "compress", "z", false, "Compress the backup with gzip, the default for files ending in .gz")
+	dbBackupCmd.Flags().BoolVar(&backupOptions.progress, "progress", false, "Print the progress of the backup")
+	dbCmd.AddCommand(dbBackupCmd)
+	dbRestoreCmd.Flags().StringVarP(&restoreIn, "in", "i", "", "The backup to restore")
+	dbRestoreCmd.Flags().BoolVar(&restoreProgress, "progress", false, "Print the progress of the restore")
+	dbCmd.AddCommand(dbRestoreCmd)
+	RootCmd.AddCommand(dbCmd)
+}
@@ -15,5 +15,6 @@ func init() {
 	generateCmd.AddCommand(generate.FizzCmd)
 	generateCmd.AddCommand(generate.SQLCmd)
 	generateCmd.AddCommand(generate.ModelCmd)
+	generateCmd.AddCommand(generate.SequenceCmd)
 	RootCmd.AddCommand(generateCmd)
 }
@@ -1,10 +1,13 @@
 package generate
 
 import (
+	"bytes"
 	"fmt"
 	"strings"
+	"text/template"
 
 	"github.com/markbates/inflect"
+	"github.com/pkg/errors"
 )
 
 type attribute struct {
@@ -15,7 +18,63 @@ type attribute struct {
 }
 
 func (a attribute) String() string {
-	return fmt.Sprintf("\t%s %s `%s:\"%s\" db:\"%s\"`", a.Name.Camel(), a.GoType, structTag, a.Name, a.Name)
+	return fmt.Sprintf("\t%s %s `%s`", a.Name.Camel(), a.GoType, a.Tags())
+}
+
+// tagData is handed to the tags template.
+type tagData struct {
+	// Name is the name of the column
+	Name string
+	// Key is the name of the field in the tags, following the tag case
+	Key      string
+	GoType   string
+	Nullable bool
+}
+
+// parseTagsTemplate parses the template of the struct tags, and tries it
+// on an attribute so a template using unknown fields fails early.
+func parseTagsTemplate(src string) error {
+	tagsTemplate = nil
+	if src == "" {
+		return nil
+	}
+	t, err := template.New("tags").Parse(src)
+	if err != nil {
+		return errors.Wrap(err, "invalid tags template")
+	}
+	if err := t.Execute(&bytes.Buffer{}, tagData{}); err != nil {
+		return errors.Wrap(err, "invalid tags template")
+	}
+	tagsTemplate = t
+	return nil
+}
+
+// Key returns the name of the attribute in the struct tags other than db.
+func (a attribute) Key() string {
+	switch tagCase {
+	case "camel":
+		return inflect.CamelizeDownFirst(string(a.Name))
+	case "kebab":
+		return inflect.Dasherize(string(a.Name))
+	default:
+		return a.Name.Underscore()
+	}
+}
+
+// Tags returns the struct tags of the attribute: the struct tag and the
+// additional tags, or the tags template if there is one, then db.
+func (a attribute) Tags() string {
+	db := fmt.Sprintf("db:\"%s\"", a.Name)
+	if tagsTemplate != nil {
+		bb := &bytes.Buffer{}
+		tagsTemplate.Execute(bb, tagData{Name: string(a.Name), Key: a.Key(), GoType: a.GoType, Nullable: a.Nullable})
+		return strings.TrimSpace(bb.String() + " " + db)
+	}
+	xs := []string{}
+	for _, t := range append([]string{structTag}, extraTags...) {
+		xs = append(xs, fmt.Sprintf("%s:\"%s\"", t, a.Key()))
+	}
+	return strings.Join(append(xs, db), " ")
 }
 
 func (a attribute) IsValidable() bool {
@@ -64,3 +64,29 @@ func Test_newAttribute(t *testing.T) {
 	}
 
 }
+
+func Test_attribute_Tags(t *testing.T) {
+	r := require.New(t)
+
+	defer func(tags []string, c string) {
+		extraTags, tagCase = tags, c
+		r.NoError(parseTagsTemplate(""))
+	}(extraTags, tagCase)
+
+	model := newModel("car")
+	a := newAttribute("first_name", &model)
+	r.Equal(`json:"first_name" db:"first_name"`, a.Tags())
+
+	extraTags, tagCase = []string{"form", "xml"}, "camel"
+	r.Equal(`json:"firstName" form:"firstName" xml:"firstName" db:"first_name"`, a.Tags())
+
+	tagCase = "kebab"
+	r.Equal("first-name", a.Key())
+
+	r.NoError(parseTagsTemplate(`json:"{{.Key}}{{if .Nullable}},omitempty{{end}}" openapi:"{{.Name}}"`))
+	a = newAttribute("nickname:nulls.String", &model)
+	r.Equal(`json:"nickname,omitempty" openapi:"nickname" db:"nickname"`, a.Tags())
+
+	r.Error(parseTagsTemplate(`json:"{{.Unknown}}"`))
+	r.Error(parseTagsTemplate(`json:"{{.Key"`))
+}
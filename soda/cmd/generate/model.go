@@ -26,6 +26,10 @@ type model struct {
 	HasUUID   bool
 	HasSlices bool
 	HasID     bool
+
+	NoTimestamps   bool
+	SoftDelete     bool
+	OptimisticLock bool
 }
 
 func (m model) Generate() error {
@@ -86,6 +90,58 @@ func (m *model) addID() {
 	m.HasID = true
 }
 
+// addSerialID adds an auto-incremented integer ID, if no custom ID is
+// provided. It is set by the database, so it is not validated as present.
+func (m *model) addSerialID() {
+	if m.HasID {
+		return
+	}
+	id := attribute{Name: inflect.Name("id"), OriginalType: "int", GoType: "int"}
+	m.Attributes = append([]attribute{id}, m.Attributes...)
+	m.HasID = true
+}
+
+// disableTimestamps removes the created_at and updated_at columns.
+func (m *model) disableTimestamps() {
+	m.NoTimestamps = true
+	attrs := []attribute{}
+	usesTime := false
+	for _, a := range m.Attributes {
+		if a.Name == "created_at" || a.Name == "updated_at" {
+			continue
+		}
+		if a.GoType == "time.Time" {
+			usesTime = true
+		}
+		attrs = append(attrs, a)
+	}
+	m.Attributes = attrs
+	if usesTime {
+		return
+	}
+	imports := []string{}
+	for _, i := range m.Imports {
+		if i != "time" {
+			imports = append(imports, i)
+		}
+	}
+	m.Imports = imports
+}
+
+// addSoftDelete adds the nullable deleted_at column marking the soft
+// deleted rows.
+func (m *model) addSoftDelete() {
+	m.SoftDelete = true
+	m.addAttribute(newAttribute("deleted_at:nulls.Time", m))
+}
+
+// addLockVersion adds the lock_version column used for optimistic
+// locking. It starts at 0, so it is not validated as present.
+func (m *model) addLockVersion() {
+	m.OptimisticLock = true
+	m.Attributes = append(m.Attributes, newAttribute("lock_version:int", m))
+}
+
 func (m model) generateModelFile() error {
 	err := os.MkdirAll(m.Package, 0766)
 	if err != nil {
@@ -117,6 +173,8 @@ func (m model) Fizz() string {
 		case "created_at", "updated_at":
 		case "id":
 			s = append(s, fmt.Sprintf("\tt.Column(\"id\", \"%s\", {\"primary\": true})", fizzColType(a.OriginalType)))
+		case "lock_version":
+			s = append(s, fmt.Sprintf("\tt.Column(\"lock_version\", \"%s\", {\"default\": 0})", fizzColType(a.OriginalType)))
 		default:
 			x := fmt.Sprintf("\tt.Column(\"%s\", \"%s\", {})", a.Name.Underscore(), fizzColType(a.OriginalType))
 			if a.Nullable {
@@ -125,7 +183,13 @@ func (m model) Fizz() string {
 			s = append(s, x)
 		}
 	}
+	if m.NoTimestamps {
+		s = append(s, "\tt.DisableTimestamps()")
+	}
 	s = append(s, "})")
+	if m.SoftDelete {
+		s = append(s, fmt.Sprintf("add_index(\"%s\", \"deleted_at\", {})", m.Name.Table()))
+	}
 	return strings.Join(s, "\n")
 }
 
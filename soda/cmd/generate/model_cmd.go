@@ -2,6 +2,7 @@ package generate
 
 import (
 	"regexp"
+	"text/template"
 
 	"github.com/markbates/inflect"
 
@@ -11,12 +12,28 @@ import (
 
 var skipMigration bool
 var structTag string
+var timestamps bool
+var uuidID string
+var softDelete bool
+var optimisticLock bool
+var extraTags []string
+var tagCase string
+var tagsTemplateSrc string
+var tagsTemplate *template.Template
 
 var nrx = regexp.MustCompile(`^nulls\.(.+)`)
 
 func init() {
 	ModelCmd.Flags().StringVarP(&structTag, "struct-tag", "", "json", "sets the struct tags for model (xml or json)")
 	ModelCmd.Flags().BoolVarP(&skipMigration, "skip-migration", "s", false, "Skip creating a new fizz migration for this model.")
+	ModelCmd.Flags().BoolVarP(&timestamps, "timestamps", "", true, "Add the created_at and updated_at columns.")
+	ModelCmd.Flags().StringVarP(&uuidID, "uuid", "", "true", "Use a UUID primary key (true), or an auto-incremented integer one (false or serial).")
+	ModelCmd.Flag("uuid").NoOptDefVal = "true"
+	ModelCmd.Flags().BoolVarP(&softDelete, "soft-delete", "", false, "Add a nullable deleted_at column, for soft deletes.")
+	ModelCmd.Flags().BoolVarP(&optimisticLock, "optimistic-lock", "", false, "Add a lock_version column, for optimistic locking.")
+	ModelCmd.Flags().StringSliceVarP(&extraTags, "tags", "", []string{}, "Additional struct tags for the fields, e.g. form,yaml.")
+	ModelCmd.Flags().StringVarP(&tagCase, "tag-case", "", "snake", "Naming of the fields in the struct tags other than db (snake, camel or kebab).")
+	ModelCmd.Flags().StringVarP(&tagsTemplateSrc, "tags-template", "", "", "Template of the struct tags other than db, e.g. 'json:\"{{.Key}}\" example:\"{{.Name}}\"'.")
 
 	inflect.AddAcronym("ID")
 	inflect.AddAcronym("UUID")
@@ -43,11 +60,38 @@ var ModelCmd = &cobra.Command{
 			return errors.New("Invalid struct tags (use xml or json)")
 		}
 
+		if err := parseTagsTemplate(tagsTemplateSrc); err != nil {
+			return err
+		}
+		switch tagCase {
+		case "snake", "camel", "kebab":
+		default:
+			return errors.New("Invalid tag case (use snake, camel or kebab)")
+		}
+
 		for _, def := range args[1:] {
 			a := newAttribute(def, &model)
 			model.addAttribute(a)
 		}
 
+		if !timestamps {
+			model.disableTimestamps()
+		}
+		if softDelete {
+			model.addSoftDelete()
+		}
+		if optimisticLock {
+			model.addLockVersion()
+		}
+
+		switch uuidID {
+		case "true":
+		case "false", "serial":
+			model.addSerialID()
+		default:
+			return errors.New("Invalid uuid flag (use true, false or serial)")
+		}
+
 		// Add a default UUID, if no custom ID is provided
 		model.addID()
 
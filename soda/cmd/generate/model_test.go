@@ -65,3 +65,45 @@ func Test_model_addID(t *testing.T) {
 	r.Equal(string(m.Attributes[0].Name), "id")
 	r.Equal(string(m.Attributes[0].GoType), "int")
 }
+
+func Test_model_Options(t *testing.T) {
+	r := require.New(t)
+
+	m := newModel("car")
+	m.addAttribute(newAttribute("name", &m))
+	m.disableTimestamps()
+	m.addSoftDelete()
+	m.addLockVersion()
+	m.addSerialID()
+	m.addID()
+
+	names := []string{}
+	for _, a := range m.Attributes {
+		names = append(names, string(a.Name))
+	}
+	r.Equal([]string{"id", "name", "deleted_at", "lock_version"}, names)
+	r.Equal("int", m.Attributes[0].GoType)
+	r.Equal("nulls.Time", m.Attributes[2].GoType)
+	r.NotContains(m.Imports, "time")
+	r.Contains(m.Imports, "github.com/markbates/pop/nulls")
+	r.Len(m.ValidatableAttributes, 1)
+
+	r.Equal(`create_table("cars", func(t) {
+	t.Column("id", "integer", {"primary": true})
+	t.Column("name", "string", {})
+	t.Column("deleted_at", "timestamp", {"null": true})
+	t.Column("lock_version", "integer", {"default": 0})
+	t.DisableTimestamps()
+})
+add_index("cars", "deleted_at", {})`, m.Fizz())
+}
+
+func Test_model_disableTimestamps_Keeps_Time(t *testing.T) {
+	r := require.New(t)
+
+	m := newModel("car")
+	m.addAttribute(newAttribute("sold_at:time", &m))
+	m.disableTimestamps()
+	r.Contains(m.Imports, "time")
+	r.Len(m.Attributes, 1)
+}
@@ -0,0 +1,34 @@
+package generate
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/markbates/going/defaults"
+	"github.com/markbates/pop"
+	"github.com/spf13/cobra"
+)
+
+var sequenceStart int64
+
+func init() {
+	SequenceCmd.Flags().Int64Var(&sequenceStart, "start", 1, "the first value of the sequence")
+}
+
+//SequenceCmd generates a fizz migration creating a new sequence
+var SequenceCmd = &cobra.Command{
+	Use:   "sequence [name]",
+	Short: "Generates Up/Down migrations creating a sequence.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return errors.New("You must supply a name for your sequence")
+		}
+		name := args[0]
+		cflag := cmd.Flag("path")
+		migrationPath := defaults.String(cflag.Value.String(), "./migrations")
+		up := fmt.Sprintf("create_sequence(\"%s\", {\"start\": %d})", name, sequenceStart)
+		down := fmt.Sprintf("drop_sequence(\"%s\")", name)
+		return pop.MigrationCreate(migrationPath, fmt.Sprintf("create_%s", name), "fizz", []byte(up), []byte(down))
+	},
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/markbates/pop"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+var migrateLintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Flags the dangerous operations of the pending migrations.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mig, err := pop.NewFileMigrator(migrationPath, getConn())
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		issues, err := mig.Lint()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		for _, i := range issues {
+			fmt.Println(i)
+		}
+		if len(issues) > 0 {
+			return errors.Errorf("found %d dangerous operations in the pending migrations", len(issues))
+		}
+		return nil
+	},
+}
+
+func init() {
+	migrateCmd.AddCommand(migrateLintCmd)
+}
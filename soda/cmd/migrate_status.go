@@ -6,6 +6,8 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var migrateStatusVerbose bool
+
 var migrateStatusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Displays the status of all migrations.",
@@ -14,10 +16,14 @@ var migrateStatusCmd = &cobra.Command{
 		if err != nil {
 			return errors.WithStack(err)
 		}
+		if migrateStatusVerbose {
+			return mig.VerboseStatus()
+		}
 		return mig.Status()
 	},
 }
 
 func init() {
+	migrateStatusCmd.Flags().BoolVar(&migrateStatusVerbose, "verbose", false, "Also shows when, by whom and in how long the migrations were applied")
 	migrateCmd.AddCommand(migrateStatusCmd)
 }
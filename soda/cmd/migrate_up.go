@@ -6,6 +6,8 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var migrateUpConcurrency int
+
 var migrateUpCmd = &cobra.Command{
 	Use:   "up",
 	Short: "Apply all of the 'up' migrations.",
@@ -14,10 +16,12 @@ var migrateUpCmd = &cobra.Command{
 		if err != nil {
 			return errors.WithStack(err)
 		}
+		mig.Concurrency = migrateUpConcurrency
 		return mig.Up()
 	},
 }
 
 func init() {
+	migrateUpCmd.Flags().IntVar(&migrateUpConcurrency, "concurrency", 1, "The number of independent migrations to apply at once")
 	migrateCmd.AddCommand(migrateUpCmd)
 }
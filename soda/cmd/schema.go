@@ -14,5 +14,6 @@ var schemaCmd = &cobra.Command{
 func init() {
 	schemaCmd.AddCommand(schema.LoadCmd)
 	schemaCmd.AddCommand(schema.DumpCmd)
+	schemaCmd.AddCommand(schema.ApplyCmd)
 	RootCmd.AddCommand(schemaCmd)
 }
@@ -0,0 +1,67 @@
+package schema
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/markbates/pop"
+	"github.com/spf13/cobra"
+)
+
+var applyOptions = struct {
+	env       string
+	allowDrop bool
+	yes       bool
+}{}
+
+var ApplyCmd = &cobra.Command{
+	Use:   "apply [schema.fizz]",
+	Short: "Bring a database to the schema of a fizz definition",
+	Long: `Compares the tables and indexes of a fizz schema definition with the
+database, prints the changes bringing the database to the definition, and
+applies them once confirmed.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := "schema.fizz"
+		if len(args) > 0 {
+			path = args[0]
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		c, err := pop.Connect(applyOptions.env)
+		if err != nil {
+			return err
+		}
+
+		plan, err := c.PlanSchema(f, pop.SchemaPlanOptions{AllowDrop: applyOptions.allowDrop})
+		if err != nil {
+			return err
+		}
+		fmt.Println(plan)
+		if len(plan.Changes) == 0 {
+			return nil
+		}
+
+		if !applyOptions.yes {
+			fmt.Print("\nApply these changes? [y/N] ")
+			answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+			answer = strings.ToLower(strings.TrimSpace(answer))
+			if answer != "y" && answer != "yes" {
+				fmt.Println("Nothing was applied.")
+				return nil
+			}
+		}
+		return c.ApplySchema(plan)
+	},
+}
+
+func init() {
+	ApplyCmd.Flags().StringVarP(&applyOptions.env, "env", "e", "development", "The environment you want to run schema against. Will use $GO_ENV if set.")
+	ApplyCmd.Flags().BoolVar(&applyOptions.allowDrop, "allow-drop", false, "Drop the tables and columns which are not in the definition")
+	ApplyCmd.Flags().BoolVarP(&applyOptions.yes, "yes", "y", false, "Apply the changes without asking for confirmation")
+}
@@ -0,0 +1,73 @@
+package pop
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/markbates/pop/columns"
+	"github.com/markbates/pop/nulls"
+)
+
+var nullsTimeType = reflect.TypeOf(nulls.Time{})
+
+// softDeletable returns true for the models with a `DeletedAt nulls.Time`
+// field, which are soft deleted: `Destroy` sets their deleted_at column
+// rather than deleting them, and queries skip the rows it is set for.
+func (m *Model) softDeletable() bool {
+	if _, ok := m.Value.(string); ok {
+		return false
+	}
+	t := indirectType(reflect.TypeOf(m.Value))
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	f, ok := t.FieldByName("DeletedAt")
+	return ok && f.Type == nullsTimeType
+}
+
+// Unscoped returns a query including the soft deleted rows.
+func (c *Connection) Unscoped() *Query {
+	return Q(c).Unscoped()
+}
+
+// Unscoped includes the rows of soft deleted models in the results of the
+// query, which are otherwise skipped.
+//
+//	err := c.Unscoped().Find(&post, id)
+func (q *Query) Unscoped() *Query {
+	q.unscoped = true
+	return q
+}
+
+// HardDestroy deletes the model, even if it is soft deleted by `Destroy`.
+func (c *Connection) HardDestroy(model interface{}) error {
+	_, err := c.destroy(model, true)
+	return err
+}
+
+// softDestroy sets the deleted_at column of the model, unless its row is
+// already soft deleted.
+func (c *Connection) softDestroy(m *Model) error {
+	fbn, err := m.fieldByName("DeletedAt")
+	if err != nil {
+		return err
+	}
+	fbn.Set(reflect.ValueOf(nulls.NewTime(time.Now())))
+	cols := columns.NewColumns(m.TableName())
+	cols.Add("deleted_at")
+	m.checkNotDeleted = true
+	return c.Dialect.Update(c.Store, m, cols)
+}
+
+func (sq *sqlBuilder) softDeleteClause() (clause, bool) {
+	if sq.Query.unscoped || !sq.Model.softDeletable() {
+		return clause{}, false
+	}
+	alias := sq.Model.As
+	if alias == "" {
+		alias = strings.Replace(sq.Model.TableName(), ".", "_", -1)
+	}
+	return clause{Fragment: fmt.Sprintf("%s.deleted_at IS NULL", alias)}, true
+}
@@ -0,0 +1,53 @@
+package pop_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/markbates/pop"
+	"github.com/markbates/pop/nulls"
+	"github.com/stretchr/testify/require"
+)
+
+type Note struct {
+	ID        int        `db:"id"`
+	Title     string     `db:"title"`
+	DeletedAt nulls.Time `db:"deleted_at"`
+	CreatedAt time.Time  `db:"created_at"`
+	UpdatedAt time.Time  `db:"updated_at"`
+}
+
+func Test_SoftDelete(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		kept := &Note{Title: "kept"}
+		r.NoError(tx.Create(kept))
+		n := &Note{Title: "deleted"}
+		r.NoError(tx.Create(n))
+
+		r.NoError(tx.Destroy(n))
+		r.True(n.DeletedAt.Valid)
+
+		r.Error(tx.Find(&Note{}, n.ID))
+		notes := []Note{}
+		r.NoError(tx.All(&notes))
+		r.Len(notes, 1)
+		r.Equal(kept.ID, notes[0].ID)
+		count, err := tx.Where("title = ?", "deleted").Count(&Note{})
+		r.NoError(err)
+		r.Equal(0, count)
+
+		found := &Note{}
+		r.NoError(tx.Unscoped().Find(found, n.ID))
+		r.True(found.DeletedAt.Valid)
+		count, err = tx.Unscoped().Count(&Note{})
+		r.NoError(err)
+		r.Equal(2, count)
+
+		r.NoError(tx.HardDestroy(n))
+		r.Error(tx.Unscoped().Find(&Note{}, n.ID))
+		count, err = tx.Unscoped().Count(&Note{})
+		r.NoError(err)
+		r.Equal(1, count)
+	})
+}
@@ -1,12 +1,14 @@
 package pop
 
 import (
+	"bytes"
 	"fmt"
+	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 
-	"github.com/jmoiron/sqlx"
 	"github.com/markbates/pop/columns"
 )
 
@@ -16,6 +18,7 @@ type sqlBuilder struct {
 	AddColumns []string
 	sql        string
 	args       []interface{}
+	err        error
 }
 
 func newSQLBuilder(q Query, m *Model, addColumns ...string) *sqlBuilder {
@@ -28,41 +31,62 @@ func newSQLBuilder(q Query, m *Model, addColumns ...string) *sqlBuilder {
 }
 
 func (sq *sqlBuilder) String() string {
-	if sq.sql == "" {
-		sq.compile()
-	}
+	sq.compile()
 	return sq.sql
 }
 
 func (sq *sqlBuilder) Args() []interface{} {
-	if len(sq.args) == 0 {
-		if len(sq.Query.RawSQL.Arguments) > 0 {
-			sq.args = sq.Query.RawSQL.Arguments
-		} else {
-			sq.compile()
-		}
-	}
+	sq.compile()
 	return sq.args
 }
 
+// Err returns the first error found while building the query, such as
+// a clause with the wrong number of arguments.
+func (sq *sqlBuilder) Err() error {
+	sq.compile()
+	return sq.err
+}
+
 var inRegex = regexp.MustCompile(`(?i)in\s*\(\s*\?\s*\)`)
 
 func (sq *sqlBuilder) compile() {
 	if sq.sql == "" {
-		if sq.Query.RawSQL.Fragment != "" {
-			sq.sql = sq.Query.RawSQL.Fragment
-		} else {
-			sq.sql = sq.buildSelectSQL()
+		sq.sql = sq.Query.Connection.Dialect.TranslateSQL(sq.untranslated())
+	}
+}
+
+// untranslated builds the query with `?` placeholders, before they are
+// translated to the ones of the dialect.
+func (sq *sqlBuilder) untranslated() string {
+	if sq.Query.RawSQL.Fragment != "" {
+		sql, args := sq.bind("raw query", sq.Query.RawSQL.Fragment, sq.Query.RawSQL.Arguments)
+		if len(args) > 0 {
+			sq.args = args
 		}
+		return sql
+	}
+	return sq.buildSelectSQL()
+}
 
-		if inRegex.MatchString(sq.sql) {
-			s, _, err := sqlx.In(sq.sql, sq.Args())
-			if err == nil {
-				sq.sql = s
-			}
+// bind binds the arguments of a clause, keeping the first error.
+func (sq *sqlBuilder) bind(kind string, fragment string, args []interface{}) (string, []interface{}) {
+	fragment, args, err := expandSubqueries(fragment, args)
+	if err != nil {
+		if sq.err == nil {
+			sq.err = err
 		}
-		sq.sql = sq.Query.Connection.Dialect.TranslateSQL(sq.sql)
+		return fragment, args
+	}
+	// statements using the native placeholders of the dialect are
+	// passed through as is.
+	if len(placeholders(fragment)) == 0 && strings.Contains(fragment, "$") {
+		return fragment, args
+	}
+	f, a, err := bindArgs(kind, fragment, args)
+	if err != nil && sq.err == nil {
+		sq.err = err
 	}
+	return f, a
 }
 
 func (sq *sqlBuilder) buildSelectSQL() string {
@@ -70,13 +94,33 @@ func (sq *sqlBuilder) buildSelectSQL() string {
 
 	fc := sq.buildfromClauses()
 
-	sql := fmt.Sprintf("SELECT %s FROM %s", cols.Readable().SelectString(), fc)
+	var list string
+	if len(sq.AddColumns) == 0 && len(sq.Query.selectSubqueries) == 0 && len(sq.Query.selectExprs) == 0 {
+		list = sq.selectList(cols)
+	} else {
+		rc := cols.Readable()
+		subs := append(sq.buildSelectSubqueries(rc), sq.buildSelectExprs(rc)...)
+		list = joinSelect(rc.QuotedSelectString(sq.Query.Connection.Dialect.Quote), subs)
+	}
+	b := buffers.Get().(*bytes.Buffer)
+	b.Reset()
+	b.WriteString("SELECT ")
+	b.WriteString(sq.buildDistinct())
+	b.WriteString(list)
+	b.WriteString(" FROM ")
+	b.WriteString(fc.String())
+	sql := b.String()
+	buffers.Put(b)
 
+	sql = sq.buildPartitionJoins(sql)
 	sql = sq.buildJoinClauses(sql)
+	sql = sq.buildAsOfSystemTimeClause(sql)
 	sql = sq.buildWhereClauses(sql)
 	sql = sq.buildGroupClauses(sql)
 	sql = sq.buildOrderClauses(sql)
 	sql = sq.buildPaginationClauses(sql)
+	sql = sq.buildLockClause(sql)
+	sql = sq.buildHints(sql)
 
 	return sql
 }
@@ -90,16 +134,20 @@ func (sq *sqlBuilder) buildfromClauses() fromClauses {
 	}
 
 	fc := sq.Query.fromClauses
-	for _, m := range models {
+	for i, m := range models {
 		tableName := m.TableName()
 		asName := m.As
 		if asName == "" {
 			asName = strings.Replace(tableName, ".", "_", -1)
 		}
-		fc = append(fc, fromClause{
-			From: tableName,
-			As:   asName,
-		})
+		f := fromClause{
+			From: sq.Query.Connection.Dialect.Quote(tableName),
+			As:   sq.Query.Connection.Dialect.Quote(asName),
+		}
+		if i == 0 {
+			f.IndexHints = sq.buildIndexHints()
+		}
+		fc = append(fc, f)
 	}
 
 	return fc
@@ -112,25 +160,49 @@ func (sq *sqlBuilder) buildWhereClauses(sql string) string {
 		sq.Query.Where(fmt.Sprintf("%s.id = %s.%s", sq.Model.TableName(), mc.Through.TableName(), sq.Model.associationName()))
 	}
 
-	wc := sq.Query.whereClauses
+	wc := sq.whereClauses()
 	if len(wc) > 0 {
-		sql = fmt.Sprintf("%s WHERE %s", sql, wc.Join(" AND "))
-		for _, arg := range wc.Args() {
-			sq.args = append(sq.args, arg)
+		fragments := make([]string, len(wc))
+		for i, c := range wc {
+			var args []interface{}
+			fragments[i], args = sq.bind("where", c.Fragment, c.Arguments)
+			sq.args = append(sq.args, args...)
 		}
+		sql += " WHERE " + strings.Join(fragments, " AND ")
 	}
 	return sql
 }
 
+// whereClauses returns the clauses of the query, along with the ones
+// implied by its model and its options.
+func (sq *sqlBuilder) whereClauses() clauses {
+	wc := sq.Query.whereClauses
+	if dc, ok := sq.discriminatorClause(); ok {
+		wc = append(clauses{dc}, wc...)
+	}
+	if sc, ok := sq.softDeleteClause(); ok {
+		wc = append(clauses{sc}, wc...)
+	}
+	if pc, ok := sq.partitionClause(); ok {
+		wc = append(clauses{pc}, wc...)
+	}
+	if cc, ok := sq.cursorClause(); ok {
+		wc = append(wc, cc)
+	}
+	return wc
+}
+
 func (sq *sqlBuilder) buildJoinClauses(sql string) string {
 	oc := sq.Query.joinClauses
 	if len(oc) > 0 {
-		sql += " " + oc.String()
-		for i := range oc {
-			for _, arg := range oc[i].Arguments {
-				sq.args = append(sq.args, arg)
-			}
+		bound := make(joinClauses, len(oc))
+		for i, c := range oc {
+			var args []interface{}
+			c.On, args = sq.bind("join", c.On, c.Arguments)
+			sq.args = append(sq.args, args...)
+			bound[i] = c
 		}
+		sql += " " + bound.String()
 	}
 
 	return sql
@@ -140,26 +212,31 @@ func (sq *sqlBuilder) buildGroupClauses(sql string) string {
 	gc := sq.Query.groupClauses
 	if len(gc) > 0 {
 		sql = fmt.Sprintf("%s GROUP BY %s", sql, gc.String())
+	}
 
-		hc := sq.Query.havingClauses
-		if len(hc) > 0 {
-			sql = fmt.Sprintf("%s HAVING %s", sql, hc.String())
-		}
-
-		for i := range hc {
-			for _, arg := range hc[i].Arguments {
-				sq.args = append(sq.args, arg)
-			}
+	// without GROUP BY, HAVING applies to the whole results as one group.
+	hc := sq.Query.havingClauses
+	if len(hc) > 0 {
+		bound := make(havingClauses, len(hc))
+		for i, c := range hc {
+			var args []interface{}
+			c.Condition, args = sq.bind("having", c.Condition, c.Arguments)
+			sq.args = append(sq.args, args...)
+			bound[i] = c
 		}
+		sql = fmt.Sprintf("%s HAVING %s", sql, bound.String())
 	}
 
 	return sql
 }
 
 func (sq *sqlBuilder) buildOrderClauses(sql string) string {
+	if sq.Query.CursorPaginator != nil {
+		return fmt.Sprintf("%s ORDER BY %s ASC", sql, sq.cursorColumn())
+	}
 	oc := sq.Query.orderClauses
 	if len(oc) > 0 {
-		sql = fmt.Sprintf("%s ORDER BY %s", sql, oc.Join(", "))
+		sql += " ORDER BY " + oc.Join(", ")
 		for _, arg := range oc.Args() {
 			sq.args = append(sq.args, arg)
 		}
@@ -168,33 +245,60 @@ func (sq *sqlBuilder) buildOrderClauses(sql string) string {
 }
 
 func (sq *sqlBuilder) buildPaginationClauses(sql string) string {
+	if sq.Query.CursorPaginator != nil {
+		// the extra row tells whether there is a next page.
+		return fmt.Sprintf("%s LIMIT %d", sql, sq.Query.CursorPaginator.Size+1)
+	}
 	if sq.Query.limitResults > 0 && sq.Query.Paginator == nil {
-		sql = fmt.Sprintf("%s LIMIT %d", sql, sq.Query.limitResults)
+		sql += " LIMIT " + strconv.Itoa(sq.Query.limitResults)
 	}
 	if sq.Query.Paginator != nil {
-		sql = fmt.Sprintf("%s LIMIT %d", sql, sq.Query.Paginator.PerPage)
-		sql = fmt.Sprintf("%s OFFSET %d", sql, sq.Query.Paginator.Offset)
+		sql += " LIMIT " + strconv.Itoa(sq.Query.Paginator.PerPage) + " OFFSET " + strconv.Itoa(sq.Query.Paginator.Offset)
 	}
 	return sql
 }
 
-var columnCache = map[string]columns.Columns{}
+// columnsKey identifies the columns of a model type for a table, under
+// an alias. Types sharing a table, like the subtypes of a single table
+// inheritance, have their own columns.
+type columnsKey struct {
+	table string
+	t     reflect.Type
+	alias string
+}
+
+// selectKey identifies the select list of the columns of a model, which
+// depends on the quoting of the dialect.
+type selectKey struct {
+	columnsKey
+	dialect  reflect.Type
+	quoteAll bool
+}
+
+var columnCache = map[columnsKey]columns.Columns{}
+var selectCache = map[selectKey]string{}
 var columnCacheMutex = sync.Mutex{}
 
+// buffers are the buffers the statements are built in.
+var buffers = sync.Pool{New: func() interface{} { return &bytes.Buffer{} }}
+
+func (sq *sqlBuilder) columnsKey() columnsKey {
+	return columnsKey{sq.Model.TableName(), indirectType(reflect.TypeOf(sq.Model.Value)), sq.Model.As}
+}
+
 func (sq *sqlBuilder) buildColumns() columns.Columns {
-	tableName := sq.Model.TableName()
 	acl := len(sq.AddColumns)
 	if acl <= 0 {
+		key := sq.columnsKey()
 		columnCacheMutex.Lock()
-		cols, ok := columnCache[tableName]
+		cols, ok := columnCache[key]
 		columnCacheMutex.Unlock()
-		//if alias is different, remake columns
-		if ok && cols.TableAlias == sq.Model.As {
+		if ok {
 			return cols
 		}
-		cols = columns.ColumnsForStructWithAlias(sq.Model.Value, tableName, sq.Model.As)
+		cols = columns.ColumnsForStructWithAlias(sq.Model.Value, key.table, key.alias)
 		columnCacheMutex.Lock()
-		columnCache[tableName] = cols
+		columnCache[key] = cols
 		columnCacheMutex.Unlock()
 		return cols
 	}
@@ -204,3 +308,21 @@ func (sq *sqlBuilder) buildColumns() columns.Columns {
 	cols.Add(sq.AddColumns...)
 	return cols
 }
+
+// selectList returns the quoted select list of the columns of the model,
+// computed once per model and dialect.
+func (sq *sqlBuilder) selectList(cols columns.Columns) string {
+	d := sq.Query.Connection.Dialect
+	key := selectKey{sq.columnsKey(), reflect.TypeOf(d), d.Details().QuoteIdentifiers()}
+	columnCacheMutex.Lock()
+	list, ok := selectCache[key]
+	columnCacheMutex.Unlock()
+	if ok {
+		return list
+	}
+	list = cols.Readable().QuotedSelectString(d.Quote)
+	columnCacheMutex.Lock()
+	selectCache[key] = list
+	columnCacheMutex.Unlock()
+	return list
+}
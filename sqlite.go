@@ -5,6 +5,7 @@ package pop
 import (
 	"fmt"
 	"io"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -17,7 +18,7 @@ import (
 	"github.com/markbates/pop/fizz"
 	"github.com/markbates/pop/fizz/translators"
 	// Load SQLite3 CGo driver
-	_ "github.com/mattn/go-sqlite3"
+	sqlite3 "github.com/mattn/go-sqlite3"
 	"github.com/pkg/errors"
 )
 
@@ -27,14 +28,84 @@ type sqlite struct {
 	gil               *sync.Mutex
 	smGil             *sync.Mutex
 	ConnectionDetails *ConnectionDetails
+	version           string
+	versionOnce       sync.Once
 }
 
 func (m *sqlite) Details() *ConnectionDetails {
 	return m.ConnectionDetails
 }
 
+// Quote quotes the identifier key, e.g. "users.name", if it is a reserved
+// word or if the "quote_identifiers" option is set.
+func (m *sqlite) Quote(key string) string {
+	return quoteIdentifier(m.ConnectionDetails, `"`, `"`, key)
+}
+
+// URL applies the "busy_timeout" (5000ms by default) and
+// "foreign_keys" options to every connection of the pool. With the
+// "shared_memory" option the database lives in memory, and is shared by
+// all of the connections of the pool, which is handy for tests. The
+// parameters already in the database take precedence over the options.
 func (m *sqlite) URL() string {
-	return m.ConnectionDetails.Database + "?_busy_timeout=5000"
+	c := m.ConnectionDetails
+	db, query := c.Database, ""
+	if i := strings.Index(db, "?"); i >= 0 {
+		db, query = db[:i], db[i+1:]
+	}
+	params, _ := url.ParseQuery(query)
+	set := func(key, value string) {
+		if _, ok := params[key]; !ok {
+			params.Set(key, value)
+		}
+	}
+	set("_busy_timeout", defaults.String(c.Options["busy_timeout"], "5000"))
+	if fk := m.foreignKeysParam(); fk != "" {
+		set("_foreign_keys", fk)
+	}
+	if m.sharedMemory() {
+		set("mode", "memory")
+		set("cache", "shared")
+		if !strings.HasPrefix(db, "file:") {
+			db = "file:" + db
+		}
+	}
+	return db + "?" + params.Encode()
+}
+
+// foreignKeysParam returns the "_foreign_keys" parameter of the
+// "foreign_keys" option, or an empty string to leave the default.
+func (m *sqlite) foreignKeysParam() string {
+	switch strings.ToLower(m.Details().Options["foreign_keys"]) {
+	case "on", "true", "1":
+		return "1"
+	case "off", "false", "0":
+		return "0"
+	}
+	return ""
+}
+
+func (m *sqlite) sharedMemory() bool {
+	return m.Details().Options["shared_memory"] == "true"
+}
+
+// afterOpen sets the "journal_mode" option, e.g. "WAL". The journal
+// mode is persisted in the database file, so it only needs to be set
+// once.
+func (m *sqlite) afterOpen(s store) error {
+	jm := m.Details().Options["journal_mode"]
+	if jm == "" || m.sharedMemory() {
+		return nil
+	}
+	var mode string
+	query := fmt.Sprintf("PRAGMA journal_mode=%s", jm)
+	if err := s.Get(&mode, query); err != nil {
+		return errors.Wrapf(err, "could not set SQLite journal mode to %s", jm)
+	}
+	if !strings.EqualFold(mode, jm) {
+		return errors.Errorf("could not set SQLite journal mode to %s, it is %s", jm, mode)
+	}
+	return nil
 }
 
 func (m *sqlite) MigrationURL() string {
@@ -43,19 +114,73 @@ func (m *sqlite) MigrationURL() string {
 
 func (m *sqlite) Create(s store, model *Model, cols columns.Columns) error {
 	return m.locker(m.smGil, func() error {
-		return errors.Wrap(genericCreate(s, model, cols), "sqlite create")
+		return errors.Wrap(genericCreate(s, model, cols, m), "sqlite create")
 	})
 }
 
+// upsert uses INSERT ... ON CONFLICT on SQLite 3.24 and later. Older
+// versions update the conflicting row, and insert the model if there is
+// none.
+func (m *sqlite) upsert(s store, model *Model, cols, update, conflict []string) error {
+	if sqliteOnConflict(m.serverVersion(s)) {
+		return m.locker(m.smGil, func() error {
+			return errors.Wrap(onConflictUpsert(s, model, cols, update, conflict, m), "sqlite upsert")
+		})
+	}
+	if len(update) == 0 {
+		update = conflict[:1]
+	}
+	sets := make([]string, len(update))
+	for i, name := range update {
+		sets[i] = fmt.Sprintf("%s = :%s", m.Quote(name), name)
+	}
+	where := make([]string, len(conflict))
+	for i, name := range conflict {
+		where[i] = fmt.Sprintf("%s = :%s", m.Quote(name), name)
+	}
+	return m.locker(m.smGil, func() error {
+		query := fmt.Sprintf("UPDATE %s SET %s WHERE %s", m.Quote(model.TableName()), strings.Join(sets, ", "), strings.Join(where, " AND "))
+		res, err := s.NamedExec(query, model.Value)
+		if err != nil {
+			return errors.Wrap(err, "sqlite upsert")
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			return nil
+		}
+		query = upsertInsert(model, cols, m)
+		_, err = s.NamedExec(query, model.Value)
+		return errors.Wrap(err, "sqlite upsert")
+	})
+}
+
+// serverVersion returns the sqlite_version() of the library, it is only
+// asked once per dialect.
+func (m *sqlite) serverVersion(s store) string {
+	m.versionOnce.Do(func() {
+		if err := s.Get(&m.version, "SELECT sqlite_version()"); err != nil {
+			logMessage(nil, LogWarn, fmt.Sprintf("could not get the SQLite version: %s", err))
+		}
+	})
+	return m.version
+}
+
+// sqliteOnConflict returns true for SQLite versions supporting
+// INSERT ... ON CONFLICT DO UPDATE (3.24 and later).
+func sqliteOnConflict(version string) bool {
+	var major, minor int
+	fmt.Sscanf(version, "%d.%d", &major, &minor)
+	return major > 3 || (major == 3 && minor >= 24)
+}
+
 func (m *sqlite) Update(s store, model *Model, cols columns.Columns) error {
 	return m.locker(m.smGil, func() error {
-		return errors.Wrap(genericUpdate(s, model, cols), "sqlite update")
+		return errors.Wrap(genericUpdate(s, model, cols, m), "sqlite update")
 	})
 }
 
 func (m *sqlite) Destroy(s store, model *Model) error {
 	return m.locker(m.smGil, func() error {
-		return errors.Wrap(genericDestroy(s, model), "sqlite destroy")
+		return errors.Wrap(genericDestroy(s, model, m), "sqlite destroy")
 	})
 }
 
@@ -71,6 +196,180 @@ func (m *sqlite) SelectMany(s store, models *Model, query Query) error {
 	})
 }
 
+// nextSequenceValue inserts a row in the table standing for the sequence.
+func (m *sqlite) nextSequenceValue(s store, name string) (int64, error) {
+	var n int64
+	err := m.locker(m.smGil, func() error {
+		query := fmt.Sprintf("INSERT INTO \"%s\" DEFAULT VALUES", name)
+		res, err := s.Exec(query)
+		if err != nil {
+			return err
+		}
+		n, err = res.LastInsertId()
+		return err
+	})
+	return n, err
+}
+
+// reserveSequenceValues inserts n rows at once in the table standing for
+// the sequence, and gets their ids back from the last one.
+func (m *sqlite) reserveSequenceValues(s store, name string, n int) ([]int64, error) {
+	ns := make([]int64, 0, n)
+	err := m.locker(m.smGil, func() error {
+		query := fmt.Sprintf("INSERT INTO \"%s\" (\"id\") VALUES (NULL)%s", name, strings.Repeat(", (NULL)", n-1))
+		res, err := s.Exec(query)
+		if err != nil {
+			return err
+		}
+		last, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
+		for i := int64(n - 1); i >= 0; i-- {
+			ns = append(ns, last-i)
+		}
+		return nil
+	})
+	return ns, err
+}
+
+// maintain checks the integrity of the database, then analyzes the
+// tables. The whole database is vacuumed when no tables are given.
+func (m *sqlite) maintain(c *Connection, tables []string) ([]MaintenanceResult, error) {
+	results := []MaintenanceResult{}
+	check := []string{}
+	if err := c.RawQuery("PRAGMA integrity_check").All(&check); err != nil {
+		return results, err
+	}
+	msg := strings.Join(check, "; ")
+	results = append(results, MaintenanceResult{Statement: "PRAGMA integrity_check", Message: msg})
+	if msg != "ok" {
+		return results, errors.Errorf("integrity check failed: %s", msg)
+	}
+
+	statements := []string{"ANALYZE", "VACUUM"}
+	if len(tables) > 0 {
+		statements = []string{}
+		for _, t := range tables {
+			statements = append(statements, fmt.Sprintf("ANALYZE \"%s\"", t))
+		}
+	}
+	for i, query := range statements {
+		if err := c.RawQuery(query).Exec(); err != nil {
+			return results, err
+		}
+		r := MaintenanceResult{Statement: query}
+		if len(tables) > 0 {
+			r.Table = tables[i]
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+func (m *sqlite) tableNames(c *Connection) ([]string, error) {
+	return sqliteTables(c)
+}
+
+// sqliteTables lists the tables of the database, leaving out the ones
+// of SQLite.
+func sqliteTables(c *Connection) ([]string, error) {
+	tables := []string{}
+	err := c.RawQuery("SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name").All(&tables)
+	return tables, err
+}
+
+// sqlitePragma runs a table valued pragma, and returns its rows by column.
+func sqlitePragma(c *Connection, query string) ([]map[string]interface{}, error) {
+	rows, err := c.Store.Queryx(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	res := []map[string]interface{}{}
+	for rows.Next() {
+		row := map[string]interface{}{}
+		if err := rows.MapScan(row); err != nil {
+			return res, err
+		}
+		res = append(res, row)
+	}
+	return res, rows.Err()
+}
+
+// sqliteInterrupted returns true if err is the error of a statement
+// interrupted when its context was done.
+func sqliteInterrupted(err error) bool {
+	e, ok := err.(sqlite3.Error)
+	return ok && e.Code == sqlite3.ErrInterrupt
+}
+
+func sqliteString(v interface{}) string {
+	if b, ok := v.([]byte); ok {
+		return string(b)
+	}
+	return fmt.Sprint(v)
+}
+
+func (m *sqlite) indexes(c *Connection) ([]IndexInfo, error) {
+	tables, err := sqliteTables(c)
+	if err != nil {
+		return nil, err
+	}
+	indexes := []IndexInfo{}
+	for _, t := range tables {
+		list, err := sqlitePragma(c, fmt.Sprintf("PRAGMA index_list(\"%s\")", t))
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range list {
+			i := IndexInfo{Table: t, Name: sqliteString(l["name"])}
+			cols, err := sqlitePragma(c, fmt.Sprintf("PRAGMA index_info(\"%s\")", i.Name))
+			if err != nil {
+				return nil, err
+			}
+			for _, col := range cols {
+				i.Columns = append(i.Columns, sqliteString(col["name"]))
+			}
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes, nil
+}
+
+// foreignKeys names the foreign keys after the fizz convention, since
+// SQLite does not keep their names.
+func (m *sqlite) foreignKeys(c *Connection) ([]IndexInfo, error) {
+	tables, err := sqliteTables(c)
+	if err != nil {
+		return nil, err
+	}
+	fks := []IndexInfo{}
+	for _, t := range tables {
+		list, err := sqlitePragma(c, fmt.Sprintf("PRAGMA foreign_key_list(\"%s\")", t))
+		if err != nil {
+			return nil, err
+		}
+		byID := map[string]*IndexInfo{}
+		ids := []string{}
+		for _, l := range list {
+			id := sqliteString(l["id"])
+			fk, ok := byID[id]
+			if !ok {
+				fk = &IndexInfo{Table: t}
+				byID[id] = fk
+				ids = append(ids, id)
+			}
+			fk.Columns = append(fk.Columns, sqliteString(l["from"]))
+			fk.Name = fmt.Sprintf("%s_%s_%s_fk", t, sqliteString(l["table"]), sqliteString(l["to"]))
+		}
+		for _, id := range ids {
+			fks = append(fks, *byID[id])
+		}
+	}
+	return fks, nil
+}
+
 func (m *sqlite) Lock(fn func() error) error {
 	return m.locker(m.gil, fn)
 }
@@ -91,6 +390,9 @@ func (m *sqlite) locker(l *sync.Mutex, fn func() error) error {
 }
 
 func (m *sqlite) CreateDB() error {
+	if m.sharedMemory() {
+		return nil
+	}
 	d := filepath.Dir(m.ConnectionDetails.Database)
 	err := os.MkdirAll(d, 0766)
 	if err != nil {
@@ -106,6 +408,9 @@ func (m *sqlite) CreateDB() error {
 }
 
 func (m *sqlite) DropDB() error {
+	if m.sharedMemory() {
+		return nil
+	}
 	err := os.Remove(m.ConnectionDetails.Database)
 	if err != nil {
 		return errors.Wrapf(err, "could not drop SQLite database %s", m.ConnectionDetails.Database)
@@ -119,12 +424,14 @@ func (m *sqlite) TranslateSQL(sql string) string {
 }
 
 func (m *sqlite) FizzTranslator() fizz.Translator {
-	return translators.NewSQLite(m.Details().Database)
+	t := translators.NewSQLite(m.Details().Database)
+	t.ForeignKeys = m.foreignKeysParam() == "1"
+	return t
 }
 
 func (m *sqlite) DumpSchema(w io.Writer) error {
 	cmd := exec.Command("sqlite3", m.Details().Database, ".schema")
-	Log(strings.Join(cmd.Args, " "))
+	logMessage(nil, LogInfo, strings.Join(cmd.Args, " "))
 	cmd.Stdout = w
 	cmd.Stderr = os.Stderr
 
@@ -147,7 +454,7 @@ func (m *sqlite) LoadSchema(r io.Reader) error {
 		defer in.Close()
 		io.Copy(in, r)
 	}()
-	Log(strings.Join(cmd.Args, " "))
+	logMessage(nil, LogInfo, strings.Join(cmd.Args, " "))
 	err = cmd.Start()
 	if err != nil {
 		return err
@@ -162,6 +469,35 @@ func (m *sqlite) LoadSchema(r io.Reader) error {
 	return nil
 }
 
+// insertAll computes the ids of the rows from the rowid of the last one:
+// the rowids given by a single INSERT are consecutive.
+func (m *sqlite) insertAll(s store, query string, args []interface{}, n int) ([]int64, error) {
+	ids := make([]int64, n)
+	err := m.locker(m.smGil, func() error {
+		res, err := s.Exec(query, args...)
+		if err != nil {
+			return err
+		}
+		last, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
+		for i := range ids {
+			ids[i] = last - int64(n-1-i)
+		}
+		return nil
+	})
+	return ids, err
+}
+
+func (m *sqlite) Capabilities() Capabilities {
+	return Capabilities{
+		Upsert:     true,
+		CTE:        true,
+		Savepoints: true,
+	}
+}
+
 func (m *sqlite) TruncateAll(tx *Connection) error {
 	const tableNames = `SELECT name FROM sqlite_master WHERE type = "table"`
 	names := []struct {
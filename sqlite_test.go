@@ -0,0 +1,377 @@
+// +build !nosqlite,!appengine,!appenginevm
+
+package pop
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	sqlite3 "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SQLite_URL_Options(t *testing.T) {
+	r := require.New(t)
+
+	d, err := newSQLite(&ConnectionDetails{Database: "./test.sqlite"})
+	r.NoError(err)
+	r.Equal("./test.sqlite?_busy_timeout=5000", d.URL())
+
+	d, err = newSQLite(&ConnectionDetails{
+		Database: "./test.sqlite",
+		Options:  map[string]string{"busy_timeout": "100", "foreign_keys": "on"},
+	})
+	r.NoError(err)
+	r.Equal("./test.sqlite?_busy_timeout=100&_foreign_keys=1", d.URL())
+
+	d, err = newSQLite(&ConnectionDetails{
+		Database: "pop_test",
+		Options:  map[string]string{"shared_memory": "true"},
+	})
+	r.NoError(err)
+	r.Equal("file:pop_test?_busy_timeout=5000&cache=shared&mode=memory", d.URL())
+
+	// the parameters of the database are merged with the options.
+	d, err = newSQLite(&ConnectionDetails{
+		Database: "file:pop_test?_busy_timeout=100&_loc=auto",
+		Options:  map[string]string{"shared_memory": "true", "foreign_keys": "on"},
+	})
+	r.NoError(err)
+	r.Equal("file:pop_test?_busy_timeout=100&_foreign_keys=1&_loc=auto&cache=shared&mode=memory", d.URL())
+}
+
+func Test_SQLite_SharedMemory(t *testing.T) {
+	r := require.New(t)
+
+	c, err := NewConnection(&ConnectionDetails{
+		Dialect:  "sqlite3",
+		Database: "pop_shared_memory_test",
+		Options:  map[string]string{"shared_memory": "true", "foreign_keys": "on"},
+	})
+	r.NoError(err)
+	r.NoError(c.Open())
+	defer c.Close()
+
+	r.NoError(c.RawQuery("CREATE TABLE parents (id integer primary key)").Exec())
+	r.NoError(c.RawQuery("CREATE TABLE children (id integer primary key, parent_id integer references parents(id))").Exec())
+
+	// the table is visible from a transaction, using another connection
+	err = c.Transaction(func(tx *Connection) error {
+		return tx.RawQuery("INSERT INTO parents (id) VALUES (1)").Exec()
+	})
+	r.NoError(err)
+
+	r.Error(c.RawQuery("INSERT INTO children (id, parent_id) VALUES (1, 42)").Exec())
+}
+
+func Test_SQLite_JournalMode(t *testing.T) {
+	r := require.New(t)
+
+	dir, err := ioutil.TempDir("", "pop")
+	r.NoError(err)
+	defer os.RemoveAll(dir)
+
+	c, err := NewConnection(&ConnectionDetails{
+		Dialect:  "sqlite3",
+		Database: filepath.Join(dir, "wal.sqlite"),
+		Options:  map[string]string{"journal_mode": "WAL"},
+	})
+	r.NoError(err)
+	r.NoError(c.Open())
+	defer c.Close()
+
+	var mode string
+	r.NoError(c.Store.Get(&mode, "PRAGMA journal_mode"))
+	r.Equal("wal", mode)
+}
+
+func Test_SQLite_Maintain(t *testing.T) {
+	r := require.New(t)
+
+	dir, err := ioutil.TempDir("", "pop")
+	r.NoError(err)
+	defer os.RemoveAll(dir)
+
+	c, err := NewConnection(&ConnectionDetails{
+		Dialect:  "sqlite3",
+		Database: filepath.Join(dir, "maintain.sqlite"),
+	})
+	r.NoError(err)
+	r.NoError(c.Open())
+	defer c.Close()
+	r.NoError(c.RawQuery("CREATE TABLE widgets (id INTEGER PRIMARY KEY, name TEXT)").Exec())
+
+	results, err := c.Maintain()
+	r.NoError(err)
+	r.Len(results, 3)
+	r.Equal("PRAGMA integrity_check", results[0].Statement)
+	r.Equal("ok", results[0].Message)
+	r.Equal("ANALYZE", results[1].Statement)
+	r.Equal("VACUUM", results[2].Statement)
+
+	results, err = c.Maintain("widgets")
+	r.NoError(err)
+	r.Len(results, 2)
+	r.Equal(`ANALYZE "widgets"`, results[1].Statement)
+	r.Equal("widgets", results[1].Table)
+
+	r.NoError(c.Rollback(func(tx *Connection) {
+		_, err := tx.Maintain()
+		r.Error(err)
+	}))
+}
+
+func Test_SQLite_IndexReport(t *testing.T) {
+	r := require.New(t)
+
+	dir, err := ioutil.TempDir("", "pop")
+	r.NoError(err)
+	defer os.RemoveAll(dir)
+
+	c, err := NewConnection(&ConnectionDetails{
+		Dialect:  "sqlite3",
+		Database: filepath.Join(dir, "indexes.sqlite"),
+	})
+	r.NoError(err)
+	r.NoError(c.Open())
+	defer c.Close()
+	r.NoError(c.RawQuery(`CREATE TABLE parents (id INTEGER PRIMARY KEY);
+CREATE TABLE children (id INTEGER PRIMARY KEY, parent_id INTEGER REFERENCES parents (id), name TEXT);
+CREATE INDEX children_name_idx ON children (name);
+CREATE INDEX children_name_copy_idx ON children (name);`).Exec())
+
+	report, err := c.IndexReport()
+	r.NoError(err)
+	r.Len(report.Unused, 0)
+	r.Len(report.Duplicates, 1)
+	r.Equal("children.children_name_idx (name), same as children_name_copy_idx", report.Duplicates[0].String())
+	r.Len(report.MissingForeignKeyIndexes, 1)
+	r.Equal("children.children_parents_id_fk (parent_id)", report.MissingForeignKeyIndexes[0].String())
+
+	r.NoError(c.RawQuery("CREATE INDEX children_parent_id_name_idx ON children (parent_id, name)").Exec())
+	report, err = c.IndexReport()
+	r.NoError(err)
+	r.Len(report.MissingForeignKeyIndexes, 0)
+}
+
+func Test_SQLite_ActiveQueries(t *testing.T) {
+	r := require.New(t)
+
+	c, err := NewConnection(&ConnectionDetails{Dialect: "sqlite3", Database: ":memory:"})
+	r.NoError(err)
+	r.NoError(c.Open())
+	defer c.Close()
+
+	_, err = c.ActiveQueries(context.Background())
+	r.EqualError(err, "sqlite3 does not support query monitoring")
+	_, err = c.Blockers(context.Background())
+	r.Error(err)
+	r.Error(c.CancelQuery(1))
+	r.NotNil(c.primaryDB())
+}
+
+func Test_SQLite_BackupRestore(t *testing.T) {
+	r := require.New(t)
+
+	dir, err := ioutil.TempDir("", "pop")
+	r.NoError(err)
+	defer os.RemoveAll(dir)
+
+	c, err := NewConnection(&ConnectionDetails{
+		Dialect:  "sqlite3",
+		Database: filepath.Join(dir, "backup.sqlite"),
+	})
+	r.NoError(err)
+	r.NoError(c.Open())
+	defer c.Close()
+	r.NoError(c.RawQuery("CREATE TABLE widgets (id INTEGER PRIMARY KEY, name TEXT, data BLOB, price REAL)").Exec())
+	r.NoError(c.RawQuery("CREATE TABLE gadgets (id INTEGER PRIMARY KEY)").Exec())
+	r.NoError(c.RawQuery("INSERT INTO widgets (name, data, price) VALUES (?, ?, ?)", "a", []byte{0, 1, 2}, 1.5).Exec())
+	r.NoError(c.RawQuery("INSERT INTO widgets (name, data, price) VALUES (?, ?, ?)", "b", nil, 2).Exec())
+	r.NoError(c.RawQuery("INSERT INTO gadgets (id) VALUES (7)").Exec())
+
+	bb := &bytes.Buffer{}
+	r.NoError(c.Backup(bb, BackupOptions{Tables: []string{"widgets"}}))
+	r.Contains(bb.String(), `"table":"widgets"`)
+	r.NotContains(bb.String(), "gadgets")
+
+	r.NoError(c.RawQuery("DELETE FROM widgets").Exec())
+	r.NoError(c.RawQuery("INSERT INTO widgets (name) VALUES ('c')").Exec())
+	r.NoError(c.Restore(bb))
+
+	names := []string{}
+	r.NoError(c.RawQuery("SELECT name FROM widgets ORDER BY id").All(&names))
+	r.Equal([]string{"a", "b"}, names)
+	var data []byte
+	r.NoError(c.Store.Get(&data, "SELECT data FROM widgets WHERE name = 'a'"))
+	r.Equal([]byte{0, 1, 2}, data)
+
+	bb.Reset()
+	r.NoError(c.Backup(bb, BackupOptions{}))
+	r.Contains(bb.String(), `"table":"gadgets"`)
+	r.Contains(bb.String(), `"table":"widgets"`)
+}
+
+func Test_SQLite_Restore_ForeignKeys(t *testing.T) {
+	r := require.New(t)
+
+	dir, err := ioutil.TempDir("", "pop")
+	r.NoError(err)
+	defer os.RemoveAll(dir)
+
+	c, err := NewConnection(&ConnectionDetails{
+		Dialect:  "sqlite3",
+		Database: filepath.Join(dir, "restore.sqlite"),
+		Options:  map[string]string{"foreign_keys": "true"},
+	})
+	r.NoError(err)
+	r.NoError(c.Open())
+	defer c.Close()
+	// the pets are restored before their owners.
+	r.NoError(c.RawQuery("CREATE TABLE owners (id INTEGER PRIMARY KEY)").Exec())
+	r.NoError(c.RawQuery("CREATE TABLE alpha_pets (id INTEGER PRIMARY KEY, owner_id INTEGER REFERENCES owners (id))").Exec())
+	r.NoError(c.RawQuery("INSERT INTO owners (id) VALUES (1)").Exec())
+	r.NoError(c.RawQuery("INSERT INTO alpha_pets (id, owner_id) VALUES (1, 1)").Exec())
+
+	bb := &bytes.Buffer{}
+	r.NoError(c.Backup(bb, BackupOptions{Native: true}))
+	r.NoError(c.Restore(bb))
+
+	n := 0
+	r.NoError(c.Store.Get(&n, "SELECT count(*) FROM alpha_pets JOIN owners ON owners.id = alpha_pets.owner_id"))
+	r.Equal(1, n)
+
+	// the foreign keys are checked right away again after the restore.
+	r.Error(c.RawQuery("DELETE FROM owners").Exec())
+}
+
+func Test_SQLite_Migration_ForeignKeys(t *testing.T) {
+	r := require.New(t)
+
+	dir, err := ioutil.TempDir("", "pop")
+	r.NoError(err)
+	defer os.RemoveAll(dir)
+
+	c, err := NewConnection(&ConnectionDetails{
+		Dialect:  "sqlite3",
+		Database: filepath.Join(dir, "migrate.sqlite"),
+		Options:  map[string]string{"foreign_keys": "on"},
+	})
+	r.NoError(err)
+	r.NoError(c.Open())
+	defer c.Close()
+	r.NoError(c.RawQuery("CREATE TABLE parents (id INTEGER PRIMARY KEY, extra TEXT)").Exec())
+	r.NoError(c.RawQuery("CREATE TABLE kids (id INTEGER PRIMARY KEY, parent_id INTEGER REFERENCES parents (id) ON DELETE CASCADE)").Exec())
+	r.NoError(c.RawQuery("INSERT INTO parents (id) VALUES (1)").Exec())
+	r.NoError(c.RawQuery("INSERT INTO kids (id, parent_id) VALUES (1, 1)").Exec())
+
+	mdir := filepath.Join(dir, "migrations")
+	r.NoError(os.Mkdir(mdir, 0755))
+	r.NoError(ioutil.WriteFile(filepath.Join(mdir, "1_drop_extra.up.fizz"), []byte(`drop_column("parents", "extra")`), 0644))
+	fm, err := NewFileMigrator(mdir, c)
+	r.NoError(err)
+
+	// rebuilding parents would cascade to the kids.
+	err = fm.Up()
+	r.Error(err)
+	r.Contains(err.Error(), "can not rebuild table parents while the foreign keys are enforced")
+	n := 0
+	r.NoError(c.Store.Get(&n, "SELECT count(*) FROM kids"))
+	r.Equal(1, n)
+}
+
+func Test_SQLite_SetApplicationName(t *testing.T) {
+	r := require.New(t)
+
+	c, err := NewConnection(&ConnectionDetails{Dialect: "sqlite3", Database: ":memory:"})
+	r.NoError(err)
+	r.NoError(c.Open())
+	defer c.Close()
+
+	r.EqualError(c.SetApplicationName("api"), "sqlite3 does not support application names")
+}
+
+func Test_SQLite_timeoutError(t *testing.T) {
+	r := require.New(t)
+
+	e := sqlite3.Error{Code: sqlite3.ErrInterrupt}
+	err := timeoutError(e)
+	r.True(IsQueryTimeout(err))
+	r.Equal(e, err.(*QueryTimeoutError).Err)
+	r.False(IsQueryTimeout(timeoutError(sqlite3.Error{Code: sqlite3.ErrBusy})))
+}
+
+func Test_SQLite_EachBatch_Timeout(t *testing.T) {
+	r := require.New(t)
+
+	c, err := NewConnection(&ConnectionDetails{Dialect: "sqlite3", Database: ":memory:"})
+	r.NoError(err)
+	r.NoError(c.Open())
+	defer c.Close()
+
+	type row struct {
+		ID int64 `db:"id"`
+	}
+	// an endless query, stopped by the deadline of the context.
+	query := "WITH RECURSIVE n(id) AS (SELECT 1 UNION ALL SELECT id + 1 FROM n) SELECT id FROM n"
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	count := 0
+	err = c.WithContext(ctx).RawQuery(query).Each(&row{}, func(interface{}) error {
+		count++
+		return nil
+	})
+	r.True(IsQueryTimeout(err))
+	r.True(count > 0)
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	// the batches are too large to be filled before the deadline.
+	size := 1 << 20
+	rows := []row{}
+	batches := []int{}
+	err = c.WithContext(ctx).RawQuery(query).EachBatch(&rows, size, func(interface{}) error {
+		batches = append(batches, len(rows))
+		return nil
+	})
+	r.True(IsQueryTimeout(err))
+	r.Len(batches, 1)
+	r.True(batches[0] > 0 && batches[0] < size)
+}
+
+func Test_sqliteOnConflict(t *testing.T) {
+	r := require.New(t)
+
+	r.True(sqliteOnConflict("3.24.0"))
+	r.True(sqliteOnConflict("3.45.1"))
+	r.False(sqliteOnConflict("3.17.0"))
+	r.False(sqliteOnConflict(""))
+}
+
+func Test_SQLite_Pool(t *testing.T) {
+	r := require.New(t)
+
+	dir, err := ioutil.TempDir("", "pop")
+	r.NoError(err)
+	defer os.RemoveAll(dir)
+
+	c, err := NewConnection(&ConnectionDetails{
+		Dialect:         "sqlite3",
+		Database:        filepath.Join(dir, "pool.sqlite"),
+		MaxOpen:         3,
+		MaxIdle:         1,
+		ConnMaxIdleTime: time.Minute,
+	})
+	r.NoError(err)
+	r.NoError(c.Open())
+	defer c.Close()
+
+	db := c.Store.(*instrumentedStore).store.(*dB)
+	r.Equal(3, db.Stats().MaxOpenConnections)
+}
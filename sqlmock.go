@@ -0,0 +1,292 @@
+package pop
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// NewConnectionWithDB returns a connection of the dialect of deets using
+// the database pool db as is, such as one created by go-sqlmock:
+//
+//	db, mock, err := sqlmock.New()
+//	c, err := pop.NewConnectionWithDB(&pop.ConnectionDetails{Dialect: "postgres", Database: "app_test"}, db)
+//
+// The statements are bound for the dialect, `$1` placeholders on
+// PostgreSQL for instance, as they are on a connection opened by pop.
+func NewConnectionWithDB(deets *ConnectionDetails, db *sql.DB) (*Connection, error) {
+	c, err := NewConnection(deets)
+	if err != nil {
+		return nil, err
+	}
+	c.replicas = nil
+	sdb := sqlx.NewDb(db, deets.Dialect)
+	sdb.Mapper = fieldMapper
+	c.Store = newInstrumentedStore(c, newDB(c.Dialect, sdb))
+	return c, nil
+}
+
+// MockStatement is a statement a connection sends to the database for an
+// operation, as returned by `Connection.ExpectCreate` and the like, to be
+// expected by a mock of the database such as go-sqlmock:
+//
+//	stmts, err := c.ExpectCreate(&User{Name: "Mark"})
+//	s := stmts[0]
+//	mock.ExpectQuery(s.Pattern()).WithArgs(s.Args...).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+//	err = c.Create(&User{Name: "Mark"})
+type MockStatement struct {
+	// SQL is the statement, bound for the dialect of the connection. It
+	// is "BEGIN", "COMMIT" or "ROLLBACK" for the statements of a
+	// transaction.
+	SQL string
+	// Args are the arguments of the statement. The times, such as the
+	// timestamps pop sets, and the generated UUIDs match any value of
+	// their type, as they change from one run to the next.
+	Args []driver.Value
+	// Query is true for the statements returning rows, expected with
+	// `ExpectQuery`, and false for the ones expected with `ExpectExec`.
+	Query bool
+}
+
+// Pattern returns the regular expression matching the statement exactly.
+func (s MockStatement) Pattern() string {
+	return "^" + regexp.QuoteMeta(s.SQL) + "$"
+}
+
+// ExpectCreate returns the statements the connection sends to create the
+// model. The model is not changed, the statements are those of a copy.
+func (c *Connection) ExpectCreate(model interface{}, excludeColumns ...string) ([]MockStatement, error) {
+	return c.recordStatements(model, func(rc *Connection, m interface{}) error {
+		return rc.Create(m, excludeColumns...)
+	})
+}
+
+// ExpectUpdate returns the statements the connection sends to update the
+// model.
+func (c *Connection) ExpectUpdate(model interface{}, excludeColumns ...string) ([]MockStatement, error) {
+	return c.recordStatements(model, func(rc *Connection, m interface{}) error {
+		return rc.Update(m, excludeColumns...)
+	})
+}
+
+// ExpectDestroy returns the statements the connection sends to destroy the
+// model.
+func (c *Connection) ExpectDestroy(model interface{}) ([]MockStatement, error) {
+	return c.recordStatements(model, func(rc *Connection, m interface{}) error {
+		return rc.Destroy(m)
+	})
+}
+
+// ExpectFind returns the statements the connection sends to find the
+// model with the id.
+func (c *Connection) ExpectFind(model interface{}, id interface{}) ([]MockStatement, error) {
+	return c.recordStatements(model, func(rc *Connection, m interface{}) error {
+		return rc.Find(m, id)
+	})
+}
+
+// ExpectAll returns the statements the query sends to load the models.
+//
+//	stmts, err := c.Where("name = ?", "Mark").ExpectAll(&users)
+func (q *Query) ExpectAll(models interface{}) ([]MockStatement, error) {
+	return q.Connection.recordStatements(models, func(rc *Connection, m interface{}) error {
+		rq := &Query{}
+		q.Clone(rq)
+		rq.Connection = rc
+		return rq.All(m)
+	})
+}
+
+// ExpectFirst returns the statements the query sends to load the first
+// model.
+func (q *Query) ExpectFirst(model interface{}) ([]MockStatement, error) {
+	return q.Connection.recordStatements(model, func(rc *Connection, m interface{}) error {
+		rq := &Query{}
+		q.Clone(rq)
+		rq.Connection = rc
+		return rq.First(m)
+	})
+}
+
+// recordStatements runs fn with a connection of the dialect of c which
+// records its statements rather than sending them, and a copy of model.
+// The queries return no rows, but the id of the inserts returning it,
+// and the statements change one row. The MariaDB server version is not
+// asked, the one known to c is used: the inserts do not return the id
+// until c has asked it.
+func (c *Connection) recordStatements(model interface{}, fn func(rc *Connection, m interface{}) error) ([]MockStatement, error) {
+	rec := &statementRecorder{}
+	deets := *c.Dialect.Details()
+	rc, err := NewConnectionWithDB(&deets, sql.OpenDB(rec))
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	rc.noAssociations = c.noAssociations
+	if m, ok := c.Dialect.(*mysql); ok {
+		rc.Dialect.(*mysql).version = m.knownVersion()
+	}
+
+	m := model
+	v := reflect.ValueOf(model)
+	if v.Kind() == reflect.Ptr && !v.IsNil() {
+		cp := reflect.New(v.Elem().Type())
+		cp.Elem().Set(v.Elem())
+		m = cp.Interface()
+	}
+	isModel := reflect.Indirect(reflect.ValueOf(m)).Kind() == reflect.Struct
+	var id interface{}
+	if isModel {
+		id = (&Model{Value: m}).ID()
+	}
+	if err := fn(rc, m); err != nil && errors.Cause(err) != sql.ErrNoRows {
+		return nil, err
+	}
+	// the ids generated on the way are not known in advance.
+	generated := []driver.Value{}
+	if isModel {
+		if nid := (&Model{Value: m}).ID(); !reflect.DeepEqual(nid, id) {
+			if dv, err := driver.DefaultParameterConverter.ConvertValue(nid); err == nil && dv != nil {
+				if _, ok := dv.(int64); !ok {
+					generated = append(generated, dv)
+				}
+			}
+		}
+	}
+	for _, s := range rec.statements {
+		for i, a := range s.Args {
+			if _, ok := a.(time.Time); ok {
+				s.Args[i] = anyMockValue{a}
+				continue
+			}
+			for _, g := range generated {
+				if reflect.DeepEqual(a, g) {
+					s.Args[i] = anyMockValue{a}
+				}
+			}
+		}
+	}
+	return rec.statements, nil
+}
+
+// anyMockValue matches any value of the type of the value it holds. It
+// implements the `Argument` interface of go-sqlmock.
+type anyMockValue struct {
+	v driver.Value
+}
+
+func (a anyMockValue) Match(v driver.Value) bool {
+	return reflect.TypeOf(v) == reflect.TypeOf(a.v)
+}
+
+// statementRecorder is a database driver recording the statements it is
+// given.
+type statementRecorder struct {
+	statements []MockStatement
+}
+
+func (r *statementRecorder) Connect(context.Context) (driver.Conn, error) {
+	return &recorderConn{r}, nil
+}
+
+func (r *statementRecorder) Driver() driver.Driver {
+	return recorderDriver{r}
+}
+
+func (r *statementRecorder) record(query string, args []driver.NamedValue, isQuery bool) {
+	s := MockStatement{SQL: query, Args: []driver.Value{}, Query: isQuery}
+	for _, a := range args {
+		s.Args = append(s.Args, a.Value)
+	}
+	r.statements = append(r.statements, s)
+}
+
+type recorderDriver struct {
+	r *statementRecorder
+}
+
+func (d recorderDriver) Open(string) (driver.Conn, error) {
+	return &recorderConn{d.r}, nil
+}
+
+type recorderConn struct {
+	r *statementRecorder
+}
+
+func (c *recorderConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.Errorf("the statement recorder does not prepare statements: %s", query)
+}
+
+func (c *recorderConn) Close() error {
+	return nil
+}
+
+func (c *recorderConn) Begin() (driver.Tx, error) {
+	c.r.record("BEGIN", nil, false)
+	return c, nil
+}
+
+func (c *recorderConn) Commit() error {
+	c.r.record("COMMIT", nil, false)
+	return nil
+}
+
+func (c *recorderConn) Rollback() error {
+	c.r.record("ROLLBACK", nil, false)
+	return nil
+}
+
+func (c *recorderConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.r.record(query, args, false)
+	return recorderResult{}, nil
+}
+
+// recorderResult is the result of the statements, which insert or change
+// a row with the id 1.
+type recorderResult struct{}
+
+func (recorderResult) LastInsertId() (int64, error) {
+	return 1, nil
+}
+
+func (recorderResult) RowsAffected() (int64, error) {
+	return 1, nil
+}
+
+func (c *recorderConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.r.record(query, args, true)
+	if strings.Contains(strings.ToLower(query), " returning id") {
+		return &recorderRows{cols: []string{"id"}, rows: [][]driver.Value{{int64(1)}}}, nil
+	}
+	return &recorderRows{}, nil
+}
+
+type recorderRows struct {
+	cols []string
+	rows [][]driver.Value
+}
+
+func (r *recorderRows) Columns() []string {
+	return r.cols
+}
+
+func (r *recorderRows) Close() error {
+	return nil
+}
+
+func (r *recorderRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
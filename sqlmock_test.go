@@ -0,0 +1,123 @@
+package pop
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type mockedUser struct {
+	ID        int       `db:"id"`
+	Name      string    `db:"name"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func (mockedUser) TableName() string {
+	return "users"
+}
+
+type mockedSong struct {
+	ID    uuid.UUID `db:"id"`
+	Title string    `db:"title"`
+}
+
+func (mockedSong) TableName() string {
+	return "songs"
+}
+
+func Test_ExpectCreate(t *testing.T) {
+	r := require.New(t)
+
+	c, err := NewConnection(&ConnectionDetails{Dialect: "postgres", Database: "pop_test"})
+	r.NoError(err)
+	u := &mockedUser{Name: "Mark"}
+	stmts, err := c.ExpectCreate(u)
+	r.NoError(err)
+	r.Zero(u.ID)
+	r.Len(stmts, 1)
+	s := stmts[0]
+	r.True(s.Query)
+	r.Equal("INSERT INTO users (created_at, name, updated_at) VALUES ($1, $2, $3) returning id", s.SQL)
+	r.Equal(`^INSERT INTO users \(created_at, name, updated_at\) VALUES \(\$1, \$2, \$3\) returning id$`, s.Pattern())
+	r.Len(s.Args, 3)
+	r.Equal("Mark", s.Args[1])
+	r.True(s.Args[0].(anyMockValue).Match(time.Now()))
+	r.False(s.Args[0].(anyMockValue).Match("now"))
+
+	c, err = NewConnection(&ConnectionDetails{Dialect: "mysql", Database: "pop_test"})
+	r.NoError(err)
+	stmts, err = c.ExpectCreate(&mockedSong{Title: "Yesterday"})
+	r.NoError(err)
+	r.Len(stmts, 1)
+	r.False(stmts[0].Query)
+	r.Equal("INSERT INTO songs (id, title) VALUES (?, ?)", stmts[0].SQL)
+	r.True(stmts[0].Args[0].(anyMockValue).Match("d1d1b3b4-9f3e-4a5e-8d37-6f5c6e6a2f10"))
+	r.Equal("Yesterday", stmts[0].Args[1])
+
+	stmts, err = c.ExpectCreate(&mockedUser{Name: "Mark"})
+	r.NoError(err)
+	r.Len(stmts, 1)
+	r.False(stmts[0].Query)
+	r.Equal("INSERT INTO users (created_at, name, updated_at) VALUES (?, ?, ?)", stmts[0].SQL)
+
+	c, err = NewConnection(&ConnectionDetails{Dialect: "mariadb", Database: "pop_test"})
+	r.NoError(err)
+	stmts, err = c.ExpectCreate(&mockedUser{Name: "Mark"})
+	r.NoError(err)
+	r.Len(stmts, 1)
+	r.False(stmts[0].Query)
+
+	c.Dialect.(*mysql).version = "10.5.8-MariaDB"
+	stmts, err = c.ExpectCreate(&mockedUser{Name: "Mark"})
+	r.NoError(err)
+	r.Len(stmts, 1)
+	r.True(stmts[0].Query)
+	r.Equal("INSERT INTO users (created_at, name, updated_at) VALUES (?, ?, ?) RETURNING id", stmts[0].SQL)
+}
+
+func Test_ExpectUpdate_Destroy_Find(t *testing.T) {
+	r := require.New(t)
+
+	c, err := NewConnection(&ConnectionDetails{Dialect: "postgres", Database: "pop_test"})
+	r.NoError(err)
+	u := &mockedUser{ID: 1, Name: "Mark"}
+
+	stmts, err := c.ExpectUpdate(u)
+	r.NoError(err)
+	r.Len(stmts, 1)
+	r.Equal("UPDATE users SET name = $1, updated_at = $2 where users.id = $3", stmts[0].SQL)
+
+	stmts, err = c.ExpectDestroy(u)
+	r.NoError(err)
+	r.Len(stmts, 1)
+	r.Equal("DELETE FROM users WHERE users.id = $1", stmts[0].SQL)
+	r.Equal([]driver.Value{int64(1)}, stmts[0].Args)
+
+	stmts, err = c.ExpectFind(&mockedUser{}, 1)
+	r.NoError(err)
+	r.Len(stmts, 1)
+	r.True(stmts[0].Query)
+	r.Equal([]driver.Value{int64(1)}, stmts[0].Args)
+
+	stmts, err = c.Where("name = ?", "Mark").ExpectAll(&[]mockedUser{})
+	r.NoError(err)
+	r.Len(stmts, 1)
+	r.Contains(stmts[0].SQL, "WHERE name = $1")
+	r.Equal([]driver.Value{"Mark"}, stmts[0].Args)
+}
+
+func Test_NewConnectionWithDB(t *testing.T) {
+	r := require.New(t)
+
+	rec := &statementRecorder{}
+	c, err := NewConnectionWithDB(&ConnectionDetails{Dialect: "postgres", Database: "pop_test"}, sql.OpenDB(rec))
+	r.NoError(err)
+	r.NoError(c.RawQuery("UPDATE users SET name = ? WHERE id = ?", "Mark", 1).Exec())
+	r.Len(rec.statements, 1)
+	r.Equal("UPDATE users SET name = $1 WHERE id = $2", rec.statements[0].SQL)
+}
@@ -0,0 +1,249 @@
+package pop
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// the statements worth preparing once, the other ones (DDL, pragmas...)
+// are always sent as is.
+var cacheableStmtX = regexp.MustCompile(`(?i)^\s*(SELECT|INSERT|UPDATE|DELETE|WITH)\b`)
+
+// stmtCache keeps the statements prepared on a database pool, keyed by
+// their SQL, so that the hot queries are prepared once. The least
+// recently used statements are closed beyond size, and the ones unused
+// for ttl, if it is set.
+type stmtCache struct {
+	db   *sqlx.DB
+	size int
+	ttl  time.Duration
+
+	mu      sync.Mutex
+	lru     *list.List
+	entries map[string]*list.Element
+	hits    int
+	misses  int
+}
+
+type cachedStmt struct {
+	query   string
+	stmt    *sqlx.Stmt
+	used    time.Time
+	refs    int
+	evicted bool
+}
+
+// newStmtCache returns the statement cache of the pool db configured by
+// the details, or nil if the cache is disabled.
+func newStmtCache(cd *ConnectionDetails, db *sqlx.DB) *stmtCache {
+	size := cd.StatementCacheSize()
+	if size <= 0 {
+		return nil
+	}
+	return &stmtCache{
+		db:      db,
+		size:    size,
+		ttl:     cd.StatementCacheTTL(),
+		lru:     list.New(),
+		entries: map[string]*list.Element{},
+	}
+}
+
+// acquire returns the prepared statement for query, preparing it if
+// needed, or nil if it can not be prepared. The statement must be
+// released once it has been run.
+func (sc *stmtCache) acquire(ctx context.Context, query string) *cachedStmt {
+	if !cacheableStmtX.MatchString(query) {
+		return nil
+	}
+	now := time.Now()
+	sc.mu.Lock()
+	if el, ok := sc.entries[query]; ok {
+		cs := el.Value.(*cachedStmt)
+		if sc.ttl == 0 || now.Sub(cs.used) < sc.ttl {
+			cs.used = now
+			cs.refs++
+			sc.hits++
+			sc.lru.MoveToFront(el)
+			sc.mu.Unlock()
+			return cs
+		}
+		sc.evict(el)
+	}
+	sc.misses++
+	sc.mu.Unlock()
+
+	stmt, err := sc.db.PreparexContext(ctx, query)
+	if err != nil {
+		// the statements the driver can not prepare are sent as is.
+		return nil
+	}
+
+	defer sc.mu.Unlock()
+	sc.mu.Lock()
+	if el, ok := sc.entries[query]; ok {
+		// prepared concurrently
+		stmt.Close()
+		cs := el.Value.(*cachedStmt)
+		cs.used = now
+		cs.refs++
+		sc.lru.MoveToFront(el)
+		return cs
+	}
+	cs := &cachedStmt{query: query, stmt: stmt, used: now, refs: 1}
+	sc.entries[query] = sc.lru.PushFront(cs)
+	for sc.lru.Len() > sc.size {
+		sc.evict(sc.lru.Back())
+	}
+	return cs
+}
+
+// release releases a statement returned by acquire, closing it if it was
+// evicted in the meantime.
+func (sc *stmtCache) release(cs *cachedStmt) {
+	defer sc.mu.Unlock()
+	sc.mu.Lock()
+	cs.refs--
+	if cs.evicted && cs.refs == 0 {
+		cs.stmt.Close()
+	}
+}
+
+// evict removes an entry of the cache, closing its statement unless it
+// is running. It must be called with the lock held.
+func (sc *stmtCache) evict(el *list.Element) {
+	cs := el.Value.(*cachedStmt)
+	sc.lru.Remove(el)
+	delete(sc.entries, cs.query)
+	cs.evicted = true
+	if cs.refs == 0 {
+		cs.stmt.Close()
+	}
+}
+
+// close closes all the statements of the cache.
+func (sc *stmtCache) close() {
+	defer sc.mu.Unlock()
+	sc.mu.Lock()
+	for sc.lru.Len() > 0 {
+		sc.evict(sc.lru.Back())
+	}
+}
+
+// StatementCacheStats are the statistics of the statement cache of a
+// connection, see `Connection.StatementCacheStats`.
+type StatementCacheStats struct {
+	// Size is the number of statements in the cache
+	Size int
+	// Hits is the number of statements run with a cached statement
+	Hits int
+	// Misses is the number of statements prepared for the cache
+	Misses int
+}
+
+// StatementCacheStats returns the statistics of the statement cache of
+// the connection, enabled with the "statement_cache_size" option. They
+// are zero if the cache is disabled.
+func (c *Connection) StatementCacheStats() StatementCacheStats {
+	var db *dB
+	switch s := c.Store.(type) {
+	case *instrumentedStore:
+		db, _ = s.store.(*dB)
+	case *dB:
+		db = s
+	}
+	if db == nil || db.stmts == nil {
+		return StatementCacheStats{}
+	}
+	sc := db.stmts
+	defer sc.mu.Unlock()
+	sc.mu.Lock()
+	return StatementCacheStats{Size: sc.lru.Len(), Hits: sc.hits, Misses: sc.misses}
+}
+
+func (db *dB) Select(dest interface{}, query string, args ...interface{}) error {
+	return db.SelectContext(context.Background(), dest, query, args...)
+}
+
+func (db *dB) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	if cs := db.prepared(ctx, query); cs != nil {
+		defer db.stmts.release(cs)
+		return cs.stmt.SelectContext(ctx, dest, args...)
+	}
+	return db.DB.SelectContext(ctx, dest, query, args...)
+}
+
+func (db *dB) Get(dest interface{}, query string, args ...interface{}) error {
+	return db.GetContext(context.Background(), dest, query, args...)
+}
+
+func (db *dB) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	if cs := db.prepared(ctx, query); cs != nil {
+		defer db.stmts.release(cs)
+		return cs.stmt.GetContext(ctx, dest, args...)
+	}
+	return db.DB.GetContext(ctx, dest, query, args...)
+}
+
+func (db *dB) Queryx(query string, args ...interface{}) (*sqlx.Rows, error) {
+	return db.QueryxContext(context.Background(), query, args...)
+}
+
+func (db *dB) QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error) {
+	if cs := db.prepared(ctx, query); cs != nil {
+		// the rows keep the statement open until they are closed.
+		defer db.stmts.release(cs)
+		return cs.stmt.QueryxContext(ctx, args...)
+	}
+	return db.DB.QueryxContext(ctx, query, args...)
+}
+
+func (db *dB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return db.ExecContext(context.Background(), query, args...)
+}
+
+func (db *dB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if cs := db.prepared(ctx, query); cs != nil {
+		defer db.stmts.release(cs)
+		return cs.stmt.ExecContext(ctx, args...)
+	}
+	return db.DB.ExecContext(ctx, query, args...)
+}
+
+func (db *dB) NamedExec(query string, arg interface{}) (sql.Result, error) {
+	return db.NamedExecContext(context.Background(), query, arg)
+}
+
+func (db *dB) NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error) {
+	if db.stmts == nil {
+		return db.DB.NamedExecContext(ctx, query, arg)
+	}
+	q, args, err := db.BindNamed(query, arg)
+	if err != nil {
+		return nil, err
+	}
+	return db.ExecContext(ctx, q, args...)
+}
+
+// prepared returns the cached statement for query, if the statement
+// cache is enabled.
+func (db *dB) prepared(ctx context.Context, query string) *cachedStmt {
+	if db.stmts == nil {
+		return nil
+	}
+	return db.stmts.acquire(ctx, query)
+}
+
+// Close closes the cached statements, and the pool.
+func (db *dB) Close() error {
+	if db.stmts != nil {
+		db.stmts.close()
+	}
+	return db.DB.Close()
+}
@@ -0,0 +1,109 @@
+// +build !nosqlite,!appengine,!appenginevm
+
+package pop
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type cachedWidget struct {
+	ID   int    `db:"id"`
+	Name string `db:"name"`
+}
+
+func (cachedWidget) TableName() string {
+	return "widgets"
+}
+
+func stmtCacheConnection(r *require.Assertions, dir string, options map[string]string) *Connection {
+	c, err := NewConnection(&ConnectionDetails{Dialect: "sqlite3", Database: filepath.Join(dir, "stmts.sqlite"), Options: options})
+	r.NoError(err)
+	r.NoError(c.Open())
+	r.NoError(c.RawQuery("CREATE TABLE IF NOT EXISTS widgets (id INTEGER PRIMARY KEY, name TEXT)").Exec())
+	return c
+}
+
+func Test_StatementCache(t *testing.T) {
+	r := require.New(t)
+
+	dir, err := ioutil.TempDir("", "pop")
+	r.NoError(err)
+	defer os.RemoveAll(dir)
+
+	c := stmtCacheConnection(r, dir, map[string]string{"statement_cache_size": "2"})
+	defer c.Close()
+	// DDL is not cached
+	r.Equal(StatementCacheStats{}, c.StatementCacheStats())
+
+	w := &cachedWidget{Name: "gear"}
+	r.NoError(c.Create(w))
+	for i := 0; i < 3; i++ {
+		found := &cachedWidget{}
+		r.NoError(c.Find(found, w.ID))
+		r.Equal("gear", found.Name)
+	}
+	stats := c.StatementCacheStats()
+	r.Equal(2, stats.Size)
+	r.Equal(2, stats.Misses)
+	r.Equal(2, stats.Hits)
+
+	// a third statement evicts the least recently used one
+	n, err := c.Count(&cachedWidget{})
+	r.NoError(err)
+	r.Equal(1, n)
+	r.NoError(c.Create(&cachedWidget{Name: "cog"}))
+	stats = c.StatementCacheStats()
+	r.Equal(2, stats.Size)
+	r.Equal(4, stats.Misses)
+
+	rows, err := c.Store.Queryx("SELECT name FROM widgets ORDER BY id")
+	r.NoError(err)
+	names := []string{}
+	for rows.Next() {
+		var name string
+		r.NoError(rows.Scan(&name))
+		names = append(names, name)
+	}
+	r.NoError(rows.Close())
+	r.Equal([]string{"gear", "cog"}, names)
+}
+
+func Test_StatementCache_TTL(t *testing.T) {
+	r := require.New(t)
+
+	dir, err := ioutil.TempDir("", "pop")
+	r.NoError(err)
+	defer os.RemoveAll(dir)
+
+	c := stmtCacheConnection(r, dir, map[string]string{"statement_cache_size": "10", "statement_cache_ttl": "10ms"})
+	defer c.Close()
+
+	_, err = c.Count(&cachedWidget{})
+	r.NoError(err)
+	time.Sleep(20 * time.Millisecond)
+	_, err = c.Count(&cachedWidget{})
+	r.NoError(err)
+	stats := c.StatementCacheStats()
+	r.Equal(0, stats.Hits)
+	r.Equal(2, stats.Misses)
+	r.Equal(1, stats.Size)
+}
+
+func Test_StatementCache_Disabled(t *testing.T) {
+	r := require.New(t)
+
+	dir, err := ioutil.TempDir("", "pop")
+	r.NoError(err)
+	defer os.RemoveAll(dir)
+
+	c := stmtCacheConnection(r, dir, nil)
+	defer c.Close()
+	r.NoError(c.Create(&cachedWidget{Name: "gear"}))
+	r.Equal(StatementCacheStats{}, c.StatementCacheStats())
+}
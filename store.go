@@ -1,6 +1,7 @@
 package pop
 
 import (
+	"context"
 	"database/sql"
 
 	"github.com/jmoiron/sqlx"
@@ -11,7 +12,9 @@ import (
 type store interface {
 	Select(interface{}, string, ...interface{}) error
 	Get(interface{}, string, ...interface{}) error
+	Queryx(string, ...interface{}) (*sqlx.Rows, error)
 	NamedExec(string, interface{}) (sql.Result, error)
+	BindNamed(string, interface{}) (string, []interface{}, error)
 	Exec(string, ...interface{}) (sql.Result, error)
 	PrepareNamed(string) (*sqlx.NamedStmt, error)
 	Transaction() (*Tx, error)
@@ -19,3 +22,15 @@ type store interface {
 	Commit() error
 	Close() error
 }
+
+// contextStore is implemented by the stores able to bind the statements,
+// and the transactions, to a context. The connections carrying a context
+// use it, so that cancelling the context aborts their queries.
+type contextStore interface {
+	SelectContext(context.Context, interface{}, string, ...interface{}) error
+	GetContext(context.Context, interface{}, string, ...interface{}) error
+	QueryxContext(context.Context, string, ...interface{}) (*sqlx.Rows, error)
+	NamedExecContext(context.Context, string, interface{}) (sql.Result, error)
+	ExecContext(context.Context, string, ...interface{}) (sql.Result, error)
+	TransactionContext(context.Context) (*Tx, error)
+}
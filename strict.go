@@ -0,0 +1,87 @@
+package pop
+
+import (
+	"database/sql"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/markbates/pop/columns"
+	"github.com/pkg/errors"
+)
+
+// strict returns true if the query checks that its results have all of
+// the columns of m, which is only done for structs.
+func (q Query) strict(m *Model) bool {
+	if q.Connection == nil || q.Connection.Dialect == nil || !q.Connection.Dialect.Details().Strict() {
+		return false
+	}
+	t := modelType(reflect.TypeOf(m.Value))
+	return t.Kind() == reflect.Struct && t != timeType && !reflect.PtrTo(t).Implements(scannerType)
+}
+
+// strictGet is store.Get, failing if a readable column of the model is
+// missing from the results.
+func strictGet(s store, model *Model, query string, args []interface{}) error {
+	rows, err := s.Queryx(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	if err = checkResultColumns(rows, model); err != nil {
+		return err
+	}
+	if !rows.Next() {
+		if err = rows.Err(); err != nil {
+			return err
+		}
+		return sql.ErrNoRows
+	}
+	if err := rows.StructScan(model.Value); err != nil {
+		return scanError(rows, model, err)
+	}
+	return nil
+}
+
+// strictSelect is store.Select, failing if a readable column of the
+// models is missing from the results.
+func strictSelect(s store, models *Model, query string, args []interface{}) error {
+	rows, err := s.Queryx(query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	if err = checkResultColumns(rows, models); err != nil {
+		return err
+	}
+	if err := scanModels(rows, models.Value); err != nil {
+		return scanError(rows, models, err)
+	}
+	return nil
+}
+
+// checkResultColumns returns an error listing the readable columns of the
+// model the rows do not have. The columns of the rows the model does not
+// map are already reported when the rows are scanned.
+func checkResultColumns(rows *sqlx.Rows, model *Model) error {
+	cols, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	found := map[string]bool{}
+	for _, c := range cols {
+		found[c] = true
+	}
+	missing := []string{}
+	for name := range columns.ColumnsForStruct(model.Value, model.TableName()).Readable().Cols {
+		if !found[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	sort.Strings(missing)
+	return errors.Errorf("the results of %s are missing the columns %s", model.TableName(), strings.Join(missing, ", "))
+}
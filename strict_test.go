@@ -0,0 +1,40 @@
+package pop_test
+
+import (
+	"testing"
+
+	"github.com/markbates/pop"
+	"github.com/markbates/pop/nulls"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Strict(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		cd := tx.Dialect.Details()
+		if cd.Options == nil {
+			cd.Options = map[string]string{}
+		}
+		cd.Options["strict"] = "true"
+		defer delete(cd.Options, "strict")
+
+		r.NoError(tx.Create(&User{Name: nulls.NewString("Mark")}))
+
+		u := &User{}
+		r.NoError(tx.First(u))
+		users := Users{}
+		r.NoError(tx.All(&users))
+		r.Len(users, 1)
+
+		err := tx.RawQuery("SELECT id, name FROM users").First(u)
+		r.Error(err)
+		r.Contains(err.Error(), "missing the columns alive, bio")
+		r.Error(tx.RawQuery("SELECT id, name FROM users").All(&users))
+
+		r.Error(tx.RawQuery("SELECT * FROM users WHERE id < 0").First(u))
+
+		// the columns the model does not map are always an error.
+		delete(cd.Options, "strict")
+		r.Error(tx.RawQuery("SELECT id, name, 1 AS unknown FROM users").First(u))
+	})
+}
@@ -30,6 +30,7 @@ test "postgres"
 test "cockroach"
 test "sqlite"
 test "mysql"
+test "tidb"
 
 docker-compose down
 
@@ -0,0 +1,72 @@
+package pop
+
+import (
+	"context"
+
+	_mysql "github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+// ErrQueryTimeout is the cause of the errors returned when a query was
+// cancelled by the database because it ran longer than allowed, or
+// because its context deadline was exceeded.
+//
+//	if errors.Cause(err) == pop.ErrQueryTimeout {
+//		// retry later, or return partial results
+//	}
+var ErrQueryTimeout = errors.New("query timed out")
+
+// QueryTimeoutError wraps the driver error of a timed out query. Its
+// cause is `ErrQueryTimeout`.
+type QueryTimeoutError struct {
+	Err error
+}
+
+func (e *QueryTimeoutError) Error() string {
+	return ErrQueryTimeout.Error() + ": " + e.Err.Error()
+}
+
+// Cause returns `ErrQueryTimeout`, so `errors.Cause` can be used to
+// detect timeouts whatever the dialect.
+func (e *QueryTimeoutError) Cause() error {
+	return ErrQueryTimeout
+}
+
+// Unwrap returns the driver error, for `errors.As` of the standard
+// library.
+func (e *QueryTimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// IsQueryTimeout returns true if err was returned for a query that
+// timed out.
+func IsQueryTimeout(err error) bool {
+	return errors.Cause(err) == ErrQueryTimeout
+}
+
+// timeoutError turns the driver errors meaning a query was cancelled
+// into a `QueryTimeoutError`, other errors are returned untouched.
+func timeoutError(err error) error {
+	if err == nil || IsQueryTimeout(err) {
+		return err
+	}
+	switch e := errors.Cause(err).(type) {
+	case *pq.Error:
+		// query_canceled, raised by statement_timeout
+		if e.Code == "57014" {
+			return &QueryTimeoutError{Err: err}
+		}
+	case *_mysql.MySQLError:
+		// ER_QUERY_TIMEOUT and ER_QUERY_INTERRUPTED
+		if e.Number == 3024 || e.Number == 1317 {
+			return &QueryTimeoutError{Err: err}
+		}
+	default:
+		// SQLite interrupts the statements whose context is done.
+		if e == context.DeadlineExceeded || sqliteInterrupted(e) {
+			return &QueryTimeoutError{Err: err}
+		}
+	}
+	return err
+}
@@ -0,0 +1,34 @@
+package pop
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	_mysql "github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_timeoutError(t *testing.T) {
+	r := require.New(t)
+
+	r.Nil(timeoutError(nil))
+	r.Equal(sql.ErrNoRows, timeoutError(sql.ErrNoRows))
+	r.False(IsQueryTimeout(timeoutError(&pq.Error{Code: "23505"})))
+	r.False(IsQueryTimeout(timeoutError(errors.New("the connection was interrupted"))))
+
+	for _, e := range []error{
+		&pq.Error{Code: "57014", Message: "canceling statement due to statement timeout"},
+		&_mysql.MySQLError{Number: 3024, Message: "maximum statement execution time exceeded"},
+		errors.WithStack(context.DeadlineExceeded),
+	} {
+		err := timeoutError(e)
+		r.True(IsQueryTimeout(err))
+		r.Equal(ErrQueryTimeout, errors.Cause(err))
+		r.Equal(e, err.(*QueryTimeoutError).Err)
+		r.Equal(err, timeoutError(err))
+		r.Equal(e, err.(*QueryTimeoutError).Unwrap())
+	}
+}
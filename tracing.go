@@ -0,0 +1,134 @@
+package pop
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// Tracer starts the spans of the statements and transactions pop runs, so
+// they show up in distributed traces. An OpenTelemetry tracer only needs
+// a small adapter:
+//
+//	type otelTracer struct{ trace.Tracer }
+//
+//	func (t otelTracer) Start(ctx context.Context, name string) (context.Context, pop.Span) {
+//		ctx, s := t.Tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient))
+//		return ctx, otelSpan{s}
+//	}
+//
+//	type otelSpan struct{ trace.Span }
+//
+//	func (s otelSpan) SetAttribute(key string, value interface{}) {
+//		s.SetAttributes(attribute.String(key, fmt.Sprint(value)))
+//	}
+//
+//	func (s otelSpan) End(err error) {
+//		if err != nil {
+//			s.RecordError(err)
+//			s.SetStatus(codes.Error, err.Error())
+//		}
+//		s.Span.End()
+//	}
+type Tracer interface {
+	// Start starts a span named name, child of the span of ctx, if any.
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a statement or a transaction being traced.
+type Span interface {
+	SetAttribute(key string, value interface{})
+	// End ends the span, which failed with err if it is not nil.
+	End(err error)
+}
+
+var defaultTracer Tracer
+var defaultTracerMu = sync.RWMutex{}
+
+// SetTracer sets the tracer of the connections which have none of their
+// own, see `Connection.WithTracer`. SetTracer(nil) turns tracing off.
+func SetTracer(t Tracer) {
+	defer defaultTracerMu.Unlock()
+	defaultTracerMu.Lock()
+	defaultTracer = t
+}
+
+// WithTracer returns a copy of the connection tracing its statements with
+// t, as do the transactions started from it, rather than with the tracer
+// set by `SetTracer`.
+//
+// The span of a statement is named after its verb, such as "SELECT", and
+// is a child of the span of the connection context, see
+// `Connection.WithContext`, or of the span of its transaction, named
+// "pop.transaction". The spans have the "db.system", "db.name" and
+// "db.statement" attributes, and "db.rows_affected" when it is known.
+func (c *Connection) WithTracer(t Tracer) *Connection {
+	cn := *c
+	cn.tracer = t
+	if is, ok := c.Store.(*instrumentedStore); ok {
+		cn.Store = newInstrumentedStore(&cn, is.store)
+	}
+	return &cn
+}
+
+// tracerOf returns the tracer of the connection c, which may be nil, or
+// nil if it is not traced.
+func tracerOf(c *Connection) Tracer {
+	if c != nil && c.tracer != nil {
+		return c.tracer
+	}
+	defaultTracerMu.RLock()
+	defer defaultTracerMu.RUnlock()
+	return defaultTracer
+}
+
+// startSpan starts a span named name for the connection c, or returns nil
+// if it is not traced.
+func startSpan(c *Connection, name string) (context.Context, Span) {
+	t := tracerOf(c)
+	if t == nil {
+		return nil, nil
+	}
+	ctx := context.Background()
+	if c.TX != nil && c.TX.spanCtx != nil {
+		ctx = c.TX.spanCtx
+	} else if c.ctx != nil {
+		ctx = c.ctx
+	}
+	ctx, span := t.Start(ctx, name)
+	if c.Dialect != nil {
+		span.SetAttribute("db.system", dbSystem(c.Dialect.Details().Dialect))
+		span.SetAttribute("db.name", c.Dialect.Details().Database)
+	}
+	return ctx, span
+}
+
+// startStatementSpan starts the span of the statement query, or returns
+// nil if the connection is not traced.
+func startStatementSpan(c *Connection, query string) Span {
+	if c == nil {
+		return nil
+	}
+	name := "pop.query"
+	if f := strings.Fields(query); len(f) > 0 {
+		name = strings.ToUpper(f[0])
+	}
+	_, span := startSpan(c, name)
+	if span != nil {
+		span.SetAttribute("db.statement", query)
+	}
+	return span
+}
+
+// dbSystem returns the OpenTelemetry name of the database of a dialect.
+func dbSystem(dialect string) string {
+	switch dialect {
+	case "postgres":
+		return "postgresql"
+	case "cockroach":
+		return "cockroachdb"
+	case "sqlite3":
+		return "sqlite"
+	}
+	return dialect
+}
@@ -0,0 +1,113 @@
+package pop_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/markbates/pop"
+	"github.com/markbates/pop/nulls"
+	"github.com/stretchr/testify/require"
+)
+
+type spanKey struct{}
+
+// tracer records the spans it starts.
+type tracer struct {
+	mu    sync.Mutex
+	spans []*span
+}
+
+type span struct {
+	name   string
+	parent *span
+	attrs  map[string]interface{}
+	ended  bool
+	err    error
+}
+
+func (t *tracer) Start(ctx context.Context, name string) (context.Context, pop.Span) {
+	s := &span{name: name, attrs: map[string]interface{}{}}
+	s.parent, _ = ctx.Value(spanKey{}).(*span)
+	t.mu.Lock()
+	t.spans = append(t.spans, s)
+	t.mu.Unlock()
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+func (s *span) SetAttribute(key string, value interface{}) {
+	s.attrs[key] = value
+}
+
+func (s *span) End(err error) {
+	s.ended = true
+	s.err = err
+}
+
+func Test_WithTracer(t *testing.T) {
+	transaction(func(tx *pop.Connection) {
+		r := require.New(t)
+
+		tr := &tracer{}
+		c := tx.WithTracer(tr)
+		r.NoError(c.Create(&User{Name: nulls.NewString("Mark")}))
+		r.Len(tr.spans, 1)
+
+		s := tr.spans[0]
+		r.Equal("INSERT", s.name)
+		r.True(s.ended)
+		r.NoError(s.err)
+		r.Contains(s.attrs["db.statement"], "INSERT INTO users")
+		r.Equal(int64(1), s.attrs["db.rows_affected"])
+		r.NotEmpty(s.attrs["db.system"])
+
+		tr.spans = nil
+		r.Error(c.RawQuery("SELECT * FROM unknown_table").Exec())
+		r.Len(tr.spans, 1)
+		r.Equal("SELECT", tr.spans[0].name)
+		r.Error(tr.spans[0].err)
+
+		tr.spans = nil
+		r.NoError(tx.Where("name = ?", "Mark").First(&User{}))
+		r.Len(tr.spans, 0)
+	})
+}
+
+func Test_WithTracer_Transaction(t *testing.T) {
+	r := require.New(t)
+
+	tr := &tracer{}
+	err := PDB.WithTracer(tr).Rollback(func(tx *pop.Connection) {
+		_, err := tx.Count(&Users{})
+		r.NoError(err)
+	})
+	r.NoError(err)
+	r.Len(tr.spans, 2)
+
+	txs, q := tr.spans[0], tr.spans[1]
+	r.Equal("pop.transaction", txs.name)
+	r.True(txs.ended)
+	r.Equal(true, txs.attrs["pop.rollback"])
+	r.Equal("SELECT", q.name)
+	r.Equal(txs, q.parent)
+	_, ok := q.attrs["db.rows_affected"]
+	r.False(ok)
+}
+
+func Test_SetTracer(t *testing.T) {
+	transaction(func(tx *pop.Connection) {
+		r := require.New(t)
+
+		tr := &tracer{}
+		pop.SetTracer(tr)
+		defer pop.SetTracer(nil)
+		_, err := tx.Count(&Users{})
+		r.NoError(err)
+		r.Len(tr.spans, 1)
+
+		pop.SetTracer(nil)
+		_, err = tx.Count(&Users{})
+		r.NoError(err)
+		r.Len(tr.spans, 1)
+	})
+}
@@ -0,0 +1,83 @@
+package pop
+
+import (
+	"fmt"
+
+	_mysql "github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+// MaxTransactionRetries is the number of times `TransactionWithRetry`
+// runs a transaction again after a retryable error.
+var MaxTransactionRetries = 5
+
+// TransactionWithRetry works like `Transaction`, but runs the inner function
+// again when the transaction fails because of a conflict with a concurrent
+// transaction (serialization failures and deadlocks), up to
+// `MaxTransactionRetries` times. The inner function must be safe to run more
+// than once.
+//
+// On CockroachDB the client-side retry protocol is used: the transaction is
+// rolled back to the "cockroach_restart" savepoint and retried, instead of
+// starting a new one, so it keeps its priority over the others.
+//
+// If the connection is already in a transaction, the function is only run
+// once, as only the outermost transaction can be retried.
+func (c *Connection) TransactionWithRetry(fn func(tx *Connection) error) error {
+	if c.TX != nil {
+		return fn(c)
+	}
+	if _, ok := c.Dialect.(*cockroach); ok {
+		return c.Transaction(func(tx *Connection) error {
+			return cockroachRetry(tx, fn)
+		})
+	}
+	var err error
+	for i := 0; i <= MaxTransactionRetries; i++ {
+		err = c.Transaction(fn)
+		if !isRetryableTxError(err) {
+			return err
+		}
+		logMessage(c, LogWarn, fmt.Sprintf("retrying transaction: %s", err))
+	}
+	return err
+}
+
+func cockroachRetry(tx *Connection, fn func(tx *Connection) error) error {
+	if err := tx.RawQuery("SAVEPOINT cockroach_restart").Exec(); err != nil {
+		return err
+	}
+	var err error
+	for i := 0; i <= MaxTransactionRetries; i++ {
+		err = fn(tx)
+		if err == nil {
+			err = tx.RawQuery("RELEASE SAVEPOINT cockroach_restart").Exec()
+			if err == nil {
+				return nil
+			}
+		}
+		if !isRetryableTxError(err) {
+			return err
+		}
+		logMessage(tx, LogWarn, fmt.Sprintf("retrying transaction: %s", err))
+		if rerr := tx.RawQuery("ROLLBACK TO SAVEPOINT cockroach_restart").Exec(); rerr != nil {
+			return rerr
+		}
+	}
+	return err
+}
+
+// isRetryableTxError returns true if the transaction failed because of
+// a concurrent transaction, and can be retried.
+func isRetryableTxError(err error) bool {
+	switch e := errors.Cause(err).(type) {
+	case *pq.Error:
+		// serialization_failure and deadlock_detected
+		return e.Code == "40001" || e.Code == "40P01"
+	case *_mysql.MySQLError:
+		// ER_LOCK_DEADLOCK, and TiDB optimistic transaction write conflicts
+		return e.Number == 1213 || e.Number == 9007
+	}
+	return false
+}
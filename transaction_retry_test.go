@@ -0,0 +1,58 @@
+package pop_test
+
+import (
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/markbates/pop"
+	"github.com/markbates/pop/nulls"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_TransactionWithRetry(t *testing.T) {
+	r := require.New(t)
+
+	attempts := 0
+	err := PDB.TransactionWithRetry(func(tx *pop.Connection) error {
+		attempts++
+		if err := tx.Create(&User{Name: nulls.NewString("Retried")}); err != nil {
+			return err
+		}
+		if attempts < 3 {
+			return errors.WithStack(&pq.Error{Code: "40001"})
+		}
+		return nil
+	})
+	r.NoError(err)
+	r.Equal(3, attempts)
+
+	users := Users{}
+	r.NoError(PDB.Where("name = ?", "Retried").All(&users))
+	r.Len(users, 1)
+	r.NoError(PDB.Destroy(&users[0]))
+}
+
+func Test_TransactionWithRetry_NotRetryable(t *testing.T) {
+	r := require.New(t)
+
+	attempts := 0
+	err := PDB.TransactionWithRetry(func(tx *pop.Connection) error {
+		attempts++
+		return errors.New("boom")
+	})
+	r.Error(err)
+	r.Equal(1, attempts)
+}
+
+func Test_TransactionWithRetry_GivesUp(t *testing.T) {
+	r := require.New(t)
+
+	attempts := 0
+	err := PDB.TransactionWithRetry(func(tx *pop.Connection) error {
+		attempts++
+		return &pq.Error{Code: "40P01"}
+	})
+	r.Error(err)
+	r.Equal(pop.MaxTransactionRetries+1, attempts)
+}
@@ -0,0 +1,117 @@
+package pop
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// Trees are stored as an adjacency list: each row references its parent
+// with a nullable "parent_id" column (see the fizz `t.Tree()` helper).
+// Descendants and ancestors are found with recursive common table
+// expressions, supported by PostgreSQL, CockroachDB, SQLite, MySQL 8 and
+// MariaDB 10.2.
+
+// Descendants finds all the descendants of node, at any depth, and puts
+// them in models.
+//
+//	categories := []Category{}
+//	err := c.Descendants(&root, &categories)
+func (c *Connection) Descendants(node interface{}, models interface{}) error {
+	return Q(c).Descendants(node, models)
+}
+
+// Descendants finds all the descendants of node, at any depth, matching
+// the query, and puts them in models.
+//
+//	err := c.Order("name").Descendants(&root, &categories)
+func (q *Query) Descendants(node interface{}, models interface{}) error {
+	m := &Model{Value: node}
+	query := fmt.Sprintf(`WITH RECURSIVE tree AS (
+SELECT id FROM %[1]s WHERE parent_id = ?
+UNION
+SELECT %[1]s.id FROM %[1]s INNER JOIN tree ON %[1]s.parent_id = tree.id
+) SELECT id FROM tree`, m.TableName())
+	return q.treeSelect(m, models, query, m.ID())
+}
+
+// Ancestors finds all the ancestors of node, up to the root of the tree,
+// and puts them in models.
+//
+//	categories := []Category{}
+//	err := c.Ancestors(&leaf, &categories)
+func (c *Connection) Ancestors(node interface{}, models interface{}) error {
+	return Q(c).Ancestors(node, models)
+}
+
+// Ancestors finds all the ancestors of node, up to the root of the tree,
+// matching the query, and puts them in models.
+func (q *Query) Ancestors(node interface{}, models interface{}) error {
+	m := &Model{Value: node}
+	query := fmt.Sprintf(`WITH RECURSIVE tree AS (
+SELECT id, parent_id FROM %[1]s WHERE id = ?
+UNION
+SELECT %[1]s.id, %[1]s.parent_id FROM %[1]s INNER JOIN tree ON %[1]s.id = tree.parent_id
+) SELECT id FROM tree WHERE id <> ?`, m.TableName())
+	return q.treeSelect(m, models, query, m.ID(), m.ID())
+}
+
+// treeSelect finds the ids returned by query, then loads the matching
+// rows into models.
+func (q *Query) treeSelect(m *Model, models interface{}, query string, args ...interface{}) error {
+	fbn, err := m.fieldByName("ID")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	ids := reflect.New(reflect.SliceOf(fbn.Type()))
+	query = q.Connection.Dialect.TranslateSQL(query)
+	if err := q.Connection.Store.Select(ids.Interface(), query, args...); err != nil {
+		return errors.WithStack(err)
+	}
+
+	rv := reflect.Indirect(reflect.ValueOf(models))
+	rv.Set(reflect.MakeSlice(rv.Type(), 0, 0))
+	if ids.Elem().Len() == 0 {
+		return nil
+	}
+	in := make([]interface{}, ids.Elem().Len())
+	for i := range in {
+		in[i] = ids.Elem().Index(i).Interface()
+	}
+	return q.Where(fmt.Sprintf("%s.id in (?)", m.TableName()), in...).All(models)
+}
+
+// MoveSubtree moves node, along with all of its descendants, under
+// parent. A nil parent makes node a root. Moving a node under itself or
+// one of its descendants returns an error.
+//
+//	err := c.MoveSubtree(&category, &newParent)
+func (c *Connection) MoveSubtree(node interface{}, parent interface{}) error {
+	m := &Model{Value: node}
+	var parentID interface{}
+	if parent != nil {
+		pm := &Model{Value: parent}
+		parentID = pm.ID()
+		if fmt.Sprint(parentID) == fmt.Sprint(m.ID()) {
+			return errors.Errorf("can not move %s %v under itself", m.TableName(), m.ID())
+		}
+
+		descendants := reflect.New(reflect.SliceOf(reflect.Indirect(reflect.ValueOf(node)).Type()))
+		if err := c.Descendants(node, descendants.Interface()); err != nil {
+			return errors.WithStack(err)
+		}
+		for i := 0; i < descendants.Elem().Len(); i++ {
+			dm := &Model{Value: descendants.Elem().Index(i).Addr().Interface()}
+			if fmt.Sprint(dm.ID()) == fmt.Sprint(parentID) {
+				return errors.Errorf("can not move %s %v under its descendant %v", m.TableName(), m.ID(), parentID)
+			}
+		}
+	}
+
+	stmt := fmt.Sprintf("UPDATE %s SET parent_id = ? WHERE id = ?", m.TableName())
+	if err := c.RawQuery(stmt, parentID, m.ID()).Exec(); err != nil {
+		return errors.WithStack(err)
+	}
+	return c.Reload(node)
+}
@@ -0,0 +1,92 @@
+package pop_test
+
+import (
+	"testing"
+
+	"github.com/markbates/pop"
+	"github.com/markbates/pop/nulls"
+	"github.com/stretchr/testify/require"
+)
+
+// createCategoryTree creates:
+//
+//	root
+//	├── a
+//	│   └── a1
+//	│       └── a1x
+//	└── b
+func createCategoryTree(tx *pop.Connection) map[string]*Category {
+	tree := map[string]*Category{}
+	for _, n := range [][2]string{{"root", ""}, {"a", "root"}, {"b", "root"}, {"a1", "a"}, {"a1x", "a1"}} {
+		c := &Category{Name: n[0]}
+		if p, ok := tree[n[1]]; ok {
+			c.ParentID = nulls.NewInt(p.ID)
+		}
+		if err := tx.Create(c); err != nil {
+			panic(err)
+		}
+		tree[n[0]] = c
+	}
+	return tree
+}
+
+func names(categories Categories) []string {
+	n := []string{}
+	for _, c := range categories {
+		n = append(n, c.Name)
+	}
+	return n
+}
+
+func Test_Descendants(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		tree := createCategoryTree(tx)
+
+		categories := Categories{}
+		r.NoError(tx.Order("name").Descendants(tree["root"], &categories))
+		r.Equal([]string{"a", "a1", "a1x", "b"}, names(categories))
+
+		r.NoError(tx.Order("name").Descendants(tree["a"], &categories))
+		r.Equal([]string{"a1", "a1x"}, names(categories))
+
+		r.NoError(tx.Descendants(tree["b"], &categories))
+		r.Len(categories, 0)
+	})
+}
+
+func Test_Ancestors(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		tree := createCategoryTree(tx)
+
+		categories := Categories{}
+		r.NoError(tx.Order("name").Ancestors(tree["a1x"], &categories))
+		r.Equal([]string{"a", "a1", "root"}, names(categories))
+
+		r.NoError(tx.Ancestors(tree["root"], &categories))
+		r.Len(categories, 0)
+	})
+}
+
+func Test_MoveSubtree(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		tree := createCategoryTree(tx)
+
+		r.NoError(tx.MoveSubtree(tree["a1"], tree["b"]))
+		r.Equal(tree["b"].ID, tree["a1"].ParentID.Int)
+
+		categories := Categories{}
+		r.NoError(tx.Order("name").Descendants(tree["b"], &categories))
+		r.Equal([]string{"a1", "a1x"}, names(categories))
+
+		r.Error(tx.MoveSubtree(tree["root"], tree["a1x"]))
+		r.Error(tx.MoveSubtree(tree["b"], tree["b"]))
+
+		r.NoError(tx.MoveSubtree(tree["b"], nil))
+		r.False(tree["b"].ParentID.Valid)
+		r.NoError(tx.Order("name").Descendants(tree["root"], &categories))
+		r.Equal([]string{"a"}, names(categories))
+	})
+}
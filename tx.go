@@ -1,6 +1,7 @@
 package pop
 
 import (
+	"context"
 	"math/rand"
 	"time"
 
@@ -15,13 +16,25 @@ func init() {
 type Tx struct {
 	ID int
 	*sqlx.Tx
+	// done is called once the transaction is over
+	done func()
+	// span traces the transaction, its statements are children of spanCtx
+	span    Span
+	spanCtx context.Context
+	// store runs the statements of the transactions without a *sqlx.Tx,
+	// those of the memory connections
+	store store
 }
 
 func newTX(db *dB) (*Tx, error) {
+	return newTXContext(context.Background(), db)
+}
+
+func newTXContext(ctx context.Context, db *dB) (*Tx, error) {
 	t := &Tx{
 		ID: rand.Int(),
 	}
-	tx, err := db.Beginx()
+	tx, err := db.BeginTxx(ctx, nil)
 	t.Tx = tx
 	return t, errors.Wrap(err, "could not create new transaction")
 }
@@ -32,6 +45,48 @@ func (tx *Tx) Transaction() (*Tx, error) {
 	return tx, nil
 }
 
+// TransactionContext returns the current transaction, the context it was
+// started with still applies.
+func (tx *Tx) TransactionContext(ctx context.Context) (*Tx, error) {
+	return tx, nil
+}
+
 func (tx *Tx) Close() error {
 	return nil
 }
+
+// Commit commits the transaction.
+func (tx *Tx) Commit() (err error) {
+	defer func() { tx.finish(false, err) }()
+	if tx.store != nil {
+		return tx.store.Commit()
+	}
+	return tx.Tx.Commit()
+}
+
+// Rollback aborts the transaction.
+func (tx *Tx) Rollback() (err error) {
+	defer func() { tx.finish(true, err) }()
+	if tx.store != nil {
+		return tx.store.Rollback()
+	}
+	return tx.Tx.Rollback()
+}
+
+// statements returns the store running the statements of the transaction.
+func (tx *Tx) statements() store {
+	if tx.store != nil {
+		return tx.store
+	}
+	return tx
+}
+
+func (tx *Tx) finish(rollback bool, err error) {
+	if tx.done != nil {
+		tx.done()
+	}
+	if tx.span != nil {
+		tx.span.SetAttribute("pop.rollback", rollback)
+		tx.span.End(err)
+	}
+}
@@ -0,0 +1,183 @@
+package pop
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/markbates/pop/columns"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// upserter is implemented by the dialects able to insert a row, or update
+// the row it conflicts with, in a single statement.
+type upserter interface {
+	// upsert inserts the cols of model, updating the update columns of
+	// the row conflicting on the conflict columns instead.
+	upsert(s store, model *Model, cols, update, conflict []string) error
+}
+
+// Upsert inserts the model, or updates the row it conflicts with on the
+// conflictColumns, "id" by default, which must be the columns of a unique
+// index. The timestamps are set, and the created_at of an updated row is
+// kept:
+//
+//	err := c.Upsert(&user, "email")
+//
+// It is an INSERT ... ON CONFLICT on PostgreSQL, CockroachDB and SQLite,
+// and an INSERT ... ON DUPLICATE KEY UPDATE on MySQL, where any unique
+// index conflicts. Since it is not known whether the row was created or
+// updated, only the BeforeSave and AfterSave callbacks are run. The ID of
+// the model is read back from the conflict columns, unless they hold it.
+func (c *Connection) Upsert(model interface{}, conflictColumns ...string) error {
+	if err := checkModel(model); err != nil {
+		return err
+	}
+	u, ok := c.Dialect.(upserter)
+	if !ok {
+		return errors.Errorf("%s does not support upserts", c.Dialect.Details().Dialect)
+	}
+	if len(conflictColumns) == 0 {
+		conflictColumns = []string{"id"}
+	}
+	return c.timeFunc("Upsert", func() error {
+		sm := &Model{Value: model}
+		if err := sm.beforeSave(c); err != nil {
+			return err
+		}
+
+		cols := columns.ColumnsForStructWithAlias(model, sm.TableName(), sm.As)
+		c.removeAssociationColumns(model, cols)
+		w := cols.Writeable()
+
+		sm.setDiscriminator()
+		sm.touchCreatedAt()
+		sm.touchUpdatedAt()
+
+		if err := c.ensureRangePartition(sm); err != nil {
+			return err
+		}
+
+		onID := false
+		for _, name := range conflictColumns {
+			onID = onID || name == "id"
+		}
+		switch kt := sm.PrimaryKeyType(); kt {
+		case "int", "int64":
+			if fmt.Sprint(sm.ID()) == "0" {
+				if seq := sm.sequence(); seq != "" && !onID {
+					id, err := c.NextSequenceValue(seq)
+					if err != nil {
+						return err
+					}
+					sm.setID(id)
+				} else if onID {
+					return errors.Errorf("%s can not be upserted on its id without an id", sm.TableName())
+				}
+			}
+			if fmt.Sprint(sm.ID()) != "0" {
+				w.Add("id")
+			}
+		case "UUID", "string":
+			if sm.ID() == emptyUUID || fmt.Sprint(sm.ID()) == "" {
+				if onID || kt == "string" {
+					return errors.Errorf("%s can not be upserted on its id without an id", sm.TableName())
+				}
+				id, err := uuid.NewV4()
+				if err != nil {
+					return errors.WithStack(err)
+				}
+				sm.setID(id)
+			}
+			w.Add("id")
+		default:
+			return errors.Errorf("can not use %s as a primary key type!", kt)
+		}
+
+		names := []string{}
+		update := []string{}
+		for name := range w.Cols {
+			names = append(names, name)
+			if name != "id" && name != "created_at" && !containsString(conflictColumns, name) {
+				update = append(update, name)
+			}
+		}
+		sort.Strings(names)
+		sort.Strings(update)
+
+		if err := u.upsert(c.Store, sm, names, update, conflictColumns); err != nil {
+			return err
+		}
+		if !onID {
+			if err := c.readUpsertedID(sm, conflictColumns); err != nil {
+				return err
+			}
+		}
+		return sm.afterSave(c)
+	})
+}
+
+// readUpsertedID sets the ID of the model to the id of the row matching
+// its conflict columns, which is not the ID of the model if the row was
+// updated.
+func (c *Connection) readUpsertedID(m *Model, conflict []string) error {
+	field, err := m.fieldByName("ID")
+	if err != nil {
+		return err
+	}
+	where := make([]string, len(conflict))
+	for i, name := range conflict {
+		where[i] = fmt.Sprintf("%s = :%s", c.Dialect.Quote(name), name)
+	}
+	query := fmt.Sprintf("SELECT id FROM %s WHERE %s", c.Dialect.Quote(m.TableName()), strings.Join(where, " AND "))
+	id := reflect.New(field.Type())
+	if err := namedGet(c.Store, id.Interface(), query, m.Value); err != nil {
+		return errors.Wrapf(err, "could not read the id of the upserted %s", m.TableName())
+	}
+	field.Set(id.Elem())
+	return nil
+}
+
+// onConflictUpsert is the INSERT ... ON CONFLICT of PostgreSQL,
+// CockroachDB and SQLite 3.24+.
+func onConflictUpsert(s store, model *Model, cols, update, conflict []string, q quoter) error {
+	action := "DO NOTHING"
+	if len(update) > 0 {
+		sets := make([]string, len(update))
+		for i, name := range update {
+			sets[i] = fmt.Sprintf("%s = EXCLUDED.%s", q.Quote(name), q.Quote(name))
+		}
+		action = "DO UPDATE SET " + strings.Join(sets, ", ")
+	}
+	query := fmt.Sprintf("%s ON CONFLICT (%s) %s", upsertInsert(model, cols, q), quoteAll(conflict, q), action)
+	_, err := s.NamedExec(query, model.Value)
+	return errors.WithStack(err)
+}
+
+// upsertInsert returns the INSERT statement of the cols of model.
+func upsertInsert(model *Model, cols []string, q quoter) string {
+	values := make([]string, len(cols))
+	for i, name := range cols {
+		values[i] = ":" + name
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", q.Quote(model.TableName()), quoteAll(cols, q), strings.Join(values, ", "))
+}
+
+func quoteAll(names []string, q quoter) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = q.Quote(name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func containsString(xs []string, s string) bool {
+	for _, x := range xs {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package pop_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/markbates/pop"
+	"github.com/stretchr/testify/require"
+)
+
+type Label struct {
+	ID        int       `db:"id"`
+	Name      string    `db:"name"`
+	Color     string    `db:"color"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func Test_Upsert(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *pop.Connection) {
+		l := &Label{Name: "bug", Color: "red"}
+		r.NoError(tx.Upsert(l, "name"))
+		r.NotZero(l.ID)
+		r.False(l.CreatedAt.IsZero())
+
+		created := &Label{}
+		r.NoError(tx.Find(created, l.ID))
+
+		time.Sleep(10 * time.Millisecond)
+		u := &Label{Name: "bug", Color: "orange"}
+		r.NoError(tx.Upsert(u, "name"))
+		r.Equal(l.ID, u.ID)
+
+		found := &Label{}
+		r.NoError(tx.Find(found, l.ID))
+		r.Equal("orange", found.Color)
+		r.Equal(created.CreatedAt.Unix(), found.CreatedAt.Unix())
+		r.True(found.UpdatedAt.After(created.UpdatedAt))
+
+		found.Color = "yellow"
+		r.NoError(tx.Upsert(found))
+		r.NoError(tx.Reload(found))
+		r.Equal("yellow", found.Color)
+
+		count, err := tx.Count(&Label{})
+		r.NoError(err)
+		r.Equal(1, count)
+
+		r.Error(tx.Upsert(&Label{Name: "feature"}))
+	})
+}
@@ -0,0 +1,203 @@
+package pop
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/markbates/validate"
+	"github.com/markbates/validate/validators"
+	"github.com/pkg/errors"
+)
+
+// validationRule is a rule of a validate tag, such as "max=255".
+type validationRule struct {
+	name  string
+	param string
+}
+
+// tagRule checks a field against a rule; it returns the error message, or
+// "" if the field is valid. Only "required" is checked on blank fields.
+type tagRule func(name string, value interface{}, param string) string
+
+var tagRules = map[string]tagRule{
+	"required": func(name string, value interface{}, param string) string {
+		// blank fields never reach the rules, see checkTags.
+		return ""
+	},
+	"email": func(name string, value interface{}, param string) string {
+		return runValidator(&validators.EmailIsPresent{Name: name, Field: fmt.Sprint(value)})
+	},
+	"url": func(name string, value interface{}, param string) string {
+		return runValidator(&validators.URLIsPresent{Name: name, Field: fmt.Sprint(value)})
+	},
+	"min": func(name string, value interface{}, param string) string {
+		n, _ := strconv.ParseFloat(param, 64)
+		if size, unit := measure(value); size < n {
+			return fmt.Sprintf("%s must be at least %s%s.", name, param, unit)
+		}
+		return ""
+	},
+	"max": func(name string, value interface{}, param string) string {
+		n, _ := strconv.ParseFloat(param, 64)
+		if size, unit := measure(value); size > n {
+			return fmt.Sprintf("%s must be at most %s%s.", name, param, unit)
+		}
+		return ""
+	},
+	"unique": func(name string, value interface{}, param string) string {
+		// checked against the database, see validateUnique.
+		return ""
+	},
+	"oneof": func(name string, value interface{}, param string) string {
+		list := strings.Split(param, "|")
+		return runValidator(&validators.StringInclusion{Name: name, Field: fmt.Sprint(value), List: list})
+	},
+}
+
+// tagRuleParams lists the rules taking a parameter.
+var tagRuleParams = map[string]bool{"min": true, "max": true, "oneof": true}
+
+// parseValidateTag parses the rules of a validate tag, such as
+// "required,email,max=255".
+func parseValidateTag(tag string) ([]validationRule, error) {
+	rules := []validationRule{}
+	for _, part := range strings.Split(tag, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		r := validationRule{name: part}
+		if i := strings.Index(part, "="); i != -1 {
+			r.name, r.param = part[:i], part[i+1:]
+		}
+		if _, ok := tagRules[r.name]; !ok {
+			return rules, errors.Errorf("unknown validation rule %q", r.name)
+		}
+		if tagRuleParams[r.name] != (r.param != "") {
+			return rules, errors.Errorf("validation rule %q is malformed", part)
+		}
+		if (r.name == "min" || r.name == "max") && !isNumber(r.param) {
+			return rules, errors.Errorf("validation rule %q needs a number", part)
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// validateTags checks the fields of model against the rules of their
+// validate tags:
+//
+//	type User struct {
+//		ID    int    `db:"id"`
+//		Email string `db:"email" validate:"required,email,max=255"`
+//		Role  string `db:"role" validate:"oneof=admin|member"`
+//	}
+//
+// The rules are "required", "email", "url", "min=n" and "max=n", which
+// bound the length of strings and slices and the value of numbers,
+// "oneof=a|b", and "unique", which checks the column against the other
+// rows of the table. Blank fields are only checked by "required".
+func validateTags(model interface{}) (*validate.Errors, error) {
+	verrs := validate.NewErrors()
+	v := reflect.Indirect(reflect.ValueOf(model))
+	if v.Kind() != reflect.Struct {
+		return verrs, nil
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, ok := f.Tag.Lookup("validate")
+		if !ok || f.PkgPath != "" {
+			continue
+		}
+		rules, err := parseValidateTag(tag)
+		if err != nil {
+			return verrs, errors.Wrapf(err, "invalid validate tag on %s.%s", t.Name(), f.Name)
+		}
+		checkTags(verrs, f.Name, v.Field(i), rules)
+	}
+	return verrs, nil
+}
+
+// checkTags checks a single field against its rules.
+func checkTags(verrs *validate.Errors, name string, fv reflect.Value, rules []validationRule) {
+	value, blank := fieldValue(fv)
+	for _, r := range rules {
+		if blank {
+			if r.name == "required" {
+				verrs.Add(validators.GenerateKey(name), fmt.Sprintf("%s can not be blank.", name))
+			}
+			continue
+		}
+		if msg := tagRules[r.name](name, value, r.param); msg != "" {
+			verrs.Add(validators.GenerateKey(name), msg)
+		}
+	}
+}
+
+// fieldValue returns the value of a field, unwrapped from the nulls types,
+// and whether it is blank.
+func fieldValue(fv reflect.Value) (interface{}, bool) {
+	if fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			return nil, true
+		}
+		fv = fv.Elem()
+	}
+	value := fv.Interface()
+	if reflect.DeepEqual(value, reflect.Zero(fv.Type()).Interface()) {
+		return value, true
+	}
+	if vr, ok := value.(driver.Valuer); ok {
+		dv, err := vr.Value()
+		if err != nil || dv == nil {
+			return nil, true
+		}
+		value = dv
+	}
+	if s, ok := value.(string); ok && strings.TrimSpace(s) == "" {
+		return value, true
+	}
+	return value, false
+}
+
+// measure returns the length of strings and slices, or the value of
+// numbers, along with the unit to report it in.
+func measure(value interface{}) (float64, string) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String())), " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(v.Len()), " items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), ""
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), ""
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), ""
+	}
+	return 0, ""
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
+
+// runValidator runs a single validator of the validate package, and
+// returns its first message.
+func runValidator(v validate.Validator) string {
+	verrs := validate.NewErrors()
+	v.IsValid(verrs)
+	for _, msgs := range verrs.Errors {
+		if len(msgs) > 0 {
+			return msgs[0]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,67 @@
+package pop
+
+import (
+	"testing"
+
+	"github.com/markbates/pop/nulls"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseValidateTag(t *testing.T) {
+	r := require.New(t)
+
+	rules, err := parseValidateTag("required, email,max=255")
+	r.NoError(err)
+	r.Equal([]validationRule{{name: "required"}, {name: "email"}, {name: "max", param: "255"}}, rules)
+
+	_, err = parseValidateTag("required,uniq")
+	r.EqualError(err, `unknown validation rule "uniq"`)
+	_, err = parseValidateTag("max")
+	r.EqualError(err, `validation rule "max" is malformed`)
+	_, err = parseValidateTag("min=a")
+	r.EqualError(err, `validation rule "min=a" needs a number`)
+}
+
+func Test_validateTags(t *testing.T) {
+	r := require.New(t)
+
+	type account struct {
+		Email   string       `validate:"required,email"`
+		Website nulls.String `validate:"url"`
+		Role    string       `validate:"oneof=admin|member"`
+		Age     int          `validate:"min=18,max=130"`
+		Tags    []string     `validate:"max=2"`
+		Nick    *string      `validate:"required"`
+	}
+
+	verrs, err := validateTags(&account{Email: "mark@example.com", Role: "admin", Age: 30, Nick: new(string)})
+	r.NoError(err)
+	r.Equal([]string{"Nick can not be blank."}, verrs.Get("nick"))
+	r.Equal(1, verrs.Count())
+
+	nick := "mark"
+	verrs, err = validateTags(&account{
+		Email:   "mark",
+		Website: nulls.NewString("not a url"),
+		Role:    "owner",
+		Age:     12,
+		Tags:    []string{"a", "b", "c"},
+		Nick:    &nick,
+	})
+	r.NoError(err)
+	r.Len(verrs.Get("email"), 1)
+	r.Len(verrs.Get("website"), 1)
+	r.Equal([]string{"Role is not in the list [admin, member]."}, verrs.Get("role"))
+	r.Equal([]string{"Age must be at least 18."}, verrs.Get("age"))
+	r.Equal([]string{"Tags must be at most 2 items."}, verrs.Get("tags"))
+
+	type broken struct {
+		Name string `validate:"requird"`
+	}
+	_, err = validateTags(&broken{})
+	r.Error(err)
+	r.Error(ValidateModel(&struct {
+		ID   int    `db:"id"`
+		Name string `db:"name" validate:"requird"`
+	}{}))
+}
@@ -0,0 +1,147 @@
+package pop
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/markbates/pop/columns"
+	"github.com/markbates/validate"
+	"github.com/markbates/validate/validators"
+	"github.com/pkg/errors"
+)
+
+// uniqueBatchSize caps the number of values of a single uniqueness query,
+// to stay below the limits of the drivers on bound arguments.
+const uniqueBatchSize = 500
+
+// uniqueField is a field of a model tagged with the "unique" rule.
+type uniqueField struct {
+	name   string
+	index  []int
+	column string
+}
+
+// uniqueFields returns the fields of t tagged with the "unique" rule.
+func uniqueFields(t reflect.Type) []uniqueField {
+	fields := []uniqueField{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, ok := f.Tag.Lookup("validate")
+		if !ok || f.PkgPath != "" {
+			continue
+		}
+		rules, _ := parseValidateTag(tag)
+		for _, r := range rules {
+			if r.name == "unique" {
+				fields = append(fields, uniqueField{name: f.Name, index: f.Index, column: columns.TagsFor(f).Find("db").Value})
+			}
+		}
+	}
+	return fields
+}
+
+// validateUnique checks the fields tagged "unique" of models, which must
+// be of the same type, with a single IN query per column: a value is taken
+// if another row has it, or an earlier model of the batch. The errors are
+// returned by model.
+func (c *Connection) validateUnique(models []interface{}) ([]*validate.Errors, error) {
+	verrs := make([]*validate.Errors, len(models))
+	for i := range verrs {
+		verrs[i] = validate.NewErrors()
+	}
+	if len(models) == 0 {
+		return verrs, nil
+	}
+	m := &Model{Value: models[0]}
+	t := reflect.Indirect(reflect.ValueOf(models[0])).Type()
+	if t.Kind() != reflect.Struct {
+		return verrs, nil
+	}
+	for _, f := range uniqueFields(t) {
+		values := make([]interface{}, len(models))
+		keys := []interface{}{}
+		for i, model := range models {
+			v, blank := fieldValue(reflect.Indirect(reflect.ValueOf(model)).FieldByIndex(f.index))
+			if !blank {
+				values[i] = v
+				keys = append(keys, v)
+			}
+		}
+		taken, err := c.takenValues(m.TableName(), f.column, keys)
+		if err != nil {
+			return verrs, errors.Wrapf(err, "could not check the uniqueness of %s", f.name)
+		}
+
+		seen := map[string]bool{}
+		for i, model := range models {
+			if values[i] == nil {
+				continue
+			}
+			k := fmt.Sprint(values[i])
+			id := fmt.Sprint((&Model{Value: model}).ID())
+			if owner, ok := taken[k]; (ok && owner != id) || seen[k] {
+				verrs[i].Add(validators.GenerateKey(f.name), fmt.Sprintf("%s has already been taken.", f.name))
+			}
+			seen[k] = true
+		}
+	}
+	return verrs, nil
+}
+
+// takenValues returns the values of column already stored in table, with
+// the id of the row holding them.
+func (c *Connection) takenValues(table string, column string, values []interface{}) (map[string]string, error) {
+	taken := map[string]string{}
+	for len(values) > 0 {
+		n := len(values)
+		if n > uniqueBatchSize {
+			n = uniqueBatchSize
+		}
+		rows := []struct {
+			ID    string `db:"id"`
+			Value string `db:"value"`
+		}{}
+		query := fmt.Sprintf("SELECT id, %s AS value FROM %s WHERE %s IN (?)", c.Dialect.Quote(column), c.Dialect.Quote(table), c.Dialect.Quote(column))
+		if err := c.RawQuery(query, values[:n]...).All(&rows); err != nil {
+			return taken, err
+		}
+		for _, r := range rows {
+			taken[r.Value] = r.ID
+		}
+		values = values[n:]
+	}
+	return taken, nil
+}
+
+// validateAndWriteAll validates all of the models of a slice with
+// check, checking their uniqueness at once, and writes them in a
+// transaction if they are all valid. The errors of the models are keyed by
+// their index, as in "3.email".
+func (c *Connection) validateAndWriteAll(models []interface{}, check func(*Model, *Connection) (*validate.Errors, error), write func(tx *Connection, model interface{}) error) (*validate.Errors, error) {
+	verrs := validate.NewErrors()
+	uerrs, err := c.validateUnique(models)
+	if err != nil {
+		return verrs, err
+	}
+	for i, model := range models {
+		merrs, err := check(&Model{Value: model, batched: true}, c)
+		if err != nil {
+			return verrs, err
+		}
+		merrs.Append(uerrs[i])
+		for key, msgs := range merrs.Errors {
+			for _, msg := range msgs {
+				verrs.Add(fmt.Sprintf("%d.%s", i, key), msg)
+			}
+		}
+	}
+	if verrs.HasAny() {
+		return verrs, nil
+	}
+	if c.TX != nil {
+		return verrs, c.writeAll(models, write)
+	}
+	return verrs, c.Transaction(func(tx *Connection) error {
+		return tx.writeAll(models, write)
+	})
+}
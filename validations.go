@@ -19,10 +19,25 @@ func (m *Model) validate(c *Connection) (*validate.Errors, error) {
 			return validate.NewErrors(), errors.WithStack(err)
 		}
 	}
+	verrs, err := validateTags(m.Value)
+	if err != nil {
+		return verrs, err
+	}
+	if !m.batched {
+		uerrs, err := c.validateUnique([]interface{}{m.Value})
+		if err != nil {
+			return verrs, err
+		}
+		verrs.Append(uerrs[0])
+	}
 	if x, ok := m.Value.(validateable); ok {
-		return x.Validate(c)
+		vs, err := x.Validate(c)
+		if vs != nil {
+			verrs.Append(vs)
+		}
+		return verrs, err
 	}
-	return validate.NewErrors(), nil
+	return verrs, nil
 }
 
 type validateCreateable interface {
@@ -0,0 +1,35 @@
+package validators
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/markbates/validate"
+	"github.com/markbates/validate/validators"
+)
+
+// DateRange checks that End comes after Start. Unset times are not
+// compared, so an open ended range is valid; AllowEqual accepts an empty
+// range. The error is reported on End.
+type DateRange struct {
+	StartName  string
+	Start      time.Time
+	EndName    string
+	End        time.Time
+	AllowEqual bool
+	Message    string
+}
+
+// IsValid adds an error if the range is reversed.
+func (v *DateRange) IsValid(errors *validate.Errors) {
+	if v.Start.IsZero() || v.End.IsZero() {
+		return
+	}
+	if v.End.After(v.Start) || (v.AllowEqual && v.End.Equal(v.Start)) {
+		return
+	}
+	if v.Message == "" {
+		v.Message = fmt.Sprintf("%s must be after %s.", v.EndName, v.StartName)
+	}
+	errors.Add(validators.GenerateKey(v.EndName), v.Message)
+}
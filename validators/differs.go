@@ -0,0 +1,30 @@
+package validators
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/markbates/validate"
+	"github.com/markbates/validate/validators"
+)
+
+// Differs checks that Field is not equal to OtherField, such as a new
+// password and the old one. Blank fields are not compared.
+type Differs struct {
+	Name       string
+	Field      interface{}
+	Other      string
+	OtherField interface{}
+	Message    string
+}
+
+// IsValid adds an error if both fields are set and equal.
+func (v *Differs) IsValid(errors *validate.Errors) {
+	if isBlank(v.Field) || isBlank(v.OtherField) || !reflect.DeepEqual(v.Field, v.OtherField) {
+		return
+	}
+	if v.Message == "" {
+		v.Message = fmt.Sprintf("%s must differ from %s.", v.Name, v.Other)
+	}
+	errors.Add(validators.GenerateKey(v.Name), v.Message)
+}
@@ -0,0 +1,48 @@
+package validators
+
+import (
+	"fmt"
+
+	"github.com/markbates/validate"
+	"github.com/markbates/validate/validators"
+)
+
+// RequiredIf checks that Field is set whenever OtherField is.
+type RequiredIf struct {
+	Name       string
+	Field      interface{}
+	Other      string
+	OtherField interface{}
+	Message    string
+}
+
+// IsValid adds an error if OtherField is set but Field is blank.
+func (v *RequiredIf) IsValid(errors *validate.Errors) {
+	if isBlank(v.OtherField) || !isBlank(v.Field) {
+		return
+	}
+	if v.Message == "" {
+		v.Message = fmt.Sprintf("%s can not be blank when %s is set.", v.Name, v.Other)
+	}
+	errors.Add(validators.GenerateKey(v.Name), v.Message)
+}
+
+// RequiredUnless checks that Field is set whenever OtherField is not.
+type RequiredUnless struct {
+	Name       string
+	Field      interface{}
+	Other      string
+	OtherField interface{}
+	Message    string
+}
+
+// IsValid adds an error if both Field and OtherField are blank.
+func (v *RequiredUnless) IsValid(errors *validate.Errors) {
+	if !isBlank(v.OtherField) || !isBlank(v.Field) {
+		return
+	}
+	if v.Message == "" {
+		v.Message = fmt.Sprintf("%s can not be blank when %s is not set.", v.Name, v.Other)
+	}
+	errors.Add(validators.GenerateKey(v.Name), v.Message)
+}
@@ -0,0 +1,127 @@
+// Package validators holds cross-field and conditional validators, to use
+// along with the ones of github.com/markbates/validate/validators in the
+// Validate methods of the models:
+//
+//	func (e *Event) Validate(tx *pop.Connection) (*validate.Errors, error) {
+//		return validators.Validate(tx,
+//			&validators.DateRange{StartName: "StartsAt", Start: e.StartsAt, EndName: "EndsAt", End: e.EndsAt},
+//			&validators.RequiredIf{Name: "Venue", Field: e.Venue, Other: "Tickets", OtherField: e.Tickets},
+//		)
+//	}
+package validators
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"strings"
+
+	"github.com/markbates/pop"
+	"github.com/markbates/validate"
+	"github.com/pkg/errors"
+)
+
+// TxValidator is a validator needing the connection, for instance to look
+// rows up. It is run by `Validate`, which stops at the first database
+// error.
+type TxValidator interface {
+	IsValidTx(tx *pop.Connection, errors *validate.Errors) error
+}
+
+// Validate runs the validators, which are either `validate.Validator` or
+// `TxValidator`, and returns the errors they found. The TxValidators are
+// given tx.
+func Validate(tx *pop.Connection, vs ...interface{}) (*validate.Errors, error) {
+	verrs := validate.NewErrors()
+	for _, v := range vs {
+		if err := run(tx, v, verrs); err != nil {
+			return verrs, err
+		}
+	}
+	return verrs, nil
+}
+
+func run(tx *pop.Connection, v interface{}, verrs *validate.Errors) error {
+	switch t := v.(type) {
+	case TxValidator:
+		return t.IsValidTx(tx, verrs)
+	case validate.Validator:
+		t.IsValid(verrs)
+		return nil
+	}
+	return errors.Errorf("%T is not a validator", v)
+}
+
+// When runs its validators only if the condition holds. The condition gets
+// the connection, so it can query the database; here vv is the validators
+// package of github.com/markbates/validate:
+//
+//	&validators.When{
+//		Condition: func(tx *pop.Connection) (bool, error) {
+//			return tx.Where("id = ? AND requires_vat = ?", o.CountryID, true).Exists(&Country{})
+//		},
+//		Validators: []interface{}{
+//			&vv.StringIsPresent{Name: "VATNumber", Field: o.VATNumber},
+//		},
+//	}
+type When struct {
+	Condition  func(tx *pop.Connection) (bool, error)
+	Validators []interface{}
+}
+
+// IsValidTx implements `TxValidator`.
+func (v *When) IsValidTx(tx *pop.Connection, verrs *validate.Errors) error {
+	ok, err := v.Condition(tx)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if !ok {
+		return nil
+	}
+	for _, vv := range v.Validators {
+		if err := run(tx, vv, verrs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// If runs the validators only if cond is true.
+func If(cond bool, vs ...validate.Validator) validate.Validator {
+	return validate.ValidatorFunc(func(verrs *validate.Errors) {
+		if !cond {
+			return
+		}
+		for _, v := range vs {
+			v.IsValid(verrs)
+		}
+	})
+}
+
+// isBlank returns true for nil, zero values, blank strings and the unset
+// nulls types.
+func isBlank(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return true
+		}
+		return isBlank(rv.Elem().Interface())
+	}
+	if reflect.DeepEqual(v, reflect.Zero(rv.Type()).Interface()) {
+		return true
+	}
+	if vr, ok := v.(driver.Valuer); ok {
+		dv, err := vr.Value()
+		if err != nil || dv == nil {
+			return true
+		}
+		v = dv
+	}
+	if s, ok := v.(string); ok {
+		return strings.TrimSpace(s) == ""
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package validators
+
+import (
+	"testing"
+	"time"
+
+	"github.com/markbates/pop"
+	"github.com/markbates/pop/nulls"
+	"github.com/markbates/validate"
+	"github.com/markbates/validate/validators"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RequiredIf(t *testing.T) {
+	r := require.New(t)
+
+	verrs := validate.Validate(
+		&RequiredIf{Name: "Venue", Field: "", Other: "Tickets", OtherField: 10},
+		&RequiredIf{Name: "Street", Field: nulls.String{}, Other: "City", OtherField: nulls.NewString("Paris")},
+		&RequiredIf{Name: "Notes", Field: "", Other: "Reason", OtherField: "  "},
+		&RequiredUnless{Name: "Phone", Field: "", Other: "Email", OtherField: ""},
+		&RequiredUnless{Name: "Fax", Field: "", Other: "Email", OtherField: "a@b.c"},
+	)
+	r.Equal([]string{"Venue can not be blank when Tickets is set."}, verrs.Get("venue"))
+	r.Len(verrs.Get("street"), 1)
+	r.Empty(verrs.Get("notes"))
+	r.Equal([]string{"Phone can not be blank when Email is not set."}, verrs.Get("phone"))
+	r.Empty(verrs.Get("fax"))
+}
+
+func Test_Differs(t *testing.T) {
+	r := require.New(t)
+
+	verrs := validate.Validate(
+		&Differs{Name: "NewPassword", Field: "secret", Other: "OldPassword", OtherField: "secret"},
+		&Differs{Name: "Backup", Field: "", Other: "Email", OtherField: ""},
+		&Differs{Name: "Email", Field: "a@b.c", Other: "Backup", OtherField: "d@e.f"},
+	)
+	r.Equal([]string{"NewPassword must differ from OldPassword."}, verrs.Get("new_password"))
+	r.Equal(1, verrs.Count())
+}
+
+func Test_DateRange(t *testing.T) {
+	r := require.New(t)
+	now := time.Now()
+
+	verrs := validate.Validate(&DateRange{StartName: "StartsAt", Start: now, EndName: "EndsAt", End: now.Add(-time.Hour)})
+	r.Equal([]string{"EndsAt must be after StartsAt."}, verrs.Get("ends_at"))
+
+	verrs = validate.Validate(
+		&DateRange{StartName: "StartsAt", Start: now, EndName: "EndsAt", End: now.Add(time.Hour)},
+		&DateRange{StartName: "StartsAt", Start: now, EndName: "EndsAt"},
+		&DateRange{StartName: "StartsAt", Start: now, EndName: "EndsAt", End: now, AllowEqual: true},
+	)
+	r.False(verrs.HasAny())
+
+	verrs = validate.Validate(&DateRange{StartName: "StartsAt", Start: now, EndName: "EndsAt", End: now})
+	r.True(verrs.HasAny())
+}
+
+func Test_Validate_When(t *testing.T) {
+	r := require.New(t)
+
+	holds := func(b bool) func(*pop.Connection) (bool, error) {
+		return func(*pop.Connection) (bool, error) { return b, nil }
+	}
+	verrs, err := Validate(nil,
+		&When{Condition: holds(true), Validators: []interface{}{&validators.StringIsPresent{Name: "TaxNumber"}}},
+		&When{Condition: holds(false), Validators: []interface{}{&validators.StringIsPresent{Name: "Siret"}}},
+		If(true, &validators.StringIsPresent{Name: "Name"}),
+		If(false, &validators.StringIsPresent{Name: "Nick"}),
+	)
+	r.NoError(err)
+	r.Len(verrs.Get("tax_number"), 1)
+	r.Len(verrs.Get("name"), 1)
+	r.Equal(2, verrs.Count())
+
+	_, err = Validate(nil, &When{Condition: func(*pop.Connection) (bool, error) {
+		return false, errors.New("boom")
+	}})
+	r.EqualError(err, "boom")
+
+	_, err = Validate(nil, "nope")
+	r.EqualError(err, "string is not a validator")
+}
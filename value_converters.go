@@ -0,0 +1,121 @@
+package pop
+
+import (
+	"database/sql/driver"
+	"sync"
+	"time"
+
+	"github.com/markbates/pop/nulls"
+	uuid "github.com/satori/go.uuid"
+)
+
+// ValueConverter converts an argument before it is sent to the database,
+// to work around the quirks of a driver. It returns the converted value and
+// true, or false to leave the value to the next converters.
+type ValueConverter func(v interface{}) (interface{}, bool)
+
+var valueConverters = map[string][]ValueConverter{}
+var valueConvertersMu = sync.RWMutex{}
+
+// RegisterValueConverter registers a converter for the arguments of all
+// of the statements sent through the connections of a dialect: "postgres",
+// "cockroach", "mysql" or "sqlite3". This includes the fields of the models
+// being created or updated. Converters run in the order they were
+// registered, and the first one converting a value wins.
+//
+//	pop.RegisterValueConverter("mysql", pop.UUIDBytes)
+//	pop.RegisterValueConverter("sqlite3", pop.TimeFormat("2006-01-02 15:04:05"))
+func RegisterValueConverter(dialect string, fn ValueConverter) {
+	valueConvertersMu.Lock()
+	defer valueConvertersMu.Unlock()
+	valueConverters[dialect] = append(valueConverters[dialect], fn)
+}
+
+// valueConverter is implemented by the dialects converting some values
+// themselves, depending on their options.
+type valueConverter interface {
+	convertValue(v interface{}) (interface{}, bool)
+}
+
+// valueConvertersFor returns the converters of d: its own, followed by the
+// registered ones.
+func valueConvertersFor(d dialect) []ValueConverter {
+	fns := []ValueConverter{}
+	if vc, ok := d.(valueConverter); ok {
+		fns = append(fns, vc.convertValue)
+	}
+	valueConvertersMu.RLock()
+	defer valueConvertersMu.RUnlock()
+	return append(fns, valueConverters[d.Details().Dialect]...)
+}
+
+// convertValues runs the converters fns on args. args is returned as is
+// when there is nothing to convert.
+func convertValues(fns []ValueConverter, args []interface{}) []interface{} {
+	if len(fns) == 0 || len(args) == 0 {
+		return args
+	}
+	converted := make([]interface{}, len(args))
+	for i, arg := range args {
+		converted[i] = arg
+		for _, fn := range fns {
+			if v, ok := fn(arg); ok {
+				converted[i] = v
+				break
+			}
+		}
+	}
+	return converted
+}
+
+// driverValue returns the value a driver.Valuer stands for, so converters
+// handle nullable types like their plain counterparts.
+func driverValue(v interface{}) interface{} {
+	if dv, ok := v.(driver.Valuer); ok {
+		if value, err := dv.Value(); err == nil {
+			return value
+		}
+	}
+	return v
+}
+
+// BoolInt sends booleans as 1 and 0, for drivers and columns without a
+// boolean type.
+func BoolInt(v interface{}) (interface{}, bool) {
+	if b, ok := driverValue(v).(bool); ok {
+		if b {
+			return int64(1), true
+		}
+		return int64(0), true
+	}
+	return v, false
+}
+
+// UUIDBytes sends UUIDs as their 16 bytes, for BINARY(16) columns.
+func UUIDBytes(v interface{}) (interface{}, bool) {
+	switch u := v.(type) {
+	case uuid.UUID:
+		return u.Bytes(), true
+	case *uuid.UUID:
+		if u != nil {
+			return u.Bytes(), true
+		}
+	case nulls.UUID:
+		if u.Valid {
+			return u.UUID.Bytes(), true
+		}
+		return nil, true
+	}
+	return v, false
+}
+
+// TimeFormat returns a converter sending times as strings, in the given
+// layout. The times are converted to UTC first.
+func TimeFormat(layout string) ValueConverter {
+	return func(v interface{}) (interface{}, bool) {
+		if t, ok := driverValue(v).(time.Time); ok {
+			return t.UTC().Format(layout), true
+		}
+		return v, false
+	}
+}
@@ -0,0 +1,49 @@
+package pop
+
+import (
+	"testing"
+	"time"
+
+	"github.com/markbates/pop/nulls"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_convertValues(t *testing.T) {
+	r := require.New(t)
+	defer delete(valueConverters, "test")
+	d := &postgresql{ConnectionDetails: &ConnectionDetails{Dialect: "test"}}
+
+	args := []interface{}{true, 1}
+	r.Len(valueConvertersFor(d), 0)
+	r.Equal(args, convertValues(valueConvertersFor(d), args))
+
+	RegisterValueConverter("test", BoolInt)
+	RegisterValueConverter("test", func(v interface{}) (interface{}, bool) {
+		return "second", true
+	})
+	r.Equal([]interface{}{int64(1), "second", int64(0)}, convertValues(valueConvertersFor(d), []interface{}{true, 1, nulls.NewBool(false)}))
+	r.Equal([]interface{}{true, 1}, args)
+}
+
+func Test_ValueConverters(t *testing.T) {
+	r := require.New(t)
+
+	u, err := uuid.NewV4()
+	r.NoError(err)
+	v, ok := UUIDBytes(u)
+	r.True(ok)
+	r.Equal(u.Bytes(), v)
+	_, ok = UUIDBytes(u.String())
+	r.False(ok)
+	v, ok = UUIDBytes(nulls.UUID{})
+	r.True(ok)
+	r.Nil(v)
+
+	tm := time.Date(2018, 3, 12, 10, 30, 0, 0, time.FixedZone("CET", 3600))
+	v, ok = TimeFormat("2006-01-02 15:04:05")(tm)
+	r.True(ok)
+	r.Equal("2018-03-12 09:30:00", v)
+	_, ok = TimeFormat("2006-01-02")(nulls.Time{})
+	r.False(ok)
+}
@@ -0,0 +1,86 @@
+package pop
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"time"
+
+	"github.com/markbates/pop/columns"
+)
+
+var valuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+
+// WithoutAssociations returns a copy of the connection whose Create and
+// Update leave out the nested structs of a model, for the models which
+// only carry them as view data that must never be persisted.
+//
+//	post.Author = author // rendered with the post, never written
+//	err := tx.WithoutAssociations().Create(&post)
+//
+// The fields tagged with `has_many`, `has_one`, `belongs_to` or
+// `many_to_many` are never written by pop: this also covers the untagged
+// struct fields, which are otherwise mapped to a column of their name.
+func (c *Connection) WithoutAssociations() *Connection {
+	cn := *c
+	cn.noAssociations = true
+	if is, ok := c.Store.(*instrumentedStore); ok {
+		cn.Store = newInstrumentedStore(&cn, is.store)
+	}
+	return &cn
+}
+
+// WithoutAssociations is `Connection.WithoutAssociations` for the
+// connection of the query.
+func (q *Query) WithoutAssociations() *Query {
+	cq := *q
+	cq.Connection = q.Connection.WithoutAssociations()
+	return &cq
+}
+
+// removeAssociationColumns removes the columns of the nested structs of
+// model from cols, if the connection ignores them.
+func (c *Connection) removeAssociationColumns(model interface{}, cols columns.Columns) {
+	if !c.noAssociations {
+		return
+	}
+	cols.Remove(nestedStructColumns(reflect.TypeOf(model))...)
+}
+
+// nestedStructColumns returns the columns pop maps the untagged struct,
+// pointer to struct and slice of struct fields of t to.
+func nestedStructColumns(t reflect.Type) []string {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" || f.Anonymous {
+			continue
+		}
+		// TagsFor defaults the db tag of the fields without pop tags.
+		if len(columns.TagsFor(f)) != 1 || f.Tag.Get("db") != "" {
+			continue
+		}
+		if isNestedStruct(f.Type) {
+			names = append(names, columns.ColumnName(f.Name))
+		}
+	}
+	return names
+}
+
+func isNestedStruct(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		if t.Implements(valuerType) {
+			return false
+		}
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == reflect.TypeOf(time.Time{}) {
+		return false
+	}
+	return !t.Implements(valuerType) && !reflect.PtrTo(t).Implements(valuerType)
+}